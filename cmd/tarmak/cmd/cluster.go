@@ -2,9 +2,13 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 
+	"github.com/jetstack/tarmak/pkg/tarmak/cluster/firewall"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils/consts"
 )
@@ -118,6 +122,162 @@ func clusterLogsFlags(fs *flag.FlagSet) {
 		utils.DefaultLogsUntilPlaceholder,
 		"gather logs until date",
 	)
+
+	fs.BoolVarP(
+		&store.Follow,
+		"follow",
+		"f",
+		false,
+		"stream new log entries of all targets until interrupted, only writes a bundle if --path is given",
+	)
+
+	fs.StringSliceVar(
+		&store.Units,
+		"unit",
+		[]string{},
+		"only gather logs of these systemd units, can be repeated",
+	)
+
+	fs.StringVar(
+		&store.Priority,
+		"priority",
+		"",
+		"only gather logs of this priority or a range like err..warning (emerg, alert, crit, err, warning, notice, info, debug)",
+	)
+}
+
+func clusterStatusFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Status
+
+	fs.BoolVarP(
+		&store.Watch,
+		"watch",
+		"w",
+		false,
+		"keep watching instances and print their state changes",
+	)
+}
+
+func clusterRollingUpdateFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.RollingUpdate
+
+	fs.StringVar(
+		&store.Pool,
+		"pool",
+		"",
+		"only replace the instances of this instance pool",
+	)
+
+	fs.IntVar(
+		&store.MaxUnavailable,
+		"max-unavailable",
+		1,
+		"maximum number of instances of a pool replaced at the same time, stateful pools are always replaced one at a time",
+	)
+}
+
+func clusterEtcdBackupFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Etcd.Backup
+
+	clusterFlagEtcdClusters(fs, &store.EtcdClusters)
+}
+
+func clusterEtcdRestoreFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Etcd.Restore
+
+	fs.StringVar(
+		&store.Backup,
+		"backup",
+		"",
+		"name of the backup to restore, defaults to the latest one",
+	)
+
+	clusterFlagEtcdClusters(fs, &store.EtcdClusters)
+
+	fs.BoolVar(
+		&store.AutoApprove,
+		"auto-approve",
+		false,
+		"auto-approve replacing the data of the etcd clusters",
+	)
+}
+
+func clusterInstancesRollbackFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Instances.Rollback
+
+	fs.StringVar(
+		&store.Hash,
+		"hash",
+		"",
+		"hash of the manifest to roll back to, a prefix as shown by cluster status is sufficient",
+	)
+
+	fs.StringSliceVar(
+		&store.Instances,
+		"instances",
+		[]string{},
+		"names of the instances to roll back, defaults to all",
+	)
+}
+
+func clusterUpgradeFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Upgrade
+
+	fs.StringVar(
+		&store.To,
+		"to",
+		"",
+		"Kubernetes version to upgrade to, only the next minor version is supported",
+	)
+}
+
+func clusterFirewallShowFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Firewall.Show
+
+	fs.BoolVar(
+		&store.Graph,
+		"graph",
+		false,
+		"print the flows as a Graphviz graph in the dot language",
+	)
+}
+
+func clusterFirewallExportFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Firewall.Export
+
+	fs.StringVar(
+		&store.Format,
+		"format",
+		firewall.ExportIPTables,
+		fmt.Sprintf("format of the rules, one of: %s", strings.Join(firewall.ExportFormats, "|")),
+	)
+
+	fs.StringSliceVar(
+		&store.Roles,
+		"roles",
+		[]string{},
+		"roles of the instances the rules are exported for",
+	)
+}
+
+func clusterValidateFlags(fs *flag.FlagSet) {
+	store := &globalFlags.Cluster.Validate
+
+	fs.BoolVar(
+		&store.AllEnvironments,
+		"all-environments",
+		false,
+		"validate the configuration of all environments rather than the current one",
+	)
+}
+
+func clusterFlagEtcdClusters(fs *flag.FlagSet, store *[]string) {
+	fs.StringSliceVar(
+		store,
+		"etcd-clusters",
+		[]string{},
+		"etcd clusters to operate on (k8s-main, k8s-events, overlay), defaults to all",
+	)
 }
 
 func clusterFlagDryRun(fs *flag.FlagSet, store *bool) {
@@ -15,8 +15,8 @@ var clusterApplyCmd = &cobra.Command{
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		store := &globalFlags.Cluster.Apply
 
-		if store.DryRun {
-			return errors.New("dry run is not yet supported")
+		if store.DryRun && store.InfrastructureOnly {
+			return errors.New("the flag --dry-run only supports configuration changes, use 'tarmak cluster plan' to show infrastructure changes")
 		}
 
 		if store.InfrastructureOnly && store.ConfigurationOnly {
@@ -0,0 +1,15 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var clusterEtcdCmd = &cobra.Command{
+	Use:   "etcd",
+	Short: "Operations on the etcd clusters of a cluster",
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterEtcdCmd)
+}
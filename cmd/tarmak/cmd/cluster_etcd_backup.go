@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterEtcdBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Take snapshots of the etcd clusters and upload them to the remote state",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).EtcdBackup)
+	},
+}
+
+func init() {
+	clusterEtcdBackupFlags(clusterEtcdBackupCmd.PersistentFlags())
+	clusterEtcdCmd.AddCommand(clusterEtcdBackupCmd)
+}
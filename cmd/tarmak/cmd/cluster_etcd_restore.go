@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterEtcdRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Replace the data of the etcd clusters with the snapshots of a backup, one member at a time",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).EtcdRestore)
+	},
+}
+
+func init() {
+	clusterEtcdRestoreFlags(clusterEtcdRestoreCmd.PersistentFlags())
+	clusterEtcdCmd.AddCommand(clusterEtcdRestoreCmd)
+}
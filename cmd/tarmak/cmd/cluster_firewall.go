@@ -0,0 +1,15 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var clusterFirewallCmd = &cobra.Command{
+	Use:   "firewall",
+	Short: "Operations on the firewall rules of a cluster",
+}
+
+func init() {
+	clusterCmd.AddCommand(clusterFirewallCmd)
+}
@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterFirewallExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print host level iptables or nftables rules enforcing the firewall rules for instances of roles",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).FirewallExport)
+	},
+}
+
+func init() {
+	clusterFirewallExportFlags(clusterFirewallExportCmd.PersistentFlags())
+	clusterFirewallCmd.AddCommand(clusterFirewallExportCmd)
+}
@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterFirewallShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the flows permitted between the roles of the cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).FirewallShow)
+	},
+}
+
+func init() {
+	clusterFirewallShowFlags(clusterFirewallShowCmd.PersistentFlags())
+	clusterFirewallCmd.AddCommand(clusterFirewallShowCmd)
+}
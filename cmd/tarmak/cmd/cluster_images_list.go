@@ -4,12 +4,13 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 )
 
 var clusterImagesListCmd = &cobra.Command{
@@ -19,37 +20,26 @@ var clusterImagesListCmd = &cobra.Command{
 		t := tarmak.New(globalFlags)
 		defer t.Cleanup()
 
-		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 8, 0, '\t', 0)
-
 		images, err := t.Packer().List()
 		t.Perform(err)
 
-		format := "%s\t%s\t%s\t%v\t%s\t%s\n"
-		fmt.Fprintf(
-			w,
-			format,
-			"Image ID",
-			"Base Image",
-			"Location",
-			"Encrypted",
-			"Tags",
-			"Created",
-		)
+		list := &tarmakv1alpha1.ImageList{}
+		list.APIVersion = tarmakv1alpha1.SchemeGroupVersion.String()
+		list.Kind = "ImageList"
 
+		varMaps := make([]map[string]string, 0)
 		for _, image := range images {
-			fmt.Fprintf(
-				w,
-				format,
-				image.Name,
-				image.BaseImage,
-				image.Location,
-				image.Encrypted,
-				image.Annotations,
-				image.CreationTimestamp.Format(time.RFC3339),
-			)
+			list.Items = append(list.Items, *image)
+			varMaps = append(varMaps, map[string]string{
+				"image id":   image.Name,
+				"base image": image.BaseImage,
+				"location":   image.Location,
+				"encrypted":  fmt.Sprintf("%v", image.Encrypted),
+				"tags":       fmt.Sprintf("%v", image.Annotations),
+				"created":    image.CreationTimestamp.Format(time.RFC3339),
+			})
 		}
-		w.Flush()
+		t.Perform(utils.List(os.Stdout, globalFlags.Output, list, []string{"image id", "base image", "location", "encrypted", "tags", "created"}, nil, varMaps))
 	},
 }
 
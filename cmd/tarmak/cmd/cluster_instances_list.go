@@ -3,10 +3,12 @@ package cmd
 
 import (
 	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 )
@@ -22,11 +24,28 @@ var clusterInstancesListCmd = &cobra.Command{
 			logrus.Fatal(err)
 		}
 
+		list := &tarmakv1alpha1.HostList{}
+		list.APIVersion = tarmakv1alpha1.SchemeGroupVersion.String()
+		list.Kind = "HostList"
+
 		varMaps := make([]map[string]string, 0)
 		for _, host := range hosts {
-			varMaps = append(varMaps, host.Parameters())
+			parameters := host.Parameters()
+			parameters["user"] = host.User()
+			parameters["aliases"] = strings.Join(host.Aliases(), ", ")
+			varMaps = append(varMaps, parameters)
+
+			item := tarmakv1alpha1.Host{
+				Hostname:   host.Hostname(),
+				User:       host.User(),
+				Roles:      host.Roles(),
+				Aliases:    host.Aliases(),
+				Parameters: host.Parameters(),
+			}
+			item.Name = host.ID()
+			list.Items = append(list.Items, item)
 		}
-		utils.ListParameters(os.Stdout, []string{"id", "hostname", "roles"}, varMaps)
+		t.Perform(utils.List(os.Stdout, globalFlags.Output, list, []string{"id", "hostname", "roles"}, []string{"user", "aliases"}, varMaps))
 	},
 }
 
@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterInstancesRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Pin instances to an earlier puppet manifest until the next apply",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).Rollback)
+	},
+}
+
+func init() {
+	clusterInstancesRollbackFlags(clusterInstancesRollbackCmd.PersistentFlags())
+	clusterInstancesCmd.AddCommand(clusterInstancesRollbackCmd)
+}
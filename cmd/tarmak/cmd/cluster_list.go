@@ -3,10 +3,12 @@ package cmd
 
 import (
 	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 )
@@ -18,6 +20,10 @@ var clusterListCmd = &cobra.Command{
 		t := tarmak.New(globalFlags)
 		defer t.Cleanup()
 
+		list := &tarmakv1alpha1.ClusterInfoList{}
+		list.APIVersion = tarmakv1alpha1.SchemeGroupVersion.String()
+		list.Kind = "ClusterInfoList"
+
 		varMaps := make([]map[string]string, 0)
 		for _, env := range t.Environments() {
 			for _, cluster := range env.Clusters() {
@@ -26,22 +32,29 @@ var clusterListCmd = &cobra.Command{
 					kubernetesVersion = cluster.Config().Kubernetes.Version
 				}
 
-				current := "false"
-				if t.Cluster().Name() == cluster.Name() && t.Cluster().Environment().Name() == cluster.Environment().Name() {
-					current = "true"
+				current := t.Cluster().Name() == cluster.Name() && t.Cluster().Environment().Name() == cluster.Environment().Name()
+
+				info := tarmakv1alpha1.ClusterInfo{
+					Environment:       cluster.Environment().Name(),
+					Type:              cluster.Type(),
+					KubernetesVersion: kubernetesVersion,
+					PublicZone:        env.Provider().PublicZone(),
+					Current:           current,
 				}
+				info.Name = cluster.Name()
+				list.Items = append(list.Items, info)
 
 				varMaps = append(varMaps, map[string]string{
-					"name":        cluster.Name(),
-					"environment": cluster.Environment().Name(),
-					"version":     kubernetesVersion,
-					"type":        cluster.Type(),
-					"zone":        env.Provider().PublicZone(),
-					"current":     current,
+					"name":        info.Name,
+					"environment": info.Environment,
+					"version":     info.KubernetesVersion,
+					"type":        info.Type,
+					"zone":        info.PublicZone,
+					"current":     strconv.FormatBool(info.Current),
 				})
 			}
 		}
-		utils.ListParameters(os.Stdout, []string{"name", "environment", "zone", "type", "version", "current"}, varMaps)
+		t.Perform(utils.List(os.Stdout, globalFlags.Output, list, []string{"name", "environment", "zone", "type", "version", "current"}, nil, varMaps))
 	},
 }
 
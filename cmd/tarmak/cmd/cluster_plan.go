@@ -10,6 +10,7 @@ import (
 var clusterPlanCmd = &cobra.Command{
 	Use:   "plan",
 	Short: "Plan changes on the currently configured cluster",
+	Long:  "Plan changes on the currently configured cluster, the summary of the plan is printed in the --output format",
 	Run: func(cmd *cobra.Command, args []string) {
 		t := tarmak.New(globalFlags)
 
@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterRollingUpdateCmd = &cobra.Command{
+	Use:   "rolling-update",
+	Short: "Replace the instances of the cluster pool by pool, to roll out new images and launch configurations",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).RollingUpdate)
+	},
+}
+
+func init() {
+	clusterRollingUpdateFlags(clusterRollingUpdateCmd.PersistentFlags())
+	clusterCmd.AddCommand(clusterRollingUpdateCmd)
+}
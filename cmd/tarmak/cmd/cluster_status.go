@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the configuration state of all instances in the cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).Status)
+	},
+}
+
+func init() {
+	clusterStatusFlags(clusterStatusCmd.PersistentFlags())
+	clusterCmd.AddCommand(clusterStatusCmd)
+}
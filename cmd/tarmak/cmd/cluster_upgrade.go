@@ -0,0 +1,23 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade Kubernetes of the cluster by one minor version, an interrupted upgrade is resumed by running it again",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).Upgrade)
+	},
+}
+
+func init() {
+	clusterUpgradeFlags(clusterUpgradeCmd.PersistentFlags())
+	clusterCmd.AddCommand(clusterUpgradeCmd)
+}
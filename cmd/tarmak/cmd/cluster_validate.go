@@ -0,0 +1,24 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var clusterValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration of the current environment or all environments offline",
+	Run: func(cmd *cobra.Command, args []string) {
+		globalFlags.Validate = true
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).ValidateConfig)
+	},
+}
+
+func init() {
+	clusterValidateFlags(clusterValidateCmd.PersistentFlags())
+	clusterCmd.AddCommand(clusterValidateCmd)
+}
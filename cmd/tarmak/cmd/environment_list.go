@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 )
@@ -16,11 +17,20 @@ var environmentListCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		t := tarmak.New(globalFlags)
 		defer t.Cleanup()
+
+		list := &tarmakv1alpha1.EnvironmentList{}
+		list.APIVersion = tarmakv1alpha1.SchemeGroupVersion.String()
+		list.Kind = "EnvironmentList"
+
 		varMaps := make([]map[string]string, 0)
 		for _, env := range t.Environments() {
-			varMaps = append(varMaps, env.Parameters())
+			parameters := env.Parameters()
+			parameters["project"] = env.Config().Project
+			parameters["contact"] = env.Config().Contact
+			varMaps = append(varMaps, parameters)
+			list.Items = append(list.Items, *env.Config())
 		}
-		utils.ListParameters(os.Stdout, []string{"name", "provider", "location"}, varMaps)
+		t.Perform(utils.List(os.Stdout, globalFlags.Output, list, []string{"name", "provider", "location"}, []string{"project", "contact"}, varMaps))
 	},
 }
 
@@ -0,0 +1,15 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var environmentVaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Operations on the vault cluster of the current environment",
+}
+
+func init() {
+	environmentCmd.AddCommand(environmentVaultCmd)
+}
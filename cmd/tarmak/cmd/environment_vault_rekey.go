@@ -0,0 +1,22 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var environmentVaultRekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Replace the vault unseal key and store the new one for the unsealers",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).VaultRekey)
+	},
+}
+
+func init() {
+	environmentVaultCmd.AddCommand(environmentVaultRekeyCmd)
+}
@@ -0,0 +1,22 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/jetstack/tarmak/pkg/tarmak"
+)
+
+var environmentVaultRotateRootTokenCmd = &cobra.Command{
+	Use:   "rotate-root-token",
+	Short: "Replace the vault root token and revoke the old one",
+	Run: func(cmd *cobra.Command, args []string) {
+		t := tarmak.New(globalFlags)
+		defer t.Cleanup()
+		t.CancellationContext().WaitOrCancel(t.NewCmdTarmak(cmd.Flags(), args).VaultRotateRootToken)
+	},
+}
+
+func init() {
+	environmentVaultCmd.AddCommand(environmentVaultRotateRootTokenCmd)
+}
@@ -6,21 +6,52 @@ import (
 
 	"github.com/spf13/cobra"
 
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 )
 
+// providerKeys are the parameters of amazon providers
+var providerKeys = []string{
+	"name",
+	"cloud",
+	"public_zone",
+	"bucket_prefix",
+	"vault_path",
+	"amazon_profile",
+}
+
+// providerWideKeys are the parameters of google and azure providers
+var providerWideKeys = []string{
+	"project",
+	"credentials",
+	"subscription_id",
+	"resource_group",
+	"storage_account_prefix",
+	"client_id",
+	"inventory",
+	"state_path",
+}
+
 var providerListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Print a list of providers",
 	Run: func(cmd *cobra.Command, args []string) {
 		t := tarmak.New(globalFlags)
 		defer t.Cleanup()
+
+		list := &tarmakv1alpha1.ProviderList{}
+		list.APIVersion = tarmakv1alpha1.SchemeGroupVersion.String()
+		list.Kind = "ProviderList"
+		for _, prov := range t.Config().Providers() {
+			list.Items = append(list.Items, *prov)
+		}
+
 		varMaps := make([]map[string]string, 0)
 		for _, prov := range t.Providers() {
 			varMaps = append(varMaps, prov.Parameters())
 		}
-		utils.ListParameters(os.Stdout, []string{"name", "cloud"}, varMaps)
+		t.Perform(utils.List(os.Stdout, globalFlags.Output, list, providerKeys, providerWideKeys, varMaps))
 	},
 }
 
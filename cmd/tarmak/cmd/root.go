@@ -107,6 +107,14 @@ func init() {
 		"override the current cluster set in the config",
 	)
 
+	RootCmd.PersistentFlags().StringVarP(
+		&globalFlags.Output,
+		"output",
+		"o",
+		utils.OutputTable,
+		fmt.Sprintf("output format of commands printing tables, one of: %s", strings.Join(utils.OutputFormats, "|")),
+	)
+
 	RootCmd.PersistentFlags().BoolVar(
 		&globalFlags.IgnoreMissingPublicKeyTags,
 		"ignore-missing-public-key-tags",
@@ -2,9 +2,16 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/jetstack/tarmak/pkg/wing"
+	"github.com/jetstack/tarmak/pkg/wing/signature"
 )
 
 var agentFlags = &wing.Flags{}
@@ -12,16 +19,52 @@ var agentFlags = &wing.Flags{}
 var agentCmd = &cobra.Command{
 	Use:   "agent",
 	Short: "Launch Wing agent",
+	Long:  "Launch Wing agent. Flags not given on the command line are read from WING_<FLAG> environment variables, e.g. WING_RECONVERGE_INTERVAL.",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		return flagsFromEnvironment(cmd.Flags())
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		w := wing.New(agentFlags)
 		w.Must(w.Run(args))
 	},
 }
 
+// flagsFromEnvironment sets flags not given on the command line from the
+// environment, this allows configuring the agent without changing its
+// command line
+func flagsFromEnvironment(fs *pflag.FlagSet) error {
+	var result error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+
+		name := "WING_" + strings.ToUpper(strings.Replace(f.Name, "-", "_", -1))
+		value, ok := os.LookupEnv(name)
+		if !ok || value == "" {
+			return
+		}
+
+		if err := f.Value.Set(value); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid value '%s' of %s: %s", value, name, err))
+		}
+	})
+	return result
+}
+
 func init() {
 	agentCmd.Flags().StringVar(&agentFlags.ClusterName, "cluster-name", "myenv-mycluster", "this specifies the cluster name [environment]-[cluster]")
 	agentCmd.Flags().StringVar(&agentFlags.ServerURL, "server-url", "https://localhost:9443", "this specifies the URL to the wing server")
-	agentCmd.Flags().StringVar(&agentFlags.ManifestURL, "manifest-url", "", "this specifies the URL where the puppet.tar.gz can be found")
+	agentCmd.Flags().StringVar(&agentFlags.ManifestURL, "manifest-url", "", "this specifies the URL where the puppet.tar.gz can be found (s3://, gs://, azblob://, https:// with a <URL>.sha256 checksum, file:// or a local path)")
+	agentCmd.Flags().StringVar(&agentFlags.ManifestPublicKeyPath, "manifest-public-key", signature.DefaultPublicKeyPath, "this specifies the path of the public key manifests need to be signed with, they are not verified if it does not exist")
+	agentCmd.Flags().StringVar(&agentFlags.ManifestCacheDir, "manifest-cache-dir", wing.DefaultManifestCacheDir, "this specifies the directory verified manifests are cached in, caching is disabled if empty")
+	agentCmd.Flags().IntVar(&agentFlags.ManifestCacheSize, "manifest-cache-size", wing.DefaultManifestCacheSize, "this specifies the number of manifests kept in the cache")
+	agentCmd.Flags().StringVar(&agentFlags.MetricsListenAddress, "metrics-listen-address", wing.DefaultMetricsListenAddress, "this specifies the address prometheus metrics are served on, metrics are disabled if empty")
+	agentCmd.Flags().DurationVar(&agentFlags.ConvergeRetryInitialInterval, "converge-retry-initial-interval", wing.DefaultConvergeRetryInitialInterval, "this specifies the interval before retrying a failed puppet apply, it grows exponentially with every retry")
+	agentCmd.Flags().DurationVar(&agentFlags.ConvergeRetryMaxInterval, "converge-retry-max-interval", wing.DefaultConvergeRetryMaxInterval, "this specifies the maximum interval between retries of a failed puppet apply")
+	agentCmd.Flags().DurationVar(&agentFlags.ConvergeRetryMaxElapsedTime, "converge-retry-max-elapsed-time", wing.DefaultConvergeRetryMaxElapsedTime, "this specifies the duration after which a failing converge run stops retrying")
+	agentCmd.Flags().DurationVar(&agentFlags.ReconvergeInterval, "reconverge-interval", 0, "this specifies the interval of scheduled converge runs repairing configuration drift, they are disabled if zero")
+	agentCmd.Flags().DurationVar(&agentFlags.ReconvergeJitter, "reconverge-jitter", 0, "this specifies the maximum random delay added to the reconverge interval")
 	agentCmd.Flags().StringVar(&agentFlags.InstanceName, "instance-name", wing.DefaultInstanceName, "this specifies the instance's name")
 
 	RootCmd.AddCommand(agentCmd)
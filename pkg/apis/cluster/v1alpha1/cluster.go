@@ -67,6 +67,7 @@ type Cluster struct {
 	KubernetesAPI   *KubernetesAPI      `json:"kubernetesAPI,omitempty"`
 	GroupIdentifier string              `json:"groupIdentifier,omitempty"`
 	VaultHelper     *ClusterVaultHelper `json:"vaultHelper,omitempty"`
+	Wing            *ClusterWing        `json:"wing,omitempty"`
 
 	Environment string             `json:"environment,omitempty"`
 	Kubernetes  *ClusterKubernetes `json:"kubernetes,omitempty"`
@@ -108,6 +109,10 @@ type ClusterKubernetes struct {
 	Calico            *ClusterKubernetesCalico            `json:"calico,omitempty"`
 
 	GlobalFeatureGates map[string]bool `json:"globalFeatureGates,omitempty"`
+
+	// Addons deployed into the cluster, an entry replaces the deprecated
+	// settings of the addon like tiller or dashboard
+	Addons []ClusterKubernetesAddon `json:"addons,omitempty"`
 }
 
 type ClusterKubernetesClusterAutoscaler struct {
@@ -232,10 +237,47 @@ type ClusterKubernetesInfluxDB struct {
 	Enabled bool `json:"enabled,omitempty"`
 }
 
+// Configure an addon of the cluster, either one known to Tarmak or a custom
+// addon applied from its manifests by the masters
+type ClusterKubernetesAddon struct {
+	// Name of the addon
+	Name string `json:"name"`
+	// Enable the addon, default: true
+	Enabled *bool `json:"enabled,omitempty"`
+	// Version overrides the default version of a known addon
+	Version string `json:"version,omitempty"`
+	// Image overrides the default image of a known addon
+	Image string `json:"image,omitempty"`
+	// Values set parameters of a known addon
+	Values map[string]string `json:"values,omitempty"`
+	// Manifests of a custom addon
+	Manifests []string `json:"manifests,omitempty"`
+}
+
 type ClusterVaultHelper struct {
 	URL string `json:"url,omitempty"`
 }
 
+// ClusterWing configures the wing agents of the cluster's instances
+type ClusterWing struct {
+	Converge *ClusterWingConverge `json:"converge,omitempty"`
+}
+
+// ClusterWingConverge configures how wing retries failed puppet applies and
+// how often it converges to repair configuration drift
+type ClusterWingConverge struct {
+	// Interval before the first retry of a failed puppet apply, it grows
+	// exponentially up to retryMaxInterval
+	RetryInitialInterval *metav1.Duration `json:"retryInitialInterval,omitempty"`
+	RetryMaxInterval     *metav1.Duration `json:"retryMaxInterval,omitempty"`
+	// A failing converge run stops retrying after this duration
+	RetryMaxElapsedTime *metav1.Duration `json:"retryMaxElapsedTime,omitempty"`
+	// Interval of scheduled converge runs, disabled if not set
+	ReconvergeInterval *metav1.Duration `json:"reconvergeInterval,omitempty"`
+	// Maximum random delay added to the reconverge interval
+	ReconvergeJitter *metav1.Duration `json:"reconvergeJitter,omitempty"`
+}
+
 type ClusterKubernetesScheduler struct {
 	FeatureGates map[string]bool `json:"featureGates,omitempty"`
 }
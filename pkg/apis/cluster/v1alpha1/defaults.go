@@ -146,6 +146,65 @@ func SetDefaults_Cluster(obj *Cluster) {
 			}
 		}
 
+		if loggingSink.Loki != nil {
+			if loggingSink.Loki.TLS == nil {
+				loggingSink.Loki.TLS = boolPointer(true)
+			}
+			if loggingSink.Loki.Port == 0 {
+				if *loggingSink.Loki.TLS {
+					loggingSink.Loki.Port = 443
+				} else {
+					loggingSink.Loki.Port = 3100
+				}
+			}
+			if len(loggingSink.Loki.Labels) == 0 {
+				loggingSink.Loki.Labels = map[string]string{"job": "fluent-bit"}
+			}
+		}
+
+		if loggingSink.SplunkHEC != nil {
+			if loggingSink.SplunkHEC.TLS == nil {
+				loggingSink.SplunkHEC.TLS = boolPointer(true)
+			}
+			if loggingSink.SplunkHEC.Port == 0 {
+				loggingSink.SplunkHEC.Port = 8088
+			}
+		}
+
+		if loggingSink.HTTP != nil {
+			if loggingSink.HTTP.TLS == nil {
+				loggingSink.HTTP.TLS = boolPointer(true)
+			}
+			if loggingSink.HTTP.Port == 0 {
+				if *loggingSink.HTTP.TLS {
+					loggingSink.HTTP.Port = 443
+				} else {
+					loggingSink.HTTP.Port = 80
+				}
+			}
+			if loggingSink.HTTP.URI == "" {
+				loggingSink.HTTP.URI = "/"
+			}
+			if loggingSink.HTTP.Format == "" {
+				loggingSink.HTTP.Format = "json"
+			}
+		}
+
+		if loggingSink.Kafka != nil {
+			if loggingSink.Kafka.TLS == nil {
+				loggingSink.Kafka.TLS = boolPointer(true)
+			}
+		}
+
+		if loggingSink.S3 != nil {
+			if loggingSink.S3.Region == "" {
+				loggingSink.S3.Region = obj.Location
+			}
+			if loggingSink.S3.Prefix == "" {
+				loggingSink.S3.Prefix = "fluent-bit-logs"
+			}
+		}
+
 		if len(loggingSink.Types) == 0 {
 			loggingSink.Types = []LoggingSinkType{"all"}
 		}
@@ -237,6 +296,20 @@ func SetDefaults_InstancePool(obj *InstancePool) {
 	}
 }
 
+func SetDefaults_InstancePoolSpot(obj *InstancePoolSpot) {
+	if obj.SpotPercentage == nil {
+		obj.SpotPercentage = intPointer(100)
+	}
+
+	if obj.AllocationStrategy == "" {
+		obj.AllocationStrategy = SpotAllocationStrategyLowestPrice
+	}
+
+	if obj.InterruptionHandler == nil {
+		obj.InterruptionHandler = boolPointer(true)
+	}
+}
+
 func SetDefaults_ClusterKubernetesAPIServerAmazonAccessLogs(obj *ClusterKubernetesAPIServerAmazonAccessLogs) {
 	if obj.Enabled == nil {
 		if len(obj.Bucket) > 0 {
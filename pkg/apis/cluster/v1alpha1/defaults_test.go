@@ -60,3 +60,62 @@ func TestLoggingDefaults(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggingDefaultsSinks(t *testing.T) {
+
+	cluster := &Cluster{
+		Location: "eu-west-1",
+		LoggingSinks: []*LoggingSink{
+			&LoggingSink{
+				Loki: &LoggingSinkLoki{
+					TLS: boolPointer(false),
+				},
+			},
+			&LoggingSink{
+				SplunkHEC: &LoggingSinkSplunkHEC{},
+			},
+			&LoggingSink{
+				HTTP: &LoggingSinkHTTP{},
+			},
+			&LoggingSink{
+				Kafka: &LoggingSinkKafka{},
+			},
+			&LoggingSink{
+				S3: &LoggingSinkS3{},
+			},
+		},
+	}
+
+	SetDefaults_Cluster(cluster)
+
+	loki := cluster.LoggingSinks[0].Loki
+	if *loki.TLS || loki.Port != 3100 || loki.Labels["job"] != "fluent-bit" {
+		t.Errorf("unexpected loki defaults: %+v", loki)
+	}
+
+	splunk := cluster.LoggingSinks[1].SplunkHEC
+	if !*splunk.TLS || splunk.Port != 8088 {
+		t.Errorf("unexpected splunk defaults: %+v", splunk)
+	}
+
+	http := cluster.LoggingSinks[2].HTTP
+	if !*http.TLS || http.Port != 443 || http.URI != "/" || http.Format != "json" {
+		t.Errorf("unexpected http defaults: %+v", http)
+	}
+
+	kafka := cluster.LoggingSinks[3].Kafka
+	if !*kafka.TLS {
+		t.Errorf("unexpected kafka defaults: %+v", kafka)
+	}
+
+	s3 := cluster.LoggingSinks[4].S3
+	if s3.Region != "eu-west-1" || s3.Prefix != "fluent-bit-logs" {
+		t.Errorf("unexpected s3 defaults: %+v", s3)
+	}
+
+	for index, loggingSink := range cluster.LoggingSinks {
+		if len(loggingSink.Types) != 1 || loggingSink.Types[0] != LoggingSinkTypeAll {
+			t.Errorf("unexpected types for logging sink %d: %+v", index, loggingSink.Types)
+		}
+	}
+}
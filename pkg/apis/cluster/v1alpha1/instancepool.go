@@ -50,7 +50,9 @@ type InstancePool struct {
 	Type              string                  `json:"type,omitempty"`
 	Image             string                  `json:"image,omitempty"`
 	Size              string                  `json:"size,omitempty"`
+	InstanceType      string                  `json:"instanceType,omitempty"` // provider specific instance type, takes precedence over size
 	SpotPrice         string                  `json:"spotPrice,omitempty"`
+	Spot              *InstancePoolSpot       `json:"spot,omitempty"`
 	BootstrapScripts  []string                `json:"bootstrapScripts,omitempty"`
 	Subnets           []*Subnet               `json:"subnets,omitempty"`
 	Firewalls         []*Firewall             `json:"firewalls,omitempty"`
@@ -64,6 +66,39 @@ type InstancePool struct {
 	Amazon *InstancePoolAmazon `json:"amazon,omitempty"`
 }
 
+const (
+	SpotAllocationStrategyLowestPrice       = "lowest-price"
+	SpotAllocationStrategyCapacityOptimized = "capacity-optimized"
+)
+
+// Spot instances of an instance pool, replaces the spotPrice
+type InstancePoolSpot struct {
+	// Additional sizes or instance types the instances can be launched with,
+	// the size of the instance pool is always used
+	Sizes []string `json:"sizes,omitempty"`
+
+	// Number of instances launched on-demand before launching spot instances
+	OnDemandBaseCapacity int `json:"onDemandBaseCapacity,omitempty"`
+
+	// Percentage of instances above the on-demand base capacity launched as
+	// spot instances, defaults to 100
+	SpotPercentage *int `json:"spotPercentage,omitempty"`
+
+	// Strategy allocating spot instances, defaults to lowest-price
+	AllocationStrategy string `json:"allocationStrategy,omitempty"`
+
+	// Number of spot pools with the lowest price instances are allocated
+	// from, defaults to 2
+	InstancePools int `json:"instancePools,omitempty"`
+
+	// Maximum price per hour, defaults to the on-demand price
+	MaxPrice string `json:"maxPrice,omitempty"`
+
+	// Cordon and drain nodes on the termination notice of their spot
+	// instance, defaults to true
+	InterruptionHandler *bool `json:"interruptionHandler,omitempty"`
+}
+
 type InstancePoolKubernetes struct {
 	Version string `json:"version,omitempty"`
 }
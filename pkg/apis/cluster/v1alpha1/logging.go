@@ -26,6 +26,11 @@ type LoggingSinkType string
 
 type LoggingSink struct {
 	Elasticsearch *LoggingSinkElasticsearch `json:"elasticsearch,omitempty"`
+	Loki          *LoggingSinkLoki          `json:"loki,omitempty"`
+	SplunkHEC     *LoggingSinkSplunkHEC     `json:"splunkHEC,omitempty"`
+	HTTP          *LoggingSinkHTTP          `json:"http,omitempty"`
+	Kafka         *LoggingSinkKafka         `json:"kafka,omitempty"`
+	S3            *LoggingSinkS3            `json:"s3,omitempty"`
 	Types         []LoggingSinkType         `json:"types,omitempty"`
 }
 
@@ -37,10 +42,84 @@ type LoggingSinkElasticsearch struct {
 	TLS            *bool          `json:"tls,omitempty"`
 	TLSVerify      bool           `json:"tlsVerify,omitempty"`
 	TLSCA          string         `json:"tlsCA,omitempty"`
+	TLSCert        string         `json:"tlsCert,omitempty"`
+	TLSKey         string         `json:"tlsKey,omitempty"`
 	HTTPBasicAuth  *HTTPBasicAuth `json:"httpBasicAuth,omitempty"`
 	AmazonESProxy  *AmazonESProxy `json:"amazonESProxy,omitempty"`
 }
 
+type LoggingSinkLoki struct {
+	// https://docs.fluentbit.io/manual/pipeline/outputs/loki
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+	// Tenant to push to, if Loki runs in multi-tenant mode
+	TenantID string `json:"tenantID,omitempty"`
+	// Labels added to all log streams, defaults to job=fluent-bit
+	Labels        map[string]string `json:"labels,omitempty"`
+	TLS           *bool             `json:"tls,omitempty"`
+	TLSVerify     bool              `json:"tlsVerify,omitempty"`
+	TLSCA         string            `json:"tlsCA,omitempty"`
+	TLSCert       string            `json:"tlsCert,omitempty"`
+	TLSKey        string            `json:"tlsKey,omitempty"`
+	HTTPBasicAuth *HTTPBasicAuth    `json:"httpBasicAuth,omitempty"`
+}
+
+type LoggingSinkSplunkHEC struct {
+	// https://docs.fluentbit.io/manual/pipeline/outputs/splunk
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+	// HTTP Event Collector token
+	Token     string `json:"token,omitempty"`
+	TLS       *bool  `json:"tls,omitempty"`
+	TLSVerify bool   `json:"tlsVerify,omitempty"`
+	TLSCA     string `json:"tlsCA,omitempty"`
+	TLSCert   string `json:"tlsCert,omitempty"`
+	TLSKey    string `json:"tlsKey,omitempty"`
+}
+
+type LoggingSinkHTTP struct {
+	// https://docs.fluentbit.io/manual/pipeline/outputs/http
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+	URI  string `json:"uri,omitempty"`
+	// One of json, json_lines, json_stream, msgpack or gelf
+	Format        string            `json:"format,omitempty"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	TLS           *bool             `json:"tls,omitempty"`
+	TLSVerify     bool              `json:"tlsVerify,omitempty"`
+	TLSCA         string            `json:"tlsCA,omitempty"`
+	TLSCert       string            `json:"tlsCert,omitempty"`
+	TLSKey        string            `json:"tlsKey,omitempty"`
+	HTTPBasicAuth *HTTPBasicAuth    `json:"httpBasicAuth,omitempty"`
+}
+
+type LoggingSinkKafka struct {
+	// https://docs.fluentbit.io/manual/pipeline/outputs/kafka
+	// List of host:port of the brokers
+	Brokers   []string   `json:"brokers,omitempty"`
+	Topic     string     `json:"topic,omitempty"`
+	TLS       *bool      `json:"tls,omitempty"`
+	TLSVerify bool       `json:"tlsVerify,omitempty"`
+	TLSCA     string     `json:"tlsCA,omitempty"`
+	TLSCert   string     `json:"tlsCert,omitempty"`
+	TLSKey    string     `json:"tlsKey,omitempty"`
+	SASL      *KafkaSASL `json:"sasl,omitempty"`
+}
+
+type LoggingSinkS3 struct {
+	// https://docs.fluentbit.io/manual/pipeline/outputs/s3
+	// The instances need to be allowed to put objects into the bucket, for
+	// example through additionalIAMPolicies
+	Bucket string `json:"bucket,omitempty"`
+	Region string `json:"region,omitempty"`
+	// Prefix of the object keys, defaults to fluent-bit-logs
+	Prefix string `json:"prefix,omitempty"`
+	// Role to assume for uploading
+	RoleARN string `json:"roleARN,omitempty"`
+	// Custom endpoint for S3 compatible storage
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
 type AmazonESProxy struct {
 	Port int `json:"port,omitempty"`
 }
@@ -49,3 +128,10 @@ type HTTPBasicAuth struct {
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
 }
+
+type KafkaSASL struct {
+	// One of PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	Mechanism string `json:"mechanism,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+}
@@ -7,6 +7,7 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -69,6 +70,11 @@ func (in *Cluster) DeepCopyInto(out *Cluster) {
 		*out = new(ClusterVaultHelper)
 		**out = **in
 	}
+	if in.Wing != nil {
+		in, out := &in.Wing, &out.Wing
+		*out = new(ClusterWing)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Kubernetes != nil {
 		in, out := &in.Kubernetes, &out.Kubernetes
 		*out = new(ClusterKubernetes)
@@ -206,6 +212,13 @@ func (in *ClusterKubernetes) DeepCopyInto(out *ClusterKubernetes) {
 			(*out)[key] = val
 		}
 	}
+	if in.Addons != nil {
+		in, out := &in.Addons, &out.Addons
+		*out = make([]ClusterKubernetesAddon, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -340,6 +353,39 @@ func (in *ClusterKubernetesAPIServerOIDC) DeepCopy() *ClusterKubernetesAPIServer
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterKubernetesAddon) DeepCopyInto(out *ClusterKubernetesAddon) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKubernetesAddon.
+func (in *ClusterKubernetesAddon) DeepCopy() *ClusterKubernetesAddon {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterKubernetesAddon)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterKubernetesCalico) DeepCopyInto(out *ClusterKubernetesCalico) {
 	*out = *in
@@ -656,6 +702,68 @@ func (in *ClusterVaultHelper) DeepCopy() *ClusterVaultHelper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWing) DeepCopyInto(out *ClusterWing) {
+	*out = *in
+	if in.Converge != nil {
+		in, out := &in.Converge, &out.Converge
+		*out = new(ClusterWingConverge)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWing.
+func (in *ClusterWing) DeepCopy() *ClusterWing {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWingConverge) DeepCopyInto(out *ClusterWingConverge) {
+	*out = *in
+	if in.RetryInitialInterval != nil {
+		in, out := &in.RetryInitialInterval, &out.RetryInitialInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetryMaxInterval != nil {
+		in, out := &in.RetryMaxInterval, &out.RetryMaxInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetryMaxElapsedTime != nil {
+		in, out := &in.RetryMaxElapsedTime, &out.RetryMaxElapsedTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ReconvergeInterval != nil {
+		in, out := &in.ReconvergeInterval, &out.ReconvergeInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ReconvergeJitter != nil {
+		in, out := &in.ReconvergeJitter, &out.ReconvergeJitter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWingConverge.
+func (in *ClusterWingConverge) DeepCopy() *ClusterWingConverge {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWingConverge)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EgressRule) DeepCopyInto(out *EgressRule) {
 	*out = *in
@@ -751,6 +859,11 @@ func (in *InstancePool) DeepCopyInto(out *InstancePool) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Spot != nil {
+		in, out := &in.Spot, &out.Spot
+		*out = new(InstancePoolSpot)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BootstrapScripts != nil {
 		in, out := &in.BootstrapScripts, &out.BootstrapScripts
 		*out = make([]string, len(*in))
@@ -880,6 +993,37 @@ func (in *InstancePoolKubernetes) DeepCopy() *InstancePoolKubernetes {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstancePoolSpot) DeepCopyInto(out *InstancePoolSpot) {
+	*out = *in
+	if in.Sizes != nil {
+		in, out := &in.Sizes, &out.Sizes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SpotPercentage != nil {
+		in, out := &in.SpotPercentage, &out.SpotPercentage
+		*out = new(int)
+		**out = **in
+	}
+	if in.InterruptionHandler != nil {
+		in, out := &in.InterruptionHandler, &out.InterruptionHandler
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstancePoolSpot.
+func (in *InstancePoolSpot) DeepCopy() *InstancePoolSpot {
+	if in == nil {
+		return nil
+	}
+	out := new(InstancePoolSpot)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InternetGW) DeepCopyInto(out *InternetGW) {
 	*out = *in
@@ -898,6 +1042,22 @@ func (in *InternetGW) DeepCopy() *InternetGW {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KafkaSASL) DeepCopyInto(out *KafkaSASL) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KafkaSASL.
+func (in *KafkaSASL) DeepCopy() *KafkaSASL {
+	if in == nil {
+		return nil
+	}
+	out := new(KafkaSASL)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernetesAPI) DeepCopyInto(out *KubernetesAPI) {
 	*out = *in
@@ -940,6 +1100,31 @@ func (in *LoggingSink) DeepCopyInto(out *LoggingSink) {
 		*out = new(LoggingSinkElasticsearch)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Loki != nil {
+		in, out := &in.Loki, &out.Loki
+		*out = new(LoggingSinkLoki)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SplunkHEC != nil {
+		in, out := &in.SplunkHEC, &out.SplunkHEC
+		*out = new(LoggingSinkSplunkHEC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(LoggingSinkHTTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kafka != nil {
+		in, out := &in.Kafka, &out.Kafka
+		*out = new(LoggingSinkKafka)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(LoggingSinkS3)
+		**out = **in
+	}
 	if in.Types != nil {
 		in, out := &in.Types, &out.Types
 		*out = make([]LoggingSinkType, len(*in))
@@ -989,6 +1174,140 @@ func (in *LoggingSinkElasticsearch) DeepCopy() *LoggingSinkElasticsearch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSinkHTTP) DeepCopyInto(out *LoggingSinkHTTP) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPBasicAuth != nil {
+		in, out := &in.HTTPBasicAuth, &out.HTTPBasicAuth
+		*out = new(HTTPBasicAuth)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSinkHTTP.
+func (in *LoggingSinkHTTP) DeepCopy() *LoggingSinkHTTP {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSinkHTTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSinkKafka) DeepCopyInto(out *LoggingSinkKafka) {
+	*out = *in
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SASL != nil {
+		in, out := &in.SASL, &out.SASL
+		*out = new(KafkaSASL)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSinkKafka.
+func (in *LoggingSinkKafka) DeepCopy() *LoggingSinkKafka {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSinkKafka)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSinkLoki) DeepCopyInto(out *LoggingSinkLoki) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.HTTPBasicAuth != nil {
+		in, out := &in.HTTPBasicAuth, &out.HTTPBasicAuth
+		*out = new(HTTPBasicAuth)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSinkLoki.
+func (in *LoggingSinkLoki) DeepCopy() *LoggingSinkLoki {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSinkLoki)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSinkS3) DeepCopyInto(out *LoggingSinkS3) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSinkS3.
+func (in *LoggingSinkS3) DeepCopy() *LoggingSinkS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSinkS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoggingSinkSplunkHEC) DeepCopyInto(out *LoggingSinkSplunkHEC) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoggingSinkSplunkHEC.
+func (in *LoggingSinkSplunkHEC) DeepCopy() *LoggingSinkSplunkHEC {
+	if in == nil {
+		return nil
+	}
+	out := new(LoggingSinkSplunkHEC)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
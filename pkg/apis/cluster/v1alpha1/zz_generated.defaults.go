@@ -53,6 +53,9 @@ func SetObjectDefaults_ClusterList(in *ClusterList) {
 
 func SetObjectDefaults_InstancePool(in *InstancePool) {
 	SetDefaults_InstancePool(in)
+	if in.Spot != nil {
+		SetDefaults_InstancePoolSpot(in.Spot)
+	}
 	for i := range in.Volumes {
 		a := &in.Volumes[i]
 		SetObjectDefaults_Volume(a)
@@ -34,6 +34,21 @@ func init() {
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&Config{},
+		&ConfigList{},
+		&Provider{},
+		&ProviderList{},
+		&Environment{},
+		&EnvironmentList{},
+		&Image{},
+		&ImageList{},
+		&Host{},
+		&HostList{},
+		&ClusterInfo{},
+		&ClusterInfoList{},
+		&FirewallRule{},
+		&FirewallRuleList{},
+		&ValidationFinding{},
+		&ValidationFindingList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -44,9 +44,10 @@ type Provider struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Amazon *ProviderAmazon `json:"amazon,omitempty"`
-	GCP    *ProviderGCP    `json:"gcp,omitempty"`
-	Azure  *ProviderAzure  `json:"azure,omitempty"`
+	Amazon    *ProviderAmazon    `json:"amazon,omitempty"`
+	GCP       *ProviderGCP       `json:"gcp,omitempty"`
+	Azure     *ProviderAzure     `json:"azure,omitempty"`
+	Baremetal *ProviderBaremetal `json:"baremetal,omitempty"`
 }
 
 type ProviderAmazon struct {
@@ -58,14 +59,37 @@ type ProviderAmazon struct {
 
 	PublicZone         string `json:"publicZone,omitempty"`
 	PublicHostedZoneID string `json:"publicHostedZoneID,omitempty"`
+
+	// EC2 instance types of the instance pool sizes, overriding the defaults
+	// and adding custom sizes
+	InstanceTypes map[string]string `json:"instanceTypes,omitempty"`
 }
 
 type ProviderGCP struct {
-	Project string `json:"project,omitempty"`
+	Project      string `json:"project,omitempty"`
+	Credentials  string `json:"credentials,omitempty"` // path to a service account key file, defaults to application default credentials
+	BucketPrefix string `json:"bucketPrefix,omitempty"`
+
+	PublicZone        string `json:"publicZone,omitempty"`
+	PublicManagedZone string `json:"publicManagedZone,omitempty"` // name of the Cloud DNS managed zone serving PublicZone
 }
 
 type ProviderAzure struct {
-	SubscriptionID string `json:"subscriptionID,omitempty"`
+	SubscriptionID       string `json:"subscriptionID,omitempty"`
+	TenantID             string `json:"tenantID,omitempty"`
+	ClientID             string `json:"clientID,omitempty"`      // service principal to use, defaults to the Azure CLI login; the secret is read from AZURE_CLIENT_SECRET
+	ResourceGroup        string `json:"resourceGroup,omitempty"` // resource group containing all resources of the provider
+	StorageAccountPrefix string `json:"storageAccountPrefix,omitempty"`
+
+	PublicZone              string `json:"publicZone,omitempty"`
+	PublicZoneResourceGroup string `json:"publicZoneResourceGroup,omitempty"` // resource group of the Azure DNS zone serving PublicZone
+}
+
+type ProviderBaremetal struct {
+	InventoryPath string `json:"inventoryPath,omitempty"` // static inventory of existing hosts, their roles and SSH host keys
+	StatePath     string `json:"statePath,omitempty"`     // directory on a local or shared filesystem holding state, puppet manifests and vault unseal keys
+
+	PublicZone string `json:"publicZone,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -120,6 +144,122 @@ type Image struct {
 	Encrypted bool   `json:"encrypted,omitempty"`
 }
 
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ImageList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Items []Image `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Host is an instance of a cluster, its name is the provider's instance ID
+type Host struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Hostname   string            `json:"hostname,omitempty"`
+	User       string            `json:"user,omitempty"`
+	Roles      []string          `json:"roles,omitempty"`
+	Aliases    []string          `json:"aliases,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"` // provider specific parameters of the host
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type HostList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Items []Host `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterInfo summarises a cluster of the configuration
+type ClusterInfo struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Environment       string `json:"environment,omitempty"`
+	Type              string `json:"type,omitempty"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"` // empty for hub clusters
+	PublicZone        string `json:"publicZone,omitempty"`
+	Current           bool   `json:"current,omitempty"` // cluster is the current cluster
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ClusterInfoList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Items []ClusterInfo `json:"items"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// FirewallRule is a flow permitted by the firewall of a cluster
+type FirewallRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Role      string `json:"role,omitempty"`      // role of the instances the rule applies to
+	Direction string `json:"direction,omitempty"` // ingress or egress
+	Peer      string `json:"peer,omitempty"`      // role or CIDR of the other side of the flow
+	Service   string `json:"service,omitempty"`
+	Protocol  string `json:"protocol,omitempty"`
+	Ports     string `json:"ports,omitempty"` // port or port range, all for protocols without ports
+	Comment   string `json:"comment,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type FirewallRuleList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Items []FirewallRule `json:"items"`
+}
+
+const (
+	ValidationSeverityError   = "error"
+	ValidationSeverityWarning = "warning"
+)
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ValidationFinding is an error or a warning of the validation of the
+// configuration
+type ValidationFinding struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Severity string `json:"severity,omitempty"` // error or warning
+	Field    string `json:"field,omitempty"`    // location of the field in the configuration, like clusters[dev-cluster].network.cidr
+	Message  string `json:"message,omitempty"`
+}
+
+// +k8s:openapi-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type ValidationFindingList struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Items []ValidationFinding `json:"items"`
+}
+
 // This represents tarmaks global flags
 type Flags struct {
 	Verbose         bool   `json:"verbose,omitempty"`         // logrus log level to run with
@@ -128,8 +268,12 @@ type Flags struct {
 
 	Initialize bool `json:"initialize,omitempty"` // run tarmak in initialize mode, don't parse config before rnning init
 
+	Validate bool `json:"validate,omitempty"` // run tarmak in validate mode, don't fail on an invalid config before validating it
+
 	CurrentCluster string `json:"currentCluster,omitempty"` // override the current cluster set in tarmak config
 
+	Output string `json:"output,omitempty"` // output format of list and plan commands (table, wide, json or yaml)
+
 	Cluster ClusterFlags `json:"cluster,omitempty"` // cluster specific flags
 
 	Environment EnvironmentFlags `json:"environment,omitempty"` // environment specific flags
@@ -144,12 +288,19 @@ type Flags struct {
 
 // This contains the cluster specifc operation flags
 type ClusterFlags struct {
-	Apply      ClusterApplyFlags      `json:"apply,omitempty"`      // flags for applying clusters
-	Destroy    ClusterDestroyFlags    `json:"destroy,omitempty"`    // flags for destroying clusters
-	Images     ClusterImagesFlags     `json:"images,omitempty"`     // flags for handling images
-	Plan       ClusterPlanFlags       `json:"plan,omitempty"`       // flags for planning clusters
-	Kubeconfig ClusterKubeconfigFlags `json:"kubeconfig,omitempty"` // flags for kubeconfig of clusters
-	Logs       ClusterLogsFlags       `json:"logs,omitempty"`       // flags for getting logs from clusters
+	Apply         ClusterApplyFlags         `json:"apply,omitempty"`         // flags for applying clusters
+	Destroy       ClusterDestroyFlags       `json:"destroy,omitempty"`       // flags for destroying clusters
+	Images        ClusterImagesFlags        `json:"images,omitempty"`        // flags for handling images
+	Plan          ClusterPlanFlags          `json:"plan,omitempty"`          // flags for planning clusters
+	Kubeconfig    ClusterKubeconfigFlags    `json:"kubeconfig,omitempty"`    // flags for kubeconfig of clusters
+	Logs          ClusterLogsFlags          `json:"logs,omitempty"`          // flags for getting logs from clusters
+	Status        ClusterStatusFlags        `json:"status,omitempty"`        // flags for showing the status of clusters
+	RollingUpdate ClusterRollingUpdateFlags `json:"rollingUpdate,omitempty"` // flags for replacing the instances of clusters
+	Etcd          ClusterEtcdFlags          `json:"etcd,omitempty"`          // flags for backing up and restoring etcd of clusters
+	Instances     ClusterInstancesFlags     `json:"instances,omitempty"`     // flags for operations on instances of clusters
+	Upgrade       ClusterUpgradeFlags       `json:"upgrade,omitempty"`       // flags for upgrading Kubernetes of clusters
+	Firewall      ClusterFirewallFlags      `json:"firewall,omitempty"`      // flags for showing and exporting the firewall rules of clusters
+	Validate      ClusterValidateFlags      `json:"validate,omitempty"`      // flags for validating the configuration of clusters
 }
 
 // Contains the cluster plan flags
@@ -202,6 +353,77 @@ type ClusterLogsFlags struct {
 	Path  string `json:"path,omitempty"`  // path to store logs bundle
 	Since string `json:"since,omitempty"` // fetch logs since date
 	Until string `json:"until,omitempty"` // fetch logs until date
+
+	Follow   bool     `json:"follow,omitempty"`   // stream new log entries until interrupted
+	Units    []string `json:"units,omitempty"`    // only show entries of these systemd units
+	Priority string   `json:"priority,omitempty"` // only show entries of this priority or a priority range
+}
+
+// Contains the cluster status flags
+type ClusterStatusFlags struct {
+	Watch bool `json:"watch,omitempty"` // keep printing state changes of instances
+}
+
+// Contains the cluster rolling update flags
+type ClusterRollingUpdateFlags struct {
+	Pool           string `json:"pool,omitempty"`           // only replace instances of this instance pool
+	MaxUnavailable int    `json:"maxUnavailable,omitempty"` // maximum number of instances per pool replaced at once
+}
+
+// Contains the cluster etcd flags
+type ClusterEtcdFlags struct {
+	Backup  ClusterEtcdBackupFlags  `json:"backup,omitempty"`  // flags for backing up etcd
+	Restore ClusterEtcdRestoreFlags `json:"restore,omitempty"` // flags for restoring etcd
+}
+
+// Contains the cluster etcd backup flags
+type ClusterEtcdBackupFlags struct {
+	EtcdClusters []string `json:"etcdClusters,omitempty"` // etcd clusters to snapshot
+}
+
+// Contains the cluster etcd restore flags
+type ClusterEtcdRestoreFlags struct {
+	Backup       string   `json:"backup,omitempty"`       // name of the backup to restore, the latest one if empty
+	EtcdClusters []string `json:"etcdClusters,omitempty"` // etcd clusters to restore
+	AutoApprove  bool     `json:"autoApprove,omitempty"`  // auto-approve replacing the data of the etcd clusters
+}
+
+// Contains the cluster instances flags
+type ClusterInstancesFlags struct {
+	Rollback ClusterInstancesRollbackFlags `json:"rollback,omitempty"` // flags for rolling back the manifest of instances
+}
+
+// Contains the cluster instances rollback flags
+type ClusterInstancesRollbackFlags struct {
+	Hash      string   `json:"hash,omitempty"`      // hash or hash prefix of the manifest to roll back to
+	Instances []string `json:"instances,omitempty"` // names of the instances to roll back, all if empty
+}
+
+// Contains the cluster upgrade flags
+type ClusterUpgradeFlags struct {
+	To string `json:"to,omitempty"` // Kubernetes version to upgrade to
+}
+
+// Contains the cluster firewall flags
+type ClusterFirewallFlags struct {
+	Show   ClusterFirewallShowFlags   `json:"show,omitempty"`   // flags for showing the firewall rules
+	Export ClusterFirewallExportFlags `json:"export,omitempty"` // flags for exporting host level firewall rules
+}
+
+// Contains the cluster firewall show flags
+type ClusterFirewallShowFlags struct {
+	Graph bool `json:"graph,omitempty"` // print the rules as a Graphviz graph
+}
+
+// Contains the cluster firewall export flags
+type ClusterFirewallExportFlags struct {
+	Format string   `json:"format,omitempty"` // iptables or nftables
+	Roles  []string `json:"roles,omitempty"`  // roles of the instance the rules are exported for
+}
+
+// Contains the cluster validate flags
+type ClusterValidateFlags struct {
+	AllEnvironments bool `json:"allEnvironments,omitempty"` // validate all environments rather than the current one
 }
 
 // Contains the environment destroy flags
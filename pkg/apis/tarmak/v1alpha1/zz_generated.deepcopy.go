@@ -43,6 +43,121 @@ func (in *ClusterDestroyFlags) DeepCopy() *ClusterDestroyFlags {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEtcdBackupFlags) DeepCopyInto(out *ClusterEtcdBackupFlags) {
+	*out = *in
+	if in.EtcdClusters != nil {
+		in, out := &in.EtcdClusters, &out.EtcdClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEtcdBackupFlags.
+func (in *ClusterEtcdBackupFlags) DeepCopy() *ClusterEtcdBackupFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEtcdBackupFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEtcdFlags) DeepCopyInto(out *ClusterEtcdFlags) {
+	*out = *in
+	in.Backup.DeepCopyInto(&out.Backup)
+	in.Restore.DeepCopyInto(&out.Restore)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEtcdFlags.
+func (in *ClusterEtcdFlags) DeepCopy() *ClusterEtcdFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEtcdFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterEtcdRestoreFlags) DeepCopyInto(out *ClusterEtcdRestoreFlags) {
+	*out = *in
+	if in.EtcdClusters != nil {
+		in, out := &in.EtcdClusters, &out.EtcdClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterEtcdRestoreFlags.
+func (in *ClusterEtcdRestoreFlags) DeepCopy() *ClusterEtcdRestoreFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterEtcdRestoreFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFirewallExportFlags) DeepCopyInto(out *ClusterFirewallExportFlags) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFirewallExportFlags.
+func (in *ClusterFirewallExportFlags) DeepCopy() *ClusterFirewallExportFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterFirewallExportFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFirewallFlags) DeepCopyInto(out *ClusterFirewallFlags) {
+	*out = *in
+	out.Show = in.Show
+	in.Export.DeepCopyInto(&out.Export)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFirewallFlags.
+func (in *ClusterFirewallFlags) DeepCopy() *ClusterFirewallFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterFirewallFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFirewallShowFlags) DeepCopyInto(out *ClusterFirewallShowFlags) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFirewallShowFlags.
+func (in *ClusterFirewallShowFlags) DeepCopy() *ClusterFirewallShowFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterFirewallShowFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterFlags) DeepCopyInto(out *ClusterFlags) {
 	*out = *in
@@ -51,7 +166,14 @@ func (in *ClusterFlags) DeepCopyInto(out *ClusterFlags) {
 	out.Images = in.Images
 	out.Plan = in.Plan
 	out.Kubeconfig = in.Kubeconfig
-	out.Logs = in.Logs
+	in.Logs.DeepCopyInto(&out.Logs)
+	out.Status = in.Status
+	out.RollingUpdate = in.RollingUpdate
+	in.Etcd.DeepCopyInto(&out.Etcd)
+	in.Instances.DeepCopyInto(&out.Instances)
+	out.Upgrade = in.Upgrade
+	in.Firewall.DeepCopyInto(&out.Firewall)
+	out.Validate = in.Validate
 	return
 }
 
@@ -98,6 +220,103 @@ func (in *ClusterImagesFlags) DeepCopy() *ClusterImagesFlags {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInfo) DeepCopyInto(out *ClusterInfo) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInfo.
+func (in *ClusterInfo) DeepCopy() *ClusterInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterInfo) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInfoList) DeepCopyInto(out *ClusterInfoList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInfoList.
+func (in *ClusterInfoList) DeepCopy() *ClusterInfoList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInfoList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterInfoList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInstancesFlags) DeepCopyInto(out *ClusterInstancesFlags) {
+	*out = *in
+	in.Rollback.DeepCopyInto(&out.Rollback)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInstancesFlags.
+func (in *ClusterInstancesFlags) DeepCopy() *ClusterInstancesFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInstancesFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterInstancesRollbackFlags) DeepCopyInto(out *ClusterInstancesRollbackFlags) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterInstancesRollbackFlags.
+func (in *ClusterInstancesRollbackFlags) DeepCopy() *ClusterInstancesRollbackFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterInstancesRollbackFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterKubeconfigFlags) DeepCopyInto(out *ClusterKubeconfigFlags) {
 	*out = *in
@@ -117,6 +336,11 @@ func (in *ClusterKubeconfigFlags) DeepCopy() *ClusterKubeconfigFlags {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterLogsFlags) DeepCopyInto(out *ClusterLogsFlags) {
 	*out = *in
+	if in.Units != nil {
+		in, out := &in.Units, &out.Units
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -146,6 +370,70 @@ func (in *ClusterPlanFlags) DeepCopy() *ClusterPlanFlags {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterRollingUpdateFlags) DeepCopyInto(out *ClusterRollingUpdateFlags) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRollingUpdateFlags.
+func (in *ClusterRollingUpdateFlags) DeepCopy() *ClusterRollingUpdateFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterRollingUpdateFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatusFlags) DeepCopyInto(out *ClusterStatusFlags) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatusFlags.
+func (in *ClusterStatusFlags) DeepCopy() *ClusterStatusFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatusFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterUpgradeFlags) DeepCopyInto(out *ClusterUpgradeFlags) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUpgradeFlags.
+func (in *ClusterUpgradeFlags) DeepCopy() *ClusterUpgradeFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterUpgradeFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterValidateFlags) DeepCopyInto(out *ClusterValidateFlags) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterValidateFlags.
+func (in *ClusterValidateFlags) DeepCopy() *ClusterValidateFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterValidateFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Config) DeepCopyInto(out *Config) {
 	*out = *in
@@ -328,10 +616,69 @@ func (in *EnvironmentList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirewallRule) DeepCopyInto(out *FirewallRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirewallRule.
+func (in *FirewallRule) DeepCopy() *FirewallRule {
+	if in == nil {
+		return nil
+	}
+	out := new(FirewallRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FirewallRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FirewallRuleList) DeepCopyInto(out *FirewallRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FirewallRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FirewallRuleList.
+func (in *FirewallRuleList) DeepCopy() *FirewallRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(FirewallRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FirewallRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Flags) DeepCopyInto(out *Flags) {
 	*out = *in
-	out.Cluster = in.Cluster
+	in.Cluster.DeepCopyInto(&out.Cluster)
 	out.Environment = in.Environment
 	return
 }
@@ -346,6 +693,82 @@ func (in *Flags) DeepCopy() *Flags {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Host) DeepCopyInto(out *Host) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Host.
+func (in *Host) DeepCopy() *Host {
+	if in == nil {
+		return nil
+	}
+	out := new(Host)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Host) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostList) DeepCopyInto(out *HostList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Host, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostList.
+func (in *HostList) DeepCopy() *HostList {
+	if in == nil {
+		return nil
+	}
+	out := new(HostList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HostList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Image) DeepCopyInto(out *Image) {
 	*out = *in
@@ -372,6 +795,39 @@ func (in *Image) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageList) DeepCopyInto(out *ImageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Image, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageList.
+func (in *ImageList) DeepCopy() *ImageList {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Provider) DeepCopyInto(out *Provider) {
 	*out = *in
@@ -392,6 +848,11 @@ func (in *Provider) DeepCopyInto(out *Provider) {
 		*out = new(ProviderAzure)
 		**out = **in
 	}
+	if in.Baremetal != nil {
+		in, out := &in.Baremetal, &out.Baremetal
+		*out = new(ProviderBaremetal)
+		**out = **in
+	}
 	return
 }
 
@@ -421,6 +882,13 @@ func (in *ProviderAmazon) DeepCopyInto(out *ProviderAmazon) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.InstanceTypes != nil {
+		in, out := &in.InstanceTypes, &out.InstanceTypes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -450,6 +918,22 @@ func (in *ProviderAzure) DeepCopy() *ProviderAzure {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProviderBaremetal) DeepCopyInto(out *ProviderBaremetal) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProviderBaremetal.
+func (in *ProviderBaremetal) DeepCopy() *ProviderBaremetal {
+	if in == nil {
+		return nil
+	}
+	out := new(ProviderBaremetal)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderGCP) DeepCopyInto(out *ProviderGCP) {
 	*out = *in
@@ -498,3 +982,62 @@ func (in *ProviderList) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationFinding) DeepCopyInto(out *ValidationFinding) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationFinding.
+func (in *ValidationFinding) DeepCopy() *ValidationFinding {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationFinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ValidationFinding) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidationFindingList) DeepCopyInto(out *ValidationFindingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ValidationFinding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidationFindingList.
+func (in *ValidationFindingList) DeepCopy() *ValidationFindingList {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidationFindingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ValidationFindingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
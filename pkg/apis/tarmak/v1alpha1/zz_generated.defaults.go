@@ -32,6 +32,9 @@ func SetObjectDefaults_Config(in *Config) {
 		for j := range a.InstancePools {
 			b := &a.InstancePools[j]
 			clusterv1alpha1.SetDefaults_InstancePool(b)
+			if b.Spot != nil {
+				clusterv1alpha1.SetDefaults_InstancePoolSpot(b.Spot)
+			}
 			for k := range b.Volumes {
 				c := &b.Volumes[k]
 				clusterv1alpha1.SetDefaults_Volume(c)
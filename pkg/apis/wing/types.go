@@ -25,7 +25,7 @@ type InstanceSpec struct {
 	DryRun   *InstanceSpecManifest
 }
 
-//  InstaceSpecManifest defines location and hash for a specific manifest
+// InstaceSpecManifest defines location and hash for a specific manifest
 type InstanceSpecManifest struct {
 	Path             string
 	Hash             string
@@ -38,7 +38,7 @@ type InstanceStatus struct {
 	DryRun   *InstanceStatusManifest
 }
 
-//  InstaceSpecManifest defines the state and hash of a run manifest
+// InstaceSpecManifest defines the state and hash of a run manifest
 type InstanceManifestState string
 type InstanceStatusManifest struct {
 	State               InstanceManifestState
@@ -46,6 +46,17 @@ type InstanceStatusManifest struct {
 	LastUpdateTimestamp metav1.Time
 	Messages            []string
 	ExitCodes           []int
+	Report              *InstanceManifestReport
+}
+
+// InstanceManifestReport summarises the report of a puppet run
+type InstanceManifestReport struct {
+	ResourcesTotal     int
+	ResourcesChanged   int
+	ResourcesFailed    int
+	ResourcesOutOfSync int
+	FailedResources    []string
+	Timings            map[string]metav1.Duration
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -7,4 +7,15 @@ const (
 	InstanceManifestStateConverging = InstanceManifestState("converging")
 	InstanceManifestStateConverged  = InstanceManifestState("converged")
 	InstanceManifestStateError      = InstanceManifestState("error")
+
+	// the manifest is unsigned or not signed by the environment's key
+	InstanceManifestStateVerificationFailed = InstanceManifestState("verificationFailed")
 )
+
+// InstanceManifestStates lists all states a manifest run can be in
+var InstanceManifestStates = []InstanceManifestState{
+	InstanceManifestStateConverging,
+	InstanceManifestStateConverged,
+	InstanceManifestStateError,
+	InstanceManifestStateVerificationFailed,
+}
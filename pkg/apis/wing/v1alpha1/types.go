@@ -25,7 +25,7 @@ type InstanceSpec struct {
 	DryRun   *InstanceSpecManifest `json:"dryRun,omitempty"`
 }
 
-//  InstaceSpecManifest defines location and hash for a specific manifest
+// InstaceSpecManifest defines location and hash for a specific manifest
 type InstanceSpecManifest struct {
 	Path             string      `json:"path,omitempty"`             // PATH to manifests (tar.gz)
 	Hash             string      `json:"hash,omitempty"`             // hash of manifests, prefixed with type (eg: sha256:xyz)
@@ -38,13 +38,24 @@ type InstanceStatus struct {
 	DryRun   *InstanceStatusManifest `json:"dryRun,omitempty"`
 }
 
-//  InstaceSpecManifest defines the state and hash of a run manifest
+// InstaceSpecManifest defines the state and hash of a run manifest
 type InstanceStatusManifest struct {
-	State               InstanceManifestState `json:"state,omitempty"`
-	Hash                string                `json:"hash,omitempty"`                // hash of manifests, prefixed with type (eg: sha256:xyz)
-	LastUpdateTimestamp metav1.Time           `json:"lastUpdateTimestamp,omitempty"` // timestamp when a converge was requested
-	Messages            []string              `json:"messages,omitempty"`            // contains output of the retries
-	ExitCodes           []int                 `json:"exitCodes,omitempty"`           // return code of the retries
+	State               InstanceManifestState   `json:"state,omitempty"`
+	Hash                string                  `json:"hash,omitempty"`                // hash of manifests, prefixed with type (eg: sha256:xyz)
+	LastUpdateTimestamp metav1.Time             `json:"lastUpdateTimestamp,omitempty"` // timestamp when a converge was requested
+	Messages            []string                `json:"messages,omitempty"`            // contains output of the retries
+	ExitCodes           []int                   `json:"exitCodes,omitempty"`           // return code of the retries
+	Report              *InstanceManifestReport `json:"report,omitempty"`              // summary of the last puppet run
+}
+
+// InstanceManifestReport summarises the report of a puppet run
+type InstanceManifestReport struct {
+	ResourcesTotal     int                        `json:"resourcesTotal,omitempty"`
+	ResourcesChanged   int                        `json:"resourcesChanged,omitempty"`
+	ResourcesFailed    int                        `json:"resourcesFailed,omitempty"`
+	ResourcesOutOfSync int                        `json:"resourcesOutOfSync,omitempty"`
+	FailedResources    []string                   `json:"failedResources,omitempty"` // titles of failed resources (eg: File[/etc/motd])
+	Timings            map[string]metav1.Duration `json:"timings,omitempty"`         // time spent per resource type, config_retrieval and total
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
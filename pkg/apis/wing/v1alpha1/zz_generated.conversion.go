@@ -10,6 +10,7 @@ import (
 	unsafe "unsafe"
 
 	wing "github.com/jetstack/tarmak/pkg/apis/wing"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -41,6 +42,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*InstanceManifestReport)(nil), (*wing.InstanceManifestReport)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_InstanceManifestReport_To_wing_InstanceManifestReport(a.(*InstanceManifestReport), b.(*wing.InstanceManifestReport), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*wing.InstanceManifestReport)(nil), (*InstanceManifestReport)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_wing_InstanceManifestReport_To_v1alpha1_InstanceManifestReport(a.(*wing.InstanceManifestReport), b.(*InstanceManifestReport), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*InstanceSpec)(nil), (*wing.InstanceSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_InstanceSpec_To_wing_InstanceSpec(a.(*InstanceSpec), b.(*wing.InstanceSpec), scope)
 	}); err != nil {
@@ -134,6 +145,36 @@ func Convert_wing_InstanceList_To_v1alpha1_InstanceList(in *wing.InstanceList, o
 	return autoConvert_wing_InstanceList_To_v1alpha1_InstanceList(in, out, s)
 }
 
+func autoConvert_v1alpha1_InstanceManifestReport_To_wing_InstanceManifestReport(in *InstanceManifestReport, out *wing.InstanceManifestReport, s conversion.Scope) error {
+	out.ResourcesTotal = in.ResourcesTotal
+	out.ResourcesChanged = in.ResourcesChanged
+	out.ResourcesFailed = in.ResourcesFailed
+	out.ResourcesOutOfSync = in.ResourcesOutOfSync
+	out.FailedResources = *(*[]string)(unsafe.Pointer(&in.FailedResources))
+	out.Timings = *(*map[string]v1.Duration)(unsafe.Pointer(&in.Timings))
+	return nil
+}
+
+// Convert_v1alpha1_InstanceManifestReport_To_wing_InstanceManifestReport is an autogenerated conversion function.
+func Convert_v1alpha1_InstanceManifestReport_To_wing_InstanceManifestReport(in *InstanceManifestReport, out *wing.InstanceManifestReport, s conversion.Scope) error {
+	return autoConvert_v1alpha1_InstanceManifestReport_To_wing_InstanceManifestReport(in, out, s)
+}
+
+func autoConvert_wing_InstanceManifestReport_To_v1alpha1_InstanceManifestReport(in *wing.InstanceManifestReport, out *InstanceManifestReport, s conversion.Scope) error {
+	out.ResourcesTotal = in.ResourcesTotal
+	out.ResourcesChanged = in.ResourcesChanged
+	out.ResourcesFailed = in.ResourcesFailed
+	out.ResourcesOutOfSync = in.ResourcesOutOfSync
+	out.FailedResources = *(*[]string)(unsafe.Pointer(&in.FailedResources))
+	out.Timings = *(*map[string]v1.Duration)(unsafe.Pointer(&in.Timings))
+	return nil
+}
+
+// Convert_wing_InstanceManifestReport_To_v1alpha1_InstanceManifestReport is an autogenerated conversion function.
+func Convert_wing_InstanceManifestReport_To_v1alpha1_InstanceManifestReport(in *wing.InstanceManifestReport, out *InstanceManifestReport, s conversion.Scope) error {
+	return autoConvert_wing_InstanceManifestReport_To_v1alpha1_InstanceManifestReport(in, out, s)
+}
+
 func autoConvert_v1alpha1_InstanceSpec_To_wing_InstanceSpec(in *InstanceSpec, out *wing.InstanceSpec, s conversion.Scope) error {
 	out.Converge = (*wing.InstanceSpecManifest)(unsafe.Pointer(in.Converge))
 	out.DryRun = (*wing.InstanceSpecManifest)(unsafe.Pointer(in.DryRun))
@@ -208,6 +249,7 @@ func autoConvert_v1alpha1_InstanceStatusManifest_To_wing_InstanceStatusManifest(
 	out.LastUpdateTimestamp = in.LastUpdateTimestamp
 	out.Messages = *(*[]string)(unsafe.Pointer(&in.Messages))
 	out.ExitCodes = *(*[]int)(unsafe.Pointer(&in.ExitCodes))
+	out.Report = (*wing.InstanceManifestReport)(unsafe.Pointer(in.Report))
 	return nil
 }
 
@@ -222,6 +264,7 @@ func autoConvert_wing_InstanceStatusManifest_To_v1alpha1_InstanceStatusManifest(
 	out.LastUpdateTimestamp = in.LastUpdateTimestamp
 	out.Messages = *(*[]string)(unsafe.Pointer(&in.Messages))
 	out.ExitCodes = *(*[]int)(unsafe.Pointer(&in.ExitCodes))
+	out.Report = (*InstanceManifestReport)(unsafe.Pointer(in.Report))
 	return nil
 }
 
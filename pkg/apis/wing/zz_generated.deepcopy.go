@@ -7,6 +7,7 @@
 package wing
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -79,6 +80,34 @@ func (in *InstanceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceManifestReport) DeepCopyInto(out *InstanceManifestReport) {
+	*out = *in
+	if in.FailedResources != nil {
+		in, out := &in.FailedResources, &out.FailedResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timings != nil {
+		in, out := &in.Timings, &out.Timings
+		*out = make(map[string]v1.Duration, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceManifestReport.
+func (in *InstanceManifestReport) DeepCopy() *InstanceManifestReport {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceManifestReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceSpec) DeepCopyInto(out *InstanceSpec) {
 	*out = *in
@@ -162,6 +191,11 @@ func (in *InstanceStatusManifest) DeepCopyInto(out *InstanceStatusManifest) {
 		*out = make([]int, len(*in))
 		copy(*out, *in)
 	}
+	if in.Report != nil {
+		in, out := &in.Report, &out.Report
+		*out = new(InstanceManifestReport)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -0,0 +1,723 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+// Package arm is a minimal client for the Azure Resource Manager and Key Vault
+// REST APIs
+package arm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	managementEndpoint = "https://management.azure.com"
+
+	apiVersionSubscriptions = "2016-06-01"
+	apiVersionResources     = "2018-05-01"
+	apiVersionCompute       = "2018-06-01"
+	apiVersionSKUs          = "2017-09-01"
+	apiVersionNetwork       = "2018-08-01"
+	apiVersionDNS           = "2018-03-01-preview"
+	apiVersionStorage       = "2018-02-01"
+)
+
+type VirtualMachine struct {
+	ID                string
+	Name              string
+	Location          string
+	ProvisioningState string
+	PrivateIP         string
+	PublicIP          string
+	Tags              map[string]string
+}
+
+type Image struct {
+	ID       string
+	Name     string
+	Location string
+	Tags     map[string]string
+}
+
+// Client queries the REST API of the Azure Resource Manager and Key Vault
+type Client struct {
+	subscriptionID string
+	authorizer     Authorizer
+	client         *http.Client
+
+	pollInterval time.Duration
+}
+
+func New(subscriptionID string, authorizer Authorizer) *Client {
+	return &Client{
+		subscriptionID: subscriptionID,
+		authorizer:     authorizer,
+		client:         http.DefaultClient,
+		pollInterval:   5 * time.Second,
+	}
+}
+
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("azure api returned %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// IsNotFound returns true if the API could not find the requested resource
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+func (c *Client) do(method, resource, rawURL string, in interface{}, out interface{}) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.authorizer.Token(resource)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var errResp struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: string(data)}
+		if err := json.Unmarshal(data, &errResp); err == nil && errResp.Error.Message != "" {
+			apiErr.Code = errResp.Error.Code
+			apiErr.Message = errResp.Error.Message
+		}
+		return resp, apiErr
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *Client) managementURL(path, apiVersion string) string {
+	return fmt.Sprintf("%s%s?api-version=%s", managementEndpoint, path, url.QueryEscape(apiVersion))
+}
+
+func (c *Client) subscriptionPath(format string, args ...interface{}) string {
+	return fmt.Sprintf("/subscriptions/%s%s", c.subscriptionID, fmt.Sprintf(format, args...))
+}
+
+// list follows the nextLink of a list call and hands every page to the
+// decode function
+func (c *Client) list(rawURL string, decode func(data []byte) (nextLink string, err error)) error {
+	for rawURL != "" {
+		var page json.RawMessage
+		if _, err := c.do(http.MethodGet, ResourceManagement, rawURL, nil, &page); err != nil {
+			return err
+		}
+
+		next, err := decode(page)
+		if err != nil {
+			return err
+		}
+		rawURL = next
+	}
+	return nil
+}
+
+func matchTags(tags, selector map[string]string) bool {
+	for key, value := range selector {
+		if tags[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) Locations() ([]string, error) {
+	var page struct {
+		Value []struct {
+			Name string `json:"name"`
+		} `json:"value"`
+	}
+
+	if _, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(c.subscriptionPath("/locations"), apiVersionSubscriptions), nil, &page); err != nil {
+		return nil, err
+	}
+
+	locations := make([]string, len(page.Value))
+	for pos, location := range page.Value {
+		locations[pos] = location.Name
+	}
+
+	return locations, nil
+}
+
+type sku struct {
+	ResourceType string   `json:"resourceType"`
+	Name         string   `json:"name"`
+	Locations    []string `json:"locations"`
+	LocationInfo []struct {
+		Location string   `json:"location"`
+		Zones    []string `json:"zones"`
+	} `json:"locationInfo"`
+	Restrictions []struct {
+		Type   string   `json:"type"`
+		Values []string `json:"values"`
+	} `json:"restrictions"`
+}
+
+// virtualMachineSKUs returns all virtual machine SKUs which are not
+// restricted for the subscription in the location
+func (c *Client) virtualMachineSKUs(location string) ([]sku, error) {
+	var skus []sku
+
+	err := c.list(c.managementURL(c.subscriptionPath("/providers/Microsoft.Compute/skus"), apiVersionSKUs), func(data []byte) (string, error) {
+		var page struct {
+			Value    []sku  `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return "", err
+		}
+
+	skus:
+		for _, s := range page.Value {
+			if s.ResourceType != "virtualMachines" {
+				continue
+			}
+
+			found := false
+			for _, l := range s.Locations {
+				if strings.EqualFold(l, location) {
+					found = true
+				}
+			}
+			if !found {
+				continue
+			}
+
+			for _, r := range s.Restrictions {
+				if r.Type == "Location" {
+					continue skus
+				}
+			}
+
+			skus = append(skus, s)
+		}
+
+		return page.NextLink, nil
+	})
+
+	return skus, err
+}
+
+// Zones returns the availability zones of a location
+func (c *Client) Zones(location string) ([]string, error) {
+	skus, err := c.virtualMachineSKUs(location)
+	if err != nil {
+		return nil, err
+	}
+
+	zonesMap := map[string]bool{}
+	for _, s := range skus {
+		for _, info := range s.LocationInfo {
+			if !strings.EqualFold(info.Location, location) {
+				continue
+			}
+			for _, zone := range info.Zones {
+				zonesMap[zone] = true
+			}
+		}
+	}
+
+	var zones []string
+	for zone := range zonesMap {
+		zones = append(zones, zone)
+	}
+
+	return zones, nil
+}
+
+func (c *Client) VMSizeAvailable(location, size string) (bool, error) {
+	skus, err := c.virtualMachineSKUs(location)
+	if err != nil {
+		return false, err
+	}
+
+	for _, s := range skus {
+		if strings.EqualFold(s.Name, size) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type virtualMachine struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Location   string            `json:"location"`
+	Tags       map[string]string `json:"tags"`
+	Properties struct {
+		ProvisioningState string `json:"provisioningState"`
+		NetworkProfile    struct {
+			NetworkInterfaces []struct {
+				ID string `json:"id"`
+			} `json:"networkInterfaces"`
+		} `json:"networkProfile"`
+	} `json:"properties"`
+}
+
+type networkInterface struct {
+	ID         string `json:"id"`
+	Properties struct {
+		VirtualMachine struct {
+			ID string `json:"id"`
+		} `json:"virtualMachine"`
+		IPConfigurations []struct {
+			Properties struct {
+				Primary          bool   `json:"primary"`
+				PrivateIPAddress string `json:"privateIPAddress"`
+				PublicIPAddress  *struct {
+					ID string `json:"id"`
+				} `json:"publicIPAddress"`
+			} `json:"properties"`
+		} `json:"ipConfigurations"`
+	} `json:"properties"`
+}
+
+func (c *Client) listVirtualMachines(rawURL string) ([]virtualMachine, error) {
+	var vms []virtualMachine
+
+	err := c.list(rawURL, func(data []byte) (string, error) {
+		var page struct {
+			Value    []virtualMachine `json:"value"`
+			NextLink string           `json:"nextLink"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return "", err
+		}
+		vms = append(vms, page.Value...)
+		return page.NextLink, nil
+	})
+
+	return vms, err
+}
+
+// addresses resolves the private and public IP address of a network
+// interface
+func (c *Client) addresses(nic *networkInterface) (privateIP, publicIP string, err error) {
+	for _, ipConfig := range nic.Properties.IPConfigurations {
+		if privateIP != "" && !ipConfig.Properties.Primary {
+			continue
+		}
+		privateIP = ipConfig.Properties.PrivateIPAddress
+
+		if ipConfig.Properties.PublicIPAddress == nil {
+			continue
+		}
+
+		var pip struct {
+			Properties struct {
+				IPAddress string `json:"ipAddress"`
+			} `json:"properties"`
+		}
+		if _, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(ipConfig.Properties.PublicIPAddress.ID, apiVersionNetwork), nil, &pip); err != nil {
+			return "", "", err
+		}
+		publicIP = pip.Properties.IPAddress
+	}
+
+	return privateIP, publicIP, nil
+}
+
+// VirtualMachines returns virtual machines and scale set instances, whose
+// tags, or whose scale set's tags, match all given tags
+func (c *Client) VirtualMachines(tags map[string]string) ([]*VirtualMachine, error) {
+	var result []*VirtualMachine
+
+	vms, err := c.listVirtualMachines(c.managementURL(c.subscriptionPath("/providers/Microsoft.Compute/virtualMachines"), apiVersionCompute))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, vm := range vms {
+		if !matchTags(vm.Tags, tags) {
+			continue
+		}
+
+		machine := &VirtualMachine{
+			ID:                vm.ID,
+			Name:              vm.Name,
+			Location:          vm.Location,
+			ProvisioningState: vm.Properties.ProvisioningState,
+			Tags:              vm.Tags,
+		}
+
+		for _, ref := range vm.Properties.NetworkProfile.NetworkInterfaces {
+			nic := new(networkInterface)
+			if _, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(ref.ID, apiVersionNetwork), nil, nic); err != nil {
+				return nil, err
+			}
+			if machine.PrivateIP, machine.PublicIP, err = c.addresses(nic); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		result = append(result, machine)
+	}
+
+	scaleSets, err := c.listVirtualMachines(c.managementURL(c.subscriptionPath("/providers/Microsoft.Compute/virtualMachineScaleSets"), apiVersionCompute))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scaleSet := range scaleSets {
+		if !matchTags(scaleSet.Tags, tags) {
+			continue
+		}
+
+		instances, err := c.listVirtualMachines(c.managementURL(fmt.Sprintf("%s/virtualMachines", scaleSet.ID), apiVersionCompute))
+		if err != nil {
+			return nil, err
+		}
+
+		var nics []*networkInterface
+		err = c.list(c.managementURL(fmt.Sprintf("%s/networkInterfaces", scaleSet.ID), "2017-03-30"), func(data []byte) (string, error) {
+			var page struct {
+				Value    []*networkInterface `json:"value"`
+				NextLink string              `json:"nextLink"`
+			}
+			if err := json.Unmarshal(data, &page); err != nil {
+				return "", err
+			}
+			nics = append(nics, page.Value...)
+			return page.NextLink, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, instance := range instances {
+			// instance tags take precedence over the scale set's
+			instanceTags := map[string]string{}
+			for key, value := range scaleSet.Tags {
+				instanceTags[key] = value
+			}
+			for key, value := range instance.Tags {
+				instanceTags[key] = value
+			}
+
+			machine := &VirtualMachine{
+				ID:                instance.ID,
+				Name:              instance.Name,
+				Location:          scaleSet.Location,
+				ProvisioningState: instance.Properties.ProvisioningState,
+				Tags:              instanceTags,
+			}
+
+			for _, nic := range nics {
+				if !strings.EqualFold(nic.Properties.VirtualMachine.ID, instance.ID) {
+					continue
+				}
+				if machine.PrivateIP, machine.PublicIP, err = c.addresses(nic); err != nil {
+					return nil, err
+				}
+				break
+			}
+
+			result = append(result, machine)
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateTags merges the given tags into the tags of a virtual machine or
+// scale set instance
+func (c *Client) UpdateTags(resourceID string, tags map[string]string) error {
+	var resource map[string]interface{}
+	if _, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(resourceID, apiVersionCompute), nil, &resource); err != nil {
+		return err
+	}
+
+	existing, _ := resource["tags"].(map[string]interface{})
+	if existing == nil {
+		existing = map[string]interface{}{}
+	}
+	for key, value := range tags {
+		existing[key] = value
+	}
+	resource["tags"] = existing
+
+	// read-only fields are ignored by the API
+	_, err := c.do(http.MethodPut, ResourceManagement, c.managementURL(resourceID, apiVersionCompute), resource, nil)
+	return err
+}
+
+// waitForProvisioning polls a virtual machine or scale set instance until no
+// operation is in progress anymore
+func (c *Client) waitForProvisioning(resourceID string) error {
+	for {
+		var resource struct {
+			Properties struct {
+				ProvisioningState string `json:"provisioningState"`
+			} `json:"properties"`
+		}
+		if _, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(resourceID, apiVersionCompute), nil, &resource); err != nil {
+			return err
+		}
+
+		switch resource.Properties.ProvisioningState {
+		case "Succeeded":
+			return nil
+		case "Failed":
+			return fmt.Errorf("provisioning of '%s' failed", resourceID)
+		}
+
+		time.Sleep(c.pollInterval)
+	}
+}
+
+// ReimageScaleSetInstance applies the latest model of the scale set to one of
+// its instances and reimages it, so it boots from scratch
+func (c *Client) ReimageScaleSetInstance(resourceID string) error {
+	lowerID := strings.ToLower(resourceID)
+	pos := strings.LastIndex(lowerID, "/virtualmachines/")
+	if pos < 0 || !strings.Contains(lowerID[:pos], "/virtualmachinescalesets/") {
+		return fmt.Errorf("'%s' is not a scale set instance", resourceID)
+	}
+	scaleSetID := resourceID[:pos]
+	params := map[string]interface{}{
+		"instanceIds": []string{resourceID[pos+len("/virtualmachines/"):]},
+	}
+
+	for _, action := range []string{"manualupgrade", "reimage"} {
+		if _, err := c.do(http.MethodPost, ResourceManagement, c.managementURL(fmt.Sprintf("%s/%s", scaleSetID, action), apiVersionCompute), params, nil); err != nil {
+			return err
+		}
+
+		if err := c.waitForProvisioning(resourceID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteVirtualMachine deletes a virtual machine together with its OS disk,
+// so it can be recreated under the same name
+func (c *Client) DeleteVirtualMachine(resourceID string) error {
+	var vm struct {
+		Properties struct {
+			StorageProfile struct {
+				OSDisk struct {
+					ManagedDisk *struct {
+						ID string `json:"id"`
+					} `json:"managedDisk"`
+				} `json:"osDisk"`
+			} `json:"storageProfile"`
+		} `json:"properties"`
+	}
+	if _, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(resourceID, apiVersionCompute), nil, &vm); err != nil {
+		return err
+	}
+
+	if _, err := c.do(http.MethodDelete, ResourceManagement, c.managementURL(resourceID, apiVersionCompute), nil, nil); err != nil {
+		return err
+	}
+
+	// the OS disk can only be deleted once it is detached
+	for {
+		_, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(resourceID, apiVersionCompute), nil, nil)
+		if IsNotFound(err) {
+			break
+		} else if err != nil {
+			return err
+		}
+		time.Sleep(c.pollInterval)
+	}
+
+	if disk := vm.Properties.StorageProfile.OSDisk.ManagedDisk; disk != nil {
+		if _, err := c.do(http.MethodDelete, ResourceManagement, c.managementURL(disk.ID, apiVersionCompute), nil, nil); err != nil && !IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) Images(tags map[string]string) ([]*Image, error) {
+	var images []*Image
+
+	err := c.list(c.managementURL(c.subscriptionPath("/providers/Microsoft.Compute/images"), apiVersionCompute), func(data []byte) (string, error) {
+		var page struct {
+			Value []struct {
+				ID       string            `json:"id"`
+				Name     string            `json:"name"`
+				Location string            `json:"location"`
+				Tags     map[string]string `json:"tags"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return "", err
+		}
+		for _, item := range page.Value {
+			if !matchTags(item.Tags, tags) {
+				continue
+			}
+			images = append(images, &Image{
+				ID:       item.ID,
+				Name:     item.Name,
+				Location: item.Location,
+				Tags:     item.Tags,
+			})
+		}
+		return page.NextLink, nil
+	})
+
+	return images, err
+}
+
+func (c *Client) DNSZoneExists(resourceGroup, zone string) (bool, error) {
+	_, err := c.do(http.MethodGet, ResourceManagement, c.managementURL(c.subscriptionPath("/resourceGroups/%s/providers/Microsoft.Network/dnsZones/%s", resourceGroup, zone), apiVersionDNS), nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) EnsureResourceGroup(name, location string) error {
+	rawURL := c.managementURL(c.subscriptionPath("/resourcegroups/%s", name), apiVersionResources)
+
+	_, err := c.do(http.MethodGet, ResourceManagement, rawURL, nil, nil)
+	if err == nil {
+		return nil
+	} else if !IsNotFound(err) {
+		return err
+	}
+
+	_, err = c.do(http.MethodPut, ResourceManagement, rawURL, map[string]string{"location": location}, nil)
+	return err
+}
+
+func (c *Client) storageAccountURL(resourceGroup, name string) string {
+	return c.managementURL(c.subscriptionPath("/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s", resourceGroup, name), apiVersionStorage)
+}
+
+func (c *Client) StorageAccountExists(resourceGroup, name string) (bool, error) {
+	_, err := c.do(http.MethodGet, ResourceManagement, c.storageAccountURL(resourceGroup, name), nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateStorageAccount creates a storage account and waits for it to be
+// provisioned
+func (c *Client) CreateStorageAccount(resourceGroup, name, location string) error {
+	params := map[string]interface{}{
+		"location": location,
+		"kind":     "StorageV2",
+		"sku": map[string]string{
+			"name": "Standard_LRS",
+		},
+		"properties": map[string]interface{}{
+			"supportsHttpsTrafficOnly": true,
+		},
+	}
+
+	if _, err := c.do(http.MethodPut, ResourceManagement, c.storageAccountURL(resourceGroup, name), params, nil); err != nil {
+		return err
+	}
+
+	for {
+		var account struct {
+			Properties struct {
+				ProvisioningState string `json:"provisioningState"`
+			} `json:"properties"`
+		}
+		_, err := c.do(http.MethodGet, ResourceManagement, c.storageAccountURL(resourceGroup, name), nil, &account)
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+
+		switch account.Properties.ProvisioningState {
+		case "Succeeded":
+			return nil
+		case "Failed":
+			return fmt.Errorf("provisioning of storage account '%s' failed", name)
+		}
+
+		time.Sleep(c.pollInterval)
+	}
+}
+
+func (c *Client) DeleteStorageAccount(resourceGroup, name string) error {
+	_, err := c.do(http.MethodDelete, ResourceManagement, c.storageAccountURL(resourceGroup, name), nil, nil)
+	return err
+}
+
+func (c *Client) StorageAccountKey(resourceGroup, name string) (string, error) {
+	var keys struct {
+		Keys []struct {
+			Value string `json:"value"`
+		} `json:"keys"`
+	}
+
+	rawURL := c.managementURL(c.subscriptionPath("/resourceGroups/%s/providers/Microsoft.Storage/storageAccounts/%s/listKeys", resourceGroup, name), apiVersionStorage)
+	if _, err := c.do(http.MethodPost, ResourceManagement, rawURL, nil, &keys); err != nil {
+		return "", err
+	}
+
+	if len(keys.Keys) == 0 {
+		return "", fmt.Errorf("storage account '%s' has no access keys", name)
+	}
+
+	return keys.Keys[0].Value, nil
+}
@@ -0,0 +1,137 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package arm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/Azure/go-autorest/autorest/azure"
+)
+
+const (
+	// ResourceManagement is the token audience of the Azure Resource Manager
+	ResourceManagement = "https://management.azure.com/"
+	// ResourceKeyVault is the token audience of the Key Vault data plane
+	ResourceKeyVault = "https://vault.azure.net"
+	// ResourceStorage is the token audience of the Storage data plane
+	ResourceStorage = "https://storage.azure.com/"
+
+	// EnvClientSecret contains the secret of the service principal
+	EnvClientSecret = "AZURE_CLIENT_SECRET"
+)
+
+// Authorizer returns bearer tokens for a resource
+type Authorizer interface {
+	Token(resource string) (string, error)
+}
+
+// NewAuthorizer authenticates as the service principal clientID, if a
+// secret is provided through AZURE_CLIENT_SECRET, and otherwise falls back
+// to the credentials of the Azure CLI
+func NewAuthorizer(tenantID, clientID string) (Authorizer, error) {
+	secret := os.Getenv(EnvClientSecret)
+	if clientID == "" || secret == "" {
+		return &cliAuthorizer{tokens: map[string]*cliToken{}}, nil
+	}
+
+	oauthConfig, err := adal.NewOAuthConfig(azure.PublicCloud.ActiveDirectoryEndpoint, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("error creating oauth config for tenant '%s': %s", tenantID, err)
+	}
+
+	return &adalAuthorizer{
+		tokens: map[string]*adal.ServicePrincipalToken{},
+		newToken: func(resource string) (*adal.ServicePrincipalToken, error) {
+			return adal.NewServicePrincipalToken(*oauthConfig, clientID, secret, resource)
+		},
+	}, nil
+}
+
+// NewMSIAuthorizer authenticates using the managed identity of the virtual
+// machine
+func NewMSIAuthorizer() (Authorizer, error) {
+	endpoint, err := adal.GetMSIVMEndpoint()
+	if err != nil {
+		return nil, fmt.Errorf("error getting MSI endpoint: %s", err)
+	}
+
+	return &adalAuthorizer{
+		tokens: map[string]*adal.ServicePrincipalToken{},
+		newToken: func(resource string) (*adal.ServicePrincipalToken, error) {
+			return adal.NewServicePrincipalTokenFromMSI(endpoint, resource)
+		},
+	}, nil
+}
+
+type adalAuthorizer struct {
+	lock     sync.Mutex
+	tokens   map[string]*adal.ServicePrincipalToken
+	newToken func(resource string) (*adal.ServicePrincipalToken, error)
+}
+
+func (a *adalAuthorizer) Token(resource string) (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	token, ok := a.tokens[resource]
+	if !ok {
+		var err error
+		token, err = a.newToken(resource)
+		if err != nil {
+			return "", err
+		}
+		a.tokens[resource] = token
+	}
+
+	if err := token.EnsureFresh(); err != nil {
+		return "", fmt.Errorf("error refreshing token for '%s': %s", resource, err)
+	}
+
+	return token.OAuthToken(), nil
+}
+
+type cliToken struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresOn   string `json:"expiresOn"`
+
+	expiresOn time.Time
+}
+
+type cliAuthorizer struct {
+	lock   sync.Mutex
+	tokens map[string]*cliToken
+}
+
+func (a *cliAuthorizer) Token(resource string) (string, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if token, ok := a.tokens[resource]; ok && time.Now().Add(time.Minute).Before(token.expiresOn) {
+		return token.AccessToken, nil
+	}
+
+	out, err := exec.Command("az", "account", "get-access-token", "--resource", resource, "--output", "json").Output()
+	if err != nil {
+		return "", fmt.Errorf("error getting access token from the Azure CLI, make sure you are logged in using 'az login': %s", err)
+	}
+
+	token := new(cliToken)
+	if err := json.Unmarshal(out, token); err != nil {
+		return "", fmt.Errorf("error parsing access token from the Azure CLI: %s", err)
+	}
+
+	// the CLI returns local time without a zone
+	token.expiresOn, err = time.ParseInLocation("2006-01-02 15:04:05.999999", token.ExpiresOn, time.Local)
+	if err != nil {
+		return "", fmt.Errorf("error parsing expiry of access token '%s': %s", token.ExpiresOn, err)
+	}
+
+	a.tokens[resource] = token
+
+	return token.AccessToken, nil
+}
@@ -0,0 +1,41 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package arm
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Azure AD authentication for blobs requires this version of the storage API
+const storageAPIVersion = "2017-11-09"
+
+// GetBlob downloads a blob using Azure AD authentication, this needs the
+// identity to have a data plane role like 'Storage Blob Data Reader'
+func (c *Client) GetBlob(rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := c.authorizer.Token(ResourceStorage)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("x-ms-version", storageAPIVersion)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+
+	return resp.Body, nil
+}
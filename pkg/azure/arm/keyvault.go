@@ -0,0 +1,32 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package arm
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const apiVersionKeyVault = "7.0"
+
+func keyVaultSecretURL(vault, name string) string {
+	return fmt.Sprintf("https://%s.vault.azure.net/secrets/%s?api-version=%s", vault, name, apiVersionKeyVault)
+}
+
+func (c *Client) SetSecret(vault, name, value string) error {
+	_, err := c.do(http.MethodPut, ResourceKeyVault, keyVaultSecretURL(vault, name), map[string]string{"value": value}, nil)
+	return err
+}
+
+// GetSecret returns the current version of a secret, missing secrets can be
+// detected using IsNotFound
+func (c *Client) GetSecret(vault, name string) (string, error) {
+	var secret struct {
+		Value string `json:"value"`
+	}
+
+	if _, err := c.do(http.MethodGet, ResourceKeyVault, keyVaultSecretURL(vault, name), nil, &secret); err != nil {
+		return "", err
+	}
+
+	return secret.Value, nil
+}
@@ -0,0 +1,31 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package arm
+
+import (
+	"fmt"
+)
+
+const (
+	// tag values are limited to 256 characters, so ssh host keys are split
+	// into tags named <prefix><key index>_<chunk index>
+	TagSSHHostKeyPrefix = "tarmak_ssh_host_key_"
+
+	tagValueMaxLength = 256
+)
+
+// SSHHostKeyTags splits authorized_keys formatted ssh host keys into tags
+func SSHHostKeyTags(keys []string) map[string]string {
+	tags := map[string]string{}
+
+	for keyIndex, key := range keys {
+		for chunkIndex := 0; chunkIndex*tagValueMaxLength < len(key); chunkIndex++ {
+			end := (chunkIndex + 1) * tagValueMaxLength
+			if end > len(key) {
+				end = len(key)
+			}
+			tags[fmt.Sprintf("%s%d_%d", TagSSHHostKeyPrefix, keyIndex, chunkIndex)] = key[chunkIndex*tagValueMaxLength : end]
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,422 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+// Package compute is a minimal client for the Compute Engine and Cloud DNS
+// REST APIs
+package compute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	computeEndpoint = "https://www.googleapis.com/compute/v1"
+	dnsEndpoint     = "https://www.googleapis.com/dns/v1"
+
+	scopeCloudPlatformReadOnly = "https://www.googleapis.com/auth/cloud-platform.read-only"
+
+	// ScopeCompute allows to modify Compute Engine resources
+	ScopeCompute = "https://www.googleapis.com/auth/compute"
+)
+
+type Instance struct {
+	Name      string
+	Zone      string
+	Status    string
+	PrivateIP string
+	PublicIP  string
+	Labels    map[string]string
+	Metadata  map[string]string
+}
+
+type Image struct {
+	Name              string
+	Family            string
+	CreationTimestamp string
+	Encrypted         bool
+	Labels            map[string]string
+}
+
+// Client queries the REST API of Compute Engine and Cloud DNS
+type Client struct {
+	client *http.Client
+}
+
+// New creates a client using the service account key file credentials or, if
+// empty, the application default credentials. Without additional scopes the
+// client is read-only.
+func New(credentials string, scopes ...string) (*Client, error) {
+	ctx := context.Background()
+	scopes = append([]string{scopeCloudPlatformReadOnly}, scopes...)
+
+	if credentials == "" {
+		client, err := google.DefaultClient(ctx, scopes...)
+		if err != nil {
+			return nil, err
+		}
+		return &Client{client: client}, nil
+	}
+
+	data, err := ioutil.ReadFile(credentials)
+	if err != nil {
+		return nil, fmt.Errorf("error reading credentials file '%s': %s", credentials, err)
+	}
+
+	conf, err := google.JWTConfigFromJSON(data, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing credentials file '%s': %s", credentials, err)
+	}
+
+	return &Client{client: conf.Client(ctx)}, nil
+}
+
+// NewWithClient creates a client using an already authenticated HTTP client
+func NewWithClient(client *http.Client) *Client {
+	return &Client{client: client}
+}
+
+type apiError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("google api returned %d: %s", e.StatusCode, e.Message)
+}
+
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+func (c *Client) get(rawURL string, params url.Values, out interface{}) error {
+	if len(params) > 0 {
+		rawURL = fmt.Sprintf("%s?%s", rawURL, params.Encode())
+	}
+
+	resp, err := c.client.Get(rawURL)
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, out)
+}
+
+func (c *Client) post(rawURL string, in interface{}, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Post(rawURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, out)
+}
+
+func (c *Client) delete(rawURL string, out interface{}) error {
+	req, err := http.NewRequest(http.MethodDelete, rawURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, out)
+}
+
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		msg := string(body)
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+			msg = errResp.Error.Message
+		}
+		return &apiError{StatusCode: resp.StatusCode, Message: msg}
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// list follows the page tokens of a list call and hands every page to the
+// decode function
+func (c *Client) list(rawURL string, decode func(data []byte) (nextPageToken string, err error)) error {
+	params := url.Values{}
+	for {
+		var page json.RawMessage
+		if err := c.get(rawURL, params, &page); err != nil {
+			return err
+		}
+
+		next, err := decode(page)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		params.Set("pageToken", next)
+	}
+}
+
+func (c *Client) Regions(project string) ([]string, error) {
+	var regions []string
+
+	err := c.list(fmt.Sprintf("%s/projects/%s/regions", computeEndpoint, project), func(data []byte) (string, error) {
+		var page struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return "", err
+		}
+		for _, item := range page.Items {
+			regions = append(regions, item.Name)
+		}
+		return page.NextPageToken, nil
+	})
+
+	return regions, err
+}
+
+func (c *Client) Zones(project, region string) ([]string, error) {
+	var zones []string
+
+	err := c.list(fmt.Sprintf("%s/projects/%s/zones", computeEndpoint, project), func(data []byte) (string, error) {
+		var page struct {
+			Items []struct {
+				Name   string `json:"name"`
+				Region string `json:"region"`
+				Status string `json:"status"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return "", err
+		}
+		for _, item := range page.Items {
+			if path.Base(item.Region) == region && item.Status == "UP" {
+				zones = append(zones, item.Name)
+			}
+		}
+		return page.NextPageToken, nil
+	})
+
+	return zones, err
+}
+
+func matchLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Client) Instances(project string, labels map[string]string) ([]*Instance, error) {
+	var instances []*Instance
+
+	err := c.list(fmt.Sprintf("%s/projects/%s/aggregated/instances", computeEndpoint, project), func(data []byte) (string, error) {
+		var page struct {
+			Items map[string]struct {
+				Instances []struct {
+					Name     string            `json:"name"`
+					Zone     string            `json:"zone"`
+					Status   string            `json:"status"`
+					Labels   map[string]string `json:"labels"`
+					Metadata struct {
+						Items []struct {
+							Key   string `json:"key"`
+							Value string `json:"value"`
+						} `json:"items"`
+					} `json:"metadata"`
+					NetworkInterfaces []struct {
+						NetworkIP     string `json:"networkIP"`
+						AccessConfigs []struct {
+							NatIP string `json:"natIP"`
+						} `json:"accessConfigs"`
+					} `json:"networkInterfaces"`
+				} `json:"instances"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return "", err
+		}
+
+		for _, scope := range page.Items {
+			for _, item := range scope.Instances {
+				if !matchLabels(item.Labels, labels) {
+					continue
+				}
+
+				instance := &Instance{
+					Name:     item.Name,
+					Zone:     path.Base(item.Zone),
+					Status:   item.Status,
+					Labels:   item.Labels,
+					Metadata: map[string]string{},
+				}
+				for _, m := range item.Metadata.Items {
+					instance.Metadata[m.Key] = m.Value
+				}
+				if len(item.NetworkInterfaces) > 0 {
+					instance.PrivateIP = item.NetworkInterfaces[0].NetworkIP
+					if len(item.NetworkInterfaces[0].AccessConfigs) > 0 {
+						instance.PublicIP = item.NetworkInterfaces[0].AccessConfigs[0].NatIP
+					}
+				}
+				instances = append(instances, instance)
+			}
+		}
+		return page.NextPageToken, nil
+	})
+
+	return instances, err
+}
+
+func (c *Client) Images(project string, labels map[string]string) ([]*Image, error) {
+	var images []*Image
+
+	err := c.list(fmt.Sprintf("%s/projects/%s/global/images", computeEndpoint, project), func(data []byte) (string, error) {
+		var page struct {
+			Items []struct {
+				Name               string            `json:"name"`
+				Family             string            `json:"family"`
+				Status             string            `json:"status"`
+				CreationTimestamp  string            `json:"creationTimestamp"`
+				Labels             map[string]string `json:"labels"`
+				ImageEncryptionKey *struct{}         `json:"imageEncryptionKey"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return "", err
+		}
+		for _, item := range page.Items {
+			if item.Status != "READY" || !matchLabels(item.Labels, labels) {
+				continue
+			}
+			images = append(images, &Image{
+				Name:              item.Name,
+				Family:            item.Family,
+				CreationTimestamp: item.CreationTimestamp,
+				Encrypted:         item.ImageEncryptionKey != nil,
+				Labels:            item.Labels,
+			})
+		}
+		return page.NextPageToken, nil
+	})
+
+	return images, err
+}
+
+func (c *Client) MachineTypeAvailable(project, zone, machineType string) (bool, error) {
+	var out struct {
+		Name string `json:"name"`
+	}
+
+	err := c.get(fmt.Sprintf("%s/projects/%s/zones/%s/machineTypes/%s", computeEndpoint, project, zone, machineType), nil, &out)
+	if isNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (c *Client) ManagedZoneDNSName(project, managedZone string) (string, error) {
+	var out struct {
+		DNSName string `json:"dnsName"`
+	}
+
+	if err := c.get(fmt.Sprintf("%s/projects/%s/managedZones/%s", dnsEndpoint, project, managedZone), nil, &out); err != nil {
+		return "", err
+	}
+
+	return out.DNSName, nil
+}
+
+type metadataItem struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// SetInstanceMetadata sets a single metadata key of an instance, while
+// keeping all other existing keys
+func (c *Client) SetInstanceMetadata(project, zone, instance, key, value string) error {
+	instanceURL := fmt.Sprintf("%s/projects/%s/zones/%s/instances/%s", computeEndpoint, project, zone, instance)
+
+	var current struct {
+		Metadata struct {
+			Fingerprint string         `json:"fingerprint"`
+			Items       []metadataItem `json:"items"`
+		} `json:"metadata"`
+	}
+	if err := c.get(instanceURL, nil, &current); err != nil {
+		return err
+	}
+
+	items := []metadataItem{}
+	for _, item := range current.Metadata.Items {
+		if item.Key == key {
+			if item.Value == value {
+				return nil
+			}
+			continue
+		}
+		items = append(items, item)
+	}
+	items = append(items, metadataItem{Key: key, Value: value})
+
+	var operation struct {
+		Name string `json:"name"`
+	}
+	return c.post(
+		fmt.Sprintf("%s/setMetadata", instanceURL),
+		map[string]interface{}{
+			"fingerprint": current.Metadata.Fingerprint,
+			"items":       items,
+		},
+		&operation,
+	)
+}
+
+// DeleteInstance deletes an instance, instances of a managed instance group
+// get recreated by their group
+func (c *Client) DeleteInstance(project, zone, instance string) error {
+	var operation struct {
+		Name string `json:"name"`
+	}
+	return c.delete(
+		fmt.Sprintf("%s/projects/%s/zones/%s/instances/%s", computeEndpoint, project, zone, instance),
+		&operation,
+	)
+}
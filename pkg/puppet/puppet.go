@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/docker/docker/pkg/archive"
@@ -18,8 +19,10 @@ import (
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
 	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/addons"
 	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+	"github.com/jetstack/tarmak/pkg/wing/signature"
 )
 
 type Puppet struct {
@@ -55,16 +58,23 @@ func (p *Puppet) TarGz(writer io.Writer) error {
 		return err
 	}
 
+	key, err := p.tarmak.Cluster().Environment().ManifestSigningKey()
+	if err != nil {
+		return err
+	}
+
 	reader, err := archive.Tar(
 		path,
-		archive.Gzip,
+		archive.Uncompressed,
 	)
 	if err != nil {
 		return fmt.Errorf("error creating tar from path '%s': %s", path, err)
 	}
+	defer reader.Close()
 
-	if _, err := io.Copy(writer, reader); err != nil {
-		return fmt.Errorf("error writing tar: %s", err)
+	// wing verifies the signature before unpacking the manifest
+	if err := signature.SignTarGz(key, reader, writer); err != nil {
+		return fmt.Errorf("error writing signed tar: %s", err)
 	}
 
 	return nil
@@ -181,18 +191,14 @@ func kubernetesClusterConfig(conf *clusterv1alpha1.ClusterKubernetes, hieraData
 		}
 	}
 
-	// enable prometheus if set, default: enabled
-	hieraData.classes = append(hieraData.classes, `prometheus`)
-	if conf.Prometheus == nil || conf.Prometheus.Enabled {
-		mode := clusterv1alpha1.PrometheusModeFull
-		if conf.Prometheus != nil && conf.Prometheus.Mode != "" {
-			mode = conf.Prometheus.Mode
+	// addons deployed by all roles, prometheus is enabled by default
+	resolved := addons.Resolve(conf)
+	for _, addon := range resolved {
+		if !addon.Custom() && len(addon.Spec.Roles) == 0 {
+			addonConfig(addon, hieraData)
 		}
-		hieraData.variables = append(hieraData.variables, fmt.Sprintf("prometheus::mode: %s", mode))
-		hieraData.variables = append(hieraData.variables, `prometheus::ensure: "present"`)
-	} else {
-		hieraData.variables = append(hieraData.variables, `prometheus::ensure: "absent"`)
 	}
+	overprovisioning := addons.OverprovisioningEnabled(resolved)
 
 	globalGates := make(map[string]bool)
 	if conf.GlobalFeatureGates != nil {
@@ -203,7 +209,7 @@ func kubernetesClusterConfig(conf *clusterv1alpha1.ClusterKubernetes, hieraData
 	if a := conf.APIServer; a != nil {
 		compGates = a.FeatureGates
 	}
-	if gates := featureGatesString(globalGates, compGates, true, conf.ClusterAutoscaler); gates != "" {
+	if gates := featureGatesString(globalGates, compGates, true, overprovisioning); gates != "" {
 		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes::apiserver::feature_gates:%s`, gates))
 	}
 
@@ -211,7 +217,7 @@ func kubernetesClusterConfig(conf *clusterv1alpha1.ClusterKubernetes, hieraData
 	if k := conf.Kubelet; k != nil {
 		compGates = k.FeatureGates
 	}
-	if gates := featureGatesString(globalGates, compGates, true, conf.ClusterAutoscaler); gates != "" {
+	if gates := featureGatesString(globalGates, compGates, true, overprovisioning); gates != "" {
 		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes::kubelet::feature_gates:%s`, gates))
 	}
 
@@ -219,7 +225,7 @@ func kubernetesClusterConfig(conf *clusterv1alpha1.ClusterKubernetes, hieraData
 	if s := conf.Scheduler; s != nil {
 		compGates = s.FeatureGates
 	}
-	if gates := featureGatesString(globalGates, compGates, true, conf.ClusterAutoscaler); gates != "" {
+	if gates := featureGatesString(globalGates, compGates, true, overprovisioning); gates != "" {
 		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes::scheduler::feature_gates:%s`, gates))
 	}
 
@@ -227,7 +233,7 @@ func kubernetesClusterConfig(conf *clusterv1alpha1.ClusterKubernetes, hieraData
 	if p := conf.Proxy; p != nil {
 		compGates = p.FeatureGates
 	}
-	if gates := featureGatesString(globalGates, compGates, false, conf.ClusterAutoscaler); gates != "" {
+	if gates := featureGatesString(globalGates, compGates, false, overprovisioning); gates != "" {
 		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes::proxy::feature_gates:%s`, gates))
 	}
 
@@ -235,7 +241,7 @@ func kubernetesClusterConfig(conf *clusterv1alpha1.ClusterKubernetes, hieraData
 	if c := conf.ControllerManager; c != nil {
 		compGates = c.FeatureGates
 	}
-	if gates := featureGatesString(globalGates, compGates, false, conf.ClusterAutoscaler); gates != "" {
+	if gates := featureGatesString(globalGates, compGates, false, overprovisioning); gates != "" {
 		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes::controller_manager::feature_gates:%s`, gates))
 	}
 
@@ -256,12 +262,10 @@ func kubernetesClusterConfig(conf *clusterv1alpha1.ClusterKubernetes, hieraData
 	return
 }
 
-func featureGatesString(globalGates, componentGates map[string]bool, usePodPriority bool, conf *clusterv1alpha1.ClusterKubernetesClusterAutoscaler) string {
+func featureGatesString(globalGates, componentGates map[string]bool, usePodPriority, overprovisioning bool) string {
 	gates := utils.DuplicateMapBool(globalGates)
-	if usePodPriority {
-		if conf != nil && conf.Overprovisioning != nil && conf.Overprovisioning.Enabled {
-			gates["PodPriority"] = true
-		}
+	if usePodPriority && overprovisioning {
+		gates["PodPriority"] = true
 	}
 
 	if componentGates != nil {
@@ -286,98 +290,93 @@ func kubernetesClusterConfigPerRole(conf *clusterv1alpha1.ClusterKubernetes, rol
 		return
 	}
 
-	if roleName == clusterv1alpha1.KubernetesMasterRoleName {
-		hieraData.classes = append(hieraData.classes, `kubernetes_addons::cluster_autoscaler`)
-		if conf.ClusterAutoscaler != nil && conf.ClusterAutoscaler.Enabled {
-			hieraData.variables = append(hieraData.variables, `kubernetes_addons::cluster_autoscaler::ensure: "present"`)
-			if conf.ClusterAutoscaler.Image != "" {
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::image: "%s"`, conf.ClusterAutoscaler.Image))
-			}
-			if conf.ClusterAutoscaler.Version != "" {
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::version: "%s"`, conf.ClusterAutoscaler.Version))
-			}
-
-			if conf.ClusterAutoscaler.ScaleDownUtilizationThreshold != nil {
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::scale_down_utilization_threshold: %v`, *conf.ClusterAutoscaler.ScaleDownUtilizationThreshold))
-			}
-
-			if conf.ClusterAutoscaler.Overprovisioning != nil && conf.ClusterAutoscaler.Overprovisioning.Enabled {
-				hieraData.variables = append(hieraData.variables, `kubernetes_addons::cluster_autoscaler::enable_overprovisioning: true`)
-				hieraData.variables = append(hieraData.variables, `kubernetes::enable_pod_priority: true`)
-
-				if conf.ClusterAutoscaler.Overprovisioning.Image != "" {
-					hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::proportional_image: "%s"`, conf.ClusterAutoscaler.Overprovisioning.Image))
-				}
-				if conf.ClusterAutoscaler.Overprovisioning.Version != "" {
-					hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::proportional_version: "%s"`, conf.ClusterAutoscaler.Overprovisioning.Version))
-				}
+	resolved := addons.Resolve(conf)
 
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::reserved_millicores_per_replica: %d`, conf.ClusterAutoscaler.Overprovisioning.ReservedMillicoresPerReplica))
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::reserved_megabytes_per_replica: %d`, conf.ClusterAutoscaler.Overprovisioning.ReservedMegabytesPerReplica))
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::cores_per_replica: %d`, conf.ClusterAutoscaler.Overprovisioning.CoresPerReplica))
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::nodes_per_replica: %d`, conf.ClusterAutoscaler.Overprovisioning.NodesPerReplica))
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::cluster_autoscaler::replica_count: %d`, conf.ClusterAutoscaler.Overprovisioning.ReplicaCount))
-			}
-		} else {
-			hieraData.variables = append(hieraData.variables, `kubernetes_addons::cluster_autoscaler::ensure: "absent"`)
+	custom := make(map[string]customAddon)
+	for _, addon := range resolved {
+		if !addon.ForRole(roleName) {
+			continue
+		}
+		if addon.Custom() {
+			custom[addon.Name] = newCustomAddon(addon)
+			continue
+		}
+		// addons of all roles are part of the cluster config
+		if len(addon.Spec.Roles) > 0 {
+			addonConfig(addon, hieraData)
 		}
 	}
 
-	if roleName == clusterv1alpha1.KubernetesWorkerRoleName && conf.ClusterAutoscaler != nil && conf.ClusterAutoscaler.Enabled {
-		if conf.ClusterAutoscaler.Overprovisioning != nil && conf.ClusterAutoscaler.Overprovisioning.Enabled {
-			hieraData.variables = append(hieraData.variables, `kubernetes::enable_pod_priority: true`)
+	if len(custom) > 0 {
+		customJSON, err := json.Marshal(custom)
+		if err != nil {
+			panic(err)
 		}
+		hieraData.classes = append(hieraData.classes, `kubernetes_addons::custom`)
+		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::custom::addons: %s`, string(customJSON)))
 	}
 
-	if roleName == clusterv1alpha1.KubernetesMasterRoleName {
-		hieraData.classes = append(hieraData.classes, `kubernetes_addons::tiller`)
-		if conf.Tiller != nil && conf.Tiller.Enabled {
-			hieraData.variables = append(hieraData.variables, `kubernetes_addons::tiller::ensure: "present"`)
-			if conf.Tiller.Image != "" {
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::tiller::image: "%s"`, conf.Tiller.Image))
-			}
-			if conf.Tiller.Version != "" {
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::tiller::version: "%s"`, conf.Tiller.Version))
-			}
-		} else {
-			hieraData.variables = append(hieraData.variables, `kubernetes_addons::tiller::ensure: "absent"`)
+	if roleName == clusterv1alpha1.KubernetesMasterRoleName || roleName == clusterv1alpha1.KubernetesWorkerRoleName {
+		if addons.OverprovisioningEnabled(resolved) {
+			hieraData.variables = append(hieraData.variables, `kubernetes::enable_pod_priority: true`)
 		}
 	}
 
-	if roleName == clusterv1alpha1.KubernetesMasterRoleName {
-		hieraData.classes = append(hieraData.classes, `kubernetes_addons::dashboard`)
-		if conf.Dashboard != nil && conf.Dashboard.Enabled {
-			hieraData.variables = append(hieraData.variables, `kubernetes_addons::dashboard::ensure: "present"`)
-			if conf.Dashboard.Image != "" {
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::dashboard::image: "%s"`, conf.Dashboard.Image))
-			}
-			if conf.Dashboard.Version != "" {
-				hieraData.variables = append(hieraData.variables, fmt.Sprintf(`kubernetes_addons::dashboard::version: "%s"`, conf.Dashboard.Version))
-			}
-		} else {
-			hieraData.variables = append(hieraData.variables, `kubernetes_addons::dashboard::ensure: "absent"`)
-		}
+	return
+}
+
+type customAddon struct {
+	Ensure    string   `json:"ensure"`
+	Manifests []string `json:"manifests"`
+}
+
+func newCustomAddon(addon *addons.Addon) customAddon {
+	c := customAddon{
+		Ensure:    "present",
+		Manifests: addon.Manifests,
+	}
+	if !addon.Enabled {
+		c.Ensure = "absent"
 	}
+	return c
+}
 
-	if g := conf.Grafana; g != nil && conf.Grafana.Enabled {
-		hieraData.variables = append(hieraData.variables, `kubernetes_addons::grafana::ensure: "present"`)
-	} else {
-		hieraData.variables = append(hieraData.variables, `kubernetes_addons::grafana::ensure: "absent"`)
+// addonConfig includes the class of a known addon and sets its parameters
+func addonConfig(addon *addons.Addon, hieraData *hieraData) {
+	class := addon.Spec.Class
+	hieraData.classes = append(hieraData.classes, class)
+
+	if !addon.Enabled {
+		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`%s::ensure: "absent"`, class))
+		return
 	}
+	hieraData.variables = append(hieraData.variables, fmt.Sprintf(`%s::ensure: "present"`, class))
 
-	if h := conf.Heapster; h != nil && conf.Heapster.Enabled {
-		hieraData.variables = append(hieraData.variables, `kubernetes_addons::heapster::ensure: "present"`)
-	} else {
-		hieraData.variables = append(hieraData.variables, `kubernetes_addons::heapster::ensure: "absent"`)
+	if addon.Image != "" {
+		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`%s::image: "%s"`, class, addon.Image))
+	}
+	if addon.Version != "" {
+		hieraData.variables = append(hieraData.variables, fmt.Sprintf(`%s::version: "%s"`, class, addon.Version))
 	}
 
-	if i := conf.Heapster; i != nil && conf.InfluxDB.Enabled {
-		hieraData.variables = append(hieraData.variables, `kubernetes_addons::influxdb::ensure: "present"`)
-	} else {
-		hieraData.variables = append(hieraData.variables, `kubernetes_addons::influxdb::ensure: "absent"`)
+	var keys []string
+	for key := range addon.Values {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
 
-	return
+	for _, key := range keys {
+		value := addon.Values[key]
+		switch addon.Spec.Values[key].Type {
+		case addons.ValueTypeInteger, addons.ValueTypeFloat:
+			hieraData.variables = append(hieraData.variables, fmt.Sprintf(`%s::%s: %s`, class, key, value))
+		case addons.ValueTypeBoolean:
+			b, _ := strconv.ParseBool(value)
+			hieraData.variables = append(hieraData.variables, fmt.Sprintf(`%s::%s: %t`, class, key, b))
+		default:
+			hieraData.variables = append(hieraData.variables, fmt.Sprintf(`%s::%s: "%s"`, class, key, value))
+		}
+	}
 }
 
 func kubernetesInstancePoolConfig(conf *clusterv1alpha1.InstancePoolKubernetes, hieraData *hieraData) {
@@ -390,6 +389,22 @@ func kubernetesInstancePoolConfig(conf *clusterv1alpha1.InstancePoolKubernetes,
 	return
 }
 
+// spotInstancePoolConfig installs the spot interruption handler on nodes of
+// instance pools launching spot instances
+func spotInstancePoolConfig(conf *clusterv1alpha1.InstancePoolSpot, roleName string, hieraData *hieraData) {
+	if conf == nil {
+		return
+	}
+	if roleName != clusterv1alpha1.KubernetesWorkerRoleName && roleName != clusterv1alpha1.KubernetesMasterRoleName {
+		return
+	}
+	if conf.InterruptionHandler != nil && !*conf.InterruptionHandler {
+		return
+	}
+	hieraData.classes = append(hieraData.classes, `kubernetes::spot_interruption_handler`)
+	return
+}
+
 func (p *Puppet) contentClusterConfig(cluster interfaces.Cluster) ([]string, error) {
 
 	hieraData := &hieraData{}
@@ -436,6 +451,7 @@ func contentInstancePoolConfig(clusterConf *clusterv1alpha1.Cluster, instanceCon
 	hieraData := &hieraData{}
 	kubernetesClusterConfigPerRole(clusterConf.Kubernetes, roleName, hieraData)
 	kubernetesInstancePoolConfig(instanceConf.Kubernetes, hieraData)
+	spotInstancePoolConfig(instanceConf.Spot, roleName, hieraData)
 
 	return serialiseHieraData(hieraData)
 }
@@ -509,7 +525,8 @@ func (p *Puppet) writeHieraData(puppetPath string, cluster interfaces.Cluster) e
 	workerMinCounts := make([]int, 0)
 	workerMaxCounts := make([]int, 0)
 	workerInstancePoolNames := make([]string, 0)
-	if cluster.Config().Kubernetes.ClusterAutoscaler != nil && cluster.Config().Kubernetes.ClusterAutoscaler.Enabled {
+	clusterAutoscaler := addons.Find(addons.Resolve(cluster.Config().Kubernetes), addons.ClusterAutoscaler)
+	if clusterAutoscaler.Enabled {
 		for _, instancePool := range cluster.InstancePools() {
 			if instancePool.Role().Name() == clusterv1alpha1.KubernetesWorkerRoleName {
 				workerMinCounts = append(workerMinCounts, instancePool.MinCount())
@@ -524,7 +541,7 @@ func (p *Puppet) writeHieraData(puppetPath string, cluster interfaces.Cluster) e
 
 		classes, variables := contentInstancePoolConfig(cluster.Config(), instancePool.Config(), instancePool.Role().Name())
 
-		if instancePool.Role().Name() == clusterv1alpha1.KubernetesMasterRoleName && cluster.Config().Kubernetes.ClusterAutoscaler != nil && cluster.Config().Kubernetes.ClusterAutoscaler.Enabled {
+		if instancePool.Role().Name() == clusterv1alpha1.KubernetesMasterRoleName && clusterAutoscaler.Enabled {
 			s, err := json.Marshal(workerMinCounts)
 			if err != nil {
 				panic(err)
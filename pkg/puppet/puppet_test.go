@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/addons"
 )
 
 type featureGateMap struct {
@@ -108,6 +109,13 @@ func TestFeatureGatesString(t *testing.T) {
 	f.testFeatureMap(`
   A: true
   B: false
+  C: false`)
+
+	// overprovisioning is only deployed along with the cluster autoscaler
+	f.conf.Enabled = true
+	f.testFeatureMap(`
+  A: true
+  B: false
   C: false
   PodPriority: true`)
 
@@ -119,8 +127,100 @@ func TestFeatureGatesString(t *testing.T) {
 }
 
 func (f *featureGateMap) testFeatureMap(exp string) {
-	got := featureGatesString(f.globalGates, f.componentGates, f.usePodPriority, f.conf)
+	overprovisioning := addons.OverprovisioningEnabled(addons.Resolve(&clusterv1alpha1.ClusterKubernetes{
+		ClusterAutoscaler: f.conf,
+	}))
+	got := featureGatesString(f.globalGates, f.componentGates, f.usePodPriority, overprovisioning)
 	if got != exp {
 		f.Errorf("feature flags strings do not match\nexp=%s\ngot=%s", exp, got)
 	}
 }
+
+func TestKubernetesClusterConfigPerRoleAddons(t *testing.T) {
+	enabled := true
+	c := clusterv1alpha1.ClusterKubernetes{
+		Tiller: &clusterv1alpha1.ClusterKubernetesTiller{
+			Enabled: true,
+		},
+		Addons: []clusterv1alpha1.ClusterKubernetesAddon{
+			{
+				Name:    "dashboard",
+				Enabled: &enabled,
+				Image:   "example.com/dashboard",
+				Values:  map[string]string{"replicas": "2", "limit_cpu": "200m"},
+			},
+			{
+				Name:      "echo",
+				Manifests: []string{"kind: Namespace"},
+			},
+		},
+	}
+
+	d := hieraData{}
+	kubernetesClusterConfigPerRole(&c, clusterv1alpha1.KubernetesMasterRoleName, &d)
+
+	for _, exp := range []string{
+		`kubernetes_addons::tiller::ensure: "present"`,
+		`kubernetes_addons::dashboard::ensure: "present"`,
+		`kubernetes_addons::dashboard::image: "example.com/dashboard"`,
+		`kubernetes_addons::dashboard::limit_cpu: "200m"`,
+		`kubernetes_addons::dashboard::replicas: 2`,
+		`kubernetes_addons::cluster_autoscaler::ensure: "absent"`,
+		`kubernetes_addons::custom::addons: {"echo":{"ensure":"present","manifests":["kind: Namespace"]}}`,
+	} {
+		found := false
+		for _, v := range d.variables {
+			if v == exp {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("missing variable %s in %v", exp, d.variables)
+		}
+	}
+
+	for _, v := range d.variables {
+		if strings.HasPrefix(v, "prometheus::") {
+			t.Errorf("unexpected cluster wide variable %s", v)
+		}
+		// versions which are not set are left to the puppet classes
+		if strings.HasPrefix(v, "kubernetes_addons::tiller::version:") {
+			t.Errorf("unexpected variable %s", v)
+		}
+	}
+
+	d = hieraData{}
+	kubernetesClusterConfigPerRole(&c, clusterv1alpha1.KubernetesWorkerRoleName, &d)
+	if len(d.classes) > 0 || len(d.variables) > 0 {
+		t.Errorf("unexpected hiera data for workers: %+v", d)
+	}
+}
+
+func TestSpotInstancePoolConfig(t *testing.T) {
+	disabled := false
+	for _, tc := range []struct {
+		name    string
+		conf    *clusterv1alpha1.InstancePoolSpot
+		role    string
+		handler bool
+	}{
+		{"on-demand", nil, clusterv1alpha1.KubernetesWorkerRoleName, false},
+		{"spot worker", &clusterv1alpha1.InstancePoolSpot{}, clusterv1alpha1.KubernetesWorkerRoleName, true},
+		{"spot master", &clusterv1alpha1.InstancePoolSpot{}, clusterv1alpha1.KubernetesMasterRoleName, true},
+		{"spot without kubernetes", &clusterv1alpha1.InstancePoolSpot{}, "bastion", false},
+		{"handler disabled", &clusterv1alpha1.InstancePoolSpot{InterruptionHandler: &disabled}, clusterv1alpha1.KubernetesWorkerRoleName, false},
+	} {
+		d := hieraData{}
+		spotInstancePoolConfig(tc.conf, tc.role, &d)
+
+		found := false
+		for _, class := range d.classes {
+			if class == "kubernetes::spot_interruption_handler" {
+				found = true
+			}
+		}
+		if found != tc.handler {
+			t.Errorf("%s: unexpected interruption handler: act=%t exp=%t", tc.name, found, tc.handler)
+		}
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package addons
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+)
+
+type ValueType string
+
+const (
+	ValueTypeString  ValueType = "string"
+	ValueTypeInteger ValueType = "integer"
+	ValueTypeFloat   ValueType = "float"
+	ValueTypeBoolean ValueType = "boolean"
+)
+
+// custom addons are applied from a file named after them
+var customNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Spec describes an addon known to Tarmak and the puppet class deploying it
+type Spec struct {
+	Name  string
+	Class string
+	// Roles whose instances include the class, all roles if empty
+	Roles []string
+
+	EnabledByDefault bool
+
+	// whether the image and version can be overridden, the puppet class
+	// chooses them otherwise
+	Image    bool
+	Versions bool
+
+	// parameters of the class, which can be set as values
+	Values map[string]ValueSpec
+}
+
+type ValueSpec struct {
+	Type    ValueType
+	Default string
+	Allowed []string
+}
+
+// Addon is the configuration of an addon after resolving defaults and the
+// deprecated addon settings
+type Addon struct {
+	Name    string
+	Enabled bool
+	Version string
+	Image   string
+	Values  map[string]string
+
+	// manifests of custom addons
+	Manifests []string
+
+	// spec of known addons, nil for custom addons
+	Spec *Spec
+}
+
+// Custom returns true for addons that are not known to Tarmak
+func (a *Addon) Custom() bool {
+	return a.Spec == nil
+}
+
+// ForRole returns true if the addon is deployed by instances of the role
+func (a *Addon) ForRole(roleName string) bool {
+	if a.Spec == nil {
+		return roleName == clusterv1alpha1.KubernetesMasterRoleName
+	}
+	if len(a.Spec.Roles) == 0 {
+		return true
+	}
+	for _, r := range a.Spec.Roles {
+		if r == roleName {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the known addons followed by the custom addons of the
+// cluster. Addons configured in the addons list replace the deprecated
+// settings of the same addon.
+func Resolve(conf *clusterv1alpha1.ClusterKubernetes) []*Addon {
+	var result []*Addon
+	if conf == nil {
+		conf = &clusterv1alpha1.ClusterKubernetes{}
+	}
+
+	configured := make(map[string]*clusterv1alpha1.ClusterKubernetesAddon)
+	for pos := range conf.Addons {
+		configured[conf.Addons[pos].Name] = &conf.Addons[pos]
+	}
+
+	legacy := fromDeprecated(conf)
+
+	for _, spec := range registry {
+		addon, ok := legacy[spec.Name]
+		if c, exists := configured[spec.Name]; exists {
+			addon = fromConfig(c)
+		} else if !ok {
+			addon = &Addon{Name: spec.Name, Enabled: spec.EnabledByDefault}
+		}
+
+		addon.Spec = spec
+		for key, value := range spec.Values {
+			if _, ok := addon.Values[key]; !ok && value.Default != "" {
+				if addon.Values == nil {
+					addon.Values = make(map[string]string)
+				}
+				addon.Values[key] = value.Default
+			}
+		}
+
+		result = append(result, addon)
+	}
+
+	for pos := range conf.Addons {
+		if Known(conf.Addons[pos].Name) == nil {
+			result = append(result, fromConfig(&conf.Addons[pos]))
+		}
+	}
+
+	return result
+}
+
+// Find returns the resolved addon with the name, nil if there is none
+func Find(addons []*Addon, name string) *Addon {
+	for _, addon := range addons {
+		if addon.Name == name {
+			return addon
+		}
+	}
+	return nil
+}
+
+// OverprovisioningEnabled returns true if the cluster autoscaler is enabled
+// and overprovisions the cluster, which requires pod priorities
+func OverprovisioningEnabled(addons []*Addon) bool {
+	addon := Find(addons, ClusterAutoscaler)
+	return addon != nil && addon.Enabled && addon.Values[ValueEnableOverprovisioning] == "true"
+}
+
+func fromConfig(conf *clusterv1alpha1.ClusterKubernetesAddon) *Addon {
+	addon := &Addon{
+		Name:      conf.Name,
+		Enabled:   conf.Enabled == nil || *conf.Enabled,
+		Version:   conf.Version,
+		Image:     conf.Image,
+		Manifests: conf.Manifests,
+	}
+	if len(conf.Values) > 0 {
+		addon.Values = make(map[string]string, len(conf.Values))
+		for key, value := range conf.Values {
+			addon.Values[key] = value
+		}
+	}
+	return addon
+}
+
+// fromDeprecated converts the addon specific settings of the cluster
+func fromDeprecated(conf *clusterv1alpha1.ClusterKubernetes) map[string]*Addon {
+	addons := make(map[string]*Addon)
+
+	if c := conf.ClusterAutoscaler; c != nil {
+		addon := &Addon{
+			Name:    ClusterAutoscaler,
+			Enabled: c.Enabled,
+			Image:   c.Image,
+			Version: c.Version,
+			Values:  map[string]string{},
+		}
+		if c.ScaleDownUtilizationThreshold != nil {
+			addon.Values["scale_down_utilization_threshold"] = strconv.FormatFloat(*c.ScaleDownUtilizationThreshold, 'f', -1, 64)
+		}
+		if o := c.Overprovisioning; o != nil && o.Enabled {
+			addon.Values[ValueEnableOverprovisioning] = "true"
+			if o.Image != "" {
+				addon.Values["proportional_image"] = o.Image
+			}
+			if o.Version != "" {
+				addon.Values["proportional_version"] = o.Version
+			}
+			addon.Values["reserved_millicores_per_replica"] = strconv.Itoa(o.ReservedMillicoresPerReplica)
+			addon.Values["reserved_megabytes_per_replica"] = strconv.Itoa(o.ReservedMegabytesPerReplica)
+			addon.Values["cores_per_replica"] = strconv.Itoa(o.CoresPerReplica)
+			addon.Values["nodes_per_replica"] = strconv.Itoa(o.NodesPerReplica)
+			addon.Values["replica_count"] = strconv.Itoa(o.ReplicaCount)
+		}
+		addons[ClusterAutoscaler] = addon
+	}
+
+	if t := conf.Tiller; t != nil {
+		addons[Tiller] = &Addon{Name: Tiller, Enabled: t.Enabled, Image: t.Image, Version: t.Version}
+	}
+
+	if d := conf.Dashboard; d != nil {
+		addons[Dashboard] = &Addon{Name: Dashboard, Enabled: d.Enabled, Image: d.Image, Version: d.Version}
+	}
+
+	if g := conf.Grafana; g != nil {
+		addons[Grafana] = &Addon{Name: Grafana, Enabled: g.Enabled}
+	}
+
+	if h := conf.Heapster; h != nil {
+		addons[Heapster] = &Addon{Name: Heapster, Enabled: h.Enabled}
+	}
+
+	if i := conf.InfluxDB; i != nil {
+		addons[InfluxDB] = &Addon{Name: InfluxDB, Enabled: i.Enabled}
+	}
+
+	if p := conf.Prometheus; p != nil {
+		addon := &Addon{Name: Prometheus, Enabled: p.Enabled}
+		if p.Mode != "" {
+			addon.Values = map[string]string{"mode": p.Mode}
+		}
+		addons[Prometheus] = addon
+	}
+
+	return addons
+}
+
+// Validate checks the addons configured in the cluster against the registry
+func Validate(conf []clusterv1alpha1.ClusterKubernetesAddon) error {
+	var result *multierror.Error
+
+	names := make(map[string]bool)
+	for pos := range conf {
+		addon := &conf[pos]
+
+		if names[addon.Name] {
+			result = multierror.Append(result, fmt.Errorf("addon %s is configured more than once", addon.Name))
+			continue
+		}
+		names[addon.Name] = true
+
+		spec := Known(addon.Name)
+		if spec == nil {
+			if err := validateCustom(addon); err != nil {
+				result = multierror.Append(result, fmt.Errorf("addon %s: %s", addon.Name, err))
+			}
+			continue
+		}
+
+		for _, err := range spec.validate(addon) {
+			result = multierror.Append(result, fmt.Errorf("addon %s: %s", addon.Name, err))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func validateCustom(addon *clusterv1alpha1.ClusterKubernetesAddon) error {
+	if !customNameRegexp.MatchString(addon.Name) {
+		return fmt.Errorf("name of custom addons has to consist of lower case alphanumeric characters or '-', known addons are %s", strings.Join(knownNames(), ", "))
+	}
+	if len(addon.Manifests) == 0 {
+		return fmt.Errorf("unknown addon without manifests, known addons are %s", strings.Join(knownNames(), ", "))
+	}
+	if addon.Version != "" || addon.Image != "" || len(addon.Values) > 0 {
+		return fmt.Errorf("custom addons only support manifests")
+	}
+	return nil
+}
+
+// validate returns all errors of an addon's configuration
+func (s *Spec) validate(addon *clusterv1alpha1.ClusterKubernetesAddon) (errs []error) {
+	if len(addon.Manifests) > 0 {
+		errs = append(errs, fmt.Errorf("manifests are only supported by custom addons"))
+	}
+	if addon.Image != "" && !s.Image {
+		errs = append(errs, fmt.Errorf("image can't be overridden"))
+	}
+	if addon.Version != "" && !s.Versions {
+		errs = append(errs, fmt.Errorf("version can't be overridden"))
+	}
+
+	var keys []string
+	for key := range addon.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value, ok := s.Values[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown value %s, supported values are [%s]", key, strings.Join(s.valueNames(), " ")))
+			continue
+		}
+		if err := value.validate(addon.Values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("value %s: %s", key, err))
+		}
+	}
+
+	return errs
+}
+
+func (s *Spec) valueNames() []string {
+	var names []string
+	for name := range s.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (v ValueSpec) validate(value string) error {
+	var err error
+	switch v.Type {
+	case ValueTypeInteger:
+		_, err = strconv.Atoi(value)
+	case ValueTypeFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case ValueTypeBoolean:
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid %s", value, v.Type)
+	}
+
+	if len(v.Allowed) == 0 {
+		return nil
+	}
+	for _, allowed := range v.Allowed {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' is not one of [%s]", value, strings.Join(v.Allowed, " "))
+}
+
+func knownNames() []string {
+	var names []string
+	for _, spec := range registry {
+		names = append(names, spec.Name)
+	}
+	return names
+}
@@ -0,0 +1,203 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package addons
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+)
+
+func boolPointer(b bool) *bool {
+	return &b
+}
+
+func TestResolve_defaults(t *testing.T) {
+	resolved := Resolve(nil)
+
+	var enabled []string
+	for _, addon := range resolved {
+		if addon.Enabled {
+			enabled = append(enabled, addon.Name)
+		}
+	}
+	if exp := []string{Grafana, Heapster, InfluxDB, Prometheus}; !reflect.DeepEqual(enabled, exp) {
+		t.Errorf("unexpected enabled addons: act=%v exp=%v", enabled, exp)
+	}
+
+	// the puppet class chooses the version if it isn't set
+	if act := Find(resolved, Tiller).Version; act != "" {
+		t.Errorf("unexpected tiller version: %s", act)
+	}
+	if act, exp := Find(resolved, Prometheus).Values["mode"], clusterv1alpha1.PrometheusModeFull; act != exp {
+		t.Errorf("unexpected prometheus mode: act=%s exp=%s", act, exp)
+	}
+}
+
+func TestResolve_deprecated(t *testing.T) {
+	threshold := 0.4
+	conf := &clusterv1alpha1.ClusterKubernetes{
+		Tiller: &clusterv1alpha1.ClusterKubernetesTiller{
+			Enabled: true,
+			Version: "2.10.0",
+		},
+		Heapster: &clusterv1alpha1.ClusterKubernetesHeapster{
+			Enabled: false,
+		},
+		Prometheus: &clusterv1alpha1.ClusterKubernetesPrometheus{
+			Enabled: true,
+			Mode:    clusterv1alpha1.PrometheusModeExternalExportersOnly,
+		},
+		ClusterAutoscaler: &clusterv1alpha1.ClusterKubernetesClusterAutoscaler{
+			Enabled:                       true,
+			ScaleDownUtilizationThreshold: &threshold,
+			Overprovisioning: &clusterv1alpha1.ClusterKubernetesClusterAutoscalerOverprovisioning{
+				Enabled:         true,
+				CoresPerReplica: 4,
+			},
+		},
+	}
+	resolved := Resolve(conf)
+
+	if tiller := Find(resolved, Tiller); !tiller.Enabled || tiller.Version != "2.10.0" {
+		t.Errorf("unexpected tiller: %+v", tiller)
+	}
+	if Find(resolved, Heapster).Enabled {
+		t.Error("expected heapster to be disabled")
+	}
+	if act, exp := Find(resolved, Prometheus).Values["mode"], clusterv1alpha1.PrometheusModeExternalExportersOnly; act != exp {
+		t.Errorf("unexpected prometheus mode: act=%s exp=%s", act, exp)
+	}
+
+	exp := map[string]string{
+		"scale_down_utilization_threshold": "0.4",
+		"enable_overprovisioning":          "true",
+		"reserved_millicores_per_replica":  "0",
+		"reserved_megabytes_per_replica":   "0",
+		"cores_per_replica":                "4",
+		"nodes_per_replica":                "0",
+		"replica_count":                    "0",
+	}
+	if act := Find(resolved, ClusterAutoscaler).Values; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected cluster autoscaler values: act=%v exp=%v", act, exp)
+	}
+	if !OverprovisioningEnabled(resolved) {
+		t.Error("expected overprovisioning to be enabled")
+	}
+}
+
+func TestResolve_addons(t *testing.T) {
+	conf := &clusterv1alpha1.ClusterKubernetes{
+		Tiller: &clusterv1alpha1.ClusterKubernetesTiller{
+			Enabled: true,
+			Image:   "example.com/tiller",
+		},
+		Addons: []clusterv1alpha1.ClusterKubernetesAddon{
+			{
+				Name:    Tiller,
+				Version: "2.11.0",
+			},
+			{
+				Name:    Grafana,
+				Enabled: boolPointer(false),
+			},
+			{
+				Name:      "echo",
+				Manifests: []string{"kind: Namespace"},
+			},
+		},
+	}
+	resolved := Resolve(conf)
+
+	// the addon replaces the deprecated settings
+	if tiller := Find(resolved, Tiller); !tiller.Enabled || tiller.Version != "2.11.0" || tiller.Image != "" {
+		t.Errorf("unexpected tiller: %+v", tiller)
+	}
+	if Find(resolved, Grafana).Enabled {
+		t.Error("expected grafana to be disabled")
+	}
+
+	custom := resolved[len(resolved)-1]
+	if !custom.Custom() || custom.Name != "echo" || !custom.Enabled {
+		t.Errorf("unexpected custom addon: %+v", custom)
+	}
+	if !custom.ForRole(clusterv1alpha1.KubernetesMasterRoleName) || custom.ForRole(clusterv1alpha1.KubernetesWorkerRoleName) {
+		t.Error("expected custom addon to be applied by masters only")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	validAddons := [][]clusterv1alpha1.ClusterKubernetesAddon{
+		nil,
+		{
+			{Name: Tiller, Version: "2.11.0", Values: map[string]string{"namespace": "tiller"}},
+			{Name: ClusterAutoscaler, Values: map[string]string{"scale_down_utilization_threshold": "0.5", "enable_overprovisioning": "false"}},
+			{Name: Prometheus, Values: map[string]string{"mode": clusterv1alpha1.PrometheusModeExternalScrapeTargetsOnly}},
+		},
+		{
+			{Name: "echo", Manifests: []string{"kind: Namespace"}},
+		},
+	}
+
+	for _, addons := range validAddons {
+		if err := Validate(addons); err != nil {
+			t.Error(err)
+		}
+	}
+
+	known := "known addons are cluster-autoscaler, dashboard, grafana, heapster, influxdb, prometheus, tiller"
+	invalidAddons := []struct {
+		addons []clusterv1alpha1.ClusterKubernetesAddon
+		err    string
+	}{
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: Tiller}, {Name: Tiller}},
+			"addon tiller is configured more than once",
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: "echo"}},
+			"addon echo: unknown addon without manifests, " + known,
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: "Echo_1", Manifests: []string{"kind: Namespace"}}},
+			"addon Echo_1: name of custom addons has to consist of lower case alphanumeric characters or '-', " + known,
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: "echo", Version: "1.0", Manifests: []string{"kind: Namespace"}}},
+			"addon echo: custom addons only support manifests",
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: Tiller, Manifests: []string{"kind: Namespace"}}},
+			"addon tiller: manifests are only supported by custom addons",
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: Tiller, Values: map[string]string{"replicas": "2"}}},
+			"addon tiller: unknown value replicas, supported values are [namespace]",
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: Dashboard, Values: map[string]string{"replicas": "two"}}},
+			"addon dashboard: value replicas: 'two' is not a valid integer",
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: Prometheus, Values: map[string]string{"mode": "Partial"}}},
+			"addon prometheus: value mode: 'Partial' is not one of [Full ExternalScrapeTargetsOnly ExternalExportersOnly]",
+		},
+		{
+			[]clusterv1alpha1.ClusterKubernetesAddon{{Name: Prometheus, Version: "2.0.0"}},
+			"addon prometheus: version can't be overridden",
+		},
+	}
+
+	for _, invalid := range invalidAddons {
+		err := Validate(invalid.addons)
+		if err == nil {
+			t.Errorf("expected %+v to cause a validation error", invalid.addons)
+			continue
+		}
+		if errs := multierror.Append(nil, err).Errors; len(errs) != 1 || errs[0].Error() != invalid.err {
+			t.Errorf("unexpected error: act=%s exp=%s", err, invalid.err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package addons
+
+import (
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	ClusterAutoscaler = "cluster-autoscaler"
+	Dashboard         = "dashboard"
+	Grafana           = "grafana"
+	Heapster          = "heapster"
+	InfluxDB          = "influxdb"
+	Prometheus        = "prometheus"
+	Tiller            = "tiller"
+
+	// value of the cluster autoscaler enabling overprovisioning
+	ValueEnableOverprovisioning = "enable_overprovisioning"
+)
+
+// registry contains the addons known to Tarmak, ordered by name
+var registry = []*Spec{
+	{
+		Name:     ClusterAutoscaler,
+		Class:    "kubernetes_addons::cluster_autoscaler",
+		Roles:    []string{clusterv1alpha1.KubernetesMasterRoleName},
+		Image:    true,
+		Versions: true,
+		Values: map[string]ValueSpec{
+			"limit_cpu":                        {Type: ValueTypeString},
+			"limit_mem":                        {Type: ValueTypeString},
+			"request_cpu":                      {Type: ValueTypeString},
+			"request_mem":                      {Type: ValueTypeString},
+			"scale_down_utilization_threshold": {Type: ValueTypeFloat},
+			ValueEnableOverprovisioning:        {Type: ValueTypeBoolean},
+			"proportional_image":               {Type: ValueTypeString},
+			"proportional_version":             {Type: ValueTypeString},
+			"reserved_millicores_per_replica":  {Type: ValueTypeInteger},
+			"reserved_megabytes_per_replica":   {Type: ValueTypeInteger},
+			"cores_per_replica":                {Type: ValueTypeInteger},
+			"nodes_per_replica":                {Type: ValueTypeInteger},
+			"replica_count":                    {Type: ValueTypeInteger},
+		},
+	},
+	{
+		Name:     Dashboard,
+		Class:    "kubernetes_addons::dashboard",
+		Roles:    []string{clusterv1alpha1.KubernetesMasterRoleName},
+		Image:    true,
+		Versions: true,
+		Values: map[string]ValueSpec{
+			"limit_cpu":   {Type: ValueTypeString},
+			"limit_mem":   {Type: ValueTypeString},
+			"request_cpu": {Type: ValueTypeString},
+			"request_mem": {Type: ValueTypeString},
+			"replicas":    {Type: ValueTypeInteger},
+		},
+	},
+	{
+		Name:             Grafana,
+		Class:            "kubernetes_addons::grafana",
+		Roles:            []string{clusterv1alpha1.KubernetesMasterRoleName},
+		EnabledByDefault: true,
+		Image:            true,
+		Versions:         true,
+	},
+	{
+		Name:             Heapster,
+		Class:            "kubernetes_addons::heapster",
+		Roles:            []string{clusterv1alpha1.KubernetesMasterRoleName},
+		EnabledByDefault: true,
+		Image:            true,
+		Versions:         true,
+		Values: map[string]ValueSpec{
+			"cpu":       {Type: ValueTypeString},
+			"mem":       {Type: ValueTypeString},
+			"extra_cpu": {Type: ValueTypeString},
+			"extra_mem": {Type: ValueTypeString},
+			"sink":      {Type: ValueTypeString},
+		},
+	},
+	{
+		Name:             InfluxDB,
+		Class:            "kubernetes_addons::influxdb",
+		Roles:            []string{clusterv1alpha1.KubernetesMasterRoleName},
+		EnabledByDefault: true,
+		Image:            true,
+		Versions:         true,
+	},
+	{
+		// prometheus runs exporters on every instance of the cluster
+		Name:             Prometheus,
+		Class:            "prometheus",
+		EnabledByDefault: true,
+		Values: map[string]ValueSpec{
+			"mode": {
+				Type:    ValueTypeString,
+				Default: clusterv1alpha1.PrometheusModeFull,
+				Allowed: []string{
+					clusterv1alpha1.PrometheusModeFull,
+					clusterv1alpha1.PrometheusModeExternalScrapeTargetsOnly,
+					clusterv1alpha1.PrometheusModeExternalExportersOnly,
+				},
+			},
+		},
+	},
+	{
+		Name:     Tiller,
+		Class:    "kubernetes_addons::tiller",
+		Roles:    []string{clusterv1alpha1.KubernetesMasterRoleName},
+		Image:    true,
+		Versions: true,
+		Values: map[string]ValueSpec{
+			"namespace": {Type: ValueTypeString},
+		},
+	},
+}
+
+// Known returns the addon known to Tarmak with the name, nil if there is none
+func Known(name string) *Spec {
+	for _, spec := range registry {
+		if spec.Name == name {
+			return spec
+		}
+	}
+	return nil
+}
+
+// Registry returns all addons known to Tarmak
+func Registry() []*Spec {
+	return registry
+}
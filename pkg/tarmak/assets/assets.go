@@ -5,4 +5,4 @@
 
 package assets
 
-//go:generate go-bindata -prefix ../../../ -pkg $GOPACKAGE -o assets_bindata.go ../../../terraform/amazon/modules/... ../../../terraform/amazon/templates/... ../../../puppet/... ../../../packer/...
+//go:generate go-bindata -prefix ../../../ -pkg $GOPACKAGE -o assets_bindata.go ../../../terraform/amazon/modules/... ../../../terraform/amazon/templates/... ../../../terraform/google/modules/... ../../../terraform/google/templates/... ../../../terraform/azure/modules/... ../../../terraform/azure/templates/... ../../../puppet/... ../../../packer/...
@@ -14,6 +14,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/addons"
+	"github.com/jetstack/tarmak/pkg/tarmak/cluster/firewall"
 	"github.com/jetstack/tarmak/pkg/tarmak/instance_pool"
 	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
 	"github.com/jetstack/tarmak/pkg/tarmak/role"
@@ -61,8 +63,11 @@ func NewFromConfig(environment interfaces.Environment, conf *clusterv1alpha1.Clu
 		ctx:         environment.Tarmak().CancellationContext(),
 	}
 
+	// instance pools are set up regardless of validation errors, to report
+	// all errors of the config at once
+	var result error
 	if err := cluster.Validate(); err != nil {
-		return nil, err
+		result = multierror.Append(result, err)
 	}
 
 	cluster.roles = make(map[string]*role.Role)
@@ -90,7 +95,6 @@ func NewFromConfig(environment interfaces.Environment, conf *clusterv1alpha1.Clu
 	}
 
 	// setup instance pools
-	var result error
 	for pos, _ := range cluster.conf.InstancePools {
 		instancePool := cluster.conf.InstancePools[pos]
 		// create instance pools
@@ -290,25 +294,21 @@ func (c *Cluster) validateInstancePools() error {
 		}
 	}
 
-	if result.ErrorOrNil() != nil {
-		return result.ErrorOrNil()
-	}
-
 	// validate instance pool types according to cluster type
 	if err := c.validateClusterInstancePoolTypes(); err != nil {
-		return err
+		result = multierror.Append(result, err)
 	}
 
 	// validate instance pool count according to cluster type
 	if err := c.validateClusterInstancePoolCount(); err != nil {
-		return err
+		result = multierror.Append(result, err)
 	}
 
 	if err := c.validateSubnets(); err != nil {
-		return err
+		result = multierror.Append(result, err)
 	}
 
-	return nil
+	return result.ErrorOrNil()
 }
 
 // Verify cluster
@@ -319,6 +319,12 @@ func (c *Cluster) Verify() error {
 		return fmt.Errorf("failed to verify tarmak provider: %s", err)
 	}
 
+	// existing machines are neither booted from images nor is the hub
+	// applied using terraform
+	if c.Environment().Provider().Cloud() == clusterv1alpha1.CloudBaremetal {
+		return result.ErrorOrNil()
+	}
+
 	if err := c.VerifyInstancePools(); err != nil {
 		result = multierror.Append(result, err)
 	}
@@ -425,6 +431,16 @@ func (c *Cluster) Validate() error {
 		result = multierror.Append(result, err)
 	}
 
+	// validate wing
+	if err := c.validateWing(); err != nil {
+		result = multierror.Append(result, fmt.Errorf("invalid wing configuration: %s", err))
+	}
+
+	// validate user defined firewall rules
+	if _, err := firewall.ClusterRules(c.conf.InstancePools); err != nil {
+		result = multierror.Append(result, fmt.Errorf("invalid firewall configuration: %s", err))
+	}
+
 	// validate overprovisioning
 	if err := c.validateClusterAutoscaler(); err != nil {
 		result = multierror.Append(result, fmt.Errorf("invalid overprovisioning configuration: %s", err))
@@ -432,6 +448,16 @@ func (c *Cluster) Validate() error {
 
 	//validate apiserver
 	if k := c.Config().Kubernetes; k != nil {
+		// validate addons
+		if err := addons.Validate(k.Addons); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid addons configuration: %s", err))
+		}
+
+		// validate version skew of instance pools
+		if err := c.validateKubernetesVersions(); err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid Kubernetes versions: %s", err))
+		}
+
 		if apiServer := k.APIServer; apiServer != nil {
 			if err := c.validateAPIServer(); err != nil {
 				result = multierror.Append(result, err)
@@ -477,28 +503,6 @@ func (c *Cluster) validateNetwork() (result error) {
 	return nil
 }
 
-// validate logging configuration
-func (c *Cluster) validateLoggingSinks() (result error) {
-
-	if c.Config().LoggingSinks != nil {
-		for index, loggingSink := range c.Config().LoggingSinks {
-			if loggingSink.Elasticsearch != nil && loggingSink.Elasticsearch.AmazonESProxy != nil {
-				if loggingSink.Elasticsearch.HTTPBasicAuth != nil {
-					return fmt.Errorf("cannot enable AWS elasticsearch proxy and HTTP basic auth for logging sink %d", index)
-				}
-				if loggingSink.Elasticsearch.TLSVerify {
-					return fmt.Errorf("cannot enable AWS elasticsearch proxy and force certificate validation for logging sink %d", index)
-				}
-				if loggingSink.Elasticsearch.TLSCA != "" {
-					return fmt.Errorf("cannot enable AWS elasticsearch proxy and specify a custom CA for logging sink %d", index)
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
 // validate overprovisioning
 func (c *Cluster) validateClusterAutoscaler() (result error) {
 
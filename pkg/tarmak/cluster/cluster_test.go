@@ -3,10 +3,14 @@ package cluster
 
 import (
 	"io/ioutil"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-multierror"
 	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak/config"
@@ -234,6 +238,319 @@ func TestValidateClusterAutoscaler(t *testing.T) {
 	}
 }
 
+func TestValidateLoggingSinks(t *testing.T) {
+	clusterConfig := config.NewClusterSingle("single", "cluster")
+	cluster := &Cluster{
+		conf: clusterConfig,
+	}
+
+	validLoggingSinks := []*clusterv1alpha1.LoggingSink{
+		&clusterv1alpha1.LoggingSink{
+			Elasticsearch: &clusterv1alpha1.LoggingSinkElasticsearch{Host: "es.example.com", Port: 443},
+			Types:         []clusterv1alpha1.LoggingSinkType{"platform", "audit"},
+		},
+		&clusterv1alpha1.LoggingSink{
+			Loki: &clusterv1alpha1.LoggingSinkLoki{
+				Host:          "loki.example.com",
+				Port:          443,
+				TenantID:      "team-a",
+				Labels:        map[string]string{"job": "fluent-bit", "cluster_name": "prod"},
+				HTTPBasicAuth: &clusterv1alpha1.HTTPBasicAuth{Username: "user", Password: "secret"},
+			},
+		},
+		&clusterv1alpha1.LoggingSink{
+			SplunkHEC: &clusterv1alpha1.LoggingSinkSplunkHEC{Host: "splunk.example.com", Port: 8088, Token: "token", TLSCert: "cert", TLSKey: "key"},
+		},
+		&clusterv1alpha1.LoggingSink{
+			HTTP: &clusterv1alpha1.LoggingSinkHTTP{Host: "logs.example.com", Port: 443, URI: "/ingest", Format: "json_lines", Headers: map[string]string{"Authorization": "Bearer token"}},
+		},
+		&clusterv1alpha1.LoggingSink{
+			Kafka: &clusterv1alpha1.LoggingSinkKafka{
+				Brokers: []string{"kafka-0.example.com:9093", "kafka-1.example.com:9093"},
+				Topic:   "logs",
+				SASL:    &clusterv1alpha1.KafkaSASL{Mechanism: "SCRAM-SHA-512", Username: "user", Password: "secret"},
+			},
+		},
+		&clusterv1alpha1.LoggingSink{
+			S3: &clusterv1alpha1.LoggingSinkS3{Bucket: "logs", Region: "eu-west-1", Prefix: "fluent-bit-logs"},
+		},
+	}
+
+	for _, loggingSink := range validLoggingSinks {
+		clusterConfig.LoggingSinks = []*clusterv1alpha1.LoggingSink{loggingSink}
+		if err := cluster.validateLoggingSinks(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	tlsDisabled := false
+	invalidLoggingSinks := []struct {
+		loggingSink *clusterv1alpha1.LoggingSink
+		err         string
+	}{
+		{
+			&clusterv1alpha1.LoggingSink{},
+			"invalid logging sink 0: no destination specified, expected one of elasticsearch, loki, splunkHEC, http, kafka or s3",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				Elasticsearch: &clusterv1alpha1.LoggingSinkElasticsearch{Host: "es.example.com", Port: 443},
+				Loki:          &clusterv1alpha1.LoggingSinkLoki{Host: "loki.example.com", Port: 443},
+			},
+			"invalid logging sink 0: only one destination can be specified per logging sink, found elasticsearch, loki",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				Elasticsearch: &clusterv1alpha1.LoggingSinkElasticsearch{Host: "es.example.com", Port: 443},
+				Types:         []clusterv1alpha1.LoggingSinkType{"kernel"},
+			},
+			"invalid logging sink 0: unknown type 'kernel', valid types are: platform, application, audit, all",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				Elasticsearch: &clusterv1alpha1.LoggingSinkElasticsearch{
+					Host:          "es.example.com",
+					Port:          443,
+					AmazonESProxy: &clusterv1alpha1.AmazonESProxy{Port: 9200},
+					HTTPBasicAuth: &clusterv1alpha1.HTTPBasicAuth{Username: "user"},
+				},
+			},
+			"invalid logging sink 0: elasticsearch: cannot enable AWS elasticsearch proxy and HTTP basic auth",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				Loki: &clusterv1alpha1.LoggingSinkLoki{Port: 3100},
+			},
+			"invalid logging sink 0: loki: host is required",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				Loki: &clusterv1alpha1.LoggingSinkLoki{Host: "loki.example.com", Port: 3100, Labels: map[string]string{"cluster-name": "prod"}},
+			},
+			"invalid logging sink 0: loki: invalid label name 'cluster-name'",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				SplunkHEC: &clusterv1alpha1.LoggingSinkSplunkHEC{Host: "splunk.example.com", Port: 8088},
+			},
+			"invalid logging sink 0: splunkHEC: token is required",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				SplunkHEC: &clusterv1alpha1.LoggingSinkSplunkHEC{Host: "splunk.example.com", Port: 8088, Token: "token", TLSCert: "cert"},
+			},
+			"invalid logging sink 0: splunkHEC: client certificate and key need to be specified together",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				HTTP: &clusterv1alpha1.LoggingSinkHTTP{Host: "https://logs.example.com", Port: 443},
+			},
+			"invalid logging sink 0: http: invalid host 'https://logs.example.com', expected a hostname or IP address",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				HTTP: &clusterv1alpha1.LoggingSinkHTTP{Host: "logs.example.com", Port: 443, Format: "xml"},
+			},
+			"invalid logging sink 0: http: unknown format 'xml', valid formats are: json, json_lines, json_stream, msgpack, gelf",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				HTTP: &clusterv1alpha1.LoggingSinkHTTP{Host: "logs.example.com", Port: 80, TLS: &tlsDisabled, TLSVerify: true},
+			},
+			"invalid logging sink 0: http: cannot specify TLS options with TLS disabled",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				Kafka: &clusterv1alpha1.LoggingSinkKafka{Brokers: []string{"kafka-0.example.com"}, Topic: "logs"},
+			},
+			"invalid logging sink 0: kafka: invalid broker 'kafka-0.example.com', expected host:port",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				Kafka: &clusterv1alpha1.LoggingSinkKafka{
+					Brokers: []string{"kafka-0.example.com:9093"},
+					Topic:   "logs",
+					SASL:    &clusterv1alpha1.KafkaSASL{Mechanism: "GSSAPI", Username: "user", Password: "secret"},
+				},
+			},
+			"invalid logging sink 0: kafka: unknown SASL mechanism 'GSSAPI', valid mechanisms are: PLAIN, SCRAM-SHA-256, SCRAM-SHA-512",
+		},
+		{
+			&clusterv1alpha1.LoggingSink{
+				S3: &clusterv1alpha1.LoggingSinkS3{Region: "eu-west-1"},
+			},
+			"invalid logging sink 0: s3: bucket is required",
+		},
+	}
+
+	for _, invalid := range invalidLoggingSinks {
+		clusterConfig.LoggingSinks = []*clusterv1alpha1.LoggingSink{invalid.loggingSink}
+		err := cluster.validateLoggingSinks()
+		if err == nil {
+			t.Errorf("expected %+v to cause a validation error", invalid.loggingSink)
+			continue
+		}
+		if err.Error() != invalid.err {
+			t.Errorf("unexpected error: act=%s exp=%s", err, invalid.err)
+		}
+	}
+}
+
+func TestValidateWing(t *testing.T) {
+	duration := func(d string) *metav1.Duration {
+		parsed, err := time.ParseDuration(d)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return &metav1.Duration{Duration: parsed}
+	}
+
+	clusterConfig := config.NewClusterSingle("single", "cluster")
+	cluster := &Cluster{
+		conf: clusterConfig,
+	}
+
+	// not configured
+	if err := cluster.validateWing(); err != nil {
+		t.Errorf("validation should pass when wing is not configured: %s", err)
+	}
+	if env := cluster.WingEnvironment(); len(env) != 0 {
+		t.Errorf("unexpected wing environment: %+v", env)
+	}
+
+	// retries and reconverge
+	clusterConfig.Wing = &clusterv1alpha1.ClusterWing{Converge: &clusterv1alpha1.ClusterWingConverge{
+		RetryInitialInterval: duration("1m"),
+		RetryMaxInterval:     duration("10m"),
+		ReconvergeInterval:   duration("6h"),
+		ReconvergeJitter:     duration("30m"),
+	}}
+	if err := cluster.validateWing(); err != nil {
+		t.Errorf("validation should pass with retries and reconverge configured: %s", err)
+	}
+	exp := map[string]string{
+		"WING_CONVERGE_RETRY_INITIAL_INTERVAL": "1m0s",
+		"WING_CONVERGE_RETRY_MAX_INTERVAL":     "10m0s",
+		"WING_RECONVERGE_INTERVAL":             "6h0m0s",
+		"WING_RECONVERGE_JITTER":               "30m0s",
+	}
+	if env := cluster.WingEnvironment(); !reflect.DeepEqual(env, exp) {
+		t.Errorf("unexpected wing environment: act=%+v exp=%+v", env, exp)
+	}
+
+	invalidConverges := []struct {
+		converge *clusterv1alpha1.ClusterWingConverge
+		err      string
+	}{
+		{
+			&clusterv1alpha1.ClusterWingConverge{RetryMaxElapsedTime: duration("-1h")},
+			"retryMaxElapsedTime must not be negative",
+		},
+		{
+			&clusterv1alpha1.ClusterWingConverge{RetryInitialInterval: duration("10m"), RetryMaxInterval: duration("1m")},
+			"retryMaxInterval must not be shorter than retryInitialInterval",
+		},
+		{
+			&clusterv1alpha1.ClusterWingConverge{ReconvergeJitter: duration("30m")},
+			"reconvergeJitter requires a reconvergeInterval",
+		},
+	}
+
+	for _, invalid := range invalidConverges {
+		clusterConfig.Wing = &clusterv1alpha1.ClusterWing{Converge: invalid.converge}
+		err := cluster.validateWing()
+		if err == nil {
+			t.Errorf("expected %+v to cause a validation error", invalid.converge)
+			continue
+		}
+		if errs := multierror.Append(nil, err).Errors; len(errs) != 1 || errs[0].Error() != invalid.err {
+			t.Errorf("unexpected error: act=%s exp=%s", err, invalid.err)
+		}
+	}
+}
+
+func TestCheckKubernetesUpgrade(t *testing.T) {
+	validUpgrades := [][]string{
+		{"1.12.9", "1.13.5"},
+		{"1.12.9", "1.12.10"},
+	}
+
+	for _, upgrade := range validUpgrades {
+		if err := CheckKubernetesUpgrade(upgrade[0], upgrade[1]); err != nil {
+			t.Error(err)
+		}
+	}
+
+	invalidUpgrades := []struct {
+		from, to string
+		err      string
+	}{
+		{"1.12.9", "1.14.0", "upgrading from 1.12.9 to 1.14.0 skips a minor version, upgrade to 1.13 first"},
+		{"1.12.9", "1.12.9", "target version 1.12.9 has to be newer than the current version 1.12.9"},
+		{"1.13.5", "1.12.9", "target version 1.12.9 has to be newer than the current version 1.13.5"},
+		{"1.12.9", "2.0.0", "upgrading from 1.12.9 to 2.0.0 skips a minor version, upgrade to 1.13 first"},
+		{"1.12.9", "latest", "invalid target Kubernetes version 'latest': Malformed version: latest"},
+	}
+
+	for _, invalid := range invalidUpgrades {
+		err := CheckKubernetesUpgrade(invalid.from, invalid.to)
+		if err == nil {
+			t.Errorf("expected upgrade from %s to %s to cause an error", invalid.from, invalid.to)
+			continue
+		}
+		if err.Error() != invalid.err {
+			t.Errorf("unexpected error: act=%s exp=%s", err, invalid.err)
+		}
+	}
+}
+
+func TestValidateKubernetesVersions(t *testing.T) {
+	clusterConfig := config.NewClusterSingle("single", "cluster")
+	clusterConfig.Kubernetes.Version = "1.12.9"
+	cluster := &Cluster{
+		conf: clusterConfig,
+	}
+
+	var worker *clusterv1alpha1.InstancePool
+	for pos := range clusterConfig.InstancePools {
+		if clusterConfig.InstancePools[pos].Type == clusterv1alpha1.InstancePoolTypeWorker {
+			worker = &clusterConfig.InstancePools[pos]
+		}
+	}
+	if worker == nil {
+		t.Fatal("no worker instance pool found")
+	}
+
+	validVersions := []string{"", "1.12.9", "1.11.10", "1.10.13"}
+
+	for _, version := range validVersions {
+		worker.Kubernetes = &clusterv1alpha1.InstancePoolKubernetes{Version: version}
+		if err := cluster.validateKubernetesVersions(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	invalidVersions := []struct {
+		version string
+		err     string
+	}{
+		{"1.9.11", "instance pool worker: kubelet version 1.9.11 is more than 2 minor versions older than the API server version 1.12.9"},
+		{"1.13.5", "instance pool worker: kubelet version 1.13.5 is newer than the API server version 1.12.9"},
+	}
+
+	for _, invalid := range invalidVersions {
+		worker.Kubernetes = &clusterv1alpha1.InstancePoolKubernetes{Version: invalid.version}
+		err := cluster.validateKubernetesVersions()
+		if err == nil {
+			t.Errorf("expected instance pool version %s to cause a validation error", invalid.version)
+			continue
+		}
+		if errs := multierror.Append(nil, err).Errors; len(errs) != 1 || errs[0].Error() != invalid.err {
+			t.Errorf("unexpected error: act=%s exp=%s", err, invalid.err)
+		}
+	}
+}
+
 func TestCluster_ValidateClusterInstancePoolTypesHub(t *testing.T) {
 	clusterConfig := config.NewHub("multi")
 	config.ApplyDefaults(clusterConfig)
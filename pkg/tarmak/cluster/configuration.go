@@ -4,8 +4,11 @@ package cluster
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
@@ -38,6 +41,147 @@ func (c *Cluster) UploadConfiguration() error {
 	)
 }
 
+// This returns the hash of the current puppet.tar.gz, in the same format as
+// wing reports the hash of the manifest it has applied
+func (c *Cluster) ConfigurationHash() (string, error) {
+	buffer := new(bytes.Buffer)
+
+	// get puppet config
+	err := c.Environment().Tarmak().Puppet().TarGz(buffer)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(buffer.Bytes())), nil
+}
+
+// This runs the current puppet.tar.gz in noop mode on every instance in the
+// cluster and collects their reports
+func (c *Cluster) DryRunConfiguration() ([]*wingv1alpha1.Instance, error) {
+	c.log.Infof("requesting a dry run of the latest manifest on all instances")
+
+	buffer := new(bytes.Buffer)
+
+	// get puppet config
+	err := c.Environment().Tarmak().Puppet().TarGz(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	md5Hash := md5.Sum(buffer.Bytes())
+	sha256Hash := fmt.Sprintf("sha256:%x", sha256.Sum256(buffer.Bytes()))
+
+	manifestURL, err := c.Environment().Provider().UploadDryRunConfiguration(
+		c,
+		bytes.NewReader(buffer.Bytes()),
+		hex.EncodeToString(md5Hash[:]),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload dry run manifest: %s", err)
+	}
+
+	// connect to wing
+	client, err := c.wingInstanceClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to wing API on bastion: %s", err)
+	}
+
+	// list instances
+	instances, err := c.listInstances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %s", err)
+	}
+
+	requested := make(map[string]bool)
+	for pos, _ := range instances {
+		instance := instances[pos]
+		if instance.Spec == nil {
+			instance.Spec = &wingv1alpha1.InstanceSpec{}
+		}
+		instance.Spec.DryRun = &wingv1alpha1.InstanceSpecManifest{
+			Path: manifestURL,
+			Hash: sha256Hash,
+		}
+
+		if _, err := client.Update(instance); err != nil {
+			c.log.Warnf("error updating instance %s in wing API: %s", instance.Name, err)
+			continue
+		}
+		requested[instance.Name] = true
+	}
+
+	return c.waitForDryRun(requested, sha256Hash)
+}
+
+// This waits until all requested instances have reported a dry run result
+// for the manifest hash
+func (c *Cluster) waitForDryRun(requested map[string]bool, hash string) ([]*wingv1alpha1.Instance, error) {
+	retries := retries
+	for {
+		instances, err := c.listInstances()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list instances: %s", err)
+		}
+
+		var reported []*wingv1alpha1.Instance
+		var pending []*wingv1alpha1.Instance
+		for pos, _ := range instances {
+			instance := instances[pos]
+			if !requested[instance.Name] {
+				continue
+			}
+
+			if dryRunReported(instance, hash) {
+				reported = append(reported, instance)
+			} else {
+				pending = append(pending, instance)
+			}
+		}
+
+		if len(pending) == 0 {
+			c.log.Infof("all instances reported their dry run")
+			return reported, nil
+		}
+		c.log.Debugf("waiting for dry run of instances %s", outputInstances(pending))
+
+		select {
+		case <-c.ctx.Done():
+			return nil, c.ctx.Err()
+		default:
+		}
+
+		retries--
+		if retries == 0 {
+			break
+		}
+		time.Sleep(time.Second * 5)
+	}
+
+	return nil, fmt.Errorf("instances failed to report their dry run in time")
+}
+
+func dryRunReported(instance *wingv1alpha1.Instance, hash string) bool {
+	if instance.Spec == nil || instance.Spec.DryRun == nil {
+		return false
+	}
+	if instance.Status == nil || instance.Status.DryRun == nil {
+		return false
+	}
+
+	status := instance.Status.DryRun
+	if status.State == wingv1alpha1.InstanceManifestStateConverging {
+		return false
+	}
+
+	// errors are reported before the manifest hash is known
+	failed := status.State == wingv1alpha1.InstanceManifestStateError || status.State == wingv1alpha1.InstanceManifestStateVerificationFailed
+	if !failed && status.Hash != hash {
+		return false
+	}
+
+	return status.LastUpdateTimestamp.Time.After(instance.Spec.DryRun.RequestTimestamp.Time)
+}
+
 // This enforces a reapply of the puppet.tar.gz on every instance in the cluster
 func (c *Cluster) ReapplyConfiguration() error {
 	c.log.Infof("making sure all instances apply the latest manifest")
@@ -72,6 +216,98 @@ func (c *Cluster) ReapplyConfiguration() error {
 	return nil
 }
 
+// This pins instances to the manifest with the hash until the next
+// ReapplyConfiguration. All instances are pinned if no names are given. If the
+// hash is the one of the current puppet.tar.gz, it is uploaded without making
+// it the latest one for instances which haven't cached it, earlier manifests
+// are only applied from the cache of wing.
+func (c *Cluster) RollbackConfiguration(hash string, names []string) error {
+	manifestURL, err := c.pinnedManifestURL(hash)
+	if err != nil {
+		return err
+	}
+
+	client, err := c.wingInstanceClient()
+	if err != nil {
+		return fmt.Errorf("failed to connect to wing API on bastion: %s", err)
+	}
+
+	instances, err := c.listInstances()
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %s", err)
+	}
+
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		selected[name] = true
+	}
+
+	for pos, _ := range instances {
+		instance := instances[pos]
+		if len(selected) > 0 {
+			if !selected[instance.Name] {
+				continue
+			}
+			delete(selected, instance.Name)
+		}
+
+		if instance.Spec == nil {
+			instance.Spec = &wingv1alpha1.InstanceSpec{}
+		}
+		instance.Spec.Converge = &wingv1alpha1.InstanceSpecManifest{
+			Path: manifestURL,
+			Hash: hash,
+		}
+
+		if _, err := client.Update(instance); err != nil {
+			return fmt.Errorf("error updating instance %s in wing API: %s", instance.Name, err)
+		}
+		c.log.Infof("pinned instance %s to manifest %s", instance.Name, hash)
+	}
+
+	if len(selected) > 0 {
+		var missing []string
+		for name := range selected {
+			missing = append(missing, name)
+		}
+		sort.Strings(missing)
+		return fmt.Errorf("instances not found: %s", strings.Join(missing, ", "))
+	}
+
+	// TODO: solve this on the API server side
+	time.Sleep(time.Second * 5)
+
+	return nil
+}
+
+// pinnedManifestURL uploads the current puppet.tar.gz, if it has the hash,
+// and returns the URL of the uploaded manifest
+func (c *Cluster) pinnedManifestURL(hash string) (string, error) {
+	buffer := new(bytes.Buffer)
+
+	// get puppet config
+	err := c.Environment().Tarmak().Puppet().TarGz(buffer)
+	if err != nil {
+		return "", err
+	}
+
+	if fmt.Sprintf("sha256:%x", sha256.Sum256(buffer.Bytes())) != hash {
+		return "", nil
+	}
+
+	md5Hash := md5.Sum(buffer.Bytes())
+	manifestURL, err := c.Environment().Provider().UploadDryRunConfiguration(
+		c,
+		bytes.NewReader(buffer.Bytes()),
+		hex.EncodeToString(md5Hash[:]),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload manifest %s: %s", hash, err)
+	}
+
+	return manifestURL, nil
+}
+
 // This waits until all instances have congverged successfully
 func (c *Cluster) WaitForConvergance() error {
 	c.log.Debugf("making sure all instances have converged using puppet")
@@ -104,6 +340,11 @@ func (c *Cluster) WaitForConvergance() error {
 			)
 		}
 
+		// a manifest failing verification won't converge on retries
+		if failed := instanceByState[wingv1alpha1.InstanceManifestStateVerificationFailed]; len(failed) > 0 {
+			return fmt.Errorf("manifest signature verification failed on instances %s, their public key does not match %s", outputInstances(failed), c.Environment().ManifestSigningKeyPath())
+		}
+
 		err = c.checkAllInstancesConverged(instanceByState)
 		if err == nil {
 			c.log.Info("all instances converged")
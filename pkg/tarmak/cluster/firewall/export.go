@@ -0,0 +1,253 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	ExportIPTables = "iptables"
+	ExportNFTables = "nftables"
+
+	iptablesInputChain  = "TARMAK-INPUT"
+	iptablesOutputChain = "TARMAK-OUTPUT"
+)
+
+var ExportFormats = []string{ExportIPTables, ExportNFTables}
+
+// hostRule is a flow of an instance with its peer resolved to addresses
+type hostRule struct {
+	entry *Entry
+	// addresses of the peer, nil for CIDR peers
+	addresses []string
+}
+
+// Export writes host level firewall rules enforcing the flows of instances
+// with the roles. The addresses of the instances of other roles are looked up
+// in addresses by role, flows with peers without any known address can't be
+// enforced by iptables and are only rendered as comments.
+func Export(out io.Writer, format string, roles []string, entries []Entry, addresses map[string][]string) error {
+	if len(roles) == 0 {
+		return fmt.Errorf("no roles to export rules for")
+	}
+
+	var ingress, egress []hostRule
+	seen := map[string]bool{}
+	for pos := range entries {
+		entry := &entries[pos]
+		if !hasRole(roles, entry.Role) {
+			continue
+		}
+
+		// instances with multiple roles share flows
+		key := fmt.Sprintf("%s/%s/%s/%d-%d/%s", entry.Direction, entry.Protocol, entry.Peer, entry.FromPort, entry.ToPort, entry.Service)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		rule := hostRule{entry: entry}
+		if entry.PeerCIDR == nil {
+			rule.addresses = peerAddresses(entry.Peer, addresses)
+		}
+
+		if entry.Direction == DirectionEgress {
+			egress = append(egress, rule)
+		} else {
+			ingress = append(ingress, rule)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# firewall rules of tarmak for instances with the roles %s\n", strings.Join(roles, ", "))
+
+	switch format {
+	case ExportIPTables:
+		writeIPTables(&b, ingress, egress)
+	case ExportNFTables:
+		writeNFTables(&b, ingress, egress)
+	default:
+		return fmt.Errorf("unknown export format '%s', valid formats are: %s", format, strings.Join(ExportFormats, ", "))
+	}
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// peerAddresses returns the sorted addresses of a role, all addresses for
+// PeerAll
+func peerAddresses(peer string, addresses map[string][]string) []string {
+	unique := map[string]bool{}
+	for role, addrs := range addresses {
+		if peer != PeerAll && role != peer {
+			continue
+		}
+		for _, addr := range addrs {
+			unique[addr] = true
+		}
+	}
+
+	var result []string
+	for addr := range unique {
+		result = append(result, addr)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func isIPv6(cidr *net.IPNet) bool {
+	return cidr.IP.To4() == nil
+}
+
+func writeIPTables(b *bytes.Buffer, ingress, egress []hostRule) {
+	b.WriteString("# apply with 'iptables-restore --noflush' and jump to the chains from INPUT and OUTPUT:\n")
+	fmt.Fprintf(b, "#   iptables -I INPUT -j %s\n", iptablesInputChain)
+	fmt.Fprintf(b, "#   iptables -I OUTPUT -j %s\n", iptablesOutputChain)
+	b.WriteString("*filter\n")
+	fmt.Fprintf(b, ":%s - [0:0]\n", iptablesInputChain)
+	fmt.Fprintf(b, ":%s - [0:0]\n", iptablesOutputChain)
+
+	for _, chain := range []struct {
+		name      string
+		rules     []hostRule
+		iface     string
+		peerMatch string
+	}{
+		{iptablesInputChain, ingress, "-i", "-s"},
+		{iptablesOutputChain, egress, "-o", "-d"},
+	} {
+		fmt.Fprintf(b, "-A %s -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT\n", chain.name)
+		fmt.Fprintf(b, "-A %s %s lo -j ACCEPT\n", chain.name, chain.iface)
+
+		for _, rule := range chain.rules {
+			entry := rule.entry
+
+			peer := ""
+			if entry.PeerCIDR != nil {
+				if isIPv6(entry.PeerCIDR) {
+					fmt.Fprintf(b, "# %s: skipped, %s is an IPv6 network\n", entry.Comment, entry.Peer)
+					continue
+				}
+				peer = entry.PeerCIDR.String()
+			} else {
+				if len(rule.addresses) == 0 {
+					fmt.Fprintf(b, "# %s: skipped, no addresses of %s known\n", entry.Comment, entry.Peer)
+					continue
+				}
+				peer = strings.Join(rule.addresses, ",")
+			}
+
+			line := fmt.Sprintf("-A %s %s %s", chain.name, chain.peerMatch, peer)
+			if entry.Protocol != protocolAll {
+				line += fmt.Sprintf(" -p %s", entry.Protocol)
+			}
+			if entry.HasPorts() {
+				ports := entry.Ports()
+				if entry.FromPort != entry.ToPort {
+					ports = fmt.Sprintf("%d:%d", entry.FromPort, entry.ToPort)
+				}
+				line += fmt.Sprintf(" -m %s --dport %s", entry.Protocol, ports)
+			}
+			fmt.Fprintf(b, "%s -m comment --comment %q -j ACCEPT\n", line, entry.Comment)
+		}
+
+		fmt.Fprintf(b, "-A %s -j DROP\n", chain.name)
+	}
+
+	b.WriteString("COMMIT\n")
+}
+
+// nftSetName returns the name of the set containing the addresses of a peer
+func nftSetName(peer string) string {
+	return strings.Replace(peer, "-", "_", -1)
+}
+
+func writeNFTables(b *bytes.Buffer, ingress, egress []hostRule) {
+	// sets of the roles referenced by rules
+	sets := map[string][]string{}
+	for _, rules := range [][]hostRule{ingress, egress} {
+		for _, rule := range rules {
+			if rule.entry.PeerCIDR == nil {
+				sets[nftSetName(rule.entry.Peer)] = rule.addresses
+			}
+		}
+	}
+	var setNames []string
+	for name := range sets {
+		setNames = append(setNames, name)
+	}
+	sort.Strings(setNames)
+
+	b.WriteString("# apply with 'nft -f', the table replaces previously applied rules of tarmak\n")
+	b.WriteString("table inet tarmak\n")
+	b.WriteString("delete table inet tarmak\n")
+	b.WriteString("table inet tarmak {\n")
+
+	for _, name := range setNames {
+		fmt.Fprintf(b, "\tset %s {\n", name)
+		b.WriteString("\t\ttype ipv4_addr\n")
+		if len(sets[name]) > 0 {
+			fmt.Fprintf(b, "\t\telements = { %s }\n", strings.Join(sets[name], ", "))
+		}
+		b.WriteString("\t}\n\n")
+	}
+
+	for _, chain := range []struct {
+		name      string
+		rules     []hostRule
+		iface     string
+		peerMatch string
+	}{
+		{"input", ingress, "iif", "saddr"},
+		{"output", egress, "oif", "daddr"},
+	} {
+		fmt.Fprintf(b, "\tchain %s {\n", chain.name)
+		fmt.Fprintf(b, "\t\ttype filter hook %s priority 0; policy drop;\n", chain.name)
+		b.WriteString("\t\tct state established,related accept\n")
+		fmt.Fprintf(b, "\t\t%s \"lo\" accept\n", chain.iface)
+
+		for _, rule := range chain.rules {
+			entry := rule.entry
+
+			var match string
+			switch {
+			case entry.PeerCIDR != nil && isIPv6(entry.PeerCIDR):
+				match = fmt.Sprintf("ip6 %s %s", chain.peerMatch, entry.PeerCIDR.String())
+			case entry.PeerCIDR != nil:
+				match = fmt.Sprintf("ip %s %s", chain.peerMatch, entry.PeerCIDR.String())
+			default:
+				match = fmt.Sprintf("ip %s @%s", chain.peerMatch, nftSetName(entry.Peer))
+			}
+
+			switch {
+			case entry.HasPorts():
+				match += fmt.Sprintf(" %s dport %s", entry.Protocol, entry.Ports())
+			case entry.Protocol != protocolAll:
+				match += fmt.Sprintf(" meta l4proto %s", entry.Protocol)
+			}
+
+			fmt.Fprintf(b, "\t\t%s accept comment %q\n", match, entry.Comment)
+		}
+
+		b.WriteString("\t}\n")
+		if chain.name == "input" {
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("}\n")
+}
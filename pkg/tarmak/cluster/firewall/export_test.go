@@ -0,0 +1,77 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package firewall
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var exportAddresses = map[string][]string{
+	"master":  {"10.0.0.1"},
+	"worker":  {"10.0.1.2", "10.0.1.1"},
+	"bastion": {"10.0.2.1"},
+}
+
+func TestExport_iptables(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(&out, ExportIPTables, []string{"master"}, Matrix(Rules()), exportAddresses); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rules := out.String()
+	for _, exp := range []string{
+		":TARMAK-INPUT - [0:0]\n",
+		"-A TARMAK-INPUT -m conntrack --ctstate ESTABLISHED,RELATED -j ACCEPT\n",
+		`-A TARMAK-INPUT -s 10.0.1.1,10.0.1.2 -p tcp -m tcp --dport 6443 -m comment --comment "allow workers/master to connect to calico's service, cluster autoscaler's service + api server" -j ACCEPT`,
+		`-A TARMAK-INPUT -s 10.0.1.1,10.0.1.2 -p 4 -m comment`,
+		`-A TARMAK-OUTPUT -d 0.0.0.0/0 -m comment --comment "allow all instance to egress to anywhere" -j ACCEPT`,
+		"# allow ELB to connect to API server: skipped, no addresses of master_elb known\n",
+		"-A TARMAK-INPUT -j DROP\n",
+		"COMMIT\n",
+	} {
+		if !strings.Contains(rules, exp) {
+			t.Errorf("expected rules to contain %s:\n%s", exp, rules)
+		}
+	}
+
+	// rules of other roles are not exported
+	if strings.Contains(rules, "--dport 2379") {
+		t.Errorf("unexpected etcd rules:\n%s", rules)
+	}
+}
+
+func TestExport_nftables(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(&out, ExportNFTables, []string{"worker"}, Matrix(Rules()), exportAddresses); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	rules := out.String()
+	for _, exp := range []string{
+		"table inet tarmak {\n",
+		"\tset worker {\n\t\ttype ipv4_addr\n\t\telements = { 10.0.1.1, 10.0.1.2 }\n\t}\n",
+		"\t\ttype filter hook input priority 0; policy drop;\n",
+		`ip saddr @bastion tcp dport 22 accept comment "allow bastion to connect to all instances via SSH"`,
+		`ip daddr 0.0.0.0/0 accept comment "allow all instance to egress to anywhere"`,
+	} {
+		if !strings.Contains(rules, exp) {
+			t.Errorf("expected rules to contain %s:\n%s", exp, rules)
+		}
+	}
+
+	// flows of all protocols are not limited to a protocol
+	if strings.Contains(rules, "l4proto -1") {
+		t.Errorf("unexpected protocol match for all protocols:\n%s", rules)
+	}
+}
+
+func TestExport_invalid(t *testing.T) {
+	var out bytes.Buffer
+	if err := Export(&out, "pf", []string{"master"}, Matrix(Rules()), nil); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+	if err := Export(&out, ExportIPTables, nil, Matrix(Rules()), nil); err == nil {
+		t.Error("expected an error without roles")
+	}
+}
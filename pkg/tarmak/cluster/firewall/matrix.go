@@ -0,0 +1,175 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package firewall
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+// PeerAll is the peer of flows from or to all instances
+const PeerAll = "all"
+
+// Entry is a single flow permitted by the firewall rules
+type Entry struct {
+	// Role is the role of the instances the rule is applied to
+	Role      string
+	Direction string
+	// Peer is the role or the CIDR of the other side of the flow
+	Peer     string
+	PeerCIDR *net.IPNet
+
+	Service  string
+	Protocol string
+	FromPort uint16
+	ToPort   uint16
+	Comment  string
+}
+
+// Matrix flattens the rules into the flows between roles and CIDRs
+func Matrix(rules []*Rule) (entries []Entry) {
+	for _, rule := range rules {
+		for _, dst := range rule.Destinations {
+			for _, src := range rule.Sources {
+				destination, source := dst, src
+
+				// rules allowing all instances to access a role are
+				// specified the other way around
+				if destination.Role == "" {
+					if destination.Name != "all" || source.Role == "" {
+						continue
+					}
+					destination, source = Host{Role: source.Role}, Host{Role: PeerAll}
+				}
+
+				for _, service := range rule.Services {
+					for _, port := range service.Ports {
+						entry := Entry{
+							Role:      destination.Role,
+							Direction: rule.Direction,
+							Service:   service.Name,
+							Protocol:  service.Protocol,
+							Comment:   rule.Comment,
+						}
+
+						switch {
+						case source.CIDR != nil:
+							entry.Peer = source.CIDR.String()
+							entry.PeerCIDR = source.CIDR
+						case source.Role != "":
+							entry.Peer = source.Role
+						case source.Name == "all":
+							entry.Peer = destination.Role
+						default:
+							entry.Peer = source.Name
+						}
+
+						if service.Protocol != protocolAll {
+							if port.Single != nil {
+								entry.FromPort, entry.ToPort = *port.Single, *port.Single
+							} else {
+								entry.FromPort, entry.ToPort = *port.RangeFrom, *port.RangeTo
+							}
+						}
+
+						entries = append(entries, entry)
+					}
+				}
+			}
+		}
+	}
+
+	return entries
+}
+
+// ProtocolName returns the protocol in a human readable form
+func (e *Entry) ProtocolName() string {
+	switch e.Protocol {
+	case protocolAll:
+		return "all"
+	case "4":
+		return "ipip"
+	}
+	return e.Protocol
+}
+
+// HasPorts returns true if the flow is limited to ports
+func (e *Entry) HasPorts() bool {
+	return e.Protocol == "tcp" || e.Protocol == "udp"
+}
+
+// Ports returns the port or port range of the flow
+func (e *Entry) Ports() string {
+	if !e.HasPorts() {
+		return "all"
+	}
+	if e.FromPort == e.ToPort {
+		return fmt.Sprintf("%d", e.FromPort)
+	}
+	return fmt.Sprintf("%d-%d", e.FromPort, e.ToPort)
+}
+
+// Parameters returns the fields of the entry for listing them
+func (e *Entry) Parameters() map[string]string {
+	return map[string]string{
+		"role":      e.Role,
+		"direction": e.Direction,
+		"peer":      e.Peer,
+		"service":   e.Service,
+		"protocol":  e.ProtocolName(),
+		"ports":     e.Ports(),
+		"comment":   e.Comment,
+	}
+}
+
+// WriteGraphviz writes the flows as a directed graph in the dot language,
+// edges point in the direction connections are established
+func WriteGraphviz(out io.Writer, entries []Entry) error {
+	nodes := map[string]bool{}
+	edges := map[string][]string{}
+	var edgeKeys []string
+
+	for pos := range entries {
+		entry := &entries[pos]
+		from, to := entry.Peer, entry.Role
+		if entry.Direction == DirectionEgress {
+			from, to = entry.Role, entry.Peer
+		}
+		nodes[from] = true
+		nodes[to] = true
+
+		key := fmt.Sprintf("%q -> %q", from, to)
+		if _, ok := edges[key]; !ok {
+			edgeKeys = append(edgeKeys, key)
+		}
+		edges[key] = append(edges[key], fmt.Sprintf("%s %s/%s", entry.Service, entry.ProtocolName(), entry.Ports()))
+	}
+
+	var nodeNames []string
+	for node := range nodes {
+		nodeNames = append(nodeNames, node)
+	}
+	sort.Strings(nodeNames)
+	sort.Strings(edgeKeys)
+
+	var b bytes.Buffer
+	b.WriteString("digraph firewall {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for _, node := range nodeNames {
+		shape := "box"
+		if _, _, err := net.ParseCIDR(node); err == nil {
+			shape = "ellipse"
+		}
+		fmt.Fprintf(&b, "  %q [shape=%s];\n", node, shape)
+	}
+	for _, key := range edgeKeys {
+		fmt.Fprintf(&b, "  %s [label=%q];\n", key, strings.Join(edges[key], "\n"))
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
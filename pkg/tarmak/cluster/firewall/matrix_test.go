@@ -0,0 +1,69 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package firewall
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func findEntry(entries []Entry, role, direction, peer, service string) *Entry {
+	for pos := range entries {
+		e := &entries[pos]
+		if e.Role == role && e.Direction == direction && e.Peer == peer && e.Service == service {
+			return e
+		}
+	}
+	return nil
+}
+
+func TestMatrix(t *testing.T) {
+	entries := Matrix(Rules())
+
+	for _, tc := range []struct {
+		role, direction, peer, service string
+		protocol, ports                string
+	}{
+		{"master", DirectionIngress, "worker", "api", "tcp", "6443"},
+		{"master", DirectionIngress, "worker", "ipip", "ipip", "all"},
+		{"worker", DirectionIngress, "worker", "all", "all", "all"},
+		{"worker", DirectionEgress, "0.0.0.0/0", "all", "all", "all"},
+		{"bastion", DirectionIngress, "0.0.0.0/0", "ssh", "tcp", "22"},
+		{"etcd", DirectionIngress, "master", "etcd", "tcp", "2379"},
+		// rules allowing all instances to access a role
+		{"bastion", DirectionIngress, PeerAll, "wing", "tcp", "9443"},
+		{"vault", DirectionIngress, PeerAll, "vault", "tcp", "8200"},
+	} {
+		entry := findEntry(entries, tc.role, tc.direction, tc.peer, tc.service)
+		if entry == nil {
+			t.Errorf("no entry for %s %s of %s from %s", tc.direction, tc.service, tc.role, tc.peer)
+			continue
+		}
+		if act := entry.ProtocolName(); act != tc.protocol {
+			t.Errorf("unexpected protocol of %s: act=%s exp=%s", tc.service, act, tc.protocol)
+		}
+		if act := entry.Ports(); act != tc.ports {
+			t.Errorf("unexpected ports of %s: act=%s exp=%s", tc.service, act, tc.ports)
+		}
+	}
+}
+
+func TestWriteGraphviz(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteGraphviz(&out, Matrix(Rules())); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	graph := out.String()
+	for _, exp := range []string{
+		"digraph firewall {\n",
+		`"worker" [shape=box];`,
+		`"0.0.0.0/0" [shape=ellipse];`,
+		`"worker" -> "master" [label=`,
+		`"master" -> "master_elb" [label="api tcp/6443"];`,
+	} {
+		if !strings.Contains(graph, exp) {
+			t.Errorf("expected graph to contain %s:\n%s", exp, graph)
+		}
+	}
+}
@@ -47,6 +47,7 @@ var (
 	calicoMetricsPort            = uint16(9091)
 	nodePort                     = uint16(9100)
 	blackboxPort                 = uint16(9115)
+	wingMetricsPort              = uint16(9451)
 	wingPort                     = uint16(9443)
 	maxPort                      = uint16(65535)
 
@@ -182,6 +183,14 @@ func newNodeExporterService() Service {
 	}
 }
 
+func newWingMetricsService() Service {
+	return Service{
+		Name:     "wing_metrics",
+		Protocol: "tcp",
+		Ports:    []Port{Port{Single: &wingMetricsPort}},
+	}
+}
+
 func newEtcdOverlayService() Service {
 	return Service{
 		Name:     "etcd",
@@ -304,8 +313,8 @@ func Rules() (rules []*Rule) {
 		},
 
 		&Rule{
-			Comment:      "allow prometheus connections to node_exporter and blackbox_exporter",
-			Services:     []Service{newBlackboxExporterService(), newNodeExporterService()},
+			Comment:      "allow prometheus connections to node_exporter, blackbox_exporter and wing",
+			Services:     []Service{newBlackboxExporterService(), newNodeExporterService(), newWingMetricsService()},
 			Direction:    "ingress",
 			Sources:      []Host{Host{Role: "worker"}},
 			Destinations: []Host{Host{Role: "etcd"}},
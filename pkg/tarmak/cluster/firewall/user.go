@@ -0,0 +1,296 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package firewall
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+)
+
+const (
+	DirectionIngress = "ingress"
+	DirectionEgress  = "egress"
+
+	protocolAll = "-1"
+)
+
+// identifiers end up in the names of the terraform resources
+var identifierRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// roles with a security group per cluster, which user defined rules can be
+// added to
+var userRuleRoles = []string{
+	clusterv1alpha1.InstancePoolTypeEtcd,
+	clusterv1alpha1.InstancePoolTypeMaster,
+	clusterv1alpha1.InstancePoolTypeWorker,
+}
+
+// ClusterRules returns the built-in rules followed by the rules users
+// specified in the firewalls of the instance pools. Instance pools of the same
+// role share a security group, so their rules apply to all of them.
+func ClusterRules(instancePools []clusterv1alpha1.InstancePool) ([]*Rule, error) {
+	userRules, err := UserRules(instancePools)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := append(Rules(), userRules...)
+	if err := validateConflicts(rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// UserRules converts the firewalls of the instance pools into rules
+func UserRules(instancePools []clusterv1alpha1.InstancePool) ([]*Rule, error) {
+	var result *multierror.Error
+	var rules []*Rule
+
+	poolRoles := make(map[string]string)
+	for _, pool := range instancePools {
+		poolRoles[pool.Name] = pool.Type
+	}
+
+	for _, pool := range instancePools {
+		if len(pool.Firewalls) == 0 {
+			continue
+		}
+
+		if !supportsUserRules(pool.Type) {
+			result = multierror.Append(result, fmt.Errorf("instance pool %s: firewalls are not supported for instance pools of type %s, supported types are %s", pool.Name, pool.Type, strings.Join(userRuleRoles, ", ")))
+			continue
+		}
+
+		ingressCount, egressCount := 0, 0
+		identifiers := make(map[string]bool)
+		for _, fw := range pool.Firewalls {
+			if fw == nil {
+				continue
+			}
+
+			for _, ingress := range fw.IngressRules {
+				if ingress == nil {
+					continue
+				}
+				identifier := ruleIdentifier(ingress.Identifier, DirectionIngress, ingressCount)
+				ingressCount++
+				if err := checkIdentifier(identifiers, DirectionIngress, identifier); err != nil {
+					result = multierror.Append(result, fmt.Errorf("instance pool %s: %s", pool.Name, err))
+					continue
+				}
+
+				rule, err := newUserRule(
+					pool.Name,
+					pool.Type,
+					DirectionIngress,
+					identifier,
+					ingress.IngressProtocol,
+					ingress.IngressFromPort,
+					ingress.IngressToPort,
+					ingress.IngressSource,
+					poolRoles,
+				)
+				if err != nil {
+					result = multierror.Append(result, fmt.Errorf("instance pool %s: %s", pool.Name, err))
+					continue
+				}
+				rules = append(rules, rule)
+			}
+
+			for _, egress := range fw.EgressRules {
+				if egress == nil {
+					continue
+				}
+				identifier := ruleIdentifier(egress.Identifier, DirectionEgress, egressCount)
+				egressCount++
+				if err := checkIdentifier(identifiers, DirectionEgress, identifier); err != nil {
+					result = multierror.Append(result, fmt.Errorf("instance pool %s: %s", pool.Name, err))
+					continue
+				}
+
+				rule, err := newUserRule(
+					pool.Name,
+					pool.Type,
+					DirectionEgress,
+					identifier,
+					egress.EgressProtocol,
+					egress.EgressToPort,
+					"",
+					egress.EgressDestination,
+					poolRoles,
+				)
+				if err != nil {
+					result = multierror.Append(result, fmt.Errorf("instance pool %s: %s", pool.Name, err))
+					continue
+				}
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	if err := result.ErrorOrNil(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func supportsUserRules(role string) bool {
+	for _, r := range userRuleRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIdentifier ensures the rules of an instance pool can be told apart
+func checkIdentifier(identifiers map[string]bool, direction, identifier string) error {
+	key := fmt.Sprintf("%s/%s", direction, identifier)
+	if identifiers[key] {
+		return fmt.Errorf("%s rule identifier %s is used more than once", direction, identifier)
+	}
+	identifiers[key] = true
+	return nil
+}
+
+// ruleIdentifier defaults to the direction and position of the rule
+func ruleIdentifier(identifier, direction string, pos int) string {
+	if identifier != "" {
+		return identifier
+	}
+	return fmt.Sprintf("%s%d", direction, pos)
+}
+
+func newUserRule(poolName, role, direction, identifier, protocol, fromPort, toPort, peer string, poolRoles map[string]string) (*Rule, error) {
+	if !identifierRegexp.MatchString(identifier) {
+		return nil, fmt.Errorf("identifier '%s' has to consist of alphanumeric characters, '-' or '_'", identifier)
+	}
+
+	service, err := newUserService(fmt.Sprintf("user_%s_%s", poolName, identifier), protocol, fromPort, toPort)
+	if err != nil {
+		return nil, fmt.Errorf("%s rule %s: %s", direction, identifier, err)
+	}
+
+	host, err := newUserHost(peer, poolRoles)
+	if err != nil {
+		return nil, fmt.Errorf("%s rule %s: %s", direction, identifier, err)
+	}
+
+	return &Rule{
+		Comment:      fmt.Sprintf("user defined %s rule %s of instance pool %s", direction, identifier, poolName),
+		Services:     []Service{service},
+		Direction:    direction,
+		Sources:      []Host{host},
+		Destinations: []Host{Host{Role: role}},
+	}, nil
+}
+
+func newUserService(name, protocol, fromPort, toPort string) (Service, error) {
+	service := Service{Name: name}
+
+	switch protocol {
+	case "tcp", "udp":
+		service.Protocol = protocol
+	case "all", protocolAll:
+		service.Protocol = protocolAll
+		if fromPort != "" || toPort != "" {
+			return service, fmt.Errorf("ports can't be specified for all protocols")
+		}
+		service.Ports = []Port{Port{Single: &zeroPort}}
+		return service, nil
+	case "":
+		return service, fmt.Errorf("protocol is not specified, supported protocols are tcp, udp and all")
+	default:
+		return service, fmt.Errorf("protocol '%s' is not supported, supported protocols are tcp, udp and all", protocol)
+	}
+
+	from, err := parsePort(fromPort)
+	if err != nil {
+		return service, err
+	}
+	if toPort == "" {
+		service.Ports = []Port{Port{Single: &from}}
+		return service, nil
+	}
+
+	to, err := parsePort(toPort)
+	if err != nil {
+		return service, err
+	}
+	if from > to {
+		return service, fmt.Errorf("port range %d-%d is invalid", from, to)
+	}
+	service.Ports = []Port{Port{RangeFrom: &from, RangeTo: &to}}
+
+	return service, nil
+}
+
+func parsePort(port string) (uint16, error) {
+	if port == "" {
+		return 0, fmt.Errorf("port is not specified")
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || p == 0 {
+		return 0, fmt.Errorf("port '%s' is not a valid port", port)
+	}
+	return uint16(p), nil
+}
+
+// newUserHost returns the host for a CIDR or the role of an instance pool
+func newUserHost(peer string, poolRoles map[string]string) (Host, error) {
+	if peer == "" {
+		return Host{}, fmt.Errorf("no CIDR or instance pool specified")
+	}
+
+	if strings.Contains(peer, "/") {
+		_, cidr, err := net.ParseCIDR(peer)
+		if err != nil {
+			return Host{}, fmt.Errorf("'%s' is not a valid CIDR: %s", peer, err)
+		}
+		return Host{
+			Name: fmt.Sprintf("cidr_%s", strings.NewReplacer(".", "_", ":", "_", "/", "_").Replace(cidr.String())),
+			CIDR: cidr,
+		}, nil
+	}
+
+	role, ok := poolRoles[peer]
+	if !ok {
+		return Host{}, fmt.Errorf("'%s' is neither a CIDR nor an instance pool of the cluster", peer)
+	}
+	return Host{Role: role}, nil
+}
+
+// validateConflicts ensures no two rules result in the same rule of a
+// security group, which the cloud provider would reject
+func validateConflicts(rules []*Rule) error {
+	var result *multierror.Error
+
+	existing := make(map[string]*Rule)
+	for _, rule := range rules {
+		for _, key := range rule.keys() {
+			if other, ok := existing[key]; ok && other != rule {
+				result = multierror.Append(result, fmt.Errorf("%s conflicts with %s", rule.Comment, other.Comment))
+				break
+			}
+			existing[key] = rule
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// keys identify the security group rules generated from the rule
+func (r *Rule) keys() (keys []string) {
+	for _, entry := range Matrix([]*Rule{r}) {
+		keys = append(keys, fmt.Sprintf("%s/%s/%s/%d-%d/%s", entry.Role, entry.Direction, entry.Protocol, entry.FromPort, entry.ToPort, entry.Peer))
+	}
+	return keys
+}
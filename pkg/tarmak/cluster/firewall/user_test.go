@@ -0,0 +1,184 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package firewall
+
+import (
+	"testing"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+)
+
+func instancePool(name, poolType string, firewalls ...*clusterv1alpha1.Firewall) clusterv1alpha1.InstancePool {
+	pool := clusterv1alpha1.InstancePool{
+		Type:      poolType,
+		Firewalls: firewalls,
+	}
+	pool.Name = name
+	return pool
+}
+
+func ingressRule(identifier, protocol, from, to, source string) *clusterv1alpha1.IngressRule {
+	rule := &clusterv1alpha1.IngressRule{
+		IngressProtocol: protocol,
+		IngressFromPort: from,
+		IngressToPort:   to,
+		IngressSource:   source,
+	}
+	rule.Identifier = identifier
+	return rule
+}
+
+func egressRule(identifier, protocol, port, destination string) *clusterv1alpha1.EgressRule {
+	rule := &clusterv1alpha1.EgressRule{
+		EgressProtocol:    protocol,
+		EgressToPort:      port,
+		EgressDestination: destination,
+	}
+	rule.Identifier = identifier
+	return rule
+}
+
+func TestClusterRules_builtIn(t *testing.T) {
+	rules, err := ClusterRules(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if act, exp := len(rules), len(Rules()); act != exp {
+		t.Errorf("unexpected number of rules: act=%d exp=%d", act, exp)
+	}
+}
+
+func TestClusterRules_userRules(t *testing.T) {
+	pools := []clusterv1alpha1.InstancePool{
+		instancePool("master", clusterv1alpha1.InstancePoolTypeMaster),
+		instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+			IngressRules: []*clusterv1alpha1.IngressRule{
+				ingressRule("nodeports", "tcp", "30000", "32767", "10.0.0.0/8"),
+				ingressRule("", "udp", "53", "", "master"),
+			},
+			EgressRules: []*clusterv1alpha1.EgressRule{
+				egressRule("smtp", "tcp", "25", "192.168.1.10/32"),
+			},
+		}),
+	}
+
+	rules, err := ClusterRules(pools)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	userRules := rules[len(Rules()):]
+	if act, exp := len(userRules), 3; act != exp {
+		t.Fatalf("unexpected number of user rules: act=%d exp=%d", act, exp)
+	}
+
+	nodePorts := userRules[0]
+	if act, exp := nodePorts.Services[0].Name, "user_worker_nodeports"; act != exp {
+		t.Errorf("unexpected service name: act=%s exp=%s", act, exp)
+	}
+	if port := nodePorts.Services[0].Ports[0]; port.RangeFrom == nil || *port.RangeFrom != 30000 || *port.RangeTo != 32767 {
+		t.Errorf("unexpected port: %+v", port)
+	}
+	if act, exp := nodePorts.Sources[0].Name, "cidr_10_0_0_0_8"; act != exp {
+		t.Errorf("unexpected source name: act=%s exp=%s", act, exp)
+	}
+	if act, exp := nodePorts.Destinations[0].Role, clusterv1alpha1.InstancePoolTypeWorker; act != exp {
+		t.Errorf("unexpected destination role: act=%s exp=%s", act, exp)
+	}
+
+	dns := userRules[1]
+	if act, exp := dns.Services[0].Name, "user_worker_ingress1"; act != exp {
+		t.Errorf("unexpected service name: act=%s exp=%s", act, exp)
+	}
+	if act, exp := dns.Sources[0].Role, clusterv1alpha1.InstancePoolTypeMaster; act != exp {
+		t.Errorf("unexpected source role: act=%s exp=%s", act, exp)
+	}
+
+	smtp := userRules[2]
+	if smtp.Direction != DirectionEgress || *smtp.Services[0].Ports[0].Single != 25 {
+		t.Errorf("unexpected egress rule: %+v", smtp)
+	}
+}
+
+func TestClusterRules_invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		pool clusterv1alpha1.InstancePool
+	}{
+		{
+			name: "unsupported instance pool type",
+			pool: instancePool("bastion", clusterv1alpha1.InstancePoolTypeBastion, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("", "tcp", "80", "", "0.0.0.0/0")},
+			}),
+		},
+		{
+			name: "unsupported protocol",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("", "icmp", "", "", "0.0.0.0/0")},
+			}),
+		},
+		{
+			name: "invalid port",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("", "tcp", "70000", "", "0.0.0.0/0")},
+			}),
+		},
+		{
+			name: "invalid port range",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("", "tcp", "8080", "80", "0.0.0.0/0")},
+			}),
+		},
+		{
+			name: "ports for all protocols",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("", "all", "80", "", "0.0.0.0/0")},
+			}),
+		},
+		{
+			name: "invalid CIDR",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("", "tcp", "80", "", "10.0.0.0/33")},
+			}),
+		},
+		{
+			name: "unknown instance pool",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("", "tcp", "80", "", "frontend")},
+			}),
+		},
+		{
+			name: "invalid identifier",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{ingressRule("http ports", "tcp", "80", "", "0.0.0.0/0")},
+			}),
+		},
+		{
+			name: "duplicate identifier",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{
+					ingressRule("http", "tcp", "80", "", "0.0.0.0/0"),
+					ingressRule("http", "tcp", "8080", "", "0.0.0.0/0"),
+				},
+			}),
+		},
+		{
+			name: "conflicting user rules",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				IngressRules: []*clusterv1alpha1.IngressRule{
+					ingressRule("http", "tcp", "80", "", "10.0.0.0/8"),
+					ingressRule("web", "tcp", "80", "80", "10.0.0.1/8"),
+				},
+			}),
+		},
+		{
+			name: "conflicting built-in rule",
+			pool: instancePool("worker", clusterv1alpha1.InstancePoolTypeWorker, &clusterv1alpha1.Firewall{
+				EgressRules: []*clusterv1alpha1.EgressRule{egressRule("", "all", "", "0.0.0.0/0")},
+			}),
+		},
+	} {
+		if _, err := ClusterRules([]clusterv1alpha1.InstancePool{tc.pool}); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/go-version"
+)
+
+// kubelets may be up to two minor versions older than the API server
+const maxKubeletMinorVersionSkew = 2
+
+// kubernetesMinorVersion returns the major and minor segment of a version
+func kubernetesMinorVersion(v string) (major, minor int, err error) {
+	parsed, err := version.NewVersion(v)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Kubernetes version '%s': %s", v, err)
+	}
+	segments := parsed.Segments()
+	return segments[0], segments[1], nil
+}
+
+// CheckKubernetesUpgrade verifies that a cluster can be upgraded between the
+// versions, Kubernetes only supports upgrades by a single minor version
+func CheckKubernetesUpgrade(from, to string) error {
+	fromVersion, err := version.NewVersion(from)
+	if err != nil {
+		return fmt.Errorf("invalid current Kubernetes version '%s': %s", from, err)
+	}
+	toVersion, err := version.NewVersion(to)
+	if err != nil {
+		return fmt.Errorf("invalid target Kubernetes version '%s': %s", to, err)
+	}
+
+	if !toVersion.GreaterThan(fromVersion) {
+		return fmt.Errorf("target version %s has to be newer than the current version %s", to, from)
+	}
+
+	fromMajor, fromMinor, _ := kubernetesMinorVersion(from)
+	toMajor, toMinor, _ := kubernetesMinorVersion(to)
+	if fromMajor != toMajor || toMinor > fromMinor+1 {
+		return fmt.Errorf("upgrading from %s to %s skips a minor version, upgrade to %d.%d first", from, to, fromMajor, fromMinor+1)
+	}
+
+	return nil
+}
+
+// checkKubeletVersionSkew verifies that kubelets of a version are supported by
+// the API server, they must not be newer and at most two minor versions older
+func checkKubeletVersionSkew(apiServer, kubelet string) error {
+	apiServerMajor, apiServerMinor, err := kubernetesMinorVersion(apiServer)
+	if err != nil {
+		return err
+	}
+	kubeletMajor, kubeletMinor, err := kubernetesMinorVersion(kubelet)
+	if err != nil {
+		return err
+	}
+
+	if kubeletMajor != apiServerMajor || kubeletMinor > apiServerMinor {
+		return fmt.Errorf("kubelet version %s is newer than the API server version %s", kubelet, apiServer)
+	}
+	if apiServerMinor-kubeletMinor > maxKubeletMinorVersionSkew {
+		return fmt.Errorf("kubelet version %s is more than %d minor versions older than the API server version %s", kubelet, maxKubeletMinorVersionSkew, apiServer)
+	}
+
+	return nil
+}
+
+// validateKubernetesVersions checks the versions instance pools override
+// against the version of the cluster
+func (c *Cluster) validateKubernetesVersions() error {
+	return validateInstancePoolVersions(c.conf.Kubernetes.Version, c.instancePoolVersions())
+}
+
+// instancePoolVersions returns the Kubernetes versions instance pools
+// override by name
+func (c *Cluster) instancePoolVersions() map[string]string {
+	versions := map[string]string{}
+	for _, pool := range c.conf.InstancePools {
+		if pool.Kubernetes != nil && pool.Kubernetes.Version != "" {
+			versions[pool.Name] = pool.Kubernetes.Version
+		}
+	}
+	return versions
+}
+
+func validateInstancePoolVersions(clusterVersion string, poolVersions map[string]string) error {
+	var result *multierror.Error
+	for name, poolVersion := range poolVersions {
+		if err := checkKubeletVersionSkew(clusterVersion, poolVersion); err != nil {
+			result = multierror.Append(result, fmt.Errorf("instance pool %s: %s", name, err))
+		}
+	}
+	return result.ErrorOrNil()
+}
+
+// SetKubernetesVersion changes the Kubernetes version of the cluster and
+// persists it in the configuration. Instance pools overriding the cluster's
+// version with the same version follow the change, other overrides are kept
+// if their version skew is still supported.
+func (c *Cluster) SetKubernetesVersion(v string) error {
+	if c.conf.Kubernetes == nil {
+		return fmt.Errorf("cluster %s has no Kubernetes configuration", c.ClusterName())
+	}
+	previous := c.conf.Kubernetes.Version
+
+	poolVersions := c.instancePoolVersions()
+	for name, poolVersion := range poolVersions {
+		if poolVersion == previous {
+			poolVersions[name] = v
+		}
+	}
+	if err := validateInstancePoolVersions(v, poolVersions); err != nil {
+		return err
+	}
+
+	c.conf.Kubernetes.Version = v
+	// the instance pools share the Kubernetes configuration with the cluster's
+	// configuration
+	for pos := range c.conf.InstancePools {
+		if k := c.conf.InstancePools[pos].Kubernetes; k != nil && k.Version == previous {
+			k.Version = v
+		}
+	}
+
+	if err := c.Environment().Tarmak().Config().UpdateCluster(c.conf); err != nil {
+		return fmt.Errorf("failed to update configuration of cluster %s: %s", c.ClusterName(), err)
+	}
+
+	return nil
+}
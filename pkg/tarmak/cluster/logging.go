@@ -0,0 +1,280 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+var (
+	loggingSinkTypes = []string{
+		string(clusterv1alpha1.LoggingSinkTypePlatform),
+		string(clusterv1alpha1.LoggingSinkTypeApplication),
+		string(clusterv1alpha1.LoggingSinkTypeAudit),
+		string(clusterv1alpha1.LoggingSinkTypeAll),
+	}
+
+	loggingSinkHTTPFormats = []string{"json", "json_lines", "json_stream", "msgpack", "gelf"}
+
+	loggingSinkKafkaSASLMechanisms = []string{"PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512"}
+)
+
+// tls options every network logging sink has
+type loggingSinkTLS struct {
+	tls       *bool
+	tlsVerify bool
+	tlsCA     string
+	tlsCert   string
+	tlsKey    string
+}
+
+// validate logging configuration
+func (c *Cluster) validateLoggingSinks() (result error) {
+
+	if c.Config().LoggingSinks != nil {
+		for index, loggingSink := range c.Config().LoggingSinks {
+			if err := validateLoggingSink(loggingSink); err != nil {
+				return fmt.Errorf("invalid logging sink %d: %s", index, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateLoggingSink(loggingSink *clusterv1alpha1.LoggingSink) error {
+	for _, t := range loggingSink.Types {
+		if !utils.SliceContains(loggingSinkTypes, string(t)) {
+			return fmt.Errorf("unknown type '%s', valid types are: %s", t, strings.Join(loggingSinkTypes, ", "))
+		}
+	}
+
+	var sinks []string
+	var err error
+
+	if es := loggingSink.Elasticsearch; es != nil {
+		sinks = append(sinks, "elasticsearch")
+		err = validateElasticsearchLoggingSink(es)
+	}
+
+	if loki := loggingSink.Loki; loki != nil {
+		sinks = append(sinks, "loki")
+		err = validateLokiLoggingSink(loki)
+	}
+
+	if splunk := loggingSink.SplunkHEC; splunk != nil {
+		sinks = append(sinks, "splunkHEC")
+		err = validateSplunkHECLoggingSink(splunk)
+	}
+
+	if http := loggingSink.HTTP; http != nil {
+		sinks = append(sinks, "http")
+		err = validateHTTPLoggingSink(http)
+	}
+
+	if kafka := loggingSink.Kafka; kafka != nil {
+		sinks = append(sinks, "kafka")
+		err = validateKafkaLoggingSink(kafka)
+	}
+
+	if s3 := loggingSink.S3; s3 != nil {
+		sinks = append(sinks, "s3")
+		err = validateS3LoggingSink(s3)
+	}
+
+	switch len(sinks) {
+	case 0:
+		return fmt.Errorf("no destination specified, expected one of elasticsearch, loki, splunkHEC, http, kafka or s3")
+	case 1:
+		if err != nil {
+			return fmt.Errorf("%s: %s", sinks[0], err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("only one destination can be specified per logging sink, found %s", strings.Join(sinks, ", "))
+	}
+}
+
+func validateElasticsearchLoggingSink(es *clusterv1alpha1.LoggingSinkElasticsearch) error {
+	if es.AmazonESProxy != nil {
+		if es.HTTPBasicAuth != nil {
+			return fmt.Errorf("cannot enable AWS elasticsearch proxy and HTTP basic auth")
+		}
+		if es.TLSVerify {
+			return fmt.Errorf("cannot enable AWS elasticsearch proxy and force certificate validation")
+		}
+		if es.TLSCA != "" {
+			return fmt.Errorf("cannot enable AWS elasticsearch proxy and specify a custom CA")
+		}
+		if es.TLSCert != "" || es.TLSKey != "" {
+			return fmt.Errorf("cannot enable AWS elasticsearch proxy and specify a client certificate")
+		}
+	}
+
+	if err := validateLoggingSinkEndpoint(es.Host, es.Port); err != nil {
+		return err
+	}
+
+	return validateLoggingSinkTLS(loggingSinkTLS{es.TLS, es.TLSVerify, es.TLSCA, es.TLSCert, es.TLSKey})
+}
+
+func validateLokiLoggingSink(loki *clusterv1alpha1.LoggingSinkLoki) error {
+	if err := validateLoggingSinkEndpoint(loki.Host, loki.Port); err != nil {
+		return err
+	}
+
+	for key := range loki.Labels {
+		if !validLokiLabelName(key) {
+			return fmt.Errorf("invalid label name '%s'", key)
+		}
+	}
+
+	if err := validateHTTPBasicAuth(loki.HTTPBasicAuth); err != nil {
+		return err
+	}
+
+	return validateLoggingSinkTLS(loggingSinkTLS{loki.TLS, loki.TLSVerify, loki.TLSCA, loki.TLSCert, loki.TLSKey})
+}
+
+func validateSplunkHECLoggingSink(splunk *clusterv1alpha1.LoggingSinkSplunkHEC) error {
+	if err := validateLoggingSinkEndpoint(splunk.Host, splunk.Port); err != nil {
+		return err
+	}
+
+	if splunk.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	return validateLoggingSinkTLS(loggingSinkTLS{splunk.TLS, splunk.TLSVerify, splunk.TLSCA, splunk.TLSCert, splunk.TLSKey})
+}
+
+func validateHTTPLoggingSink(http *clusterv1alpha1.LoggingSinkHTTP) error {
+	if err := validateLoggingSinkEndpoint(http.Host, http.Port); err != nil {
+		return err
+	}
+
+	if http.URI != "" && !strings.HasPrefix(http.URI, "/") {
+		return fmt.Errorf("uri '%s' needs to start with '/'", http.URI)
+	}
+
+	if http.Format != "" && !utils.SliceContains(loggingSinkHTTPFormats, http.Format) {
+		return fmt.Errorf("unknown format '%s', valid formats are: %s", http.Format, strings.Join(loggingSinkHTTPFormats, ", "))
+	}
+
+	for key, value := range http.Headers {
+		if key == "" || strings.ContainsAny(key, " :\n") || strings.Contains(value, "\n") {
+			return fmt.Errorf("invalid header '%s'", key)
+		}
+	}
+
+	if err := validateHTTPBasicAuth(http.HTTPBasicAuth); err != nil {
+		return err
+	}
+
+	return validateLoggingSinkTLS(loggingSinkTLS{http.TLS, http.TLSVerify, http.TLSCA, http.TLSCert, http.TLSKey})
+}
+
+func validateKafkaLoggingSink(kafka *clusterv1alpha1.LoggingSinkKafka) error {
+	if len(kafka.Brokers) == 0 {
+		return fmt.Errorf("at least one broker is required")
+	}
+	for _, broker := range kafka.Brokers {
+		if strings.ContainsAny(broker, ", ") || !strings.Contains(broker, ":") {
+			return fmt.Errorf("invalid broker '%s', expected host:port", broker)
+		}
+	}
+
+	if kafka.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+
+	if sasl := kafka.SASL; sasl != nil {
+		if !utils.SliceContains(loggingSinkKafkaSASLMechanisms, sasl.Mechanism) {
+			return fmt.Errorf("unknown SASL mechanism '%s', valid mechanisms are: %s", sasl.Mechanism, strings.Join(loggingSinkKafkaSASLMechanisms, ", "))
+		}
+		if sasl.Username == "" || sasl.Password == "" {
+			return fmt.Errorf("SASL username and password are required")
+		}
+	}
+
+	return validateLoggingSinkTLS(loggingSinkTLS{kafka.TLS, kafka.TLSVerify, kafka.TLSCA, kafka.TLSCert, kafka.TLSKey})
+}
+
+func validateS3LoggingSink(s3 *clusterv1alpha1.LoggingSinkS3) error {
+	if s3.Bucket == "" {
+		return fmt.Errorf("bucket is required")
+	}
+
+	if s3.Region == "" {
+		return fmt.Errorf("region is required")
+	}
+
+	if strings.HasPrefix(s3.Prefix, "/") || strings.HasSuffix(s3.Prefix, "/") {
+		return fmt.Errorf("prefix '%s' can't start or end with '/'", s3.Prefix)
+	}
+
+	if s3.RoleARN != "" && !strings.HasPrefix(s3.RoleARN, "arn:") {
+		return fmt.Errorf("invalid role ARN '%s'", s3.RoleARN)
+	}
+
+	return nil
+}
+
+func validateLoggingSinkEndpoint(host string, port int) error {
+	if host == "" {
+		return fmt.Errorf("host is required")
+	}
+
+	if strings.Contains(host, "://") || strings.ContainsAny(host, "/ ") {
+		return fmt.Errorf("invalid host '%s', expected a hostname or IP address", host)
+	}
+
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("invalid port %d", port)
+	}
+
+	return nil
+}
+
+func validateLoggingSinkTLS(tls loggingSinkTLS) error {
+	if tls.tls != nil && !*tls.tls {
+		if tls.tlsVerify || tls.tlsCA != "" || tls.tlsCert != "" || tls.tlsKey != "" {
+			return fmt.Errorf("cannot specify TLS options with TLS disabled")
+		}
+	}
+
+	if (tls.tlsCert == "") != (tls.tlsKey == "") {
+		return fmt.Errorf("client certificate and key need to be specified together")
+	}
+
+	return nil
+}
+
+func validateHTTPBasicAuth(auth *clusterv1alpha1.HTTPBasicAuth) error {
+	if auth != nil && auth.Username == "" {
+		return fmt.Errorf("username is required for HTTP basic auth")
+	}
+
+	return nil
+}
+
+// https://prometheus.io/docs/concepts/data_model/#metric-names-and-labels
+func validLokiLabelName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff"
+	"github.com/hashicorp/go-multierror"
 	"golang.org/x/net/context"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -46,17 +47,42 @@ func (c *Cluster) wingInstanceClient() (wingclientv1alpha1.InstanceInterface, er
 }
 
 func (c *Cluster) listInstances() (instances []*wingv1alpha1.Instance, err error) {
+	instances, _, err = c.listInstancesWithHosts()
+	return instances, err
+}
+
+// This returns all wing instances of the cluster, with the instance pool
+// derived from the roles of the provider's host if wing has not reported it
+func (c *Cluster) Instances() ([]*wingv1alpha1.Instance, error) {
+	instances, hosts, err := c.listInstancesWithHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, instance := range instances {
+		if instance.InstancePool != "" {
+			continue
+		}
+		if host, ok := hosts[instance.Name]; ok {
+			instance.InstancePool = strings.Join(host.Roles(), ",")
+		}
+	}
+
+	return instances, nil
+}
+
+func (c *Cluster) listInstancesWithHosts() (instances []*wingv1alpha1.Instance, providerInstaceMap map[string]interfaces.Host, err error) {
 	// connect to wing
 	client, err := c.wingInstanceClient()
 	if err != nil {
-		return instances, fmt.Errorf("failed to connect to wing API on bastion: %s", err)
+		return instances, nil, fmt.Errorf("failed to connect to wing API on bastion: %s", err)
 	}
 
 	// list all instances in Provider
 	providerInstances, err := c.ListHosts()
-	providerInstaceMap := make(map[string]interfaces.Host)
+	providerInstaceMap = make(map[string]interfaces.Host)
 	if err != nil {
-		return instances, fmt.Errorf("failed to list provider's instances: %s", err)
+		return instances, nil, fmt.Errorf("failed to list provider's instances: %s", err)
 	}
 
 	for pos, _ := range providerInstances {
@@ -66,7 +92,7 @@ func (c *Cluster) listInstances() (instances []*wingv1alpha1.Instance, err error
 	// list all instances in wing
 	wingInstances, err := client.List(metav1.ListOptions{})
 	if err != nil {
-		return instances, err
+		return instances, nil, err
 	}
 
 	// loop through instances
@@ -84,7 +110,7 @@ func (c *Cluster) listInstances() (instances []*wingv1alpha1.Instance, err error
 		instances = append(instances, instance)
 	}
 
-	return instances, nil
+	return instances, providerInstaceMap, nil
 
 }
 
@@ -114,3 +140,68 @@ func outputInstances(instances []*wingv1alpha1.Instance) string {
 	}
 	return strings.Join(output, ", ")
 }
+
+// WingEnvironment returns the environment variables configuring the wing
+// agents of the cluster, wing reads them as defaults of its flags
+func (c *Cluster) WingEnvironment() map[string]string {
+	env := map[string]string{}
+
+	if c.conf.Wing == nil || c.conf.Wing.Converge == nil {
+		return env
+	}
+	converge := c.conf.Wing.Converge
+
+	for _, d := range []struct {
+		name     string
+		duration *metav1.Duration
+	}{
+		{"WING_CONVERGE_RETRY_INITIAL_INTERVAL", converge.RetryInitialInterval},
+		{"WING_CONVERGE_RETRY_MAX_INTERVAL", converge.RetryMaxInterval},
+		{"WING_CONVERGE_RETRY_MAX_ELAPSED_TIME", converge.RetryMaxElapsedTime},
+		{"WING_RECONVERGE_INTERVAL", converge.ReconvergeInterval},
+		{"WING_RECONVERGE_JITTER", converge.ReconvergeJitter},
+	} {
+		if d.duration != nil {
+			env[d.name] = d.duration.Duration.String()
+		}
+	}
+
+	return env
+}
+
+// validate wing converge configuration
+func (c *Cluster) validateWing() error {
+	if c.conf.Wing == nil || c.conf.Wing.Converge == nil {
+		return nil
+	}
+	converge := c.conf.Wing.Converge
+
+	var result *multierror.Error
+
+	for _, d := range []struct {
+		name     string
+		duration *metav1.Duration
+	}{
+		{"retryInitialInterval", converge.RetryInitialInterval},
+		{"retryMaxInterval", converge.RetryMaxInterval},
+		{"retryMaxElapsedTime", converge.RetryMaxElapsedTime},
+		{"reconvergeInterval", converge.ReconvergeInterval},
+		{"reconvergeJitter", converge.ReconvergeJitter},
+	} {
+		if d.duration != nil && d.duration.Duration < 0 {
+			result = multierror.Append(result, fmt.Errorf("%s must not be negative", d.name))
+		}
+	}
+
+	if converge.RetryInitialInterval != nil && converge.RetryMaxInterval != nil &&
+		converge.RetryMaxInterval.Duration < converge.RetryInitialInterval.Duration {
+		result = multierror.Append(result, fmt.Errorf("retryMaxInterval must not be shorter than retryInitialInterval"))
+	}
+
+	if converge.ReconvergeJitter != nil && converge.ReconvergeJitter.Duration > 0 &&
+		(converge.ReconvergeInterval == nil || converge.ReconvergeInterval.Duration == 0) {
+		result = multierror.Append(result, fmt.Errorf("reconvergeJitter requires a reconvergeInterval"))
+	}
+
+	return result.ErrorOrNil()
+}
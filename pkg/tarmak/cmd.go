@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,10 +17,13 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils/consts"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+	"github.com/jetstack/tarmak/pkg/terraform/plan"
 )
 
 type CmdTarmak struct {
@@ -42,11 +46,26 @@ func (t *Tarmak) NewCmdTarmak(pflags *pflag.FlagSet, args []string) *CmdTarmak {
 }
 
 func (c *CmdTarmak) Plan() (returnCode int, err error) {
+	if c.flags.Output != "" && !utils.SliceContains(utils.OutputFormats, c.flags.Output) {
+		return 1, fmt.Errorf("unknown output format '%s', valid formats are: %s", c.flags.Output, strings.Join(utils.OutputFormats, ", "))
+	}
+
 	if err := c.setupTerraform(); err != nil {
 		return 1, err
 	}
 
-	changesNeeded, err := c.terraform.Plan(c.Cluster(), false)
+	if !c.managesInfrastructure() {
+		c.log.Infof("provider %s does not manage any infrastructure, nothing to plan", c.Cluster().Environment().Provider())
+		return 0, nil
+	}
+
+	changesNeeded, tfPlan, err := c.terraform.Plan(c.Cluster(), false)
+	if tfPlan != nil {
+		if printErr := c.printPlanSummary(tfPlan); printErr != nil {
+			return 1, printErr
+		}
+	}
+
 	if changesNeeded {
 		return 2, err
 	} else {
@@ -54,7 +73,61 @@ func (c *CmdTarmak) Plan() (returnCode int, err error) {
 	}
 }
 
+// printPlanSummary lists every change of the plan, the json and yaml output
+// is meant for automated reviews of risky changes
+func (c *CmdTarmak) printPlanSummary(tfPlan *plan.Plan) error {
+	var instancePools []string
+	for _, instancePool := range c.Cluster().InstancePools() {
+		instancePools = append(instancePools, instancePool.TFName())
+	}
+
+	summary := tfPlan.Summary(instancePools)
+
+	var varMaps []map[string]string
+	for _, group := range summary.Groups {
+		for _, change := range group.Changes {
+			varMaps = append(varMaps, map[string]string{
+				"module":        group.Module,
+				"instance pool": group.InstancePool,
+				"action":        string(change.Action),
+				"resource":      change.Address,
+				"risks":         strings.Join(change.Risks, "; "),
+				"type":          change.Type,
+			})
+		}
+	}
+
+	if err := utils.List(os.Stdout, c.flags.Output, summary, []string{"module", "instance pool", "action", "resource", "risks"}, []string{"type"}, varMaps); err != nil {
+		return err
+	}
+
+	switch c.flags.Output {
+	case utils.OutputJSON, utils.OutputYAML:
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "\nPlan: %s\n", summary)
+	if summary.Risky > 0 {
+		c.log.Warnf("plan contains %d risky changes, review them before applying", summary.Risky)
+	}
+
+	return nil
+}
+
 func (c *CmdTarmak) Apply() error {
+	if c.flags.Cluster.Apply.DryRun {
+		return c.DryRunConfiguration()
+	}
+
+	// existing machines only ever get their configuration applied
+	configurationOnly := c.flags.Cluster.Apply.ConfigurationOnly
+	if !c.managesInfrastructure() {
+		if c.flags.Cluster.Apply.InfrastructureOnly {
+			return fmt.Errorf("provider %s does not manage any infrastructure", c.Cluster().Environment().Provider())
+		}
+		configurationOnly = true
+	}
+
 	err := c.setupTerraform()
 	if err != nil {
 		return err
@@ -63,7 +136,7 @@ func (c *CmdTarmak) Apply() error {
 	// assume a change so that we wait for convergence in configuration only
 	hasChanged := true
 	// run terraform apply always, do not run it when in configuration only mode
-	if !c.flags.Cluster.Apply.ConfigurationOnly {
+	if !configurationOnly {
 		hasChanged, err = c.terraform.Apply(c.Cluster())
 		if err != nil {
 			return err
@@ -71,7 +144,7 @@ func (c *CmdTarmak) Apply() error {
 	}
 
 	// upload tar gz only if terraform hasn't uploaded it yet
-	if c.flags.Cluster.Apply.ConfigurationOnly {
+	if configurationOnly {
 		err := c.Cluster().UploadConfiguration()
 		if err != nil {
 			return err
@@ -103,13 +176,78 @@ func (c *CmdTarmak) Apply() error {
 	return nil
 }
 
+// DryRunConfiguration runs the current manifests in noop mode on all instances
+// and prints the changes that would be applied per instance
+func (c *CmdTarmak) DryRunConfiguration() error {
+	for _, f := range []func() error{
+		c.Validate,
+		c.Verify,
+		c.writeSSHConfigForClusterHosts,
+	} {
+		if err := f(); err != nil {
+			return err
+		}
+	}
+
+	instances, err := c.Cluster().DryRunConfiguration()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name < instances[j].Name
+	})
+
+	var failed []string
+	for _, instance := range instances {
+		status := instance.Status.DryRun
+
+		changes := "unknown"
+		if len(status.ExitCodes) > 0 {
+			switch code := status.ExitCodes[len(status.ExitCodes)-1]; {
+			case code == 0:
+				changes = "no changes"
+			case code == 2:
+				changes = "changes pending"
+			default:
+				changes = fmt.Sprintf("failures (return code %d)", code)
+			}
+		}
+
+		if status.State == wingv1alpha1.InstanceManifestStateError || status.State == wingv1alpha1.InstanceManifestStateVerificationFailed {
+			failed = append(failed, instance.Name)
+		}
+
+		fmt.Fprintf(os.Stdout, "==> %s: %s, %s\n", instance.Name, status.State, changes)
+		if report := status.Report; report != nil {
+			fmt.Fprintf(os.Stdout, "resources: %d total, %d out of sync, %d failed\n", report.ResourcesTotal, report.ResourcesOutOfSync, report.ResourcesFailed)
+			for _, resource := range report.FailedResources {
+				fmt.Fprintf(os.Stdout, "failed: %s\n", resource)
+			}
+		}
+		for _, message := range status.Messages {
+			fmt.Fprintf(os.Stdout, "%s\n", strings.TrimSpace(message))
+		}
+		fmt.Fprintln(os.Stdout)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("dry run failed on instances: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
 func (c *CmdTarmak) Destroy() error {
 	if err := c.setupTerraform(); err != nil {
 		return err
 	}
 
-	if err := c.terraform.Destroy(c.Cluster()); err != nil {
-		return err
+	// existing machines are left untouched
+	if c.managesInfrastructure() {
+		if err := c.terraform.Destroy(c.Cluster()); err != nil {
+			return err
+		}
 	}
 
 	if err := os.RemoveAll(c.cluster.SSHHostKeysPath()); err != nil {
@@ -401,6 +539,10 @@ func (c *CmdTarmak) kubePublicAPIEndpoint() bool {
 }
 
 func (c *CmdTarmak) Logs() error {
+	if c.flags.Cluster.Logs.Follow {
+		return c.logs.Follow(c.args, c.flags.Cluster.Logs)
+	}
+
 	err := c.logs.Aggregate(c.args, c.flags.Cluster.Logs)
 	if err != nil {
 		return err
@@ -448,6 +590,12 @@ func (c *CmdTarmak) verifyTerraformBinaryVersion() error {
 	return nil
 }
 
+// managesInfrastructure is false for providers of existing machines, which
+// do not run terraform
+func (c *CmdTarmak) managesInfrastructure() bool {
+	return c.Cluster().Environment().Provider().Cloud() != clusterv1alpha1.CloudBaremetal
+}
+
 func (c *CmdTarmak) setupTerraform() error {
 	type step struct {
 		log string
@@ -331,6 +331,17 @@ func (c *Config) AppendCluster(cluster *clusterv1alpha1.Cluster) error {
 	return c.writeYAML(c.conf)
 }
 
+// UpdateCluster replaces the configuration of an existing cluster
+func (c *Config) UpdateCluster(cluster *clusterv1alpha1.Cluster) error {
+	existing, err := c.Cluster(cluster.Environment, cluster.Name)
+	if err != nil {
+		return err
+	}
+
+	*existing = *cluster
+	return c.writeYAML(c.conf)
+}
+
 func (c *Config) UniqueClusterName(environment, name string) error {
 	for _, u := range c.Clusters(environment) {
 		if u.Name == name {
@@ -14,6 +14,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/ssh"
 	"k8s.io/client-go/rest"
 
@@ -26,6 +27,7 @@ import (
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 	"github.com/jetstack/tarmak/pkg/tarmak/vault"
 	wingclient "github.com/jetstack/tarmak/pkg/wing/client/clientset/versioned"
+	"github.com/jetstack/tarmak/pkg/wing/signature"
 )
 
 type Environment struct {
@@ -33,7 +35,8 @@ type Environment struct {
 
 	clusters []interfaces.Cluster
 
-	sshKeyPrivate interface{}
+	sshKeyPrivate      interface{}
+	manifestSigningKey ed25519.PrivateKey
 
 	// this is the cluster that contains state/vault/tools
 	HubCluster interfaces.Cluster
@@ -135,6 +138,12 @@ func (e *Environment) Variables() map[string]interface{} {
 		output[key] = value
 	}
 
+	key, err := e.ManifestSigningKey()
+	if err != nil {
+		e.log.Fatal(err)
+	}
+	output["manifest_signing_public_key"] = signature.EncodePublicKey(key.Public().(ed25519.PublicKey))
+
 	output["state_bucket"] = e.Provider().RemoteStateBucketName()
 	output["state_cluster_name"] = e.HubCluster.Name()
 	output["tools_cluster_name"] = e.HubCluster.Name()
@@ -0,0 +1,78 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package environment
+
+import (
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const manifestSigningKeyType = "ED25519 PRIVATE KEY"
+
+// ManifestSigningKeyPath is where the key signing the puppet manifests of the
+// environment is kept
+func (e *Environment) ManifestSigningKeyPath() string {
+	return filepath.Join(e.ConfigPath(), "manifest_signing_key")
+}
+
+// ManifestSigningKey returns the key signing the puppet manifests of the
+// environment, it gets generated on first use
+func (e *Environment) ManifestSigningKey() (ed25519.PrivateKey, error) {
+	if e.manifestSigningKey != nil {
+		return e.manifestSigningKey, nil
+	}
+
+	path := e.ManifestSigningKeyPath()
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		key, err := generateManifestSigningKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("error generating manifest signing key: %s", err)
+		}
+		e.log.Infof("generated manifest signing key %s", path)
+		e.manifestSigningKey = key
+		return key, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to find manifest signing key in %s: %s", path, err)
+	}
+
+	keyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read manifest signing key %s: %s", path, err)
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil || block.Type != manifestSigningKeyType || len(block.Bytes) != ed25519.SeedSize {
+		return nil, fmt.Errorf("unable to parse manifest signing key %s", path)
+	}
+
+	e.manifestSigningKey = ed25519.NewKeyFromSeed(block.Bytes)
+	return e.manifestSigningKey, nil
+}
+
+func generateManifestSigningKey(path string) (ed25519.PrivateKey, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("error creating directory: %s", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:  manifestSigningKeyType,
+		Bytes: key.Seed(),
+	})
+
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,508 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+)
+
+const (
+	etcdBackupNameLayout = "20060102T150405Z"
+	etcdSnapshotSuffix   = ".db"
+
+	etcdHealthInterval = 5 * time.Second
+	etcdHealthTimeout  = 5 * time.Minute
+
+	// returned by the snapshot script if the etcd cluster isn't running on
+	// the member
+	etcdInactiveReturnCode = 3
+)
+
+// etcd clusters running on every etcd instance, overlay is stopped if calico
+// doesn't use etcd as its datastore
+var etcdClusters = []string{"k8s-main", "k8s-events", "overlay"}
+
+var etcdBackupNameRegexp = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}Z$`)
+
+// etcdEnvScript loads the configuration puppet wrote into the systemd unit of
+// an etcd cluster, so versions, ports and certificates don't need to be known
+// by tarmak
+const etcdEnvScript = `set -euo pipefail
+unit="etcd-%s.service"
+eval "$(systemctl show "${unit}" --property=Environment | sed -e 's/^Environment=//' | tr ' ' '\n' | grep '^ETCD_[A-Z_]*=' || true)"
+if [ -z "${ETCD_DATA_DIR:-}" ]; then
+  echo "systemd unit ${unit} not found" >&2
+  exit 1
+fi
+etcd_bin="$(systemctl show "${unit}" --property=ExecStart | sed -n -e 's/.*path=\([^ ;]*\).*/\1/p')"
+etcdctl="$(dirname "${etcd_bin}")/etcdctl"
+export ETCDCTL_API=3
+export ETCDCTL_ENDPOINTS="${ETCD_LISTEN_CLIENT_URLS//0.0.0.0/127.0.0.1}"
+if [ -n "${ETCD_CERT_FILE:-}" ]; then
+  export ETCDCTL_CERT="${ETCD_CERT_FILE}" ETCDCTL_KEY="${ETCD_KEY_FILE}" ETCDCTL_CACERT="${ETCD_TRUSTED_CA_FILE}"
+fi
+snapshot="${ETCD_DATA_DIR}.snapshot.db"
+`
+
+const etcdSnapshotScript = `systemctl is-active --quiet "${unit}" || exit 3
+trap 'rm -f "${snapshot}"' EXIT
+"${etcdctl}" snapshot save "${snapshot}" >&2
+cat "${snapshot}"
+`
+
+const etcdStageScript = `cat > "${snapshot}"
+"${etcdctl}" --write-out=table snapshot status "${snapshot}" >&2
+`
+
+const etcdStopScript = `systemctl stop "${unit}"
+`
+
+// etcdRestoreScript restores the staged snapshot into a new data directory,
+// the previous one is kept next to it. All members need to use the same
+// token, which differs from the one of the cluster that took the snapshot.
+const etcdRestoreScript = `peer_url="${ETCD_INITIAL_ADVERTISE_PEER_URLS:-http://localhost:2380}"
+restore_dir="${ETCD_DATA_DIR}.restore"
+rm -rf "${restore_dir}"
+"${etcdctl}" snapshot restore "${snapshot}" \
+  --name "${ETCD_NAME}" \
+  --data-dir "${restore_dir}" \
+  --initial-cluster "${ETCD_INITIAL_CLUSTER:-${ETCD_NAME}=${peer_url}}" \
+  --initial-cluster-token "${ETCD_INITIAL_CLUSTER_TOKEN:-etcd}-${backup}" \
+  --initial-advertise-peer-urls "${peer_url}" >&2
+chown -R --reference="${ETCD_DATA_DIR}" "${restore_dir}"
+chmod --reference="${ETCD_DATA_DIR}" "${restore_dir}"
+mv "${ETCD_DATA_DIR}" "${ETCD_DATA_DIR}.before-restore-$(date -u +%Y%m%dT%H%M%SZ)"
+mv "${restore_dir}" "${ETCD_DATA_DIR}"
+rm -f "${snapshot}"
+`
+
+// etcdStartScript doesn't block, as a restored member only becomes ready once
+// a quorum of restored members has been started
+const etcdStartScript = `systemctl start --no-block "${unit}"
+`
+
+const etcdHealthScript = `"${etcdctl}" endpoint health >&2
+`
+
+// EtcdBackup takes v3 snapshots of the etcd clusters and uploads them to the
+// remote state of the provider
+func (c *CmdTarmak) EtcdBackup() error {
+	selected, err := selectEtcdClusters(c.flags.Cluster.Etcd.Backup.EtcdClusters)
+	if err != nil {
+		return err
+	}
+
+	hosts, err := c.setupEtcd()
+	if err != nil {
+		return err
+	}
+
+	provider := c.Cluster().Environment().Provider()
+	backup := time.Now().UTC().Format(etcdBackupNameLayout)
+
+	for _, etcdCluster := range selected {
+		if err := c.backupEtcdCluster(provider, hosts, backup, etcdCluster); err != nil {
+			return fmt.Errorf("failed to back up etcd cluster %s: %s", etcdCluster, err)
+		}
+	}
+
+	c.log.Infof("etcd backup %s finished", backup)
+	return nil
+}
+
+// EtcdRestore replaces the data of the etcd clusters with the snapshots of a
+// backup, one member at a time.
+func (c *CmdTarmak) EtcdRestore() error {
+	flags := c.flags.Cluster.Etcd.Restore
+
+	selected, err := selectEtcdClusters(flags.EtcdClusters)
+	if err != nil {
+		return err
+	}
+
+	hosts, err := c.setupEtcd()
+	if err != nil {
+		return err
+	}
+
+	provider := c.Cluster().Environment().Provider()
+	keys, err := provider.ListEtcdSnapshots(c.Cluster())
+	if err != nil {
+		return err
+	}
+
+	backup, snapshots, err := etcdBackupSnapshots(keys, flags.Backup)
+	if err != nil {
+		return err
+	}
+
+	var restore []string
+	for _, etcdCluster := range selected {
+		if snapshots[etcdCluster] {
+			restore = append(restore, etcdCluster)
+		} else if etcdCluster == "overlay" {
+			c.log.Infof("backup %s contains no snapshot of etcd cluster overlay, skipping it", backup)
+		} else {
+			return fmt.Errorf("backup %s contains no snapshot of etcd cluster %s", backup, etcdCluster)
+		}
+	}
+	if len(restore) == 0 {
+		return fmt.Errorf("backup %s contains no snapshots to restore", backup)
+	}
+
+	if !flags.AutoApprove {
+		response, err := input.New(os.Stdin, os.Stdout).AskYesNo(&input.AskYesNo{
+			Default: false,
+			Query: fmt.Sprintf(
+				"Replace the data of etcd clusters %s on %d members with backup %s?",
+				strings.Join(restore, ", "), len(hosts), backup,
+			),
+		})
+		if err != nil {
+			return err
+		}
+		if !response {
+			return nil
+		}
+	}
+
+	for _, etcdCluster := range restore {
+		if err := c.restoreEtcdCluster(provider, hosts, backup, etcdCluster); err != nil {
+			return fmt.Errorf("failed to restore etcd cluster %s: %s", etcdCluster, err)
+		}
+	}
+
+	// API servers need to drop their caches of the replaced data
+	if len(restore) == 1 && restore[0] == "overlay" {
+		c.log.Infof("etcd restore of backup %s finished", backup)
+		return nil
+	}
+	for _, host := range c.masterHosts() {
+		c.log.Infof("restarting kube-apiserver on %s", host.Aliases()[0])
+		if _, err := c.etcdExecute(host, "", "systemctl try-restart kube-apiserver.service\n", nil, nil); err != nil {
+			return err
+		}
+	}
+
+	c.log.Infof("etcd restore of backup %s finished", backup)
+	return nil
+}
+
+func (c *CmdTarmak) setupEtcd() ([]interfaces.Host, error) {
+	for _, f := range []func() error{
+		c.Validate,
+		c.writeSSHConfigForClusterHosts,
+	} {
+		if err := f(); err != nil {
+			return nil, err
+		}
+	}
+
+	hosts, err := c.Cluster().ListHosts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hosts: %s", err)
+	}
+
+	hosts = etcdHosts(hosts)
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("cluster %s has no etcd instances", c.Cluster().Name())
+	}
+
+	for _, host := range hosts {
+		if len(host.Aliases()) == 0 {
+			return nil, fmt.Errorf("found etcd host with no aliases: %s", host.Hostname())
+		}
+	}
+
+	return hosts, nil
+}
+
+func (c *CmdTarmak) masterHosts() []interfaces.Host {
+	hosts, err := c.Cluster().ListHosts()
+	if err != nil {
+		c.log.Warnf("failed to list hosts: %s", err)
+		return nil
+	}
+
+	var masters []interfaces.Host
+	for _, host := range hosts {
+		if hasRole(host.Roles(), clusterv1alpha1.InstancePoolTypeMaster) || hasRole(host.Roles(), "etcd-master") {
+			masters = append(masters, host)
+		}
+	}
+
+	return masters
+}
+
+// backupEtcdCluster snapshots the etcd cluster on the first member it is
+// running on
+func (c *CmdTarmak) backupEtcdCluster(provider interfaces.Provider, hosts []interfaces.Host, backup, etcdCluster string) error {
+	file, err := ioutil.TempFile("", "tarmak-etcd-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	for _, host := range hosts {
+		if err := file.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := file.Seek(0, 0); err != nil {
+			return err
+		}
+
+		c.log.Infof("taking snapshot of etcd cluster %s on %s", etcdCluster, host.Aliases()[0])
+		ret, err := c.etcdExecute(host, etcdCluster, etcdSnapshotScript, nil, file)
+		if ret == etcdInactiveReturnCode {
+			c.log.Debugf("etcd cluster %s is not running on %s", etcdCluster, host.Aliases()[0])
+			continue
+		} else if err != nil {
+			c.log.Warn(err)
+			continue
+		}
+
+		if _, err := file.Seek(0, 0); err != nil {
+			return err
+		}
+
+		key := etcdSnapshotKey(backup, etcdCluster)
+		if err := provider.UploadEtcdSnapshot(c.Cluster(), key, file); err != nil {
+			return err
+		}
+
+		c.log.Infof("uploaded snapshot %s", key)
+		return nil
+	}
+
+	if etcdCluster == "overlay" {
+		c.log.Infof("etcd cluster overlay is not running, skipping it")
+		return nil
+	}
+
+	return fmt.Errorf("no member was able to take a snapshot")
+}
+
+// restoreEtcdCluster stages the snapshot on all members before stopping any
+// of them, so unreachable members don't leave the cluster down. Then each
+// member is stopped, restored and started before the next one.
+func (c *CmdTarmak) restoreEtcdCluster(provider interfaces.Provider, hosts []interfaces.Host, backup, etcdCluster string) error {
+	file, err := ioutil.TempFile("", "tarmak-etcd-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	key := etcdSnapshotKey(backup, etcdCluster)
+	c.log.Infof("downloading snapshot %s", key)
+	if err := provider.DownloadEtcdSnapshot(c.Cluster(), key, file); err != nil {
+		return err
+	}
+
+	for _, host := range hosts {
+		if _, err := file.Seek(0, 0); err != nil {
+			return err
+		}
+
+		c.log.Infof("staging snapshot %s on %s", key, host.Aliases()[0])
+		if _, err := c.etcdExecute(host, etcdCluster, etcdStageScript, file, nil); err != nil {
+			return err
+		}
+	}
+
+	quorum := etcdQuorum(len(hosts))
+	for pos, host := range hosts {
+		for _, step := range []struct {
+			log    string
+			script string
+		}{
+			{"stopping", etcdStopScript},
+			{"restoring", fmt.Sprintf("backup=%s\n%s", backup, etcdRestoreScript)},
+			{"starting", etcdStartScript},
+		} {
+			c.log.Infof("%s etcd cluster %s on %s", step.log, etcdCluster, host.Aliases()[0])
+			if _, err := c.etcdExecute(host, etcdCluster, step.script, nil, nil); err != nil {
+				return err
+			}
+		}
+
+		// restored members form a new cluster, which only reports healthy
+		// once a quorum of them has been started
+		restored := pos + 1
+		if restored < quorum {
+			c.log.Infof("waiting for %d more members of etcd cluster %s to be restored for quorum", quorum-restored, etcdCluster)
+			continue
+		}
+
+		// the health check reads through raft, so a healthy member also
+		// confirms the quorum. Members started before the quorum was
+		// reached are checked once it has been.
+		waitFor := []interfaces.Host{host}
+		if restored == quorum {
+			waitFor = hosts[:restored]
+		}
+		for _, h := range waitFor {
+			if err := c.waitForEtcdHealth(h, etcdCluster); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// etcdQuorum returns the number of members required for a quorum in an etcd
+// cluster of the given size
+func etcdQuorum(members int) int {
+	return members/2 + 1
+}
+
+func (c *CmdTarmak) waitForEtcdHealth(host interfaces.Host, etcdCluster string) error {
+	timeout := time.After(etcdHealthTimeout)
+	for {
+		_, err := c.etcdExecute(host, etcdCluster, etcdHealthScript, nil, nil)
+		if err == nil {
+			c.log.Infof("etcd cluster %s is healthy on %s", etcdCluster, host.Aliases()[0])
+			return nil
+		}
+		c.log.Debug(err)
+
+		select {
+		case <-c.ctx.Done():
+			return c.ctx.Err()
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for etcd cluster %s on %s to become healthy: %s", etcdCluster, host.Aliases()[0], err)
+		case <-time.After(etcdHealthInterval):
+		}
+	}
+}
+
+// etcdExecute runs a script as root on an etcd member, with the configuration
+// of the etcd cluster loaded if one is given
+func (c *CmdTarmak) etcdExecute(host interfaces.Host, etcdCluster, script string, stdin io.Reader, stdout io.Writer) (int, error) {
+	if etcdCluster != "" {
+		script = fmt.Sprintf(etcdEnvScript, etcdCluster) + script
+	}
+
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	var stderr bytes.Buffer
+
+	alias := host.Aliases()[0]
+	ret, err := c.SSH().Execute(alias, []string{"sudo", "/bin/bash", "-c", shellQuote(script)}, stdin, stdout, &stderr)
+	if err != nil || ret != 0 {
+		return ret, fmt.Errorf("command on %s returned non-zero (%d): %s", alias, ret, strings.TrimSpace(stderr.String()))
+	}
+
+	c.log.Debug(strings.TrimSpace(stderr.String()))
+	return ret, nil
+}
+
+// etcdHosts returns the hosts running etcd sorted by their alias
+func etcdHosts(hosts []interfaces.Host) []interfaces.Host {
+	var etcd []interfaces.Host
+	for _, host := range hosts {
+		if hasRole(host.Roles(), clusterv1alpha1.InstancePoolTypeEtcd) || hasRole(host.Roles(), "etcd-master") {
+			etcd = append(etcd, host)
+		}
+	}
+
+	sort.SliceStable(etcd, func(i, j int) bool {
+		return hostAlias(etcd[i]) < hostAlias(etcd[j])
+	})
+
+	return etcd
+}
+
+func hostAlias(host interfaces.Host) string {
+	if aliases := host.Aliases(); len(aliases) > 0 {
+		return aliases[0]
+	}
+	return ""
+}
+
+// selectEtcdClusters verifies the etcd cluster names given, no names selects
+// all of them
+func selectEtcdClusters(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return etcdClusters, nil
+	}
+
+	var selected []string
+	for _, etcdCluster := range etcdClusters {
+		for _, name := range names {
+			if name == etcdCluster {
+				selected = append(selected, etcdCluster)
+				break
+			}
+		}
+	}
+
+	if len(selected) != len(names) {
+		return nil, fmt.Errorf("unknown etcd clusters %s, valid etcd clusters are %s", strings.Join(names, ", "), strings.Join(etcdClusters, ", "))
+	}
+
+	return selected, nil
+}
+
+func etcdSnapshotKey(backup, etcdCluster string) string {
+	return fmt.Sprintf("%s/%s%s", backup, etcdCluster, etcdSnapshotSuffix)
+}
+
+// etcdBackupSnapshots returns the backup to restore and the etcd clusters it
+// contains snapshots of, the latest backup is used if no name is given
+func etcdBackupSnapshots(keys []string, backup string) (string, map[string]bool, error) {
+	backups := map[string]map[string]bool{}
+	for _, key := range keys {
+		parts := strings.Split(key, "/")
+		if len(parts) != 2 || !etcdBackupNameRegexp.MatchString(parts[0]) || !strings.HasSuffix(parts[1], etcdSnapshotSuffix) {
+			continue
+		}
+
+		if _, ok := backups[parts[0]]; !ok {
+			backups[parts[0]] = map[string]bool{}
+		}
+		backups[parts[0]][strings.TrimSuffix(parts[1], etcdSnapshotSuffix)] = true
+	}
+
+	if len(backups) == 0 {
+		return "", nil, fmt.Errorf("no etcd backups found")
+	}
+
+	if backup == "" {
+		for name := range backups {
+			if name > backup {
+				backup = name
+			}
+		}
+	}
+
+	snapshots, ok := backups[backup]
+	if !ok {
+		var names []string
+		for name := range backups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", nil, fmt.Errorf("etcd backup %s not found, available backups are %s", backup, strings.Join(names, ", "))
+	}
+
+	return backup, snapshots, nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
@@ -0,0 +1,189 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/mocks"
+)
+
+func fakeAliasedHost(ctrl *gomock.Controller, id, alias string, roles ...string) interfaces.Host {
+	h := mocks.NewMockHost(ctrl)
+	h.EXPECT().ID().AnyTimes().Return(id)
+	h.EXPECT().Aliases().AnyTimes().Return([]string{alias})
+	h.EXPECT().Roles().AnyTimes().Return(roles)
+	return h
+}
+
+func TestEtcd_etcdHosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hosts := etcdHosts([]interfaces.Host{
+		fakeAliasedHost(ctrl, "i-bastion", "bastion", "bastion"),
+		fakeAliasedHost(ctrl, "i-etcd-3", "etcd-3", "etcd-3"),
+		fakeAliasedHost(ctrl, "i-etcd-1", "etcd-1", "etcd-1"),
+		fakeAliasedHost(ctrl, "i-master", "master", "master"),
+		fakeAliasedHost(ctrl, "i-etcd-2", "etcd-2", "etcd-2"),
+		fakeAliasedHost(ctrl, "i-single", "etcd-master", "etcd-master"),
+	})
+
+	if act, exp := hostIDs(hosts), []string{"i-etcd-1", "i-etcd-2", "i-etcd-3", "i-single"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected hosts: act=%v exp=%v", act, exp)
+	}
+}
+
+func TestEtcd_selectEtcdClusters(t *testing.T) {
+	selected, err := selectEtcdClusters(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(selected, etcdClusters) {
+		t.Errorf("expected all etcd clusters, got: %v", selected)
+	}
+
+	selected, err = selectEtcdClusters([]string{"overlay", "k8s-main"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := []string{"k8s-main", "overlay"}; !reflect.DeepEqual(selected, exp) {
+		t.Errorf("unexpected etcd clusters: act=%v exp=%v", selected, exp)
+	}
+
+	if _, err := selectEtcdClusters([]string{"k8s-main", "k8s"}); err == nil || !strings.Contains(err.Error(), "unknown etcd clusters") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEtcd_etcdBackupSnapshots(t *testing.T) {
+	keys := []string{
+		"20180701T120000Z/k8s-main.db",
+		"20180701T120000Z/k8s-events.db",
+		"20180701T120000Z/overlay.db",
+		"20180702T120000Z/k8s-main.db",
+		"20180702T120000Z/k8s-events.db",
+		"20180702T120000Z/.tmp-123",
+		"manual/k8s-main.db",
+	}
+
+	for _, c := range []struct {
+		backup    string
+		expBackup string
+		expSnaps  map[string]bool
+		err       string
+	}{
+		{"", "20180702T120000Z", map[string]bool{"k8s-main": true, "k8s-events": true}, ""},
+		{"20180701T120000Z", "20180701T120000Z", map[string]bool{"k8s-main": true, "k8s-events": true, "overlay": true}, ""},
+		{"20180703T120000Z", "", nil, "available backups are 20180701T120000Z, 20180702T120000Z"},
+		{"manual", "", nil, "etcd backup manual not found"},
+	} {
+		backup, snapshots, err := etcdBackupSnapshots(keys, c.backup)
+		if c.err != "" {
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("backup '%s': expected error '%s', got: %v", c.backup, c.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("backup '%s': unexpected error: %s", c.backup, err)
+			continue
+		}
+		if backup != c.expBackup || !reflect.DeepEqual(snapshots, c.expSnaps) {
+			t.Errorf("backup '%s': act=%s %v exp=%s %v", c.backup, backup, snapshots, c.expBackup, c.expSnaps)
+		}
+	}
+
+	if _, _, err := etcdBackupSnapshots(nil, ""); err == nil {
+		t.Error("expected an error without any backups")
+	}
+}
+
+func TestEtcd_shellQuote(t *testing.T) {
+	if act, exp := shellQuote(`echo 'a' "$b"`), `'echo '"'"'a'"'"' "$b"'`; act != exp {
+		t.Errorf("unexpected quoting: act=%s exp=%s", act, exp)
+	}
+}
+
+func TestEtcd_etcdQuorum(t *testing.T) {
+	for members, exp := range map[int]int{1: 1, 2: 2, 3: 2, 4: 3, 5: 3} {
+		if act := etcdQuorum(members); act != exp {
+			t.Errorf("unexpected quorum for %d members: act=%d exp=%d", members, act, exp)
+		}
+	}
+}
+
+// this tests that no further member is stopped before the restored members
+// reached quorum and are healthy
+func TestEtcd_restoreEtcdCluster(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hosts := []interfaces.Host{
+		fakeAliasedHost(ctrl, "i-etcd-1", "etcd-1", "etcd"),
+		fakeAliasedHost(ctrl, "i-etcd-2", "etcd-2", "etcd"),
+		fakeAliasedHost(ctrl, "i-etcd-3", "etcd-3", "etcd"),
+	}
+
+	cluster := mocks.NewMockCluster(ctrl)
+	provider := mocks.NewMockProvider(ctrl)
+	provider.EXPECT().DownloadEtcdSnapshot(cluster, "20190101T000000Z/k8s-main.db", gomock.Any()).Return(nil)
+
+	var steps []string
+	ssh := mocks.NewMockSSH(ctrl)
+	ssh.EXPECT().Execute(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(host string, cmd []string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+			script := cmd[len(cmd)-1]
+			for _, step := range []struct {
+				name   string
+				script string
+			}{
+				{"stage", etcdStageScript},
+				{"stop", etcdStopScript},
+				{"restore", etcdRestoreScript},
+				{"start", etcdStartScript},
+				{"health", etcdHealthScript},
+			} {
+				if strings.Contains(script, step.script) {
+					steps = append(steps, fmt.Sprintf("%s %s", step.name, host))
+				}
+			}
+			return 0, nil
+		},
+	)
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	c := &CmdTarmak{
+		Tarmak: &Tarmak{
+			log:     logger,
+			ssh:     ssh,
+			cluster: cluster,
+		},
+		log: logrus.NewEntry(logger),
+	}
+
+	if err := c.restoreEtcdCluster(provider, hosts, "20190101T000000Z", "k8s-main"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := []string{
+		"stage etcd-1", "stage etcd-2", "stage etcd-3",
+		"stop etcd-1", "restore etcd-1", "start etcd-1",
+		"stop etcd-2", "restore etcd-2", "start etcd-2",
+		"health etcd-1", "health etcd-2",
+		"stop etcd-3", "restore etcd-3", "start etcd-3",
+		"health etcd-3",
+	}
+	if !reflect.DeepEqual(steps, exp) {
+		t.Errorf("unexpected restore steps:\nact=%v\nexp=%v", steps, exp)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/cluster/firewall"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+// FirewallShow prints the flows permitted by the firewall rules of the
+// cluster
+func (c *CmdTarmak) FirewallShow() error {
+	entries, err := c.firewallMatrix()
+	if err != nil {
+		return err
+	}
+
+	if c.flags.Cluster.Firewall.Show.Graph {
+		return firewall.WriteGraphviz(os.Stdout, entries)
+	}
+
+	return listFirewallRules(os.Stdout, c.flags.Output, entries)
+}
+
+// FirewallExport prints host level firewall rules for instances of the given
+// roles, peers are resolved to the addresses of the cluster's instances
+func (c *CmdTarmak) FirewallExport() error {
+	flags := c.flags.Cluster.Firewall.Export
+
+	entries, err := c.firewallMatrix()
+	if err != nil {
+		return err
+	}
+
+	roles := flags.Roles
+	if len(roles) == 0 {
+		return fmt.Errorf("--roles is required, roles of the cluster are %s", strings.Join(firewallRoles(entries), ", "))
+	}
+
+	hosts, err := c.Cluster().ListHosts()
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %s", err)
+	}
+
+	addresses, err := firewallAddresses(hosts, firewallRoles(entries))
+	if err != nil {
+		return err
+	}
+
+	return firewall.Export(os.Stdout, flags.Format, roles, entries, addresses)
+}
+
+func (c *CmdTarmak) firewallMatrix() ([]firewall.Entry, error) {
+	rules, err := firewall.ClusterRules(c.Cluster().Config().InstancePools)
+	if err != nil {
+		return nil, fmt.Errorf("invalid firewall configuration: %s", err)
+	}
+	return firewall.Matrix(rules), nil
+}
+
+func listFirewallRules(out io.Writer, format string, entries []firewall.Entry) error {
+	list := &tarmakv1alpha1.FirewallRuleList{}
+	list.APIVersion = tarmakv1alpha1.SchemeGroupVersion.String()
+	list.Kind = "FirewallRuleList"
+
+	varMaps := make([]map[string]string, 0)
+	for pos := range entries {
+		entry := &entries[pos]
+		varMaps = append(varMaps, entry.Parameters())
+		list.Items = append(list.Items, tarmakv1alpha1.FirewallRule{
+			Role:      entry.Role,
+			Direction: entry.Direction,
+			Peer:      entry.Peer,
+			Service:   entry.Service,
+			Protocol:  entry.ProtocolName(),
+			Ports:     entry.Ports(),
+			Comment:   entry.Comment,
+		})
+	}
+
+	return utils.List(out, format, list, []string{"role", "direction", "peer", "service", "protocol", "ports"}, []string{"comment"}, varMaps)
+}
+
+// firewallRoles returns the roles whose instances have firewall rules
+func firewallRoles(entries []firewall.Entry) []string {
+	var roles []string
+	for _, entry := range entries {
+		if !hasRole(roles, entry.Role) {
+			roles = append(roles, entry.Role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// firewallAddresses returns the IP addresses of the hosts by role, combined
+// etcd and master hosts belong to both roles
+func firewallAddresses(hosts []interfaces.Host, roles []string) (map[string][]string, error) {
+	addresses := map[string][]string{}
+
+	for _, host := range hosts {
+		var hostRoles []string
+		for _, role := range roles {
+			if hasRole(host.Roles(), role) {
+				hostRoles = append(hostRoles, role)
+			}
+		}
+		if hasRole(host.Roles(), clusterv1alpha1.InstancePoolTypeMasterEtcd) || hasRole(host.Roles(), "etcd-master") {
+			hostRoles = append(hostRoles, clusterv1alpha1.InstancePoolTypeEtcd, clusterv1alpha1.InstancePoolTypeMaster)
+		}
+		if len(hostRoles) == 0 {
+			continue
+		}
+
+		address, err := hostAddress(host.Hostname())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve address of instance %s: %s", host.ID(), err)
+		}
+		for _, role := range hostRoles {
+			addresses[role] = append(addresses[role], address)
+		}
+	}
+
+	return addresses, nil
+}
+
+// hostAddress returns the IPv4 address of a hostname
+func hostAddress(hostname string) (string, error) {
+	if ip := net.ParseIP(hostname); ip != nil && ip.To4() != nil {
+		return ip.String(), nil
+	}
+
+	ips, err := net.LookupIP(hostname)
+	if err != nil {
+		return "", err
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.String(), nil
+		}
+	}
+	return "", fmt.Errorf("hostname %s has no IPv4 address", hostname)
+}
@@ -0,0 +1,38 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+func TestFirewallAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hosts := []interfaces.Host{
+		fakeHost(ctrl, "i-1", "10.0.0.1", "master"),
+		fakeHost(ctrl, "i-2", "10.0.1.1", "worker"),
+		fakeHost(ctrl, "i-3", "10.0.1.2", "worker"),
+		fakeHost(ctrl, "i-4", "10.0.2.1", "etcd-master"),
+		fakeHost(ctrl, "i-5", "10.0.3.1", "jenkins"),
+	}
+
+	addresses, err := firewallAddresses(hosts, []string{"etcd", "master", "worker"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := map[string][]string{
+		"master": {"10.0.0.1", "10.0.2.1"},
+		"worker": {"10.0.1.1", "10.0.1.2"},
+		"etcd":   {"10.0.2.1"},
+	}
+	if !reflect.DeepEqual(addresses, exp) {
+		t.Errorf("unexpected addresses: act=%v exp=%v", addresses, exp)
+	}
+}
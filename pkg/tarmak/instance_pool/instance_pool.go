@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
@@ -32,7 +33,8 @@ type InstancePool struct {
 	volumes    []*Volume
 	rootVolume *Volume
 
-	instanceType string
+	instanceType      string
+	spotInstanceTypes []string
 
 	role *role.Role
 }
@@ -49,13 +51,29 @@ func NewFromConfig(cluster interfaces.Cluster, conf *clusterv1alpha1.InstancePoo
 		return nil, fmt.Errorf("role '%s' is not valid for this cluster", conf.Type)
 	}
 
-	// validate instance size with cloud provider
+	// validate instance size with cloud provider, an explicit instance type
+	// takes precedence over the size
 	provider := cluster.Environment().Provider()
-	instanceType, err := provider.InstanceType(conf.Size)
-	if err != nil {
-		return nil, fmt.Errorf("instanceType '%s' is not valid for this provier", conf.Size)
+	if conf.InstanceType != "" {
+		instancePool.instanceType = conf.InstanceType
+	} else {
+		instanceType, err := provider.InstanceType(conf.Size)
+		if err != nil {
+			return nil, fmt.Errorf("instanceType '%s' is not valid for this provier", conf.Size)
+		}
+		instancePool.instanceType = instanceType
+	}
+
+	// validate additional spot instance sizes with cloud provider
+	if conf.Spot != nil {
+		for _, size := range conf.Spot.Sizes {
+			instanceType, err := provider.InstanceType(size)
+			if err != nil {
+				return nil, fmt.Errorf("spot instanceType '%s' is not valid for this provider", size)
+			}
+			instancePool.spotInstanceTypes = append(instancePool.spotInstanceTypes, instanceType)
+		}
 	}
-	instancePool.instanceType = instanceType
 
 	// validate minCount <= maxCount or minCount == maxCount if role is stateful
 	// if only one of the two values are set, we should default to the other
@@ -169,6 +187,64 @@ func (n *InstancePool) SpotPrice() string {
 	return n.conf.SpotPrice
 }
 
+// Spot returns true if the instance pool launches spot instances using a
+// mixed instances policy
+func (n *InstancePool) Spot() bool {
+	return n.conf.Spot != nil
+}
+
+// SpotInstanceTypes returns the instance types spot instances can be launched
+// with, starting with the instance type of the pool
+func (n *InstancePool) SpotInstanceTypes() []string {
+	return utils.RemoveDuplicateStrings(append([]string{n.instanceType}, n.spotInstanceTypes...))
+}
+
+func (n *InstancePool) SpotOnDemandBaseCapacity() int {
+	if n.conf.Spot == nil {
+		return 0
+	}
+	return n.conf.Spot.OnDemandBaseCapacity
+}
+
+// SpotOnDemandPercentageAboveBaseCapacity returns the percentage of
+// instances above the base capacity launched on-demand
+func (n *InstancePool) SpotOnDemandPercentageAboveBaseCapacity() int {
+	if n.conf.Spot == nil || n.conf.Spot.SpotPercentage == nil {
+		return 0
+	}
+	return 100 - *n.conf.Spot.SpotPercentage
+}
+
+func (n *InstancePool) SpotAllocationStrategy() string {
+	if n.conf.Spot == nil || n.conf.Spot.AllocationStrategy == "" {
+		return clusterv1alpha1.SpotAllocationStrategyLowestPrice
+	}
+	return n.conf.Spot.AllocationStrategy
+}
+
+func (n *InstancePool) SpotInstancePools() int {
+	if n.conf.Spot == nil || n.conf.Spot.InstancePools == 0 {
+		return 2
+	}
+	return n.conf.Spot.InstancePools
+}
+
+func (n *InstancePool) SpotMaxPrice() string {
+	if n.conf.Spot == nil {
+		return ""
+	}
+	return n.conf.Spot.MaxPrice
+}
+
+// SpotInterruptionHandler returns true if nodes of the pool should be
+// drained on the termination notice of their spot instance
+func (n *InstancePool) SpotInterruptionHandler() bool {
+	if n.conf.Spot == nil {
+		return false
+	}
+	return n.conf.Spot.InterruptionHandler == nil || *n.conf.Spot.InterruptionHandler
+}
+
 func (n *InstancePool) AmazonAdditionalIAMPolicies() []string {
 	policies := []string{}
 
@@ -200,7 +276,63 @@ func (n *InstancePool) AmazonEBSEncrypted() bool {
 }
 
 func (n *InstancePool) Validate() (result error) {
-	return n.ValidateAllowCIDRs()
+	if err := n.ValidateAllowCIDRs(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if err := n.ValidateSpot(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	return result
+}
+
+func (n *InstancePool) ValidateSpot() (result error) {
+	spot := n.conf.Spot
+
+	if n.Role().Stateful && (spot != nil || n.conf.SpotPrice != "") {
+		return fmt.Errorf("spot instances are not supported for instance pool %s of the stateful role %s", n.Name(), n.Role().Name())
+	}
+
+	if spot == nil {
+		return nil
+	}
+
+	if n.conf.SpotPrice != "" {
+		result = multierror.Append(result, errors.New("spotPrice and spot can not be used together, use spot.maxPrice instead"))
+	}
+
+	if spot.OnDemandBaseCapacity < 0 {
+		result = multierror.Append(result, fmt.Errorf("spot.onDemandBaseCapacity must not be negative, got: %d", spot.OnDemandBaseCapacity))
+	}
+
+	if p := spot.SpotPercentage; p != nil && (*p < 0 || *p > 100) {
+		result = multierror.Append(result, fmt.Errorf("spot.spotPercentage must be between 0 and 100, got: %d", *p))
+	}
+
+	if spot.InstancePools < 0 {
+		result = multierror.Append(result, fmt.Errorf("spot.instancePools must not be negative, got: %d", spot.InstancePools))
+	}
+
+	if spot.MaxPrice != "" {
+		price, err := strconv.ParseFloat(spot.MaxPrice, 64)
+		if err != nil || price <= 0 {
+			result = multierror.Append(result, fmt.Errorf("spot.maxPrice must be a positive price, got: '%s'", spot.MaxPrice))
+		}
+	}
+
+	switch spot.AllocationStrategy {
+	case "", clusterv1alpha1.SpotAllocationStrategyLowestPrice:
+	case clusterv1alpha1.SpotAllocationStrategyCapacityOptimized:
+		// spot pools are chosen by available capacity, not by their price
+		if spot.InstancePools > 0 {
+			result = multierror.Append(result, fmt.Errorf("spot.instancePools can only be used with the %s allocation strategy", clusterv1alpha1.SpotAllocationStrategyLowestPrice))
+		}
+	default:
+		result = multierror.Append(result, fmt.Errorf("spot.allocationStrategy must be %s or %s, got: '%s'", clusterv1alpha1.SpotAllocationStrategyLowestPrice, clusterv1alpha1.SpotAllocationStrategyCapacityOptimized, spot.AllocationStrategy))
+	}
+
+	return result
 }
 
 func (n *InstancePool) ValidateAllowCIDRs() (result error) {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/hashicorp/go-multierror"
 	"github.com/sirupsen/logrus"
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
@@ -203,3 +204,138 @@ func (i *fakeInstancePool) test_MinMax(min, max int, statefull bool) (instancePo
 	i.fakeCluster.EXPECT().Role(gomock.Any()).Times(1).Return(role)
 	return NewFromConfig(i.fakeCluster, i.conf)
 }
+
+func TestInstancePool_ValidateSpot(t *testing.T) {
+	percentage := 75
+	validSpots := []*clusterv1alpha1.InstancePoolSpot{
+		nil,
+		&clusterv1alpha1.InstancePoolSpot{},
+		&clusterv1alpha1.InstancePoolSpot{
+			Sizes:                []string{"large", "xlarge"},
+			OnDemandBaseCapacity: 1,
+			SpotPercentage:       &percentage,
+			AllocationStrategy:   clusterv1alpha1.SpotAllocationStrategyLowestPrice,
+			InstancePools:        3,
+			MaxPrice:             "0.25",
+		},
+	}
+
+	for _, spot := range validSpots {
+		i := InstancePool{
+			conf: &clusterv1alpha1.InstancePool{Spot: spot},
+			role: &role.Role{},
+		}
+
+		if err := i.ValidateSpot(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	i := InstancePool{
+		conf: &clusterv1alpha1.InstancePool{SpotPrice: "0.5"},
+		role: &role.Role{},
+	}
+	if err := i.ValidateSpot(); err != nil {
+		t.Error(err)
+	}
+
+	tooHigh := 101
+	invalidSpots := []struct {
+		spot *clusterv1alpha1.InstancePoolSpot
+		err  string
+	}{
+		{&clusterv1alpha1.InstancePoolSpot{OnDemandBaseCapacity: -1}, "spot.onDemandBaseCapacity must not be negative, got: -1"},
+		{&clusterv1alpha1.InstancePoolSpot{SpotPercentage: &tooHigh}, "spot.spotPercentage must be between 0 and 100, got: 101"},
+		{&clusterv1alpha1.InstancePoolSpot{InstancePools: -1}, "spot.instancePools must not be negative, got: -1"},
+		{&clusterv1alpha1.InstancePoolSpot{MaxPrice: "cheap"}, "spot.maxPrice must be a positive price, got: 'cheap'"},
+		{&clusterv1alpha1.InstancePoolSpot{AllocationStrategy: clusterv1alpha1.SpotAllocationStrategyCapacityOptimized, InstancePools: 3}, "spot.instancePools can only be used with the lowest-price allocation strategy"},
+		{&clusterv1alpha1.InstancePoolSpot{AllocationStrategy: "random"}, "spot.allocationStrategy must be lowest-price or capacity-optimized, got: 'random'"},
+	}
+
+	for _, invalid := range invalidSpots {
+		i := InstancePool{
+			conf: &clusterv1alpha1.InstancePool{Spot: invalid.spot},
+			role: &role.Role{},
+		}
+
+		err := i.ValidateSpot()
+		if err == nil {
+			t.Errorf("expected %+v to cause a validation error", invalid.spot)
+			continue
+		}
+		if errs := multierror.Append(nil, err).Errors; len(errs) != 1 || errs[0].Error() != invalid.err {
+			t.Errorf("unexpected error for %+v: act=%s exp=%s", invalid.spot, err, invalid.err)
+		}
+	}
+
+	// spot instances are not supported by stateful roles
+	etcd := &role.Role{Stateful: true}
+	etcd.WithName("etcd")
+	for _, conf := range []*clusterv1alpha1.InstancePool{
+		&clusterv1alpha1.InstancePool{Spot: &clusterv1alpha1.InstancePoolSpot{}},
+		&clusterv1alpha1.InstancePool{SpotPrice: "0.5"},
+	} {
+		i := InstancePool{conf: conf, role: etcd}
+		err := i.ValidateSpot()
+		if exp := "spot instances are not supported for instance pool etcd of the stateful role etcd"; err == nil || err.Error() != exp {
+			t.Errorf("unexpected error: act=%v exp=%s", err, exp)
+		}
+	}
+
+	i = InstancePool{
+		conf: &clusterv1alpha1.InstancePool{SpotPrice: "0.5", Spot: &clusterv1alpha1.InstancePoolSpot{}},
+		role: &role.Role{},
+	}
+	err := i.ValidateSpot()
+	if exp := "spotPrice and spot can not be used together, use spot.maxPrice instead"; err == nil || multierror.Append(nil, err).Errors[0].Error() != exp {
+		t.Errorf("unexpected error: act=%v exp=%s", err, exp)
+	}
+}
+
+func TestInstancePool_SpotInstanceTypes(t *testing.T) {
+	percentage := 30
+	i := &InstancePool{
+		conf: &clusterv1alpha1.InstancePool{
+			Spot: &clusterv1alpha1.InstancePoolSpot{SpotPercentage: &percentage},
+		},
+		instanceType:      "m5.large",
+		spotInstanceTypes: []string{"m4.large", "m5.large", "m5a.large"},
+	}
+
+	act := i.SpotInstanceTypes()
+	exp := []string{"m5.large", "m4.large", "m5a.large"}
+	if len(act) != len(exp) {
+		t.Fatalf("unexpected instance types: act=%v exp=%v", act, exp)
+	}
+	for pos := range exp {
+		if act[pos] != exp[pos] {
+			t.Errorf("unexpected instance types: act=%v exp=%v", act, exp)
+		}
+	}
+
+	if act, exp := i.SpotOnDemandPercentageAboveBaseCapacity(), 70; act != exp {
+		t.Errorf("unexpected on-demand percentage: act=%d exp=%d", act, exp)
+	}
+	if !i.SpotInterruptionHandler() {
+		t.Error("expected the interruption handler to be enabled by default")
+	}
+}
+
+func TestInstancePool_InstanceType(t *testing.T) {
+	i := newFakeInstancePool(t)
+	defer i.ctrl.Finish()
+
+	i.conf.MinCount = 1
+	i.conf.Size = clusterv1alpha1.InstancePoolSizeLarge
+	i.conf.InstanceType = "r5.large"
+	i.fakeCluster.EXPECT().Role(gomock.Any()).Return(&role.Role{})
+
+	instancePool, err := NewFromConfig(i.fakeCluster, i.conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if act, exp := instancePool.InstanceType(), "r5.large"; act != exp {
+		t.Errorf("unexpected instance type: act=%s exp=%s", act, exp)
+	}
+}
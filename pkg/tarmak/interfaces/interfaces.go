@@ -10,10 +10,12 @@ import (
 	vault "github.com/hashicorp/vault/api"
 	"github.com/jetstack/vault-unsealer/pkg/kv"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/ssh"
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
 	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak/role"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
 	wingclient "github.com/jetstack/tarmak/pkg/wing/client/clientset/versioned"
@@ -48,10 +50,20 @@ type Cluster interface {
 	ListHosts() ([]Host, error)
 	// This enforces a reapply of the puppet.tar.gz on every instance in the cluster
 	ReapplyConfiguration() error
+	// This pins instances to a puppet.tar.gz by its hash until the next reapply
+	RollbackConfiguration(hash string, names []string) error
 	// This waits until all instances have congverged successfully
 	WaitForConvergance() error
 	// This upload the puppet.tar.gz to the cluster, warning there is some duplication as terraform is also uploading this puppet.tar.gz
 	UploadConfiguration() error
+	// This runs the current puppet.tar.gz in noop mode on every instance and returns the instances with their dry run reports
+	DryRunConfiguration() ([]*wingv1alpha1.Instance, error)
+	// This returns the hash of the current puppet.tar.gz as reported by wing
+	ConfigurationHash() (string, error)
+	// This changes the Kubernetes version of the cluster and persists it
+	SetKubernetesVersion(version string) error
+	// This returns the wing instances of the cluster
+	Instances() ([]*wingv1alpha1.Instance, error)
 	// Verify the cluster (these contain more expensive calls like AWS calls
 	Verify() error
 	// Validate the cluster (these contain less expensive local calls)
@@ -69,6 +81,7 @@ type Cluster interface {
 
 	// cluster uses encrypted EBS
 	AmazonEBSEncrypted() bool
+	WingEnvironment() map[string]string
 }
 
 type Environment interface {
@@ -86,6 +99,8 @@ type Environment interface {
 	Cluster(name string) (cluster Cluster, err error)
 	SSHPrivateKeyPath() string
 	SSHPrivateKey() (signer interface{})
+	ManifestSigningKeyPath() string
+	ManifestSigningKey() (ed25519.PrivateKey, error)
 	Log() *logrus.Entry
 	Parameters() map[string]string
 	Config() *tarmakv1alpha1.Environment
@@ -127,12 +142,20 @@ type Provider interface {
 	VaultKV() (kv.Service, error)
 	VaultKVWithParams(kmsKeyID, unsealKeyName string) (kv.Service, error)
 	ListHosts(Cluster) ([]Host, error)
+	// Terminate hosts, so they get replaced using the latest launch configuration
+	TerminateHosts([]Host) error
 	InstanceType(string) (string, error)
 	VolumeType(string) (string, error)
 	String() string
 	AskEnvironmentLocation(Initialize) (string, error)
 	AskInstancePoolZones(Initialize) (zones []string, err error)
 	UploadConfiguration(Cluster, io.ReadSeeker, string) error
+	UploadDryRunConfiguration(Cluster, io.ReadSeeker, string) (manifestURL string, err error)
+	// Store etcd snapshots next to the terraform state, keys are relative to
+	// the etcd backup location of the cluster
+	UploadEtcdSnapshot(cluster Cluster, key string, snapshot io.ReadSeeker) error
+	DownloadEtcdSnapshot(cluster Cluster, key string, w io.Writer) error
+	ListEtcdSnapshots(cluster Cluster) (keys []string, err error)
 	EnsureRemoteResources() error
 	LegacyPuppetTFName() string
 	// Remove provider
@@ -176,6 +199,7 @@ type Config interface {
 	Cluster(environment string, name string) (cluster *clusterv1alpha1.Cluster, err error)
 	Clusters(environment string) (clusters []*clusterv1alpha1.Cluster)
 	AppendCluster(cluster *clusterv1alpha1.Cluster) error
+	UpdateCluster(cluster *clusterv1alpha1.Cluster) error
 	UniqueClusterName(environment, name string) error
 	Provider(name string) (provider *tarmakv1alpha1.Provider, err error)
 	Providers() (providers []*tarmakv1alpha1.Provider)
@@ -244,6 +268,7 @@ type Host interface {
 	Hostname() string
 	User() string
 	Roles() []string
+	InstancePool() string
 	SSHConfig(strictChecking string) string
 	Parameters() map[string]string
 	SSHHostPublicKeys() ([]ssh.PublicKey, error)
@@ -263,6 +288,9 @@ type Vault interface {
 	RootToken() (string, error)
 	TunnelFromFQDNs(vaultInternalFQDNs []string, vaultCA string) (VaultTunnel, error)
 	VerifyInitFromFQDNs(instances []string, vaultCA, vaultKMSKeyID, vaultUnsealKeyName string) error
+	UnsealedFromFQDNs(instances []string, vaultCA string) (unsealed []string, err error)
+	RotateRootToken(instances []string, vaultCA string) error
+	Rekey(instances []string, vaultCA string) error
 }
 
 type InstancePool interface {
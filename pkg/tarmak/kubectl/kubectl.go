@@ -288,6 +288,32 @@ func (k *Kubectl) Kubeconfig(path string, publicAPIEndpoint bool) (string, error
 	return fmt.Sprintf("KUBECONFIG=%s", path), nil
 }
 
+// Clientset returns a Kubernetes client connected to the API of the current
+// cluster
+func (k *Kubectl) Clientset(publicAPIEndpoint bool) (kubernetes.Interface, error) {
+	if k.tarmak.Cluster().Type() == clusterv1alpha1.ClusterTypeHub {
+		return nil, fmt.Errorf(
+			"current cluster is of type %s so has no Kubernetes cluster: %s",
+			clusterv1alpha1.ClusterTypeHub, k.tarmak.Cluster().Name())
+	}
+
+	if err := k.ensureWorkingKubeconfig(k.ConfigPath(), publicAPIEndpoint); err != nil {
+		return nil, err
+	}
+
+	c, err := clientcmd.LoadFromFile(k.ConfigPath())
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig, err := clientcmd.NewDefaultClientConfig(*c, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(restConfig)
+}
+
 func (k *Kubectl) setupConfig(c *api.Config, publicAPIEndpoint bool) (*api.Config, *api.Cluster, error) {
 	if c == nil {
 		c = api.NewConfig()
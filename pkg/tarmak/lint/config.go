@@ -0,0 +1,142 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package lint
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/addons"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+// EnvironmentPath returns the path of an environment in the configuration
+func EnvironmentPath(name string) *field.Path {
+	return field.NewPath("environments").Key(name)
+}
+
+// ClusterPath returns the path of a cluster in the configuration
+func ClusterPath(conf *clusterv1alpha1.Cluster) *field.Path {
+	return field.NewPath("clusters").Key(fmt.Sprintf("%s-%s", conf.Environment, conf.Name))
+}
+
+// Environment validates the fields of an environment configuration
+func Environment(f *Findings, conf *tarmakv1alpha1.Environment) {
+	validateCIDRs(f, EnvironmentPath(conf.Name).Child("adminCIDRs"), conf.AdminCIDRs)
+}
+
+// Cluster validates the fields of a cluster configuration and warns about
+// deprecated fields in use
+func Cluster(f *Findings, conf *clusterv1alpha1.Cluster) {
+	fldPath := ClusterPath(conf)
+
+	if conf.Network != nil {
+		if _, _, err := net.ParseCIDR(conf.Network.CIDR); err != nil {
+			f.Error(fldPath.Child("network", "cidr"), fmt.Errorf("error parsing network: %s", err))
+		}
+	}
+
+	if conf.Kubernetes != nil {
+		if conf.Kubernetes.APIServer != nil {
+			validateCIDRs(f, fldPath.Child("kubernetes", "apiServer", "allowCIDRs"), conf.Kubernetes.APIServer.AllowCIDRs)
+		}
+		deprecatedAddons(f, fldPath.Child("kubernetes"), conf.Kubernetes)
+	}
+
+	for _, pool := range conf.InstancePools {
+		poolPath := fldPath.Child("instancePools").Key(pool.Name)
+		validateCIDRs(f, poolPath.Child("allowCIDRs"), pool.AllowCIDRs)
+		if pool.SpotPrice != "" {
+			f.Warning(poolPath.Child("spotPrice"), "spotPrice is deprecated, use spot.maxPrice instead")
+		}
+	}
+}
+
+// NetworkOverlap validates that the networks of the clusters of an
+// environment don't overlap. Clusters deployed into an existing VPC are
+// skipped.
+func NetworkOverlap(f *Findings, clusters []*clusterv1alpha1.Cluster) {
+	type network struct {
+		conf *clusterv1alpha1.Cluster
+		net  *net.IPNet
+	}
+
+	var networks []network
+	for _, conf := range clusters {
+		if conf.Network == nil {
+			continue
+		}
+		if _, ok := conf.Network.ObjectMeta.Annotations[clusterv1alpha1.ExistingVPCAnnotationKey]; ok {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(conf.Network.CIDR)
+		if err != nil {
+			continue
+		}
+		networks = append(networks, network{conf: conf, net: ipNet})
+	}
+
+	for i := range networks {
+		for j := i + 1; j < len(networks); j++ {
+			err := utils.NetworkOverlap([]*net.IPNet{networks[i].net, networks[j].net})
+			for _, e := range errorsOf(err) {
+				f.Error(
+					ClusterPath(networks[j].conf).Child("network", "cidr"),
+					fmt.Errorf("%s of cluster %s", e, ClusterPath(networks[i].conf).String()),
+				)
+			}
+		}
+	}
+}
+
+func validateCIDRs(f *Findings, fldPath *field.Path, cidrs []string) {
+	for i, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			f.Error(fldPath.Index(i), fmt.Errorf("%s is not a valid CIDR format", cidr))
+		}
+	}
+}
+
+// deprecatedAddons warns about the settings of addons which have been
+// replaced by the addons list, if they differ from their defaults
+func deprecatedAddons(f *Findings, fldPath *field.Path, k *clusterv1alpha1.ClusterKubernetes) {
+	defaults := &clusterv1alpha1.Cluster{}
+	clusterv1alpha1.SetObjectDefaults_Cluster(defaults)
+	d := defaults.Kubernetes
+
+	for _, setting := range []struct {
+		field string
+		addon string
+		value interface{}
+		def   interface{}
+	}{
+		{"clusterAutoscaler", addons.ClusterAutoscaler, k.ClusterAutoscaler, d.ClusterAutoscaler},
+		{"tiller", addons.Tiller, k.Tiller, d.Tiller},
+		{"dashboard", addons.Dashboard, k.Dashboard, d.Dashboard},
+		{"prometheus", addons.Prometheus, k.Prometheus, d.Prometheus},
+		{"grafana", addons.Grafana, k.Grafana, d.Grafana},
+		{"heapster", addons.Heapster, k.Heapster, d.Heapster},
+		{"influxDB", addons.InfluxDB, k.InfluxDB, d.InfluxDB},
+	} {
+		if reflect.ValueOf(setting.value).IsNil() || reflect.DeepEqual(setting.value, setting.def) {
+			continue
+		}
+
+		replaced := false
+		for _, addon := range k.Addons {
+			if addon.Name == setting.addon {
+				replaced = true
+			}
+		}
+
+		if replaced {
+			f.Warning(fldPath.Child(setting.field), "%s is deprecated and replaced by the addon %s in kubernetes.addons", setting.field, setting.addon)
+		} else {
+			f.Warning(fldPath.Child(setting.field), "%s is deprecated, use an entry named %s in kubernetes.addons instead", setting.field, setting.addon)
+		}
+	}
+}
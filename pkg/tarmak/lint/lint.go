@@ -0,0 +1,99 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+)
+
+// Findings collects the errors and warnings of the validation of the
+// configuration by the field they relate to
+type Findings struct {
+	items []tarmakv1alpha1.ValidationFinding
+}
+
+// Error adds every error of err to the field. Errors which have already been
+// reported for the field or a field within it are skipped, so validations of
+// single fields take precedence over validations of whole objects.
+func (f *Findings) Error(fldPath *field.Path, err error) {
+	for _, e := range errorsOf(err) {
+		f.add(tarmakv1alpha1.ValidationSeverityError, fldPath, e.Error())
+	}
+}
+
+// Warning adds a warning to the field
+func (f *Findings) Warning(fldPath *field.Path, format string, a ...interface{}) {
+	f.add(tarmakv1alpha1.ValidationSeverityWarning, fldPath, fmt.Sprintf(format, a...))
+}
+
+func (f *Findings) add(severity string, fldPath *field.Path, message string) {
+	fld := fldPath.String()
+	for _, item := range f.items {
+		if item.Severity == severity && item.Message == message && within(item.Field, fld) {
+			return
+		}
+	}
+
+	f.items = append(f.items, tarmakv1alpha1.ValidationFinding{
+		Severity: severity,
+		Field:    fld,
+		Message:  message,
+	})
+}
+
+// Items returns the findings in the order they have been reported
+func (f *Findings) Items() []tarmakv1alpha1.ValidationFinding {
+	return f.items
+}
+
+// Errors returns the number of errors
+func (f *Findings) Errors() int {
+	return f.count(tarmakv1alpha1.ValidationSeverityError)
+}
+
+// Warnings returns the number of warnings
+func (f *Findings) Warnings() int {
+	return f.count(tarmakv1alpha1.ValidationSeverityWarning)
+}
+
+func (f *Findings) count(severity string) (count int) {
+	for _, item := range f.items {
+		if item.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// within returns true if fld is parent or a field within parent
+func within(fld, parent string) bool {
+	if !strings.HasPrefix(fld, parent) {
+		return false
+	}
+	if len(fld) == len(parent) {
+		return true
+	}
+	next := fld[len(parent)]
+	return next == '.' || next == '['
+}
+
+// errorsOf flattens the errors aggregated by multierror
+func errorsOf(err error) (errs []error) {
+	if err == nil {
+		return nil
+	}
+
+	merr, ok := err.(*multierror.Error)
+	if !ok {
+		return []error{err}
+	}
+	for _, e := range merr.Errors {
+		errs = append(errs, errorsOf(e)...)
+	}
+	return errs
+}
@@ -0,0 +1,169 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package lint
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+)
+
+func newCluster(environment, name, cidr string) *clusterv1alpha1.Cluster {
+	c := &clusterv1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Environment: environment,
+		Network: &clusterv1alpha1.Network{
+			CIDR: cidr,
+		},
+	}
+	clusterv1alpha1.SetObjectDefaults_Cluster(c)
+	return c
+}
+
+func fields(f *Findings, severity string) (result []string) {
+	for _, item := range f.Items() {
+		if item.Severity == severity {
+			result = append(result, item.Field)
+		}
+	}
+	return result
+}
+
+func TestFindings_Error(t *testing.T) {
+	f := &Findings{}
+
+	var err error
+	err = multierror.Append(err, errors.New("first"))
+	err = multierror.Append(err, multierror.Append(errors.New("second"), errors.New("third")))
+
+	fldPath := field.NewPath("clusters").Key("dev-cluster").Child("network", "cidr")
+	f.Error(fldPath, err)
+	if act, exp := f.Errors(), 3; act != exp {
+		t.Errorf("unexpected number of errors: act=%d exp=%d", act, exp)
+	}
+	if act, exp := f.Items()[0].Field, "clusters[dev-cluster].network.cidr"; act != exp {
+		t.Errorf("unexpected field: act=%s exp=%s", act, exp)
+	}
+
+	// the same error reported for the whole cluster is skipped
+	f.Error(field.NewPath("clusters").Key("dev-cluster"), errors.New("second"))
+	// the same error reported for a different cluster is not skipped
+	f.Error(field.NewPath("clusters").Key("dev-cluster2"), errors.New("second"))
+	// a warning with the same message is not skipped
+	f.Warning(fldPath, "third")
+
+	if act, exp := f.Errors(), 4; act != exp {
+		t.Errorf("unexpected number of errors: act=%d exp=%d", act, exp)
+	}
+	if act, exp := f.Warnings(), 1; act != exp {
+		t.Errorf("unexpected number of warnings: act=%d exp=%d", act, exp)
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	f := &Findings{}
+	Environment(f, &tarmakv1alpha1.Environment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "dev",
+		},
+		AdminCIDRs: []string{"0.0.0.0/0", "1.2.3.4", "10.0.0.0/8"},
+	})
+
+	if act, exp := fields(f, tarmakv1alpha1.ValidationSeverityError), []string{"environments[dev].adminCIDRs[1]"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected errors: act=%v exp=%v", act, exp)
+	}
+}
+
+func TestCluster(t *testing.T) {
+	c := newCluster("dev", "cluster", "10.99.0.0/33")
+	c.Kubernetes.APIServer = &clusterv1alpha1.ClusterKubernetesAPIServer{
+		AllowCIDRs: []string{"1.2.3.4/32", "not-a-cidr"},
+	}
+	c.InstancePools = []clusterv1alpha1.InstancePool{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "worker",
+			},
+			AllowCIDRs: []string{"10.0.0.0/88"},
+			SpotPrice:  "0.1",
+		},
+	}
+
+	f := &Findings{}
+	Cluster(f, c)
+
+	if act, exp := fields(f, tarmakv1alpha1.ValidationSeverityError), []string{
+		"clusters[dev-cluster].network.cidr",
+		"clusters[dev-cluster].kubernetes.apiServer.allowCIDRs[1]",
+		"clusters[dev-cluster].instancePools[worker].allowCIDRs[0]",
+	}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected errors: act=%v exp=%v", act, exp)
+	}
+	if act, exp := fields(f, tarmakv1alpha1.ValidationSeverityWarning), []string{
+		"clusters[dev-cluster].instancePools[worker].spotPrice",
+	}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected warnings: act=%v exp=%v", act, exp)
+	}
+}
+
+func TestCluster_deprecatedAddons(t *testing.T) {
+	c := newCluster("dev", "cluster", "10.99.0.0/16")
+
+	f := &Findings{}
+	Cluster(f, c)
+	if act, exp := f.Warnings(), 0; act != exp {
+		t.Errorf("unexpected warnings for defaults: act=%v exp=%v", f.Items(), exp)
+	}
+
+	c.Kubernetes.Tiller.Enabled = true
+	c.Kubernetes.Heapster.Enabled = false
+	c.Kubernetes.Addons = []clusterv1alpha1.ClusterKubernetesAddon{
+		{Name: "heapster"},
+	}
+
+	f = &Findings{}
+	Cluster(f, c)
+	if act, exp := fields(f, tarmakv1alpha1.ValidationSeverityWarning), []string{
+		"clusters[dev-cluster].kubernetes.tiller",
+		"clusters[dev-cluster].kubernetes.heapster",
+	}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected warnings: act=%v exp=%v", act, exp)
+	}
+	if act, exp := f.Items()[1].Message, "heapster is deprecated and replaced by the addon heapster in kubernetes.addons"; act != exp {
+		t.Errorf("unexpected message: act=%s exp=%s", act, exp)
+	}
+}
+
+func TestNetworkOverlap(t *testing.T) {
+	existing := newCluster("dev", "existing", "10.99.0.0/16")
+	existing.Network.ObjectMeta.Annotations = map[string]string{
+		clusterv1alpha1.ExistingVPCAnnotationKey: "vpc-1234",
+	}
+
+	f := &Findings{}
+	NetworkOverlap(f, []*clusterv1alpha1.Cluster{
+		newCluster("dev", "hub", "10.99.0.0/16"),
+		newCluster("dev", "cluster1", "10.98.0.0/16"),
+		newCluster("dev", "cluster2", "10.99.128.0/20"),
+		newCluster("dev", "invalid", "10.99.0.0"),
+		existing,
+	})
+
+	if act, exp := f.Items(), []tarmakv1alpha1.ValidationFinding{
+		{
+			Severity: tarmakv1alpha1.ValidationSeverityError,
+			Field:    "clusters[dev-cluster2].network.cidr",
+			Message:  "network '10.99.0.0/16' overlaps with '10.99.128.0/20' of cluster clusters[dev-hub]",
+		},
+	}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected findings: act=%+v exp=%+v", act, exp)
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package logs
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cenkalti/backoff"
+
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+const (
+	// entries are held back this long, so entries of slower hosts can be
+	// sorted in
+	followMergeDelay    = time.Second
+	followFlushInterval = 250 * time.Millisecond
+
+	// entries shown per host before following, if no --since is given
+	followLines = "10"
+)
+
+type followEntry struct {
+	host     string
+	entry    *SystemdEntry
+	received time.Time
+}
+
+// entryHeap orders entries by their timestamp
+type entryHeap []*followEntry
+
+func (h entryHeap) Len() int { return len(h) }
+func (h entryHeap) Less(i, j int) bool {
+	return h[i].entry.RealtimeTimestamp < h[j].entry.RealtimeTimestamp
+}
+func (h entryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *entryHeap) Push(x interface{}) { *h = append(*h, x.(*followEntry)) }
+func (h *entryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// Follow streams the journal of all hosts in the target groups until
+// interrupted. Entries are merged by their timestamp and prefixed with the
+// host they are from. Lost connections get re-established and continue
+// after the last entry received.
+func (l *Logs) Follow(groups []string, flags tarmakv1alpha1.ClusterLogsFlags) error {
+	groups = utils.RemoveDuplicateStrings(groups)
+
+	if flags.Until != utils.DefaultLogsUntilPlaceholder {
+		return fmt.Errorf("--until can't be used together with --follow")
+	}
+
+	if err := l.initialise(groups, flags); err != nil {
+		return fmt.Errorf("failed to initialise logs: %v", err)
+	}
+
+	// only write a bundle if asked for
+	bundle := flags.Path != utils.DefaultLogsPathPlaceholder
+
+	err := l.ssh.WriteConfig(l.tarmak.Cluster())
+	if err != nil {
+		return err
+	}
+
+	aliases, err := l.hostAliases()
+	if err != nil {
+		return err
+	}
+
+	if len(aliases) == 0 {
+		return fmt.Errorf("no host aliases found in targets '%s'", groups)
+	}
+
+	if bundle {
+		dir, err := ioutil.TempDir("", filepath.Base(l.path))
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		l.tmpDir = dir
+
+		for _, a := range aliases {
+			if err := os.Mkdir(filepath.Join(l.tmpDir, a), os.FileMode(0755)); err != nil {
+				return err
+			}
+		}
+	}
+
+	cmd := append(l.journalctlCmd(), "--follow")
+	if flags.Since != utils.DefaultLogsSincePlaceholder {
+		cmd = append(cmd, "--since", l.since)
+	} else {
+		cmd = append(cmd, "--lines", followLines)
+	}
+
+	l.log.Infof("following logs of hosts %s", aliases)
+
+	entries := make(chan *followEntry)
+	merged := make(chan error)
+	go func() {
+		merged <- l.mergeEntries(entries, aliases, bundle)
+	}()
+
+	for _, a := range aliases {
+		l.wg.Add(1)
+		go l.followHost(a, cmd, entries)
+	}
+
+	l.wg.Wait()
+	close(entries)
+
+	if err := <-merged; err != nil {
+		return err
+	}
+
+	if !bundle {
+		return nil
+	}
+
+	for _, f := range l.tmpFiles {
+		f.Close()
+	}
+
+	return l.bundleLogs()
+}
+
+// followHost keeps a journalctl --follow running on the host until
+// interrupted
+func (l *Logs) followHost(host string, cmd []string, entries chan<- *followEntry) {
+	defer l.wg.Done()
+
+	var cursor string
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxInterval = 30 * time.Second
+	expBackoff.MaxElapsedTime = 0
+
+	for {
+		hostCmd := cmd
+		if cursor != "" {
+			// don't repeat or skip entries after reconnecting
+			hostCmd = append(append([]string{}, l.journalctlCmd()...),
+				"--follow", "--after-cursor", fmt.Sprintf("'%s'", cursor))
+		}
+
+		received, err := l.followHostOnce(host, hostCmd, func(entry *SystemdEntry) {
+			cursor = entry.Cursor
+			entries <- &followEntry{
+				host:     host,
+				entry:    entry,
+				received: time.Now(),
+			}
+		})
+
+		select {
+		case <-l.ctx.Done():
+			return
+		default:
+		}
+
+		if received {
+			expBackoff.Reset()
+		}
+
+		wait := expBackoff.NextBackOff()
+		if err == nil {
+			err = fmt.Errorf("connection closed")
+		}
+		l.log.Warnf("following logs of host %s failed, reconnecting in %s: %v", host, wait, err)
+
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (l *Logs) followHostOnce(host string, cmd []string, handle func(*SystemdEntry)) (received bool, err error) {
+	reader, writer := io.Pipe()
+
+	decoded := make(chan error, 1)
+	go func() {
+		dec := json.NewDecoder(reader)
+		for {
+			entry := new(SystemdEntry)
+			if err := dec.Decode(entry); err == io.EOF {
+				decoded <- nil
+				return
+			} else if err != nil {
+				// the stream can't be recovered, keep draining it so the ssh
+				// session doesn't block
+				l.log.Warnf("error decoding journal entry of host %s, skipping remaining entries: %s", host, err)
+				io.Copy(ioutil.Discard, reader)
+				decoded <- fmt.Errorf("error decoding journal entry: %s", err)
+				return
+			}
+
+			received = true
+			handle(entry)
+		}
+	}()
+
+	err = l.fetchCmdOutput(host, cmd, writer)
+	writer.Close()
+
+	if decodeErr := <-decoded; decodeErr != nil {
+		return received, decodeErr
+	}
+
+	return received, err
+}
+
+// mergeEntries writes entries ordered by their timestamp until entries gets
+// closed
+func (l *Logs) mergeEntries(entries <-chan *followEntry, hosts []string, bundle bool) error {
+	width := 0
+	for _, host := range hosts {
+		if len(host) > width {
+			width = len(host)
+		}
+	}
+
+	h := &entryHeap{}
+	var result error
+
+	flush := func(before time.Time) {
+		for h.Len() > 0 {
+			e := (*h)[0]
+			if !before.IsZero() && e.received.After(before) {
+				return
+			}
+			heap.Pop(h)
+
+			fmt.Fprintf(l.out, "%-*s | %s\n", width, e.host, formatEntry(e.entry))
+
+			if bundle && result == nil {
+				result = l.writeToFile(e.host, e.entry)
+			}
+		}
+	}
+
+	ticker := time.NewTicker(followFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-entries:
+			if !ok {
+				flush(time.Time{})
+				return result
+			}
+			heap.Push(h, e)
+
+		case now := <-ticker.C:
+			flush(now.Add(-followMergeDelay))
+		}
+	}
+}
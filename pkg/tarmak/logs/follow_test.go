@@ -0,0 +1,98 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package logs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogs_mergeEntries(t *testing.T) {
+	out := new(bytes.Buffer)
+	l := &Logs{out: out}
+
+	entries := make(chan *followEntry)
+	merged := make(chan error)
+	go func() {
+		merged <- l.mergeEntries(entries, []string{"etcd-1", "master-1"}, false)
+	}()
+
+	now := time.Now()
+	for _, e := range []struct {
+		host      string
+		timestamp int64
+		message   string
+	}{
+		{"etcd-1", 3000000, "third"},
+		{"master-1", 1000000, "first"},
+		{"etcd-1", 4000000, "fourth"},
+		{"master-1", 2000000, "second"},
+	} {
+		entries <- &followEntry{
+			host: e.host,
+			entry: &SystemdEntry{
+				RealtimeTimestamp: e.timestamp,
+				Hostname:          e.host,
+				SyslogIdentifier:  "kubelet",
+				Pid:               "1",
+				Message:           e.message,
+			},
+			received: now,
+		}
+	}
+	close(entries)
+
+	if err := <-merged; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var act []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		fields := strings.Fields(line)
+		act = append(act, fields[0]+" "+fields[len(fields)-1])
+	}
+
+	exp := []string{"master-1 first", "master-1 second", "etcd-1 third", "etcd-1 fourth"}
+	if !reflect.DeepEqual(exp, act) {
+		t.Errorf("unexpected order exp=%+v act=%+v", exp, act)
+	}
+
+	if !strings.HasPrefix(out.String(), "master-1 | ") || !strings.Contains(out.String(), "\netcd-1   | ") {
+		t.Errorf("unexpected host prefixes:\n%s", out.String())
+	}
+}
+
+func TestLogs_journalctlCmd(t *testing.T) {
+	l := &Logs{
+		units:    []string{"kubelet", "etcd-k8s-main"},
+		priority: "warning",
+	}
+
+	exp := []string{
+		"journalctl", "-o", "json", "--no-pager",
+		"--unit", "'kubelet'", "--unit", "'etcd-k8s-main'",
+		"--priority", "warning",
+	}
+	if act := l.journalctlCmd(); !reflect.DeepEqual(exp, act) {
+		t.Errorf("unexpected command exp=%+v act=%+v", exp, act)
+	}
+}
+
+func TestLogs_priorityRegexp(t *testing.T) {
+	for priority, valid := range map[string]bool{
+		"warning":       true,
+		"3":             true,
+		"err..warning":  true,
+		"0..4":          true,
+		"warn":          false,
+		"8":             false,
+		"err..":         false,
+		"err; rm -rf /": false,
+	} {
+		if act := priorityRegexp.MatchString(priority); act != valid {
+			t.Errorf("%s: unexpected validity exp=%t act=%t", priority, valid, act)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"time"
@@ -28,6 +29,14 @@ const (
 )
 
 var (
+	// journald priorities, from highest to lowest
+	priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+	priorityRegexp = regexp.MustCompile(fmt.Sprintf(
+		`^(%[1]s|[0-7])(\.\.(%[1]s|[0-7]))?$`,
+		strings.Join(priorities, "|"),
+	))
+
 	// target groups of one or more instance groups
 	TargetGroups = []string{
 		"bastion",
@@ -45,10 +54,13 @@ type Logs struct {
 	ctx    interfaces.CancellationContext
 	log    *logrus.Entry
 
-	path    string   // target tar ball path
-	since   string   // gather logs since datetime
-	until   string   // gather logs since datetime
-	targets []string // target instance groups
+	path     string   // target tar ball path
+	since    string   // gather logs since datetime
+	until    string   // gather logs since datetime
+	targets  []string // target instance groups
+	units    []string // only gather logs of these units
+	priority string   // only gather logs of this priority (range)
+	out      io.Writer
 
 	hosts    []interfaces.Host   //target hosts
 	tmpDir   string              // tmp logs dir
@@ -103,7 +115,7 @@ func (l *Logs) Aggregate(groups []string, flags tarmakv1alpha1.ClusterLogsFlags)
 	l.log.Infof("fetching logs from targets %s", groups)
 
 	if err := l.initialise(groups, flags); err != nil {
-		return fmt.Errorf("failed to set tar gz log bundle path: %v", err)
+		return fmt.Errorf("failed to initialise logs: %v", err)
 	}
 
 	err := l.ssh.WriteConfig(l.tarmak.Cluster())
@@ -143,8 +155,7 @@ func (l *Logs) Aggregate(groups []string, flags tarmakv1alpha1.ClusterLogsFlags)
 		l.log.Infof("fetching from host %s", host)
 		err := l.fetchCmdOutput(
 			host,
-			[]string{"journalctl", "-o", "json", "--no-pager",
-				"--since", l.since, "--until", l.until},
+			append(l.journalctlCmd(), "--since", l.since, "--until", l.until),
 			writer,
 		)
 		if err != nil {
@@ -289,17 +300,32 @@ func (l *Logs) writeToFile(host string, entry *SystemdEntry) error {
 
 	}
 
-	// expected journalctl formatting
+	_, err := fmt.Fprintf(f, "%s\n", formatEntry(entry))
+	return err
+}
+
+// expected journalctl formatting
+func formatEntry(entry *SystemdEntry) string {
 	t := time.Unix(entry.RealtimeTimestamp/1000000, 0)
-	_, err := f.Write([]byte(fmt.Sprintf("%s %s %s[%s]: %v\n",
+	return fmt.Sprintf("%s %s %s[%s]: %v",
 		t.Format(timeLayout),
 		entry.Hostname,
 		entry.SyslogIdentifier,
 		entry.Pid,
-		entry.Message),
-	))
+		entry.Message,
+	)
+}
 
-	return err
+// journalctl command with the unit and priority filters applied
+func (l *Logs) journalctlCmd() []string {
+	cmd := []string{"journalctl", "-o", "json", "--no-pager"}
+	for _, unit := range l.units {
+		cmd = append(cmd, "--unit", fmt.Sprintf("'%s'", unit))
+	}
+	if l.priority != "" {
+		cmd = append(cmd, "--priority", l.priority)
+	}
+	return cmd
 }
 
 func (l *Logs) bundleLogs() error {
@@ -348,6 +374,22 @@ func (l *Logs) initialise(groups []string, flags tarmakv1alpha1.ClusterLogsFlags
 
 	l.targets = utils.RemoveDuplicateStrings(l.targets)
 
+	for _, unit := range flags.Units {
+		if unit == "" || strings.ContainsAny(unit, "'\\") {
+			return fmt.Errorf("invalid unit name '%s'", unit)
+		}
+	}
+	l.units = utils.RemoveDuplicateStrings(flags.Units)
+
+	if flags.Priority != "" && !priorityRegexp.MatchString(flags.Priority) {
+		return fmt.Errorf("invalid priority '%s', expected one of %s or a range like err..warning", flags.Priority, strings.Join(priorities, ", "))
+	}
+	l.priority = flags.Priority
+
+	if l.out == nil {
+		l.out = os.Stdout
+	}
+
 	if flags.Path == utils.DefaultLogsPathPlaceholder {
 		wd, err := os.Getwd()
 		if err != nil {
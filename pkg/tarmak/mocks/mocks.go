@@ -5,3 +5,5 @@ package mocks
 
 //go:generate mockgen -package=mocks -source=../interfaces/interfaces.go -destination tarmak.go
 //go:generate mockgen -package=mocks -source=../provider/amazon/amazon.go -destination amazon.go
+//go:generate mockgen -package=mocks -source=../provider/google/google.go -destination google.go
+//go:generate mockgen -package=mocks -source=../provider/azure/azure.go -destination azure.go
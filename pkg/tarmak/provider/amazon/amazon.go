@@ -75,6 +75,7 @@ type EC2 interface {
 	DescribeRegions(input *ec2.DescribeRegionsInput) (*ec2.DescribeRegionsOutput, error)
 	DescribeReservedInstancesOfferings(input *ec2.DescribeReservedInstancesOfferingsInput) (*ec2.DescribeReservedInstancesOfferingsOutput, error)
 	DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error)
+	TerminateInstances(input *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error)
 }
 
 type DynamoDB interface {
@@ -629,23 +630,72 @@ func (a *Amazon) verifyInstanceTypes() error {
 		return err
 	}
 
-	for _, instance := range a.tarmak.Cluster().InstancePools() {
-		instanceType, err := a.InstanceType(instance.Config().Size)
-		if err != nil {
-			return err
+	verified := make(map[string]bool)
+	for _, instancePool := range a.tarmak.Cluster().InstancePools() {
+		instanceTypes := []string{instancePool.InstanceType()}
+		if spot := instancePool.Config().Spot; spot != nil {
+			for _, size := range spot.Sizes {
+				instanceType, err := a.InstanceType(size)
+				if err != nil {
+					return err
+				}
+				instanceTypes = append(instanceTypes, instanceType)
+			}
 		}
 
-		if err := a.verifyInstanceType(instanceType, svc); err != nil {
-			result = multierror.Append(result, err)
+		zones := instancePool.Zones()
+		if len(zones) == 0 {
+			zones = a.AvailabilityZones()
+		}
+
+		for _, instanceType := range instanceTypes {
+			key := fmt.Sprintf("%s/%v", instanceType, zones)
+			if verified[key] {
+				continue
+			}
+			verified[key] = true
+
+			if err := a.verifyInstanceType(instanceType, zones, svc); err != nil {
+				result = multierror.Append(result, err)
+			}
 		}
 	}
 
 	return result
 }
 
-func (a *Amazon) verifyInstanceType(instanceType string, svc EC2) error {
+// verifyInstanceType checks that the instance type is offered in every of
+// the availability zones, or in the region if no zones are given
+func (a *Amazon) verifyInstanceType(instanceType string, zones []string, svc EC2) error {
 	var result error
 
+	if len(zones) == 0 {
+		offered, err := a.instanceTypeOffered(instanceType, "", svc)
+		if err != nil {
+			return err
+		}
+		if !offered {
+			result = multierror.Append(result, fmt.Errorf("type %s is not available in the %s region", instanceType, a.Region()))
+		}
+		return result
+	}
+
+	for _, zone := range zones {
+		offered, err := a.instanceTypeOffered(instanceType, zone, svc)
+		if err != nil {
+			return err
+		}
+		if !offered {
+			result = multierror.Append(result, fmt.Errorf("type %s is not available in the availability zone %s of the %s region", instanceType, zone, a.Region()))
+		}
+	}
+
+	return result
+}
+
+// instanceTypeOffered returns true if the instance type is offered in the
+// availability zone, the whole region is checked for an empty zone
+func (a *Amazon) instanceTypeOffered(instanceType, zone string, svc EC2) (bool, error) {
 	//Request offering, filter by given instance type
 	request := &ec2.DescribeReservedInstancesOfferingsInput{
 		InstanceTenancy:    aws.String("default"),
@@ -655,6 +705,9 @@ func (a *Amazon) verifyInstanceType(instanceType string, svc EC2) error {
 		ProductDescription: aws.String("Linux/UNIX (Amazon VPC)"),
 		InstanceType:       aws.String(instanceType),
 	}
+	if zone != "" {
+		request.AvailabilityZone = aws.String(zone)
+	}
 
 	expBackoff := backoff.NewExponentialBackOff()
 	expBackoff.MaxElapsedTime = time.Second * 10
@@ -685,18 +738,22 @@ func (a *Amazon) verifyInstanceType(instanceType string, svc EC2) error {
 
 	err := backoff.Retry(describeInstanceOfferingFunc, b)
 	if err != nil {
-		return fmt.Errorf("error reaching aws to verify instance type %s: %v", instanceType, err)
-	}
-	if len(response.ReservedInstancesOfferings) < 1 {
-		result = multierror.Append(result, fmt.Errorf("type %s is not available in the %s region", instanceType, a.Region()))
+		return false, fmt.Errorf("error reaching aws to verify instance type %s: %v", instanceType, err)
 	}
 
-	return result
+	return len(response.ReservedInstancesOfferings) > 0, nil
 }
 
 // This methods converts and possibly validates a generic instance type to a
 // provider specifc
 func (a *Amazon) InstanceType(typeIn string) (typeOut string, err error) {
+	// sizes mapped to instance types in the provider config
+	if a.conf.Amazon != nil {
+		if typeOut, ok := a.conf.Amazon.InstanceTypes[typeIn]; ok {
+			return typeOut, nil
+		}
+	}
+
 	if typeIn == clusterv1alpha1.InstancePoolSizeTiny {
 		return "t2.nano", nil
 	}
@@ -8,11 +8,13 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/golang/mock/gomock"
 	"github.com/sirupsen/logrus"
 
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
 	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
 	"github.com/jetstack/tarmak/pkg/tarmak/mocks"
 )
 
@@ -204,7 +206,7 @@ func TestAmazon_verifyInstanceType(t *testing.T) {
 
 	a.fakeEC2.EXPECT().DescribeReservedInstancesOfferings(gomock.Any()).Return(responce, nil)
 
-	err = a.verifyInstanceType("atype", svc)
+	err = a.verifyInstanceType("atype", nil, svc)
 	if err != nil {
 		t.Errorf("unexpected err:%v", err)
 	}
@@ -232,7 +234,7 @@ func TestAmazon_verifyInstanceTypeNotAllZones(t *testing.T) {
 
 	a.fakeEC2.EXPECT().DescribeReservedInstancesOfferings(gomock.Any()).Return(responce, nil)
 
-	err = a.verifyInstanceType("atype", svc)
+	err = a.verifyInstanceType("atype", nil, svc)
 	if err != nil {
 		t.Errorf("unexpected err:%v", err)
 	}
@@ -257,8 +259,150 @@ func TestAmazon_verifyInstanceTypeZonesOne(t *testing.T) {
 
 	a.fakeEC2.EXPECT().DescribeReservedInstancesOfferings(gomock.Any()).Return(responce, nil)
 
-	err = a.verifyInstanceType("atype", svc)
+	err = a.verifyInstanceType("atype", nil, svc)
 	if err != nil {
 		t.Errorf("unexpected err:%v", err)
 	}
 }
+
+func TestAmazon_verifyInstanceTypeZones(t *testing.T) {
+	a := newFakeAmazon(t)
+	defer a.ctrl.Finish()
+
+	svc, err := a.EC2()
+	if err != nil {
+		t.Errorf("unexpected err:%v", err)
+	}
+
+	offered := &ec2.DescribeReservedInstancesOfferingsOutput{
+		ReservedInstancesOfferings: []*ec2.ReservedInstancesOffering{
+			&ec2.ReservedInstancesOffering{
+				AvailabilityZone: aws.String("test-east-1a"),
+			},
+		},
+	}
+	notOffered := &ec2.DescribeReservedInstancesOfferingsOutput{}
+
+	a.fakeEnvironment.EXPECT().Location().Return("test-east-1").AnyTimes()
+
+	gomock.InOrder(
+		a.fakeEC2.EXPECT().DescribeReservedInstancesOfferings(&zoneMatcher{"test-east-1a"}).Return(offered, nil),
+		a.fakeEC2.EXPECT().DescribeReservedInstancesOfferings(&zoneMatcher{"test-east-1b"}).Return(notOffered, nil),
+	)
+
+	err = a.verifyInstanceType("r5.large", []string{"test-east-1a", "test-east-1b"}, svc)
+	if err == nil {
+		t.Fatal("expected error, got none")
+	}
+	if !strings.Contains(err.Error(), "availability zone test-east-1b") {
+		t.Errorf("unexpected error message: %s", err)
+	}
+	if strings.Contains(err.Error(), "test-east-1a") {
+		t.Errorf("unexpected error for offered zone: %s", err)
+	}
+}
+
+type zoneMatcher struct {
+	zone string
+}
+
+func (m *zoneMatcher) Matches(x interface{}) bool {
+	input, ok := x.(*ec2.DescribeReservedInstancesOfferingsInput)
+	return ok && input.AvailabilityZone != nil && *input.AvailabilityZone == m.zone
+}
+
+func (m *zoneMatcher) String() string {
+	return "is in availability zone " + m.zone
+}
+
+func TestAmazon_InstanceType(t *testing.T) {
+	a := newFakeAmazon(t)
+	defer a.ctrl.Finish()
+
+	a.conf.Amazon.InstanceTypes = map[string]string{
+		clusterv1alpha1.InstancePoolSizeLarge: "m5.xlarge",
+		"memory":                              "r5.2xlarge",
+	}
+
+	for _, tc := range []struct {
+		size string
+		exp  string
+	}{
+		{clusterv1alpha1.InstancePoolSizeSmall, "t2.medium"},
+		{clusterv1alpha1.InstancePoolSizeLarge, "m5.xlarge"},
+		{"memory", "r5.2xlarge"},
+		{"c5.large", "c5.large"},
+	} {
+		act, err := a.InstanceType(tc.size)
+		if err != nil {
+			t.Errorf("unexpected err:%v", err)
+		}
+		if act != tc.exp {
+			t.Errorf("unexpected instance type for size %s: act=%s exp=%s", tc.size, act, tc.exp)
+		}
+	}
+}
+
+func TestAmazon_TerminateHosts(t *testing.T) {
+	a := newFakeAmazon(t)
+	defer a.ctrl.Finish()
+
+	a.fakeEC2.EXPECT().TerminateInstances(&ec2.TerminateInstancesInput{
+		InstanceIds: []*string{aws.String("i-1"), aws.String("i-2")},
+	}).Return(&ec2.TerminateInstancesOutput{}, nil)
+
+	if err := a.TerminateHosts([]interfaces.Host{&host{id: "i-1"}, &host{id: "i-2"}}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestAmazon_EtcdSnapshots(t *testing.T) {
+	a := newFakeAmazon(t)
+	defer a.ctrl.Finish()
+
+	fakeS3 := mocks.NewMockS3(a.ctrl)
+	a.Amazon.s3 = fakeS3
+	a.Amazon.remoteStateKMS = "arn:aws:kms:eu-west-1:1234:key/abcd"
+	a.fakeEnvironment.EXPECT().Location().AnyTimes().Return("eu-west-1")
+	a.fakeEnvironment.EXPECT().Name().AnyTimes().Return("env")
+	a.fakeCluster.EXPECT().Name().AnyTimes().Return("cluster")
+
+	snapshot := strings.NewReader("snapshot")
+	fakeS3.EXPECT().PutObject(&s3.PutObjectInput{
+		Bucket:               aws.String("eu-west-1-terraform-state"),
+		Key:                  aws.String("env/cluster/etcd-backups/20180701T120000Z/k8s-main.db"),
+		Body:                 snapshot,
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
+		SSEKMSKeyId:          aws.String("arn:aws:kms:eu-west-1:1234:key/abcd"),
+	}).Return(&s3.PutObjectOutput{}, nil)
+
+	if err := a.UploadEtcdSnapshot(a.fakeCluster, "20180701T120000Z/k8s-main.db", snapshot); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	gomock.InOrder(
+		fakeS3.EXPECT().ListObjects(&s3.ListObjectsInput{
+			Bucket: aws.String("eu-west-1-terraform-state"),
+			Prefix: aws.String("env/cluster/etcd-backups/"),
+		}).Return(&s3.ListObjectsOutput{
+			Contents:    []*s3.Object{{Key: aws.String("env/cluster/etcd-backups/20180701T120000Z/k8s-main.db")}},
+			IsTruncated: aws.Bool(true),
+		}, nil),
+		fakeS3.EXPECT().ListObjects(&s3.ListObjectsInput{
+			Bucket: aws.String("eu-west-1-terraform-state"),
+			Prefix: aws.String("env/cluster/etcd-backups/"),
+			Marker: aws.String("env/cluster/etcd-backups/20180701T120000Z/k8s-main.db"),
+		}).Return(&s3.ListObjectsOutput{
+			Contents:    []*s3.Object{{Key: aws.String("env/cluster/etcd-backups/20180701T120000Z/overlay.db")}},
+			IsTruncated: aws.Bool(false),
+		}, nil),
+	)
+
+	keys, err := a.ListEtcdSnapshots(a.fakeCluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := []string{"20180701T120000Z/k8s-main.db", "20180701T120000Z/overlay.db"}; !reflect.DeepEqual(keys, exp) {
+		t.Errorf("unexpected keys: act=%v exp=%v", keys, exp)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package amazon
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+// This uploads an etcd snapshot to the terraform state bucket, encrypted with
+// the state's KMS key
+func (a *Amazon) UploadEtcdSnapshot(cluster interfaces.Cluster, key string, snapshot io.ReadSeeker) error {
+	kmsKeyArn, err := a.remoteStateKMSArn()
+	if err != nil {
+		return err
+	}
+
+	svc, err := a.S3()
+	if err != nil {
+		return err
+	}
+
+	_, err = svc.PutObject(&s3.PutObjectInput{
+		Bucket:               aws.String(a.RemoteStateName()),
+		Key:                  aws.String(a.etcdSnapshotPrefix(cluster) + key),
+		Body:                 snapshot,
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
+		SSEKMSKeyId:          aws.String(kmsKeyArn),
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading etcd snapshot '%s': %s", key, err)
+	}
+
+	return nil
+}
+
+func (a *Amazon) DownloadEtcdSnapshot(cluster interfaces.Cluster, key string, w io.Writer) error {
+	svc, err := a.S3()
+	if err != nil {
+		return err
+	}
+
+	obj, err := svc.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(a.RemoteStateName()),
+		Key:    aws.String(a.etcdSnapshotPrefix(cluster) + key),
+	})
+	if err != nil {
+		return fmt.Errorf("error downloading etcd snapshot '%s': %s", key, err)
+	}
+	defer obj.Body.Close()
+
+	if _, err := io.Copy(w, obj.Body); err != nil {
+		return fmt.Errorf("error downloading etcd snapshot '%s': %s", key, err)
+	}
+
+	return nil
+}
+
+func (a *Amazon) ListEtcdSnapshots(cluster interfaces.Cluster) ([]string, error) {
+	svc, err := a.S3()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := a.etcdSnapshotPrefix(cluster)
+	input := &s3.ListObjectsInput{
+		Bucket: aws.String(a.RemoteStateName()),
+		Prefix: aws.String(prefix),
+	}
+
+	var keys []string
+	for {
+		result, err := svc.ListObjects(input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing etcd snapshots: %s", err)
+		}
+
+		for _, obj := range result.Contents {
+			keys = append(keys, strings.TrimPrefix(*obj.Key, prefix))
+		}
+
+		if result.IsTruncated == nil || !*result.IsTruncated || len(result.Contents) == 0 {
+			return keys, nil
+		}
+		input.Marker = result.Contents[len(result.Contents)-1].Key
+	}
+}
+
+func (a *Amazon) etcdSnapshotPrefix(cluster interfaces.Cluster) string {
+	return fmt.Sprintf("%s/%s/etcd-backups/", cluster.Environment().Name(), cluster.Name())
+}
+
+func (a *Amazon) remoteStateKMSArn() (string, error) {
+	if a.remoteStateKMS != "" {
+		return a.remoteStateKMS, nil
+	}
+
+	svc, err := a.KMS()
+	if err != nil {
+		return "", err
+	}
+
+	k, err := svc.DescribeKey(&kms.DescribeKeyInput{
+		KeyId: aws.String(a.RemoteStateKMSName()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error looking for terraform state kms alias '%s': %s", a.RemoteStateKMSName(), err)
+	}
+
+	a.remoteStateKMS = *k.KeyMetadata.Arn
+	return a.remoteStateKMS, nil
+}
@@ -3,7 +3,6 @@ package amazon
 
 import (
 	"fmt"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,6 +12,7 @@ import (
 	"golang.org/x/crypto/ssh"
 
 	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
 )
 
 const EOF = "==EOF"
@@ -24,6 +24,7 @@ type host struct {
 	hostname       string
 	aliases        []string
 	roles          []string
+	instancePool   string
 	user           string
 	tags           []*ec2.Tag
 
@@ -40,6 +41,10 @@ func (h *host) Roles() []string {
 	return h.roles
 }
 
+func (h *host) InstancePool() string {
+	return h.instancePool
+}
+
 func (h *host) Aliases() []string {
 	return h.aliases
 }
@@ -131,43 +136,8 @@ func (h *host) SSHHostPublicKeys() ([]ssh.PublicKey, error) {
 	return hostKeys, nil
 }
 
-// TODO: this is not too provider specific and should live somewhere else
 func (h *host) SSHConfig(strictChecking string) string {
-	config := fmt.Sprintf(`host %s
-    User %s
-    Hostname %s
-
-    # use custom host key file per cluster
-    UserKnownHostsFile %s
-    StrictHostKeyChecking %s
-
-    # enable connection multiplexing
-    ControlPath %s/ssh-control-%%r@%%h:%%p
-    ControlMaster auto
-    ControlPersist 10m
-
-    # keep connections alive
-    ServerAliveInterval 60
-    IdentitiesOnly yes
-    IdentityFile %s
-`,
-		strings.Join(append(h.Aliases(), h.ID()), " "),
-		h.User(),
-		h.Hostname(),
-		h.cluster.SSHHostKeysPath(),
-		strictChecking,
-		os.TempDir(),
-		h.cluster.Environment().SSHPrivateKeyPath(),
-	)
-
-	if !h.HostnamePublic() {
-		config += fmt.Sprintf(
-			"    ProxyCommand ssh -F %s -W %%h:%%p bastion\n",
-			h.cluster.SSHConfigPath(),
-		)
-	}
-	config += "\n"
-	return config
+	return utils.HostSSHConfig(h, h.HostnamePublic(), h.cluster, strictChecking)
 }
 
 func (a *Amazon) ListHosts(c interfaces.Cluster) ([]interfaces.Host, error) {
@@ -217,6 +187,9 @@ func (a *Amazon) ListHosts(c interfaces.Cluster) ([]interfaces.Host, error) {
 				if *tag.Key == "tarmak_role" {
 					host.roles = strings.Split(*tag.Value, ",")
 				}
+				if *tag.Key == "tarmak_instance_pool" {
+					host.instancePool = *tag.Value
+				}
 
 				// skip if instance is not from the hub or current cluster
 				if *tag.Key == "Name" {
@@ -268,3 +241,28 @@ func (a *Amazon) ListHosts(c interfaces.Cluster) ([]interfaces.Host, error) {
 
 	return hostsInterfaces, nil
 }
+
+// TerminateHosts terminates the EC2 instances of the given hosts, instances
+// of auto scaling groups get replaced by their group, others by the next
+// terraform apply
+func (a *Amazon) TerminateHosts(hosts []interfaces.Host) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	svc, err := a.EC2()
+	if err != nil {
+		return err
+	}
+
+	var instanceIDs []*string
+	for _, h := range hosts {
+		instanceIDs = append(instanceIDs, aws.String(h.ID()))
+	}
+
+	if _, err := svc.TerminateInstances(&ec2.TerminateInstancesInput{InstanceIds: instanceIDs}); err != nil {
+		return fmt.Errorf("error terminating instances: %s", err)
+	}
+
+	return nil
+}
@@ -39,9 +39,9 @@ func awsGroupID(role string) string {
 	}
 }
 
-func GenerateAWSRules(role *role.Role) (awsRules []*AWSSGRule, err error) {
+func GenerateAWSRules(role *role.Role, rules []*firewall.Rule) (awsRules []*AWSSGRule, err error) {
 	// Get all firewall rules where the role is mentioned in the destination
-	for _, rule := range firewall.Rules() {
+	for _, rule := range rules {
 		for _, destination := range rule.Destinations {
 			if destination.Role == role.Name() || (role.Name() == "master" && destination.Role == masterELB) {
 				awsRules = append(awsRules, generateFromRule(rule, role, &destination)...)
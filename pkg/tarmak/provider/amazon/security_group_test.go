@@ -0,0 +1,78 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package amazon
+
+import (
+	"testing"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/cluster/firewall"
+	"github.com/jetstack/tarmak/pkg/tarmak/role"
+)
+
+func TestGenerateAWSRules_userRules(t *testing.T) {
+	fw := &clusterv1alpha1.Firewall{
+		IngressRules: []*clusterv1alpha1.IngressRule{
+			{
+				IngressProtocol: "tcp",
+				IngressFromPort: "30000",
+				IngressToPort:   "32767",
+				IngressSource:   "10.0.0.0/8",
+			},
+			{
+				IngressProtocol: "tcp",
+				IngressFromPort: "8080",
+				IngressSource:   "master",
+			},
+		},
+	}
+	fw.IngressRules[0].Identifier = "nodeports"
+
+	master := clusterv1alpha1.InstancePool{Type: clusterv1alpha1.InstancePoolTypeMaster}
+	master.Name = "master"
+	worker := clusterv1alpha1.InstancePool{
+		Type:      clusterv1alpha1.InstancePoolTypeWorker,
+		Firewalls: []*clusterv1alpha1.Firewall{fw},
+	}
+	worker.Name = "worker"
+
+	rules, err := firewall.ClusterRules([]clusterv1alpha1.InstancePool{master, worker})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	workerRole := (&role.Role{}).WithName("worker").WithPrefix("kubernetes")
+	awsRules, err := GenerateAWSRules(workerRole, rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	found := map[string]*AWSSGRule{}
+	for _, rule := range awsRules {
+		found[rule.Service] = rule
+	}
+
+	nodePorts, ok := found["user_worker_nodeports"]
+	if !ok {
+		t.Fatal("expected a rule for the node ports")
+	}
+	if nodePorts.FromPort != 30000 || nodePorts.ToPort != 32767 || nodePorts.Protocol != "tcp" {
+		t.Errorf("unexpected ports of rule: %+v", nodePorts)
+	}
+	if nodePorts.CIDRBlock == nil || nodePorts.CIDRBlock.String() != "10.0.0.0/8" {
+		t.Errorf("unexpected CIDR of rule: %v", nodePorts.CIDRBlock)
+	}
+	if act, exp := nodePorts.SGID, "${aws_security_group.kubernetes_worker.id}"; act != exp {
+		t.Errorf("unexpected security group: act=%s exp=%s", act, exp)
+	}
+
+	fromMaster, ok := found["user_worker_ingress1"]
+	if !ok {
+		t.Fatal("expected a rule for the master instance pool")
+	}
+	if act, exp := fromMaster.SourceSGGroupID, "${aws_security_group.kubernetes_master.id}"; act != exp {
+		t.Errorf("unexpected source security group: act=%s exp=%s", act, exp)
+	}
+	if fromMaster.CIDRBlock != nil {
+		t.Errorf("unexpected CIDR of rule: %v", fromMaster.CIDRBlock)
+	}
+}
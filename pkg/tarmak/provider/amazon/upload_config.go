@@ -16,24 +16,12 @@ import (
 
 // This uploads the main configuration to the S3 bucket
 func (a *Amazon) UploadConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) error {
-	svcKMS, err := a.KMS()
+	kmsKeyArn, err := a.secretsKMSKeyArn()
 	if err != nil {
 		return err
 	}
 
-	k, err := svcKMS.DescribeKey(&kms.DescribeKeyInput{
-		KeyId: aws.String(a.SecretsKMSName()),
-	})
-	if err != nil {
-		return fmt.Errorf("error looking for tarmak secrets kms alias '%s': %s", a.SecretsKMSName(), err)
-	}
-
-	bucketName := fmt.Sprintf(
-		"%s%s-%s-secrets",
-		a.conf.Amazon.BucketPrefix,
-		cluster.Environment().Name(),
-		a.Region(),
-	)
+	bucketName := a.secretsBucketName(cluster)
 
 	svc, err := a.S3()
 	if err != nil {
@@ -46,7 +34,7 @@ func (a *Amazon) UploadConfiguration(cluster interfaces.Cluster, stateFile io.Re
 		Key:                  aws.String(manifestKey),
 		Body:                 stateFile,
 		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
-		SSEKMSKeyId:          aws.String(*k.KeyMetadata.Arn),
+		SSEKMSKeyId:          aws.String(kmsKeyArn),
 	})
 	if err != nil {
 		return err
@@ -64,7 +52,7 @@ func (a *Amazon) UploadConfiguration(cluster interfaces.Cluster, stateFile io.Re
 		Key:                  aws.String(manifestKey),
 		Body:                 stateFile,
 		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
-		SSEKMSKeyId:          aws.String(*k.KeyMetadata.Arn),
+		SSEKMSKeyId:          aws.String(kmsKeyArn),
 	})
 	if err != nil {
 		return err
@@ -81,3 +69,59 @@ func (a *Amazon) UploadConfiguration(cluster interfaces.Cluster, stateFile io.Re
 
 	return nil
 }
+
+// This uploads a configuration to the S3 bucket without making it the latest
+// one, so instances can run it in dry run mode. It returns the manifest URL
+// wing is able to download it from.
+func (a *Amazon) UploadDryRunConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) (string, error) {
+	kmsKeyArn, err := a.secretsKMSKeyArn()
+	if err != nil {
+		return "", err
+	}
+
+	bucketName := a.secretsBucketName(cluster)
+
+	svc, err := a.S3()
+	if err != nil {
+		return "", err
+	}
+
+	manifestKey := filepath.Join(cluster.ClusterName(), "puppet-manifests", fmt.Sprintf("%s-puppet.tar.gz", md5Hash))
+	_, err = svc.PutObject(&s3.PutObjectInput{
+		Bucket:               aws.String(bucketName),
+		Key:                  aws.String(manifestKey),
+		Body:                 stateFile,
+		ServerSideEncryption: aws.String(s3.ServerSideEncryptionAwsKms),
+		SSEKMSKeyId:          aws.String(kmsKeyArn),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("s3://%s/%s", bucketName, manifestKey), nil
+}
+
+func (a *Amazon) secretsKMSKeyArn() (string, error) {
+	svcKMS, err := a.KMS()
+	if err != nil {
+		return "", err
+	}
+
+	k, err := svcKMS.DescribeKey(&kms.DescribeKeyInput{
+		KeyId: aws.String(a.SecretsKMSName()),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error looking for tarmak secrets kms alias '%s': %s", a.SecretsKMSName(), err)
+	}
+
+	return *k.KeyMetadata.Arn, nil
+}
+
+func (a *Amazon) secretsBucketName(cluster interfaces.Cluster) string {
+	return fmt.Sprintf(
+		"%s%s-%s-secrets",
+		a.conf.Amazon.BucketPrefix,
+		cluster.Environment().Name(),
+		a.Region(),
+	)
+}
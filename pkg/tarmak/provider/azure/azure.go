@@ -0,0 +1,565 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/azure/arm"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+)
+
+var _ interfaces.Provider = &Azure{}
+
+type Azure struct {
+	conf *tarmakv1alpha1.Provider
+
+	tarmak interfaces.Tarmak
+
+	availabilityZones *[]string
+
+	arm   ARM
+	blobs map[string]Blob
+	log   *logrus.Entry
+}
+
+type ARM interface {
+	Locations() ([]string, error)
+	Zones(location string) ([]string, error)
+	VMSizeAvailable(location, size string) (bool, error)
+	VirtualMachines(tags map[string]string) ([]*arm.VirtualMachine, error)
+	ReimageScaleSetInstance(resourceID string) error
+	DeleteVirtualMachine(resourceID string) error
+	Images(tags map[string]string) ([]*arm.Image, error)
+	DNSZoneExists(resourceGroup, zone string) (bool, error)
+	EnsureResourceGroup(name, location string) error
+	StorageAccountExists(resourceGroup, name string) (bool, error)
+	CreateStorageAccount(resourceGroup, name, location string) error
+	DeleteStorageAccount(resourceGroup, name string) error
+	StorageAccountKey(resourceGroup, name string) (string, error)
+	SetSecret(vault, name, value string) error
+	GetSecret(vault, name string) (string, error)
+}
+
+// Blob accesses the blob service of a single storage account
+type Blob interface {
+	ContainerExists(container string) (bool, error)
+	CreateContainer(container string) error
+	PutBlob(container, name string, body io.Reader) error
+	GetBlob(container, name string) ([]byte, error)
+	DeleteBlob(container, name string) error
+	ListBlobs(container, prefix string) ([]string, error)
+	BreakLease(container, name string) error
+}
+
+func NewFromConfig(tarmak interfaces.Tarmak, conf *tarmakv1alpha1.Provider) (*Azure, error) {
+
+	a := &Azure{
+		conf:   conf,
+		log:    tarmak.Log().WithField("provider_name", conf.ObjectMeta.Name),
+		tarmak: tarmak,
+	}
+
+	return a, nil
+}
+
+func (a *Azure) Name() string {
+	return a.conf.Name
+}
+
+func (a *Azure) Cloud() string {
+	return clusterv1alpha1.CloudAzure
+}
+
+// this clears all cached state from the provider
+func (a *Azure) Reset() {
+	a.arm = nil
+	a.blobs = nil
+	a.availabilityZones = nil
+}
+
+// This parameters should include non sensitive information to identify a provider
+func (a *Azure) Parameters() map[string]string {
+	p := map[string]string{
+		"name":                   a.Name(),
+		"cloud":                  a.Cloud(),
+		"subscription_id":        a.conf.Azure.SubscriptionID,
+		"resource_group":         a.ResourceGroup(),
+		"public_zone":            a.conf.Azure.PublicZone,
+		"storage_account_prefix": a.conf.Azure.StorageAccountPrefix,
+	}
+	if a.conf.Azure.ClientID != "" {
+		p["client_id"] = a.conf.Azure.ClientID
+	}
+	return p
+}
+
+func (a *Azure) String() string {
+	return fmt.Sprintf("%s[%s]", a.Cloud(), a.Name())
+}
+
+func (a *Azure) ResourceGroup() string {
+	if a.conf.Azure.ResourceGroup == "" {
+		return fmt.Sprintf("tarmak-%s", a.Name())
+	}
+	return a.conf.Azure.ResourceGroup
+}
+
+func (a *Azure) ARM() (ARM, error) {
+	if a.arm == nil {
+		authorizer, err := arm.NewAuthorizer(a.conf.Azure.TenantID, a.conf.Azure.ClientID)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Azure authorizer: %s", err)
+		}
+		a.arm = arm.New(a.conf.Azure.SubscriptionID, authorizer)
+	}
+	return a.arm, nil
+}
+
+// Blob returns a blob service client for a storage account in the provider's
+// resource group
+func (a *Azure) Blob(account string) (Blob, error) {
+	if blob, ok := a.blobs[account]; ok {
+		return blob, nil
+	}
+
+	svc, err := a.ARM()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := svc.StorageAccountKey(a.ResourceGroup(), account)
+	if err != nil {
+		return nil, fmt.Errorf("error getting access key of storage account '%s': %s", account, err)
+	}
+
+	blob, err := newBlobClient(account, key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating blob client for storage account '%s': %s", account, err)
+	}
+
+	if a.blobs == nil {
+		a.blobs = map[string]Blob{}
+	}
+	a.blobs[account] = blob
+
+	return blob, nil
+}
+
+var invalidStorageAccountChars = regexp.MustCompile("[^a-z0-9]")
+
+// storageAccountName derives a storage account name from its parts. These
+// names are limited to 24 lower case alphanumeric characters and need to be
+// globally unique, so long names are shortened and a hash of all parts is
+// appended.
+func storageAccountName(parts ...string) string {
+	name := invalidStorageAccountChars.ReplaceAllString(strings.ToLower(strings.Join(parts, "")), "")
+	if len(name) <= 24 {
+		return name
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, "/")))
+	return fmt.Sprintf("%s%x", name[:16], hash[:4])
+}
+
+func (a *Azure) ListLocations() ([]string, error) {
+	svc, err := a.ARM()
+	if err != nil {
+		return []string{}, err
+	}
+
+	locations, err := svc.Locations()
+	if err != nil {
+		return []string{}, err
+	}
+
+	sort.Strings(locations)
+
+	return locations, nil
+}
+
+func (a *Azure) AskEnvironmentLocation(init interfaces.Initialize) (location string, err error) {
+	locations, err := a.ListLocations()
+	if err != nil {
+		return "", err
+	}
+
+	locationPos, err := init.Input().AskSelection(&input.AskSelection{
+		Query:   "In which location should this environment reside?",
+		Choices: locations,
+		Default: -1,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return locations[locationPos], nil
+}
+
+func (a *Azure) AskInstancePoolZones(init interfaces.Initialize) (zones []string, err error) {
+
+	zones, err = a.getZonesByLocation()
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to get availability zones: %v", err)
+	}
+
+	if len(zones) == 0 {
+		return []string{}, fmt.Errorf("no availability zones found for location '%s'", a.Region())
+	}
+
+	sChoices := make([]bool, len(zones))
+	sChoices[0] = true
+
+	multiSel := &input.AskMultipleSelection{
+		AskSelection: &input.AskSelection{
+			Query:   "Please select availability zones. Enter numbers to toggle selection.",
+			Choices: zones,
+			Default: 1,
+		},
+		SelectedChoices: sChoices,
+		MinSelected:     1,
+		MaxSelected:     len(zones),
+	}
+
+	return init.Input().AskMultipleSelection(multiSel)
+}
+
+func (a *Azure) Region() string {
+	// without environment selected, fall back to default location
+	if a.tarmak.Environment() == nil {
+		return "westeurope"
+	}
+	return a.tarmak.Environment().Location()
+}
+
+// This return the availability zones that are used for a cluster
+func (a *Azure) AvailabilityZones() (availabiltyZones []string) {
+	if a.availabilityZones != nil {
+		return *a.availabilityZones
+	}
+
+	subnets := a.tarmak.Cluster().Subnets()
+	zones := make(map[string]bool)
+
+	for _, subnet := range subnets {
+		zones[subnet.Zone] = true
+	}
+
+	a.availabilityZones = &availabiltyZones
+
+	for zone, _ := range zones {
+		availabiltyZones = append(availabiltyZones, zone)
+	}
+
+	sort.Strings(availabiltyZones)
+
+	return availabiltyZones
+}
+
+func (a *Azure) Variables() map[string]interface{} {
+	output := map[string]interface{}{}
+	output["subscription_id"] = a.conf.Azure.SubscriptionID
+	output["resource_group"] = a.ResourceGroup()
+	output["availability_zones"] = a.AvailabilityZones()
+	output["region"] = a.Region()
+
+	output["public_zone"] = a.conf.Azure.PublicZone
+	output["public_zone_resource_group"] = a.conf.Azure.PublicZoneResourceGroup
+
+	if a.tarmak.Environment() != nil {
+		output["secrets_storage_account"] = a.secretsStorageAccountName(a.tarmak.Environment().Name())
+		output["backups_storage_account"] = a.backupsStorageAccountName(a.tarmak.Environment().Name())
+		output["vault_key_vault_name"] = a.keyVaultName(a.tarmak.Environment().Name())
+
+		// Azure requires an SSH public key when creating linux VMs
+		signer, err := ssh.NewSignerFromKey(a.tarmak.Environment().SSHPrivateKey())
+		if err != nil {
+			a.log.Warnf("unable to generate public key from private key: %s", err)
+		} else {
+			output["ssh_public_key"] = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+		}
+	}
+
+	return output
+}
+
+// This will return necessary environment variables
+func (a *Azure) Environment() ([]string, error) {
+	env := []string{
+		fmt.Sprintf("ARM_SUBSCRIPTION_ID=%s", a.conf.Azure.SubscriptionID),
+	}
+
+	if a.conf.Azure.TenantID != "" {
+		env = append(env, fmt.Sprintf("ARM_TENANT_ID=%s", a.conf.Azure.TenantID))
+	}
+
+	if a.conf.Azure.ClientID != "" {
+		env = append(env, fmt.Sprintf("ARM_CLIENT_ID=%s", a.conf.Azure.ClientID))
+	}
+
+	return env, nil
+}
+
+func (a *Azure) Validate() error {
+	var result *multierror.Error
+
+	if a.conf.Azure.SubscriptionID == "" {
+		result = multierror.Append(result, fmt.Errorf("no azure subscription specified for provider '%s'", a.Name()))
+	}
+
+	if a.conf.Azure.ClientID != "" && a.conf.Azure.TenantID == "" {
+		result = multierror.Append(result, fmt.Errorf("client ID '%s' needs a tenant ID", a.conf.Azure.ClientID))
+	}
+
+	if a.conf.Azure.PublicZone != "" && a.conf.Azure.PublicZoneResourceGroup == "" {
+		result = multierror.Append(result, fmt.Errorf("public zone '%s' needs the resource group of its DNS zone", a.conf.Azure.PublicZone))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (a *Azure) Verify() error {
+	var result *multierror.Error
+
+	// If this fails we don't want to verify any of the other steps as they will have the same error
+	if err := a.verifyCredentials(); err != nil {
+		return err
+	}
+
+	// These checks only make sense with an environment given
+	if a.tarmak.Environment() != nil {
+		if err := a.verifyAvailabilityZones(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if err := a.verifyPublicZone(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	// if no cluster exists (i.e. tarmak init has not yet been run), skip this verification check
+	if a.tarmak.Cluster() != nil {
+		if err := a.verifyInstanceTypes(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (a *Azure) EnsureRemoteResources() error {
+	if a.tarmak.Environment() == nil {
+		return nil
+	}
+
+	return a.ensureRemoteStateStorageAccount()
+}
+
+func (a *Azure) Remove() error {
+	var result *multierror.Error
+
+	if err := a.deleteRemoteStateBlobs(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	empty, err := a.remoteStateContainerEmpty()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if empty {
+		if err := a.deleteRemoteStateStorageAccount(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// Check if Azure credentials are setup correctly, by querying the locations
+// of the subscription
+func (a *Azure) verifyCredentials() error {
+	svc, err := a.ARM()
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.Locations(); err != nil {
+		return fmt.Errorf("there was a problem with veryfing your Azure credentials: %s", err)
+	}
+
+	return nil
+}
+
+func (a *Azure) getZonesByLocation() ([]string, error) {
+	svc, err := a.ARM()
+	if err != nil {
+		return []string{}, err
+	}
+
+	zones, err := svc.Zones(a.Region())
+	if err != nil {
+		return []string{}, err
+	}
+
+	sort.Strings(zones)
+
+	return zones, nil
+}
+
+func (a *Azure) verifyAvailabilityZones() error {
+	var result error
+
+	zones, err := a.getZonesByLocation()
+	if err != nil {
+		return err
+	}
+
+	if len(zones) == 0 {
+		return fmt.Errorf(
+			"no availability zone found for location '%s'",
+			a.Region(),
+		)
+	}
+
+	availabilityZones := a.AvailabilityZones()
+
+	for _, zoneConfigured := range availabilityZones {
+		found := false
+		for _, zone := range zones {
+			if zone != "" && zone == zoneConfigured {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = multierror.Append(result, fmt.Errorf(
+				"specified invalid availability zone '%s' for location '%s'",
+				zoneConfigured,
+				a.Region(),
+			))
+		}
+	}
+	if result != nil {
+		return result
+	}
+
+	if len(availabilityZones) == 0 {
+		zone := zones[0]
+		a.log.Debugf("no availability zones specified selecting zone: %s", zone)
+		availabilityZones = []string{zone}
+		a.availabilityZones = &availabilityZones
+	}
+
+	return nil
+}
+
+func (a *Azure) verifyPublicZone() error {
+	if a.conf.Azure.PublicZone == "" || a.conf.Azure.PublicZoneResourceGroup == "" {
+		return nil
+	}
+
+	svc, err := a.ARM()
+	if err != nil {
+		return err
+	}
+
+	exists, err := svc.DNSZoneExists(a.conf.Azure.PublicZoneResourceGroup, a.conf.Azure.PublicZone)
+	if err != nil {
+		return fmt.Errorf("error looking up DNS zone '%s': %s", a.conf.Azure.PublicZone, err)
+	}
+
+	if !exists {
+		return fmt.Errorf("DNS zone '%s' not found in resource group '%s'", a.conf.Azure.PublicZone, a.conf.Azure.PublicZoneResourceGroup)
+	}
+
+	return nil
+}
+
+func (a *Azure) verifyInstanceTypes() error {
+	var result error
+
+	svc, err := a.ARM()
+	if err != nil {
+		return err
+	}
+
+	verified := make(map[string]bool)
+	for _, instancePool := range a.tarmak.Cluster().InstancePools() {
+		instanceTypes := []string{instancePool.InstanceType()}
+		if spot := instancePool.Config().Spot; spot != nil {
+			for _, size := range spot.Sizes {
+				instanceType, err := a.InstanceType(size)
+				if err != nil {
+					return err
+				}
+				instanceTypes = append(instanceTypes, instanceType)
+			}
+		}
+
+		for _, instanceType := range instanceTypes {
+			if verified[instanceType] {
+				continue
+			}
+			verified[instanceType] = true
+
+			available, err := svc.VMSizeAvailable(a.Region(), instanceType)
+			if err != nil {
+				return fmt.Errorf("error reaching azure to verify virtual machine size %s: %v", instanceType, err)
+			}
+			if !available {
+				result = multierror.Append(result, fmt.Errorf("size %s is not available in location %s", instanceType, a.Region()))
+			}
+		}
+	}
+
+	return result
+}
+
+// This methods converts and possibly validates a generic instance type to a
+// provider specifc
+func (a *Azure) InstanceType(typeIn string) (typeOut string, err error) {
+	if typeIn == clusterv1alpha1.InstancePoolSizeTiny {
+		return "Standard_B1ms", nil
+	}
+	if typeIn == clusterv1alpha1.InstancePoolSizeSmall {
+		return "Standard_D1_v2", nil
+	}
+	if typeIn == clusterv1alpha1.InstancePoolSizeMedium {
+		return "Standard_D2s_v3", nil
+	}
+	if typeIn == clusterv1alpha1.InstancePoolSizeLarge {
+		return "Standard_D4s_v3", nil
+	}
+
+	// TODO: Validate custom instance type here
+	return typeIn, nil
+}
+
+// This methods converts and possibly validates a generic volume type to a
+// provider specifc
+func (a *Azure) VolumeType(typeIn string) (typeOut string, err error) {
+	if typeIn == clusterv1alpha1.VolumeTypeHDD {
+		return "Standard_LRS", nil
+	}
+	if typeIn == clusterv1alpha1.VolumeTypeSSD {
+		return "Premium_LRS", nil
+	}
+	// TODO: Validate custom volume type here
+	return typeIn, nil
+}
+
+func (a *Azure) PublicZone() string {
+	return a.conf.Azure.PublicZone
+}
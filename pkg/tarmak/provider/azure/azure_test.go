@@ -0,0 +1,342 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jetstack/vault-unsealer/pkg/kv"
+	"github.com/sirupsen/logrus"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/azure/arm"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/mocks"
+)
+
+type fakeAzure struct {
+	*Azure
+	ctrl *gomock.Controller
+
+	fakeARM         *mocks.MockARM
+	fakeBlob        *mocks.MockBlob
+	fakeEnvironment *mocks.MockEnvironment
+	fakeCluster     *mocks.MockCluster
+	fakeTarmak      *mocks.MockTarmak
+}
+
+func newFakeAzure(t *testing.T) *fakeAzure {
+
+	f := &fakeAzure{
+		ctrl: gomock.NewController(t),
+		Azure: &Azure{
+			conf: &tarmakv1alpha1.Provider{
+				Azure: &tarmakv1alpha1.ProviderAzure{
+					SubscriptionID:       "my-subscription",
+					ResourceGroup:        "my-group",
+					StorageAccountPrefix: "prefix",
+				},
+			},
+			log: logrus.WithField("test", true),
+		},
+	}
+	f.fakeARM = mocks.NewMockARM(f.ctrl)
+	f.fakeBlob = mocks.NewMockBlob(f.ctrl)
+	f.fakeEnvironment = mocks.NewMockEnvironment(f.ctrl)
+	f.fakeCluster = mocks.NewMockCluster(f.ctrl)
+	f.fakeTarmak = mocks.NewMockTarmak(f.ctrl)
+	f.Azure.arm = f.fakeARM
+	f.Azure.tarmak = f.fakeTarmak
+	f.fakeTarmak.EXPECT().Cluster().AnyTimes().Return(f.fakeCluster)
+	f.fakeTarmak.EXPECT().Environment().AnyTimes().Return(f.fakeEnvironment)
+	f.fakeCluster.EXPECT().Environment().AnyTimes().Return(f.fakeEnvironment)
+	f.fakeEnvironment.EXPECT().Location().AnyTimes().Return("westeurope")
+	f.fakeEnvironment.EXPECT().Name().AnyTimes().Return("env")
+
+	return f
+}
+
+// withBlob makes the fake blob client available for a storage account
+func (f *fakeAzure) withBlob(account string) {
+	f.Azure.blobs = map[string]Blob{account: f.fakeBlob}
+}
+
+func TestAzure_storageAccountName(t *testing.T) {
+	for _, c := range []struct {
+		parts []string
+		out   string
+	}{
+		{[]string{"prefix", "westeurope", "state"}, "prefixwesteuropestate"},
+		{[]string{"Pre-fix", "env", "secrets"}, "prefixenvsecrets"},
+		{[]string{"prefix", "australiasoutheast", "state"}, "prefixaustralias"},
+	} {
+		out := storageAccountName(c.parts...)
+		if !strings.HasPrefix(out, c.out) {
+			t.Errorf("unexpected name for %v: act=%s exp=%s...", c.parts, out, c.out)
+		}
+		if len(out) > 24 {
+			t.Errorf("name too long for %v: %s", c.parts, out)
+		}
+	}
+
+	if storageAccountName("prefix", "australiasoutheast", "state") == storageAccountName("prefix", "australiasoutheast", "other") {
+		t.Error("expected shortened names to differ")
+	}
+}
+
+func TestAzure_verifyAvailabilityZonesNoneGiven(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	a.fakeCluster.EXPECT().Subnets().Return([]clusterv1alpha1.Subnet{}).MinTimes(1)
+	a.fakeARM.EXPECT().Zones("westeurope").Return([]string{"3", "1", "2"}, nil)
+
+	if err := a.verifyAvailabilityZones(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if act, exp := a.AvailabilityZones(), []string{"1"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected zones: act=%+v exp=%+v", act, exp)
+	}
+}
+
+func TestAzure_verifyAvailabilityZonesInvalid(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	a.fakeCluster.EXPECT().Subnets().Return([]clusterv1alpha1.Subnet{
+		clusterv1alpha1.Subnet{Zone: "1"},
+		clusterv1alpha1.Subnet{Zone: "4"},
+	}).MinTimes(1)
+	a.fakeARM.EXPECT().Zones("westeurope").Return([]string{"1", "2", "3"}, nil)
+
+	err := a.verifyAvailabilityZones()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "specified invalid availability zone '4' for location 'westeurope'") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestAzure_verifyInstanceTypes(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	instancePool := mocks.NewMockInstancePool(a.ctrl)
+	instancePool.EXPECT().InstanceType().Return("Standard_E8s_v3")
+	instancePool.EXPECT().Config().Return(&clusterv1alpha1.InstancePool{
+		Size: clusterv1alpha1.InstancePoolSizeSmall,
+		Spot: &clusterv1alpha1.InstancePoolSpot{
+			Sizes: []string{clusterv1alpha1.InstancePoolSizeMedium},
+		},
+	})
+	a.fakeCluster.EXPECT().InstancePools().Return([]interfaces.InstancePool{instancePool})
+
+	a.fakeARM.EXPECT().VMSizeAvailable("westeurope", "Standard_E8s_v3").Return(false, nil)
+	a.fakeARM.EXPECT().VMSizeAvailable("westeurope", "Standard_D2s_v3").Return(true, nil)
+
+	err := a.verifyInstanceTypes()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "size Standard_E8s_v3 is not available in location westeurope") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestAzure_ListHosts(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	a.fakeCluster.EXPECT().ClusterName().AnyTimes().Return("env-cluster")
+	a.fakeEnvironment.EXPECT().HubName().AnyTimes().Return("env-hub")
+
+	a.fakeARM.EXPECT().VirtualMachines(map[string]string{
+		tagEnvironment: "env",
+	}).Return([]*arm.VirtualMachine{
+		&arm.VirtualMachine{
+			Name:              "env-bastion",
+			ProvisioningState: "Succeeded",
+			PrivateIP:         "10.0.0.4",
+			PublicIP:          "1.2.3.4",
+			Tags:              map[string]string{tagCluster: "env-hub", tagRole: "bastion"},
+		},
+		&arm.VirtualMachine{
+			Name:              "env-cluster-worker_1",
+			ProvisioningState: "Succeeded",
+			PrivateIP:         "10.0.1.5",
+			Tags:              map[string]string{tagCluster: "env-cluster", tagRole: "worker"},
+		},
+		&arm.VirtualMachine{
+			Name:              "env-cluster-worker_0",
+			ProvisioningState: "Creating",
+			PrivateIP:         "10.0.1.4",
+			Tags:              map[string]string{tagCluster: "env-cluster", tagRole: "worker"},
+		},
+		&arm.VirtualMachine{
+			Name:              "env-other-worker_0",
+			ProvisioningState: "Succeeded",
+			PrivateIP:         "10.0.2.4",
+			Tags:              map[string]string{tagCluster: "env-other", tagRole: "worker"},
+		},
+		&arm.VirtualMachine{
+			Name:              "env-cluster-worker_2",
+			ProvisioningState: "Deleting",
+			PrivateIP:         "10.0.1.6",
+			Tags:              map[string]string{tagCluster: "env-cluster", tagRole: "worker"},
+		},
+		&arm.VirtualMachine{
+			Name:              "no-role",
+			ProvisioningState: "Succeeded",
+			PrivateIP:         "10.0.1.7",
+			Tags:              map[string]string{tagCluster: "env-cluster"},
+		},
+	}, nil)
+
+	hosts, err := a.ListHosts(a.fakeCluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	act := map[string][]string{}
+	for _, h := range hosts {
+		act[h.ID()] = append(h.Aliases(), h.Hostname())
+	}
+
+	exp := map[string][]string{
+		"env-bastion":          []string{"bastion", "1.2.3.4"},
+		"env-cluster-worker_0": []string{"worker-1", "10.0.1.4"},
+		"env-cluster-worker_1": []string{"worker-2", "10.0.1.5"},
+	}
+
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected hosts: act=%+v exp=%+v", act, exp)
+	}
+}
+
+func TestAzure_SSHHostPublicKeys(t *testing.T) {
+	keys := []string{
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGCz8DzzjxEct5iXateD6IvJLp8mGYeVg+58jhs1o5UX",
+		"ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQDuJi79JH8DrAYUwrupeeSZsGWVuc4rzikynT+gYxKgn0T/GzLqXuAtX6yFPReRW+473jr3gF5sTGqjwX1W8iek59kQFuoG6AWwm77GnzQCc0VzuGTLmecCkyReSAkKBWBLBEQiJfBzNz6JZ9eJafic9jUNiLkijzRBeFxGao7SmPLmmt+Hu/xn4AfwWO1CXoXVQGWmDmmZ6Ue7i5i8B48FnTxoiS0v5TaXXhe0pYLfiTEJceL0GzdTHQ9rT7HUAX4czAoHL0/8mHvA2V4UeIWBocT3Mo5Zgf7ZSTah1W6J5x2HlRUBAROyrmDis++WBI7Ff6Z8cMdbWkwyxjCrmSxx",
+	}
+
+	h := &host{
+		tags: arm.SSHHostKeyTags(keys),
+	}
+
+	if len(h.tags) != 3 {
+		t.Errorf("expected the rsa key to be split into two tags: %+v", h.tags)
+	}
+
+	hostKeys, err := h.SSHHostPublicKeys()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if act, exp := len(hostKeys), 2; act != exp {
+		t.Fatalf("unexpected number of host keys: act=%d exp=%d", act, exp)
+	}
+	if act, exp := hostKeys[1].Type(), "ssh-rsa"; act != exp {
+		t.Errorf("unexpected host key type: act=%s exp=%s", act, exp)
+	}
+}
+
+func TestAzure_UploadConfiguration(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	a.fakeCluster.EXPECT().ClusterName().AnyTimes().Return("env-cluster")
+	a.withBlob("prefixenvsecrets")
+
+	content := []byte("puppet-tar-gz")
+
+	expectContent := func(exp []byte) func(string, string, io.Reader) error {
+		return func(_, _ string, body io.Reader) error {
+			act, err := ioutil.ReadAll(body)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(act, exp) {
+				t.Errorf("unexpected content: act=%s exp=%s", act, exp)
+			}
+			return nil
+		}
+	}
+
+	gomock.InOrder(
+		a.fakeBlob.EXPECT().PutBlob(secretsContainer, "env-cluster/puppet.tar.gz", gomock.Any()).DoAndReturn(expectContent(content)),
+		a.fakeBlob.EXPECT().PutBlob(secretsContainer, "env-cluster/puppet-manifests/abcd-puppet.tar.gz", gomock.Any()).DoAndReturn(expectContent(content)),
+		a.fakeBlob.EXPECT().PutBlob(secretsContainer, "env-cluster/puppet-manifests/latest-puppet-hash", gomock.Any()).DoAndReturn(expectContent([]byte("abcd"))),
+	)
+
+	if err := a.UploadConfiguration(a.fakeCluster, bytes.NewReader(content), "abcd"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestAzure_RemoteState(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	state := a.RemoteState("env", "cluster", "kubernetes")
+	for _, exp := range []string{
+		`backend "azurerm"`,
+		`resource_group_name = "my-group"`,
+		`storage_account_name = "prefixwesteuropestate"`,
+		`container_name = "tfstate"`,
+		`key = "env/cluster.tfstate"`,
+	} {
+		if !strings.Contains(state, exp) {
+			t.Errorf("expected remote state to contain '%s': %s", exp, state)
+		}
+	}
+}
+
+func TestAzure_ensureRemoteStateStorageAccount(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	a.withBlob("prefixwesteuropestate")
+
+	gomock.InOrder(
+		a.fakeARM.EXPECT().EnsureResourceGroup("my-group", "westeurope").Return(nil),
+		a.fakeARM.EXPECT().StorageAccountExists("my-group", "prefixwesteuropestate").Return(false, nil),
+		a.fakeARM.EXPECT().CreateStorageAccount("my-group", "prefixwesteuropestate", "westeurope").Return(nil),
+		a.fakeBlob.EXPECT().CreateContainer("tfstate").Return(nil),
+	)
+
+	if err := a.ensureRemoteStateStorageAccount(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestAzure_VaultKV(t *testing.T) {
+	a := newFakeAzure(t)
+	defer a.ctrl.Finish()
+
+	svc, err := a.VaultKVWithParams("prefixenvvault", "vault-env-")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	a.fakeARM.EXPECT().SetSecret("prefixenvvault", "vault-env-unseal-key-0", base64.StdEncoding.EncodeToString([]byte("secret"))).Return(nil)
+	if err := svc.Set("unseal_key_0", []byte("secret")); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	a.fakeARM.EXPECT().GetSecret("prefixenvvault", "vault-env-root").Return("", &arm.APIError{StatusCode: 404})
+	_, err = svc.Get("root")
+	if _, ok := err.(*kv.NotFoundError); !ok {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
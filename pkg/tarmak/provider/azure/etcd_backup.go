@@ -0,0 +1,65 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+// This uploads an etcd snapshot to the terraform state storage account,
+// storage service encryption is enabled for all blobs. The snapshots share
+// the container with the state, so they keep the account from being removed.
+func (a *Azure) UploadEtcdSnapshot(cluster interfaces.Cluster, key string, snapshot io.ReadSeeker) error {
+	blob, err := a.Blob(a.RemoteStateName())
+	if err != nil {
+		return err
+	}
+
+	if err := blob.PutBlob(remoteStateContainer, a.etcdSnapshotPrefix(cluster)+key, snapshot); err != nil {
+		return fmt.Errorf("error uploading etcd snapshot '%s': %s", key, err)
+	}
+
+	return nil
+}
+
+func (a *Azure) DownloadEtcdSnapshot(cluster interfaces.Cluster, key string, w io.Writer) error {
+	blob, err := a.Blob(a.RemoteStateName())
+	if err != nil {
+		return err
+	}
+
+	data, err := blob.GetBlob(remoteStateContainer, a.etcdSnapshotPrefix(cluster)+key)
+	if err != nil {
+		return fmt.Errorf("error downloading etcd snapshot '%s': %s", key, err)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (a *Azure) ListEtcdSnapshots(cluster interfaces.Cluster) ([]string, error) {
+	blob, err := a.Blob(a.RemoteStateName())
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := a.etcdSnapshotPrefix(cluster)
+	names, err := blob.ListBlobs(remoteStateContainer, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing etcd snapshots: %s", err)
+	}
+
+	var keys []string
+	for _, name := range names {
+		keys = append(keys, strings.TrimPrefix(name, prefix))
+	}
+
+	return keys, nil
+}
+
+func (a *Azure) etcdSnapshotPrefix(cluster interfaces.Cluster) string {
+	return fmt.Sprintf("%s/%s/etcd-backups/", cluster.Environment().Name(), cluster.Name())
+}
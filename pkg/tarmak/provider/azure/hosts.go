@@ -0,0 +1,262 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/jetstack/tarmak/pkg/azure/arm"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+const (
+	tagEnvironment = "tarmak_environment"
+	tagCluster     = "tarmak_cluster"
+	tagRole        = "tarmak_role"
+	tagPool        = "tarmak_instance_pool"
+)
+
+type host struct {
+	id             string
+	resourceID     string
+	host           string
+	hostnamePublic bool
+	hostname       string
+	aliases        []string
+	roles          []string
+	instancePool   string
+	user           string
+	tags           map[string]string
+
+	cluster interfaces.Cluster
+}
+
+var _ interfaces.Host = &host{}
+
+func (h *host) ID() string {
+	return h.id
+}
+
+func (h *host) Roles() []string {
+	return h.roles
+}
+
+func (h *host) InstancePool() string {
+	return h.instancePool
+}
+
+func (h *host) Aliases() []string {
+	return h.aliases
+}
+
+func (h *host) Hostname() string {
+	return h.hostname
+}
+
+func (h *host) HostnamePublic() bool {
+	return h.hostnamePublic
+}
+
+func (h *host) User() string {
+	return h.user
+}
+
+func (h *host) Parameters() map[string]string {
+	return map[string]string{
+		"id":       h.ID(),
+		"hostname": h.Hostname(),
+		"roles":    strings.Join(h.Roles(), ", "),
+	}
+}
+
+// sshHostKeyTags joins the chunked ssh host key tags back together
+func sshHostKeyTags(tags map[string]string) []string {
+	chunks := map[int]map[int]string{}
+
+	for key, value := range tags {
+		if !strings.HasPrefix(key, arm.TagSSHHostKeyPrefix) {
+			continue
+		}
+
+		parts := strings.Split(strings.TrimPrefix(key, arm.TagSSHHostKeyPrefix), "_")
+		if len(parts) != 2 {
+			continue
+		}
+
+		keyIndex, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		chunkIndex, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+
+		if _, ok := chunks[keyIndex]; !ok {
+			chunks[keyIndex] = map[int]string{}
+		}
+		chunks[keyIndex][chunkIndex] = value
+	}
+
+	var keyIndexes []int
+	for keyIndex := range chunks {
+		keyIndexes = append(keyIndexes, keyIndex)
+	}
+	sort.Ints(keyIndexes)
+
+	var keys []string
+	for _, keyIndex := range keyIndexes {
+		var key string
+		for chunkIndex := 0; chunkIndex < len(chunks[keyIndex]); chunkIndex++ {
+			key += chunks[keyIndex][chunkIndex]
+		}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (h *host) SSHHostPublicKeys() ([]ssh.PublicKey, error) {
+	var hostKeys []ssh.PublicKey
+
+	for _, line := range sshHostKeyTags(h.tags) {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			h.cluster.Log().Warnf(
+				"failed to parse public keys from tags '%s*' of host '%s': %v",
+				arm.TagSSHHostKeyPrefix,
+				h.Aliases(),
+				err,
+			)
+			continue
+		}
+		hostKeys = append(hostKeys, hostKey)
+	}
+
+	return hostKeys, nil
+}
+
+func (h *host) SSHConfig(strictChecking string) string {
+	return utils.HostSSHConfig(h, h.HostnamePublic(), h.cluster, strictChecking)
+}
+
+func (a *Azure) ListHosts(c interfaces.Cluster) ([]interfaces.Host, error) {
+	svc, err := a.ARM()
+	if err != nil {
+		return []interfaces.Host{}, err
+	}
+
+	vms, err := svc.VirtualMachines(map[string]string{
+		tagEnvironment: c.Environment().Name(),
+	})
+	if err != nil {
+		return []interfaces.Host{}, err
+	}
+
+	hosts := []*host{}
+
+	for _, vm := range vms {
+		if vm.PrivateIP == "" || vm.Name == "" {
+			continue
+		}
+
+		if vm.ProvisioningState != "Succeeded" && vm.ProvisioningState != "Creating" && vm.ProvisioningState != "Updating" {
+			continue
+		}
+
+		// skip if instance is not from the hub or current cluster
+		if cluster := vm.Tags[tagCluster]; cluster != c.ClusterName() && cluster != c.Environment().HubName() {
+			continue
+		}
+
+		host := &host{
+			id:             vm.Name,
+			resourceID:     vm.ID,
+			hostname:       vm.PrivateIP,
+			hostnamePublic: false,
+			user:           "centos",
+			cluster:        a.tarmak.Cluster(),
+			instancePool:   vm.Tags[tagPool],
+			tags:           vm.Tags,
+		}
+		if vm.PublicIP != "" {
+			host.hostname = vm.PublicIP
+			host.hostnamePublic = true
+		}
+
+		if roles, ok := vm.Tags[tagRole]; ok && roles != "" {
+			host.roles = strings.Split(roles, ",")
+		}
+
+		// skip non-tarmak instances
+		if len(host.roles) == 0 {
+			continue
+		}
+
+		hosts = append(hosts, host)
+	}
+
+	// make sure aliases are stable across calls
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].id < hosts[j].id })
+
+	hostsByRole := map[string][]*host{}
+	for _, h := range hosts {
+		for _, role := range h.roles {
+			hostsByRole[role] = append(hostsByRole[role], h)
+			h.aliases = append(h.aliases, fmt.Sprintf("%s-%d", role, len(hostsByRole[role])))
+		}
+	}
+
+	// remove role-1 for single instances
+	for role, hosts := range hostsByRole {
+		if len(hosts) != 1 {
+			continue
+		}
+		for pos, _ := range hosts[0].aliases {
+			if hosts[0].aliases[pos] == fmt.Sprintf("%s-1", role) {
+				hosts[0].aliases[pos] = role
+			}
+		}
+	}
+
+	hostsInterfaces := make([]interfaces.Host, len(hosts))
+
+	for pos, _ := range hosts {
+		hostsInterfaces[pos] = hosts[pos]
+	}
+
+	return hostsInterfaces, nil
+}
+
+// TerminateHosts replaces the given hosts: scale set instances get reimaged
+// with the latest model of their scale set, virtual machines get deleted and
+// recreated by the next terraform apply
+func (a *Azure) TerminateHosts(hosts []interfaces.Host) error {
+	svc, err := a.ARM()
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hosts {
+		ah, ok := h.(*host)
+		if !ok {
+			return fmt.Errorf("host '%s' is not an azure host", h.ID())
+		}
+
+		if strings.Contains(strings.ToLower(ah.resourceID), "/virtualmachinescalesets/") {
+			err = svc.ReimageScaleSetInstance(ah.resourceID)
+		} else {
+			err = svc.DeleteVirtualMachine(ah.resourceID)
+		}
+		if err != nil {
+			return fmt.Errorf("error replacing virtual machine '%s': %s", ah.id, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/azure/arm"
+)
+
+// managed images have no creation time, so image builds are expected to
+// record it in this tag
+const tagCreationTimestamp = "tarmak_creation_timestamp"
+
+func (a *Azure) DefaultImage(version string) (*tarmakv1alpha1.Image, error) {
+	return nil, fmt.Errorf(
+		"there are no pre-built images for %s %s, images need to be available in subscription '%s' with tag %s",
+		a.Cloud(),
+		version,
+		a.conf.Azure.SubscriptionID,
+		tarmakv1alpha1.ImageTagBaseImageName,
+	)
+}
+
+func (a *Azure) QueryImages(tags map[string]string) (images []*tarmakv1alpha1.Image, err error) {
+	svc, err := a.ARM()
+	if err != nil {
+		return images, err
+	}
+
+	armImages, err := svc.Images(tags)
+	if err != nil {
+		return images, err
+	}
+
+	for _, armImage := range armImages {
+		// images can only be used in their location
+		if armImage.Location != a.Region() {
+			continue
+		}
+
+		image, err := a.imageFromARMImage(armImage)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+func (a *Azure) imageFromARMImage(armImage *arm.Image) (*tarmakv1alpha1.Image, error) {
+	image := &tarmakv1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: make(map[string]string),
+		},
+	}
+
+	// copy over tags from the image to image annotations
+	for key, value := range armImage.Tags {
+		image.Annotations[key] = value
+		// copy over base image name from tags
+		if key == tarmakv1alpha1.ImageTagBaseImageName {
+			image.BaseImage = value
+		}
+	}
+
+	if value, ok := armImage.Tags[tagCreationTimestamp]; ok {
+		creationTimestamp, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing time stamp '%s'", err)
+		}
+		image.CreationTimestamp.Time = creationTimestamp
+	}
+
+	image.Name = armImage.ID
+	image.Location = armImage.Location
+	// managed disks are always encrypted at rest
+	image.Encrypted = true
+
+	return image, nil
+}
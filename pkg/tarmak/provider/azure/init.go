@@ -0,0 +1,146 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"fmt"
+	"regexp"
+
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+)
+
+var regexpStorageAccountPrefix = regexp.MustCompile("^[a-z][a-z0-9]{0,11}$")
+
+func Init(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	if provider.Azure == nil {
+		provider.Azure = &tarmakv1alpha1.ProviderAzure{}
+	}
+
+	err := initSubscription(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initServicePrincipal(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initResourceGroup(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initStorageAccountPrefix(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initPublicZone(in, provider)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func initSubscription(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	subscriptionID, err := in.AskOpen(&input.AskOpen{
+		Query: "Which Azure subscription ID should be used?",
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.Azure.SubscriptionID = subscriptionID
+	return nil
+}
+
+func initServicePrincipal(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	clientID, err := in.AskOpen(&input.AskOpen{
+		Query:      "Which service principal client ID should be used? (leave empty to use the Azure CLI login)",
+		AllowEmpty: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.Azure.ClientID = clientID
+	if clientID == "" {
+		return nil
+	}
+
+	tenantID, err := in.AskOpen(&input.AskOpen{
+		Query: "Which tenant ID does the service principal belong to?",
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.Azure.TenantID = tenantID
+	return nil
+}
+
+func initResourceGroup(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	resourceGroup, err := in.AskOpen(&input.AskOpen{
+		Query:   "Which resource group should contain the resources of this provider?",
+		Default: fmt.Sprintf("tarmak-%s", provider.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.Azure.ResourceGroup = resourceGroup
+	return nil
+}
+
+func initStorageAccountPrefix(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	for {
+		prefix, err := in.AskOpen(&input.AskOpen{
+			Query:   "Which prefix should be used for the storage accounts? ([a-z][a-z0-9]{0,11}, should be globally unique)",
+			Default: "tarmak",
+		})
+		if err != nil {
+			return err
+		}
+
+		if !regexpStorageAccountPrefix.MatchString(prefix) {
+			in.Warnf("storage account prefix '%s' is not valid", prefix)
+		} else {
+			provider.Azure.StorageAccountPrefix = prefix
+			break
+		}
+	}
+
+	return nil
+}
+
+func initPublicZone(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	for {
+		publicZone, err := in.AskOpen(&input.AskOpen{
+			Query: "Which public DNS zone should be used? (the zone needs to be served by Azure DNS)",
+		})
+		if err != nil {
+			return err
+		}
+
+		zoneValid := input.RegexpDNS.MatchString(publicZone)
+
+		if !zoneValid {
+			in.Warnf("Public DNS zone '%s' is not valid", publicZone)
+		} else {
+			provider.Azure.PublicZone = publicZone
+			break
+		}
+	}
+
+	resourceGroup, err := in.AskOpen(&input.AskOpen{
+		Query: fmt.Sprintf("Which resource group contains the DNS zone '%s'?", provider.Azure.PublicZone),
+	})
+	if err != nil {
+		return err
+	}
+	provider.Azure.PublicZoneResourceGroup = resourceGroup
+
+	return nil
+}
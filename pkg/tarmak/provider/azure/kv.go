@@ -0,0 +1,111 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+
+	"github.com/jetstack/vault-unsealer/pkg/kv"
+
+	"github.com/jetstack/tarmak/pkg/azure/arm"
+)
+
+var invalidSecretNameChars = regexp.MustCompile("[^0-9a-zA-Z-]")
+
+// keyVaultKV stores the vault unseal keys as secrets in an Azure Key Vault.
+// Secret values are strings, so keys are stored base64 encoded.
+type keyVaultKV struct {
+	arm    ARM
+	vault  string
+	prefix string
+}
+
+var _ kv.Service = &keyVaultKV{}
+
+func (k *keyVaultKV) key(key string) string {
+	return invalidSecretNameChars.ReplaceAllString(fmt.Sprintf("%s%s", k.prefix, key), "-")
+}
+
+func (k *keyVaultKV) Set(key string, value []byte) error {
+	if err := k.arm.SetSecret(k.vault, k.key(key), base64.StdEncoding.EncodeToString(value)); err != nil {
+		return fmt.Errorf("error setting secret '%s' in key vault '%s': %s", k.key(key), k.vault, err)
+	}
+	return nil
+}
+
+func (k *keyVaultKV) Get(key string) ([]byte, error) {
+	value, err := k.arm.GetSecret(k.vault, k.key(key))
+	if arm.IsNotFound(err) {
+		return nil, kv.NewNotFoundError("secret '%s' not found in key vault '%s'", k.key(key), k.vault)
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting secret '%s' from key vault '%s': %s", k.key(key), k.vault, err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding secret '%s' from key vault '%s': %s", k.key(key), k.vault, err)
+	}
+
+	return data, nil
+}
+
+func (k *keyVaultKV) Test(key string) error {
+	_, err := k.Get(key)
+	return err
+}
+
+func (a *Azure) hubOutputString(key string) (string, error) {
+	output, err := a.tarmak.Cluster().Environment().Hub().TerraformOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting hub terraform output: %s", err)
+	}
+
+	valueIntf, ok := output[key]
+	if !ok {
+		return "", fmt.Errorf("error could not find '%s' in terraform state output", key)
+	}
+
+	value, ok := valueIntf.(string)
+	if !ok {
+		return "", fmt.Errorf("error unexpected type for '%s': %T", key, valueIntf)
+	}
+
+	return value, nil
+}
+
+func (a *Azure) VaultKV() (kv.Service, error) {
+	vault, err := a.hubOutputString("vault_kms_key_id")
+	if err != nil {
+		return nil, err
+	}
+
+	unsealKeyName, err := a.hubOutputString("vault_unseal_key_name")
+	if err != nil {
+		return nil, err
+	}
+
+	return a.VaultKVWithParams(vault, unsealKeyName)
+}
+
+// On Azure the key ID references the Key Vault the unseal keys are stored in
+func (a *Azure) VaultKVWithParams(vault, unsealKeyName string) (kv.Service, error) {
+	svc, err := a.ARM()
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyVaultKV{
+		arm:    svc,
+		vault:  vault,
+		prefix: unsealKeyName,
+	}, nil
+}
+
+func (a *Azure) keyVaultName(environment string) string {
+	return storageAccountName(
+		a.conf.Azure.StorageAccountPrefix,
+		environment,
+		"vault",
+	)
+}
@@ -0,0 +1,101 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+)
+
+// blobClient implements the Blob interface on top of the Azure Storage
+// client
+type blobClient struct {
+	client storage.BlobStorageClient
+}
+
+var _ Blob = &blobClient{}
+
+func newBlobClient(account, key string) (*blobClient, error) {
+	client, err := storage.NewBasicClient(account, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &blobClient{client: client.GetBlobService()}, nil
+}
+
+func isNotFound(err error) bool {
+	storageErr, ok := err.(storage.AzureStorageServiceError)
+	return ok && storageErr.StatusCode == http.StatusNotFound
+}
+
+func (b *blobClient) ContainerExists(container string) (bool, error) {
+	return b.client.GetContainerReference(container).Exists()
+}
+
+func (b *blobClient) CreateContainer(container string) error {
+	_, err := b.client.GetContainerReference(container).CreateIfNotExists(&storage.CreateContainerOptions{
+		Access: storage.ContainerAccessTypePrivate,
+	})
+	return err
+}
+
+func (b *blobClient) PutBlob(container, name string, body io.Reader) error {
+	blob := b.client.GetContainerReference(container).GetBlobReference(name)
+	if err := blob.CreateBlockBlobFromReader(body, nil); err != nil {
+		return fmt.Errorf("error writing blob %s/%s: %s", container, name, err)
+	}
+	return nil
+}
+
+func (b *blobClient) GetBlob(container, name string) ([]byte, error) {
+	r, err := b.client.GetContainerReference(container).GetBlobReference(name).Get(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+func (b *blobClient) DeleteBlob(container, name string) error {
+	_, err := b.client.GetContainerReference(container).GetBlobReference(name).DeleteIfExists(nil)
+	return err
+}
+
+func (b *blobClient) ListBlobs(container, prefix string) ([]string, error) {
+	var names []string
+
+	params := storage.ListBlobsParameters{Prefix: prefix}
+	for {
+		resp, err := b.client.GetContainerReference(container).ListBlobs(params)
+		if isNotFound(err) {
+			return names, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, blob := range resp.Blobs {
+			names = append(names, blob.Name)
+		}
+
+		if resp.NextMarker == "" {
+			return names, nil
+		}
+		params.Marker = resp.NextMarker
+	}
+}
+
+// BreakLease releases the lease terraform holds on a locked state blob
+func (b *blobClient) BreakLease(container, name string) error {
+	_, err := b.client.GetContainerReference(container).GetBlobReference(name).BreakLeaseWithBreakPeriod(0, nil)
+	return err
+}
+
+func isLeaseNotPresent(err error) bool {
+	storageErr, ok := err.(storage.AzureStorageServiceError)
+	return ok && storageErr.Code == "LeaseNotPresentWithLeaseOperation"
+}
@@ -0,0 +1,150 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// terraform's azurerm backend stores every state in a blob of this container
+// and locks it by acquiring a lease on that blob
+const remoteStateContainer = "tfstate"
+
+func (a *Azure) RemoteStateName() string {
+	return storageAccountName(
+		a.conf.Azure.StorageAccountPrefix,
+		a.Region(),
+		"state",
+	)
+}
+
+func (a *Azure) RemoteStateBucketName() string {
+	return a.RemoteStateName()
+}
+
+func (a *Azure) RemoteStateKey(namespace string, clusterName string) string {
+	return fmt.Sprintf("%s/%s.tfstate", namespace, clusterName)
+}
+
+func (a *Azure) LegacyPuppetTFName() string {
+	return "azurerm_storage_blob.legacy-puppet-tar-gz"
+}
+
+func (a *Azure) RemoteState(namespace string, clusterName string, stackName string) string {
+	return fmt.Sprintf(`terraform {
+  backend "azurerm" {
+    resource_group_name = "%s"
+    storage_account_name = "%s"
+    container_name = "%s"
+    key = "%s"
+  }
+}`,
+		a.ResourceGroup(),
+		a.RemoteStateName(),
+		remoteStateContainer,
+		a.RemoteStateKey(namespace, clusterName),
+	)
+}
+
+func (a *Azure) RemoteStateBucketAvailable() (bool, error) {
+	svc, err := a.ARM()
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := svc.StorageAccountExists(a.ResourceGroup(), a.RemoteStateName())
+	if err != nil {
+		return false, fmt.Errorf("error while checking if remote state is available: %s", err)
+	}
+
+	return exists, nil
+}
+
+func (a *Azure) ensureRemoteStateStorageAccount() error {
+	svc, err := a.ARM()
+	if err != nil {
+		return err
+	}
+
+	if err := svc.EnsureResourceGroup(a.ResourceGroup(), a.Region()); err != nil {
+		return fmt.Errorf("error ensuring resource group '%s': %s", a.ResourceGroup(), err)
+	}
+
+	exists, err := svc.StorageAccountExists(a.ResourceGroup(), a.RemoteStateName())
+	if err != nil {
+		return fmt.Errorf("error looking for terraform state storage account: %s", err)
+	}
+
+	if !exists {
+		a.log.Infof("creating terraform state storage account '%s'", a.RemoteStateName())
+		if err := svc.CreateStorageAccount(a.ResourceGroup(), a.RemoteStateName(), a.Region()); err != nil {
+			return fmt.Errorf("error creating terraform state storage account: %s", err)
+		}
+	}
+
+	blob, err := a.Blob(a.RemoteStateName())
+	if err != nil {
+		return err
+	}
+
+	if err := blob.CreateContainer(remoteStateContainer); err != nil {
+		return fmt.Errorf("error creating terraform state container: %s", err)
+	}
+
+	return nil
+}
+
+func (a *Azure) deleteRemoteStateBlobs() error {
+	blob, err := a.Blob(a.RemoteStateName())
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("%s/", a.tarmak.Environment().Name())
+	names, err := blob.ListBlobs(remoteStateContainer, prefix)
+	if err != nil {
+		return err
+	}
+
+	key := a.RemoteStateKey(a.tarmak.Environment().Name(), a.tarmak.Cluster().Name())
+	for _, name := range names {
+		// workspaces are stored with an env:<name> suffix
+		if name != key && !strings.HasPrefix(name, fmt.Sprintf("%senv:", key)) {
+			continue
+		}
+
+		// state blobs might still be leased by a stale terraform lock
+		if err := blob.BreakLease(remoteStateContainer, name); err != nil && !isLeaseNotPresent(err) {
+			return fmt.Errorf("error breaking lease of '%s': %s", name, err)
+		}
+
+		if err := blob.DeleteBlob(remoteStateContainer, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *Azure) remoteStateContainerEmpty() (bool, error) {
+	blob, err := a.Blob(a.RemoteStateName())
+	if err != nil {
+		return false, err
+	}
+
+	names, err := blob.ListBlobs(remoteStateContainer, "")
+	if err != nil {
+		return false, err
+	}
+
+	return len(names) == 0, nil
+}
+
+func (a *Azure) deleteRemoteStateStorageAccount() error {
+	svc, err := a.ARM()
+	if err != nil {
+		return err
+	}
+
+	return svc.DeleteStorageAccount(a.ResourceGroup(), a.RemoteStateName())
+}
@@ -0,0 +1,80 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+// container of the secrets storage account holding puppet manifests and
+// the wing binary
+const secretsContainer = "tarmak"
+
+// This uploads the main configuration to the secrets storage account
+func (a *Azure) UploadConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) error {
+	blob, err := a.Blob(a.secretsStorageAccountName(cluster.Environment().Name()))
+	if err != nil {
+		return err
+	}
+
+	manifestKey := filepath.Join(cluster.ClusterName(), "puppet.tar.gz")
+	if err := blob.PutBlob(secretsContainer, manifestKey, stateFile); err != nil {
+		return err
+	}
+
+	if _, err := stateFile.Seek(0, 0); err != nil {
+		return fmt.Errorf("failed to rewind puppet state file: %s", err)
+	}
+
+	dirPath := filepath.Join(cluster.ClusterName(), "puppet-manifests")
+	hashPointerKey := filepath.Join(dirPath, "latest-puppet-hash")
+	manifestKey = filepath.Join(dirPath, fmt.Sprintf("%s-puppet.tar.gz", md5Hash))
+	if err := blob.PutBlob(secretsContainer, manifestKey, stateFile); err != nil {
+		return err
+	}
+
+	if err := blob.PutBlob(secretsContainer, hashPointerKey, bytes.NewReader([]byte(md5Hash))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// This uploads a configuration to the secrets storage account without making
+// it the latest one, so instances can run it in dry run mode. It returns the
+// manifest URL wing is able to download it from.
+func (a *Azure) UploadDryRunConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) (string, error) {
+	account := a.secretsStorageAccountName(cluster.Environment().Name())
+
+	blob, err := a.Blob(account)
+	if err != nil {
+		return "", err
+	}
+
+	manifestKey := filepath.Join(cluster.ClusterName(), "puppet-manifests", fmt.Sprintf("%s-puppet.tar.gz", md5Hash))
+	if err := blob.PutBlob(secretsContainer, manifestKey, stateFile); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", account, secretsContainer, manifestKey), nil
+}
+
+func (a *Azure) secretsStorageAccountName(environment string) string {
+	return storageAccountName(
+		a.conf.Azure.StorageAccountPrefix,
+		environment,
+		"secrets",
+	)
+}
+
+func (a *Azure) backupsStorageAccountName(environment string) string {
+	return storageAccountName(
+		a.conf.Azure.StorageAccountPrefix,
+		environment,
+		"backups",
+	)
+}
@@ -0,0 +1,197 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+)
+
+var _ interfaces.Provider = &Baremetal{}
+
+// Baremetal manages machines that already exist. Hosts are read from a static
+// inventory and all state is kept on a local or shared filesystem, so no
+// infrastructure is created by terraform.
+type Baremetal struct {
+	conf *tarmakv1alpha1.Provider
+
+	tarmak interfaces.Tarmak
+
+	inventory *inventory
+
+	log *logrus.Entry
+}
+
+func NewFromConfig(tarmak interfaces.Tarmak, conf *tarmakv1alpha1.Provider) (*Baremetal, error) {
+
+	b := &Baremetal{
+		conf:   conf,
+		log:    tarmak.Log().WithField("provider_name", conf.ObjectMeta.Name),
+		tarmak: tarmak,
+	}
+
+	return b, nil
+}
+
+func (b *Baremetal) Name() string {
+	return b.conf.Name
+}
+
+func (b *Baremetal) Cloud() string {
+	return clusterv1alpha1.CloudBaremetal
+}
+
+// this clears all cached state from the provider
+func (b *Baremetal) Reset() {
+	b.inventory = nil
+}
+
+// This parameters should include non sensitive information to identify a provider
+func (b *Baremetal) Parameters() map[string]string {
+	return map[string]string{
+		"name":        b.Name(),
+		"cloud":       b.Cloud(),
+		"inventory":   b.conf.Baremetal.InventoryPath,
+		"state_path":  b.conf.Baremetal.StatePath,
+		"public_zone": b.conf.Baremetal.PublicZone,
+	}
+}
+
+func (b *Baremetal) String() string {
+	return fmt.Sprintf("%s[%s]", b.Cloud(), b.Name())
+}
+
+func (b *Baremetal) AskEnvironmentLocation(init interfaces.Initialize) (location string, err error) {
+	for {
+		location, err := init.Input().AskOpen(&input.AskOpen{
+			Query:   "In which location (e.g. datacenter) should this environment reside? [a-z0-9-]+",
+			Default: "local",
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if !input.RegexpProviderName.MatchString(location) {
+			init.Input().Warnf("location '%s' is not valid", location)
+			continue
+		}
+
+		return location, nil
+	}
+}
+
+// There are no availability zones for existing machines, so the location of
+// the environment is used as the only zone
+func (b *Baremetal) AskInstancePoolZones(init interfaces.Initialize) (zones []string, err error) {
+	return []string{b.Region()}, nil
+}
+
+func (b *Baremetal) Region() string {
+	// without environment selected, fall back to default location
+	if b.tarmak.Environment() == nil {
+		return "local"
+	}
+	return b.tarmak.Environment().Location()
+}
+
+func (b *Baremetal) Variables() map[string]interface{} {
+	output := map[string]interface{}{}
+	output["region"] = b.Region()
+	output["public_zone"] = b.conf.Baremetal.PublicZone
+	output["state_path"] = b.conf.Baremetal.StatePath
+
+	return output
+}
+
+// This will return necessary environment variables
+func (b *Baremetal) Environment() ([]string, error) {
+	return []string{}, nil
+}
+
+func (b *Baremetal) Validate() error {
+	var result *multierror.Error
+
+	if b.conf.Baremetal.InventoryPath == "" {
+		result = multierror.Append(result, fmt.Errorf("no inventory path specified for provider '%s'", b.Name()))
+	}
+
+	if b.conf.Baremetal.StatePath == "" {
+		result = multierror.Append(result, fmt.Errorf("no state path specified for provider '%s'", b.Name()))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (b *Baremetal) Verify() error {
+	var result *multierror.Error
+
+	if _, err := b.Inventory(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if err := b.verifyStatePath(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (b *Baremetal) EnsureRemoteResources() error {
+	if b.tarmak.Environment() == nil {
+		return nil
+	}
+
+	return b.ensureStateDir()
+}
+
+// State on the filesystem is kept on purpose, as it contains the vault unseal
+// keys and is most likely shared with other environments
+func (b *Baremetal) Remove() error {
+	return b.deleteRemoteState()
+}
+
+func (b *Baremetal) verifyStatePath() error {
+	info, err := os.Stat(b.conf.Baremetal.StatePath)
+	if os.IsNotExist(err) {
+		// will be created by EnsureRemoteResources
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error accessing state path '%s': %s", b.conf.Baremetal.StatePath, err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("state path '%s' is not a directory", b.conf.Baremetal.StatePath)
+	}
+
+	return nil
+}
+
+// Machine sizes are given by the existing hardware
+func (b *Baremetal) InstanceType(typeIn string) (typeOut string, err error) {
+	return typeIn, nil
+}
+
+// Volumes are given by the existing hardware
+func (b *Baremetal) VolumeType(typeIn string) (typeOut string, err error) {
+	return typeIn, nil
+}
+
+func (b *Baremetal) PublicZone() string {
+	return b.conf.Baremetal.PublicZone
+}
+
+// Existing machines are not booted from images built by tarmak
+func (b *Baremetal) QueryImages(tags map[string]string) ([]*tarmakv1alpha1.Image, error) {
+	return []*tarmakv1alpha1.Image{}, nil
+}
+
+func (b *Baremetal) DefaultImage(version string) (*tarmakv1alpha1.Image, error) {
+	return nil, fmt.Errorf("images are not supported by provider '%s'", b.String())
+}
@@ -0,0 +1,327 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/jetstack/vault-unsealer/pkg/kv"
+	"github.com/sirupsen/logrus"
+
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/mocks"
+)
+
+const testInventory = `hosts:
+- name: bastion
+  hostname: 192.0.2.1
+  environment: env
+  cluster: hub
+  roles: [bastion]
+- hostname: 10.0.0.10
+  environment: env
+  cluster: hub
+  roles: [vault]
+  sshHostKeys:
+  - ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBLmg4Ppa4nwvHO0pnwPeCSYSmGxz67XiMs8TtlPg5XxiSc2Y7zIwjNNAdq91zYRS1I1iM5OPUsLYuugJNvcgHS8=
+- hostname: 10.0.0.20
+  environment: env
+  cluster: cluster
+  roles: [master, etcd]
+  user: admin
+- hostname: 10.0.0.21
+  environment: env
+  cluster: cluster
+  roles: [master, etcd]
+- hostname: 10.0.0.30
+  environment: env
+  cluster: other
+  roles: [worker]
+- hostname: 10.1.0.10
+  environment: staging
+  cluster: hub
+  roles: [vault]
+`
+
+type fakeBaremetal struct {
+	*Baremetal
+	ctrl *gomock.Controller
+
+	dir string
+
+	fakeEnvironment *mocks.MockEnvironment
+	fakeCluster     *mocks.MockCluster
+	fakeTarmak      *mocks.MockTarmak
+}
+
+func newFakeBaremetal(t *testing.T) *fakeBaremetal {
+	dir, err := ioutil.TempDir("", "tarmak-baremetal")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inventoryPath := filepath.Join(dir, "inventory.yaml")
+	if err := ioutil.WriteFile(inventoryPath, []byte(testInventory), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeBaremetal{
+		ctrl: gomock.NewController(t),
+		dir:  dir,
+		Baremetal: &Baremetal{
+			conf: &tarmakv1alpha1.Provider{
+				Baremetal: &tarmakv1alpha1.ProviderBaremetal{
+					InventoryPath: inventoryPath,
+					StatePath:     filepath.Join(dir, "state"),
+				},
+			},
+			log: logrus.WithField("test", true),
+		},
+	}
+	f.fakeEnvironment = mocks.NewMockEnvironment(f.ctrl)
+	f.fakeCluster = mocks.NewMockCluster(f.ctrl)
+	f.fakeTarmak = mocks.NewMockTarmak(f.ctrl)
+	f.Baremetal.tarmak = f.fakeTarmak
+	f.fakeTarmak.EXPECT().Cluster().AnyTimes().Return(f.fakeCluster)
+	f.fakeTarmak.EXPECT().Environment().AnyTimes().Return(f.fakeEnvironment)
+	f.fakeCluster.EXPECT().Environment().AnyTimes().Return(f.fakeEnvironment)
+	f.fakeCluster.EXPECT().Name().AnyTimes().Return("cluster")
+	f.fakeEnvironment.EXPECT().Name().AnyTimes().Return("env")
+
+	return f
+}
+
+func (f *fakeBaremetal) Finish() {
+	f.ctrl.Finish()
+	os.RemoveAll(f.dir)
+}
+
+func TestBaremetal_ListHosts(t *testing.T) {
+	b := newFakeBaremetal(t)
+	defer b.Finish()
+
+	hosts, err := b.ListHosts(b.fakeCluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	type result struct {
+		id, hostname, user string
+		public             bool
+		aliases            []string
+	}
+	var act []result
+	for _, h := range hosts {
+		act = append(act, result{
+			id:       h.ID(),
+			hostname: h.Hostname(),
+			user:     h.User(),
+			public:   h.(*host).HostnamePublic(),
+			aliases:  h.Aliases(),
+		})
+	}
+
+	exp := []result{
+		{"10.0.0.10", "10.0.0.10", "centos", false, []string{"vault"}},
+		{"10.0.0.20", "10.0.0.20", "admin", false, []string{"master-1", "etcd-1"}},
+		{"10.0.0.21", "10.0.0.21", "centos", false, []string{"master-2", "etcd-2"}},
+		{"bastion", "192.0.2.1", "centos", true, []string{"bastion"}},
+	}
+
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected hosts:\nact=%+v\nexp=%+v", act, exp)
+	}
+
+	keys, err := hosts[0].SSHHostPublicKeys()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(keys) != 1 || keys[0].Type() != "ecdsa-sha2-nistp256" {
+		t.Errorf("unexpected host keys: %+v", keys)
+	}
+}
+
+func TestBaremetal_parseInventoryInvalid(t *testing.T) {
+	for _, c := range []struct {
+		name, inventory, err string
+	}{
+		{"no hostname", "hosts:\n- environment: env\n  cluster: hub\n  roles: [vault]\n", "host 1 has no hostname"},
+		{"no cluster", "hosts:\n- hostname: a\n  environment: env\n  roles: [vault]\n", "host 'a' needs both environment and cluster"},
+		{"no roles", "hosts:\n- hostname: a\n  environment: env\n  cluster: hub\n", "host 'a' has no roles"},
+		{"duplicate", "hosts:\n- hostname: a\n  environment: env\n  cluster: hub\n  roles: [vault]\n- name: a\n  hostname: b\n  environment: env\n  cluster: hub\n  roles: [vault]\n", "host 'a' is listed more than once"},
+		{"unknown field", "hosts:\n- hostname: a\n  zone: b\n", "unknown field"},
+	} {
+		_, err := parseInventory([]byte(c.inventory))
+		if err == nil {
+			t.Errorf("%s: expected an error", c.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		}
+	}
+}
+
+func isNotFound(err error) bool {
+	_, ok := err.(*kv.NotFoundError)
+	return ok
+}
+
+func TestBaremetal_VaultKV(t *testing.T) {
+	b := newFakeBaremetal(t)
+	defer b.Finish()
+
+	svc, err := b.VaultKV()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := svc.Get("unseal-key-0"); !isNotFound(err) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+	if err := svc.Test("unseal-key-0"); !isNotFound(err) {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+
+	if err := svc.Set("unseal-key-0", []byte("secret")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := svc.Test("unseal-key-0"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	value, err := svc.Get("unseal-key-0")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(value) != "secret" {
+		t.Errorf("unexpected value: %s", value)
+	}
+
+	path := filepath.Join(b.dir, "state", "env", "vault", "vault-env-unseal-key-0")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("unexpected file mode of %s: %o", path, mode)
+	}
+}
+
+func TestBaremetal_UploadConfiguration(t *testing.T) {
+	b := newFakeBaremetal(t)
+	defer b.Finish()
+
+	if err := b.UploadConfiguration(b.fakeCluster, bytes.NewReader([]byte("manifest")), "abcdef"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	dir := filepath.Join(b.dir, "state", "env", "cluster")
+	for path, exp := range map[string]string{
+		"puppet.tar.gz":                         "manifest",
+		"puppet-manifests/abcdef-puppet.tar.gz": "manifest",
+		"puppet-manifests/latest-puppet-hash":   "abcdef",
+	} {
+		act, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			continue
+		}
+		if string(act) != exp {
+			t.Errorf("unexpected content of %s: act=%s exp=%s", path, act, exp)
+		}
+	}
+
+	manifestURL, err := b.UploadDryRunConfiguration(b.fakeCluster, bytes.NewReader([]byte("dry-run")), "123456")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := filepath.Join(dir, "puppet-manifests/123456-puppet.tar.gz"); manifestURL != exp {
+		t.Errorf("unexpected manifest URL: act=%s exp=%s", manifestURL, exp)
+	}
+
+	hash, err := ioutil.ReadFile(filepath.Join(dir, "puppet-manifests/latest-puppet-hash"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(hash) != "abcdef" {
+		t.Errorf("dry run must not change the latest hash, got: %s", hash)
+	}
+}
+
+func TestBaremetal_RemoteState(t *testing.T) {
+	b := newFakeBaremetal(t)
+	defer b.Finish()
+
+	exp := `terraform {
+  backend "local" {
+    path = "` + filepath.Join(b.dir, "state", "env", "cluster", "terraform.tfstate") + `"
+  }
+}`
+	if act := b.RemoteState("env", "cluster", "main"); act != exp {
+		t.Errorf("unexpected remote state:\nact=%s\nexp=%s", act, exp)
+	}
+
+	if available, err := b.RemoteStateBucketAvailable(); err != nil || available {
+		t.Errorf("expected state path not to be available: %v, %v", available, err)
+	}
+
+	if err := b.EnsureRemoteResources(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if available, err := b.RemoteStateBucketAvailable(); err != nil || !available {
+		t.Errorf("expected state path to be available: %v, %v", available, err)
+	}
+}
+
+func TestBaremetal_EtcdSnapshots(t *testing.T) {
+	b := newFakeBaremetal(t)
+	defer b.Finish()
+
+	keys, err := b.ListEtcdSnapshots(b.fakeCluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(keys) != 0 {
+		t.Errorf("expected no snapshots, got: %v", keys)
+	}
+
+	for _, key := range []string{"20180701T120000Z/k8s-main.db", "20180701T120000Z/k8s-events.db"} {
+		if err := b.UploadEtcdSnapshot(b.fakeCluster, key, strings.NewReader(key)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	keys, err = b.ListEtcdSnapshots(b.fakeCluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := []string{"20180701T120000Z/k8s-events.db", "20180701T120000Z/k8s-main.db"}; !reflect.DeepEqual(keys, exp) {
+		t.Errorf("unexpected snapshots: act=%v exp=%v", keys, exp)
+	}
+
+	var buf bytes.Buffer
+	if err := b.DownloadEtcdSnapshot(b.fakeCluster, "20180701T120000Z/k8s-main.db", &buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if act, exp := buf.String(), "20180701T120000Z/k8s-main.db"; act != exp {
+		t.Errorf("unexpected content: act=%s exp=%s", act, exp)
+	}
+
+	path := filepath.Join(b.dir, "state", "env", "cluster", "etcd-backups", "20180701T120000Z", "k8s-main.db")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("unexpected file mode of %s: %o", path, mode)
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/utils/file"
+)
+
+// This writes an etcd snapshot to the state path, it is only readable by the
+// current user. Encrypting it at rest is up to the storage of the state path.
+func (b *Baremetal) UploadEtcdSnapshot(cluster interfaces.Cluster, key string, snapshot io.ReadSeeker) error {
+	return file.WriteAtomic(filepath.Join(b.etcdSnapshotDir(cluster), filepath.FromSlash(key)), snapshot)
+}
+
+func (b *Baremetal) DownloadEtcdSnapshot(cluster interfaces.Cluster, key string, w io.Writer) error {
+	f, err := os.Open(filepath.Join(b.etcdSnapshotDir(cluster), filepath.FromSlash(key)))
+	if err != nil {
+		return fmt.Errorf("error reading etcd snapshot '%s': %s", key, err)
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (b *Baremetal) ListEtcdSnapshots(cluster interfaces.Cluster) ([]string, error) {
+	dir := b.etcdSnapshotDir(cluster)
+
+	var keys []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == dir {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+
+		// skip directories and partially written snapshots
+		if info.IsDir() || filepath.Base(path)[0] == '.' {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing etcd snapshots: %s", err)
+	}
+
+	return keys, nil
+}
+
+func (b *Baremetal) etcdSnapshotDir(cluster interfaces.Cluster) string {
+	return filepath.Join(b.remoteStateDir(cluster.Environment().Name(), cluster.Name()), "etcd-backups")
+}
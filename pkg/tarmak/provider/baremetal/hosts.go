@@ -0,0 +1,161 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+type host struct {
+	id             string
+	host           string
+	hostnamePublic bool
+	hostname       string
+	aliases        []string
+	roles          []string
+	instancePool   string
+	user           string
+	sshHostKeys    []string
+
+	cluster interfaces.Cluster
+}
+
+var _ interfaces.Host = &host{}
+
+func (h *host) ID() string {
+	return h.id
+}
+
+func (h *host) Roles() []string {
+	return h.roles
+}
+
+func (h *host) InstancePool() string {
+	return h.instancePool
+}
+
+func (h *host) Aliases() []string {
+	return h.aliases
+}
+
+func (h *host) Hostname() string {
+	return h.hostname
+}
+
+func (h *host) HostnamePublic() bool {
+	return h.hostnamePublic
+}
+
+func (h *host) User() string {
+	return h.user
+}
+
+func (h *host) Parameters() map[string]string {
+	return map[string]string{
+		"id":       h.ID(),
+		"hostname": h.Hostname(),
+		"roles":    strings.Join(h.Roles(), ", "),
+	}
+}
+
+func (h *host) SSHHostPublicKeys() ([]ssh.PublicKey, error) {
+	var hostKeys []ssh.PublicKey
+
+	for _, line := range h.sshHostKeys {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			h.cluster.Log().Warnf(
+				"failed to parse public keys from inventory of host '%s': %v",
+				h.Aliases(),
+				err,
+			)
+			continue
+		}
+		hostKeys = append(hostKeys, hostKey)
+	}
+
+	return hostKeys, nil
+}
+
+func (h *host) SSHConfig(strictChecking string) string {
+	return utils.HostSSHConfig(h, h.HostnamePublic(), h.cluster, strictChecking)
+}
+
+func (b *Baremetal) ListHosts(c interfaces.Cluster) ([]interfaces.Host, error) {
+	inv, err := b.Inventory()
+	if err != nil {
+		return []interfaces.Host{}, err
+	}
+
+	hosts := []*host{}
+
+	for _, entry := range inv.Hosts {
+		if entry.Environment != c.Environment().Name() {
+			continue
+		}
+
+		// skip if host is not from the hub or current cluster
+		if entry.Cluster != c.Name() && entry.Cluster != clusterv1alpha1.ClusterTypeHub {
+			continue
+		}
+
+		hosts = append(hosts, &host{
+			id:             entry.id(),
+			hostname:       entry.Hostname,
+			hostnamePublic: entry.public(),
+			user:           entry.user(),
+			roles:          append([]string{}, entry.Roles...),
+			instancePool:   entry.InstancePool,
+			cluster:        b.tarmak.Cluster(),
+			sshHostKeys:    entry.SSHHostKeys,
+		})
+	}
+
+	// make sure aliases are stable across calls
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].id < hosts[j].id })
+
+	hostsByRole := map[string][]*host{}
+	for _, h := range hosts {
+		for _, role := range h.roles {
+			hostsByRole[role] = append(hostsByRole[role], h)
+			h.aliases = append(h.aliases, fmt.Sprintf("%s-%d", role, len(hostsByRole[role])))
+		}
+	}
+
+	// remove role-1 for single instances
+	for role, hosts := range hostsByRole {
+		if len(hosts) != 1 {
+			continue
+		}
+		for pos, _ := range hosts[0].aliases {
+			if hosts[0].aliases[pos] == fmt.Sprintf("%s-1", role) {
+				hosts[0].aliases[pos] = role
+			}
+		}
+	}
+
+	hostsInterfaces := make([]interfaces.Host, len(hosts))
+
+	for pos, _ := range hosts {
+		hostsInterfaces[pos] = hosts[pos]
+	}
+
+	return hostsInterfaces, nil
+}
+
+// Existing machines can't be replaced by tarmak
+func (b *Baremetal) TerminateHosts(hosts []interfaces.Host) error {
+	return fmt.Errorf("provider %s can't replace the existing hosts", b.String())
+}
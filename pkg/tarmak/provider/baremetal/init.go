@@ -0,0 +1,76 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+)
+
+func Init(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	if provider.Baremetal == nil {
+		provider.Baremetal = &tarmakv1alpha1.ProviderBaremetal{}
+	}
+
+	err := initInventoryPath(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initStatePath(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initPublicZone(in, provider)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func initInventoryPath(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	inventoryPath, err := in.AskOpen(&input.AskOpen{
+		Query: "Which inventory file lists the existing hosts?",
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.Baremetal.InventoryPath = inventoryPath
+	return nil
+}
+
+func initStatePath(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	statePath, err := in.AskOpen(&input.AskOpen{
+		Query: "In which directory should state be stored? (use a shared filesystem to work with multiple operators)",
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.Baremetal.StatePath = statePath
+	return nil
+}
+
+func initPublicZone(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	for {
+		publicZone, err := in.AskOpen(&input.AskOpen{
+			Query: "Which public DNS zone do the hosts use?",
+		})
+		if err != nil {
+			return err
+		}
+
+		zoneValid := input.RegexpDNS.MatchString(publicZone)
+
+		if !zoneValid {
+			in.Warnf("Public DNS zone '%s' is not valid", publicZone)
+		} else {
+			provider.Baremetal.PublicZone = publicZone
+			break
+		}
+	}
+
+	return nil
+}
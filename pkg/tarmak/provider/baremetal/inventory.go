@@ -0,0 +1,107 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/yaml"
+)
+
+const defaultUser = "centos"
+
+// inventory lists the existing machines managed by this provider, e.g.:
+//
+//	hosts:
+//	- name: bastion
+//	  hostname: bastion.dc1.example.com
+//	  environment: onprem
+//	  cluster: hub
+//	  roles: [bastion]
+//	  public: true
+//	  sshHostKeys:
+//	  - ecdsa-sha2-nistp256 AAAA...
+type inventory struct {
+	Hosts []inventoryHost `json:"hosts"`
+}
+
+type inventoryHost struct {
+	Name         string   `json:"name,omitempty"` // unique name of the host, defaults to the hostname
+	Hostname     string   `json:"hostname"`       // address tarmak connects to using SSH
+	Environment  string   `json:"environment"`
+	Cluster      string   `json:"cluster"` // name of the cluster within the environment, e.g. hub
+	Roles        []string `json:"roles"`
+	InstancePool string   `json:"instancePool,omitempty"` // name of the instance pool in the cluster configuration, defaults to the pool of the role
+	User         string   `json:"user,omitempty"`
+	Public       *bool    `json:"public,omitempty"` // reachable without the bastion, defaults to true for bastion hosts
+	SSHHostKeys  []string `json:"sshHostKeys,omitempty"`
+}
+
+func (h *inventoryHost) id() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Hostname
+}
+
+func (h *inventoryHost) user() string {
+	if h.User != "" {
+		return h.User
+	}
+	return defaultUser
+}
+
+func (h *inventoryHost) public() bool {
+	if h.Public != nil {
+		return *h.Public
+	}
+	for _, role := range h.Roles {
+		if role == "bastion" {
+			return true
+		}
+	}
+	return false
+}
+
+func parseInventory(data []byte) (*inventory, error) {
+	inv := &inventory{}
+	if err := yaml.UnmarshalStrict(data, inv); err != nil {
+		return nil, err
+	}
+
+	ids := map[string]bool{}
+	for pos, h := range inv.Hosts {
+		if h.Hostname == "" {
+			return nil, fmt.Errorf("host %d has no hostname", pos+1)
+		}
+		if h.Environment == "" || h.Cluster == "" {
+			return nil, fmt.Errorf("host '%s' needs both environment and cluster", h.id())
+		}
+		if len(h.Roles) == 0 {
+			return nil, fmt.Errorf("host '%s' has no roles", h.id())
+		}
+		if ids[h.id()] {
+			return nil, fmt.Errorf("host '%s' is listed more than once", h.id())
+		}
+		ids[h.id()] = true
+	}
+
+	return inv, nil
+}
+
+// Inventory reads and caches the static inventory file
+func (b *Baremetal) Inventory() (*inventory, error) {
+	if b.inventory == nil {
+		data, err := ioutil.ReadFile(b.conf.Baremetal.InventoryPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading inventory: %s", err)
+		}
+
+		inv, err := parseInventory(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing inventory '%s': %s", b.conf.Baremetal.InventoryPath, err)
+		}
+		b.inventory = inv
+	}
+	return b.inventory, nil
+}
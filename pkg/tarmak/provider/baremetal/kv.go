@@ -0,0 +1,77 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jetstack/vault-unsealer/pkg/kv"
+
+	"github.com/jetstack/tarmak/pkg/utils/file"
+)
+
+// fileKV stores the vault unseal keys as files in a directory, which should
+// only be readable by the operators of the environment
+type fileKV struct {
+	dir    string
+	prefix string
+}
+
+var _ kv.Service = &fileKV{}
+
+func (k *fileKV) path(key string) string {
+	return filepath.Join(k.dir, fmt.Sprintf("%s%s", k.prefix, key))
+}
+
+func (k *fileKV) Set(key string, value []byte) error {
+	if err := file.WriteAtomic(k.path(key), bytes.NewReader(value)); err != nil {
+		return fmt.Errorf("error setting key '%s': %s", k.path(key), err)
+	}
+	return nil
+}
+
+func (k *fileKV) Get(key string) ([]byte, error) {
+	value, err := ioutil.ReadFile(k.path(key))
+	if os.IsNotExist(err) {
+		return nil, kv.NewNotFoundError("key '%s' not found", k.path(key))
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting key '%s': %s", k.path(key), err)
+	}
+	return value, nil
+}
+
+func (k *fileKV) Test(key string) error {
+	_, err := os.Stat(k.path(key))
+	if os.IsNotExist(err) {
+		return kv.NewNotFoundError("key '%s' not found", k.path(key))
+	} else if err != nil {
+		return fmt.Errorf("error testing key '%s': %s", k.path(key), err)
+	}
+	return nil
+}
+
+func (b *Baremetal) vaultDir() string {
+	return filepath.Join(b.conf.Baremetal.StatePath, b.tarmak.Environment().Name(), "vault")
+}
+
+func (b *Baremetal) VaultKV() (kv.Service, error) {
+	return b.VaultKVWithParams(
+		b.vaultDir(),
+		fmt.Sprintf("vault-%s-", b.tarmak.Environment().Name()),
+	)
+}
+
+// On baremetal the key ID references the directory the unseal keys are stored in
+func (b *Baremetal) VaultKVWithParams(dir, unsealKeyName string) (kv.Service, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("no directory given to store vault unseal keys")
+	}
+
+	return &fileKV{
+		dir:    dir,
+		prefix: unsealKeyName,
+	}, nil
+}
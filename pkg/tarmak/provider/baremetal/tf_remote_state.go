@@ -0,0 +1,68 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func (b *Baremetal) RemoteStateName() string {
+	return b.conf.Baremetal.StatePath
+}
+
+func (b *Baremetal) RemoteStateBucketName() string {
+	return b.RemoteStateName()
+}
+
+func (b *Baremetal) remoteStateDir(namespace string, clusterName string) string {
+	return filepath.Join(b.conf.Baremetal.StatePath, namespace, clusterName)
+}
+
+// No infrastructure is managed by terraform, so there is no legacy puppet
+// resource to migrate
+func (b *Baremetal) LegacyPuppetTFName() string {
+	return ""
+}
+
+func (b *Baremetal) RemoteState(namespace string, clusterName string, stackName string) string {
+	return fmt.Sprintf(`terraform {
+  backend "local" {
+    path = "%s"
+  }
+}`,
+		filepath.Join(b.remoteStateDir(namespace, clusterName), "terraform.tfstate"),
+	)
+}
+
+func (b *Baremetal) RemoteStateBucketAvailable() (bool, error) {
+	info, err := os.Stat(b.RemoteStateName())
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("error while checking if remote state is available: %s", err)
+	}
+
+	return info.IsDir(), nil
+}
+
+func (b *Baremetal) ensureStateDir() error {
+	dir := filepath.Join(b.RemoteStateName(), b.tarmak.Environment().Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating state directory: %s", err)
+	}
+
+	return nil
+}
+
+func (b *Baremetal) deleteRemoteState() error {
+	dir := b.remoteStateDir(b.tarmak.Environment().Name(), b.tarmak.Cluster().Name())
+
+	for _, name := range []string{"terraform.tfstate", "terraform.tfstate.backup"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/utils/file"
+)
+
+// This writes the main configuration to the state path, wing's file manifest
+// provider reads it from <state path>/<environment>/<cluster>/puppet.tar.gz
+func (b *Baremetal) UploadConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) error {
+	dir := b.manifestDir(cluster)
+
+	dirPath := filepath.Join(dir, "puppet-manifests")
+	if err := file.WriteAtomic(filepath.Join(dirPath, fmt.Sprintf("%s-puppet.tar.gz", md5Hash)), stateFile); err != nil {
+		return err
+	}
+
+	if _, err := stateFile.Seek(0, 0); err != nil {
+		return fmt.Errorf("failed to rewind puppet state file: %s", err)
+	}
+
+	if err := file.WriteAtomic(filepath.Join(dir, "puppet.tar.gz"), stateFile); err != nil {
+		return err
+	}
+
+	return file.WriteAtomic(filepath.Join(dirPath, "latest-puppet-hash"), bytes.NewReader([]byte(md5Hash)))
+}
+
+// This writes a configuration to the state path without making it the latest
+// one, so instances can run it in dry run mode. It returns the manifest URL
+// wing is able to read it from.
+func (b *Baremetal) UploadDryRunConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) (string, error) {
+	manifestPath := filepath.Join(b.manifestDir(cluster), "puppet-manifests", fmt.Sprintf("%s-puppet.tar.gz", md5Hash))
+	if err := file.WriteAtomic(manifestPath, stateFile); err != nil {
+		return "", err
+	}
+
+	return manifestPath, nil
+}
+
+func (b *Baremetal) manifestDir(cluster interfaces.Cluster) string {
+	return b.remoteStateDir(cluster.Environment().Name(), cluster.Name())
+}
@@ -0,0 +1,64 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+// This uploads an etcd snapshot to the terraform state bucket, GCS encrypts
+// all objects at rest
+func (g *Google) UploadEtcdSnapshot(cluster interfaces.Cluster, key string, snapshot io.ReadSeeker) error {
+	svc, err := g.GCS()
+	if err != nil {
+		return err
+	}
+
+	if err := svc.PutObject(g.RemoteStateName(), g.etcdSnapshotPrefix(cluster)+key, snapshot); err != nil {
+		return fmt.Errorf("error uploading etcd snapshot '%s': %s", key, err)
+	}
+
+	return nil
+}
+
+func (g *Google) DownloadEtcdSnapshot(cluster interfaces.Cluster, key string, w io.Writer) error {
+	svc, err := g.GCS()
+	if err != nil {
+		return err
+	}
+
+	data, err := svc.GetObject(g.RemoteStateName(), g.etcdSnapshotPrefix(cluster)+key)
+	if err != nil {
+		return fmt.Errorf("error downloading etcd snapshot '%s': %s", key, err)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
+
+func (g *Google) ListEtcdSnapshots(cluster interfaces.Cluster) ([]string, error) {
+	svc, err := g.GCS()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := g.etcdSnapshotPrefix(cluster)
+	objects, err := svc.ListObjects(g.RemoteStateName(), prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing etcd snapshots: %s", err)
+	}
+
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, strings.TrimPrefix(object, prefix))
+	}
+
+	return keys, nil
+}
+
+func (g *Google) etcdSnapshotPrefix(cluster interfaces.Cluster) string {
+	return fmt.Sprintf("%s/etcd-backups/", g.RemoteStatePrefix(cluster.Environment().Name(), cluster.Name()))
+}
@@ -0,0 +1,496 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sirupsen/logrus"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/google/compute"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+)
+
+var _ interfaces.Provider = &Google{}
+
+type Google struct {
+	conf *tarmakv1alpha1.Provider
+
+	tarmak interfaces.Tarmak
+
+	availabilityZones *[]string
+
+	gcs     GCS
+	compute Compute
+	log     *logrus.Entry
+}
+
+type GCS interface {
+	BucketExists(bucket string) (bool, error)
+	CreateBucket(project, bucket, location string) error
+	DeleteBucket(bucket string) error
+	PutObject(bucket, key string, body io.Reader) error
+	GetObject(bucket, key string) ([]byte, error)
+	DeleteObject(bucket, key string) error
+	ListObjects(bucket, prefix string) ([]string, error)
+	DeleteObjectGeneration(bucket, key string, generation int64) error
+	ListObjectGenerations(bucket, prefix string) (map[string][]int64, error)
+}
+
+type Compute interface {
+	Regions(project string) ([]string, error)
+	Zones(project, region string) ([]string, error)
+	Instances(project string, labels map[string]string) ([]*compute.Instance, error)
+	Images(project string, labels map[string]string) ([]*compute.Image, error)
+	MachineTypeAvailable(project, zone, machineType string) (bool, error)
+	ManagedZoneDNSName(project, managedZone string) (string, error)
+	DeleteInstance(project, zone, instance string) error
+}
+
+func NewFromConfig(tarmak interfaces.Tarmak, conf *tarmakv1alpha1.Provider) (*Google, error) {
+
+	g := &Google{
+		conf:   conf,
+		log:    tarmak.Log().WithField("provider_name", conf.ObjectMeta.Name),
+		tarmak: tarmak,
+	}
+
+	return g, nil
+}
+
+func (g *Google) Name() string {
+	return g.conf.Name
+}
+
+func (g *Google) Cloud() string {
+	return clusterv1alpha1.CloudGoogle
+}
+
+// this clears all cached state from the provider
+func (g *Google) Reset() {
+	g.gcs = nil
+	g.compute = nil
+	g.availabilityZones = nil
+}
+
+// This parameters should include non sensitive information to identify a provider
+func (g *Google) Parameters() map[string]string {
+	p := map[string]string{
+		"name":          g.Name(),
+		"cloud":         g.Cloud(),
+		"project":       g.conf.GCP.Project,
+		"public_zone":   g.conf.GCP.PublicZone,
+		"bucket_prefix": g.conf.GCP.BucketPrefix,
+	}
+	if g.conf.GCP.Credentials != "" {
+		p["credentials"] = g.conf.GCP.Credentials
+	}
+	return p
+}
+
+func (g *Google) String() string {
+	return fmt.Sprintf("%s[%s]", g.Cloud(), g.Name())
+}
+
+func (g *Google) Project() string {
+	return g.conf.GCP.Project
+}
+
+func (g *Google) GCS() (GCS, error) {
+	if g.gcs == nil {
+		gcs, err := newGCSClient(g.conf.GCP.Credentials)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Google Cloud Storage client: %s", err)
+		}
+		g.gcs = gcs
+	}
+	return g.gcs, nil
+}
+
+func (g *Google) Compute() (Compute, error) {
+	if g.compute == nil {
+		client, err := compute.New(g.conf.GCP.Credentials, compute.ScopeCompute)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Google Compute Engine client: %s", err)
+		}
+		g.compute = client
+	}
+	return g.compute, nil
+}
+
+func (g *Google) ListRegions() ([]string, error) {
+	svc, err := g.Compute()
+	if err != nil {
+		return []string{}, err
+	}
+
+	regions, err := svc.Regions(g.Project())
+	if err != nil {
+		return []string{}, err
+	}
+
+	sort.Strings(regions)
+
+	return regions, nil
+}
+
+func (g *Google) AskEnvironmentLocation(init interfaces.Initialize) (location string, err error) {
+	regions, err := g.ListRegions()
+	if err != nil {
+		return "", err
+	}
+
+	regionPos, err := init.Input().AskSelection(&input.AskSelection{
+		Query:   "In which region should this environment reside?",
+		Choices: regions,
+		Default: -1,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return regions[regionPos], nil
+}
+
+func (g *Google) AskInstancePoolZones(init interfaces.Initialize) (zones []string, err error) {
+
+	zones, err = g.getZonesByRegion()
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to get zones: %v", err)
+	}
+
+	if len(zones) == 0 {
+		return []string{}, fmt.Errorf("no zones found for region '%s'", g.Region())
+	}
+
+	sChoices := make([]bool, len(zones))
+	sChoices[0] = true
+
+	multiSel := &input.AskMultipleSelection{
+		AskSelection: &input.AskSelection{
+			Query:   "Please select zones. Enter numbers to toggle selection.",
+			Choices: zones,
+			Default: 1,
+		},
+		SelectedChoices: sChoices,
+		MinSelected:     1,
+		MaxSelected:     len(zones),
+	}
+
+	return init.Input().AskMultipleSelection(multiSel)
+}
+
+func (g *Google) Region() string {
+	// without environment selected, fall back to default region
+	if g.tarmak.Environment() == nil {
+		return "us-central1"
+	}
+	return g.tarmak.Environment().Location()
+}
+
+// This return the zones that are used for a cluster
+func (g *Google) AvailabilityZones() (availabiltyZones []string) {
+	if g.availabilityZones != nil {
+		return *g.availabilityZones
+	}
+
+	subnets := g.tarmak.Cluster().Subnets()
+	zones := make(map[string]bool)
+
+	for _, subnet := range subnets {
+		zones[subnet.Zone] = true
+	}
+
+	g.availabilityZones = &availabiltyZones
+
+	for zone, _ := range zones {
+		availabiltyZones = append(availabiltyZones, zone)
+	}
+
+	sort.Strings(availabiltyZones)
+
+	return availabiltyZones
+}
+
+func (g *Google) Variables() map[string]interface{} {
+	output := map[string]interface{}{}
+	output["google_project"] = g.Project()
+	output["availability_zones"] = g.AvailabilityZones()
+	output["region"] = g.Region()
+
+	output["public_zone"] = g.conf.GCP.PublicZone
+	output["public_managed_zone"] = g.conf.GCP.PublicManagedZone
+	output["bucket_prefix"] = g.conf.GCP.BucketPrefix
+
+	return output
+}
+
+// This will return necessary environment variables
+func (g *Google) Environment() ([]string, error) {
+	env := []string{
+		fmt.Sprintf("GOOGLE_PROJECT=%s", g.Project()),
+		fmt.Sprintf("GOOGLE_REGION=%s", g.Region()),
+	}
+
+	if g.conf.GCP.Credentials != "" {
+		env = append(env, fmt.Sprintf("GOOGLE_APPLICATION_CREDENTIALS=%s", g.conf.GCP.Credentials))
+	}
+
+	return env, nil
+}
+
+func (g *Google) Validate() error {
+	var result *multierror.Error
+
+	if g.conf.GCP.Project == "" {
+		result = multierror.Append(result, fmt.Errorf("no google project specified for provider '%s'", g.Name()))
+	}
+
+	if g.conf.GCP.PublicZone != "" && g.conf.GCP.PublicManagedZone == "" {
+		result = multierror.Append(result, fmt.Errorf("public zone '%s' needs the name of its managed zone", g.conf.GCP.PublicZone))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (g *Google) Verify() error {
+	var result *multierror.Error
+
+	// If this fails we don't want to verify any of the other steps as they will have the same error
+	if err := g.verifyCredentials(); err != nil {
+		return err
+	}
+
+	// These checks only make sense with an environment given
+	if g.tarmak.Environment() != nil {
+		if err := g.verifyZones(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if err := g.verifyPublicZone(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	// if no cluster exists (i.e. tarmak init has not yet been run), skip this verification check
+	if g.tarmak.Cluster() != nil {
+		if err := g.verifyInstanceTypes(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (g *Google) EnsureRemoteResources() error {
+	if g.tarmak.Environment() == nil {
+		return nil
+	}
+
+	return g.ensureRemoteStateBucket()
+}
+
+func (g *Google) Remove() error {
+	var result *multierror.Error
+
+	if err := g.deleteRemoteStateObjects(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	empty, err := g.bucketEmpty()
+	if err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if empty {
+		if err := g.deleteRemoteStateBucket(); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	return result.ErrorOrNil()
+}
+
+// Check if Google credentials are setup correctly, by querying the regions
+// of the project
+func (g *Google) verifyCredentials() error {
+	svc, err := g.Compute()
+	if err != nil {
+		return err
+	}
+
+	if _, err := svc.Regions(g.Project()); err != nil {
+		return fmt.Errorf("there was a problem with veryfing your Google credentials: %s", err)
+	}
+
+	return nil
+}
+
+func (g *Google) getZonesByRegion() ([]string, error) {
+	svc, err := g.Compute()
+	if err != nil {
+		return []string{}, err
+	}
+
+	zones, err := svc.Zones(g.Project(), g.Region())
+	if err != nil {
+		return []string{}, err
+	}
+
+	sort.Strings(zones)
+
+	return zones, nil
+}
+
+func (g *Google) verifyZones() error {
+	var result error
+
+	zones, err := g.getZonesByRegion()
+	if err != nil {
+		return err
+	}
+
+	if len(zones) == 0 {
+		return fmt.Errorf(
+			"no zone found for region '%s'",
+			g.Region(),
+		)
+	}
+
+	availabilityZones := g.AvailabilityZones()
+
+	for _, zoneConfigured := range availabilityZones {
+		found := false
+		for _, zone := range zones {
+			if zone != "" && zone == zoneConfigured {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = multierror.Append(result, fmt.Errorf(
+				"specified invalid zone '%s' for region '%s'",
+				zoneConfigured,
+				g.Region(),
+			))
+		}
+	}
+	if result != nil {
+		return result
+	}
+
+	if len(availabilityZones) == 0 {
+		zone := zones[0]
+		g.log.Debugf("no zones specified selecting zone: %s", zone)
+		availabilityZones = []string{zone}
+		g.availabilityZones = &availabilityZones
+	}
+
+	return nil
+}
+
+func (g *Google) verifyPublicZone() error {
+	if g.conf.GCP.PublicManagedZone == "" {
+		return nil
+	}
+
+	svc, err := g.Compute()
+	if err != nil {
+		return err
+	}
+
+	dnsName, err := svc.ManagedZoneDNSName(g.Project(), g.conf.GCP.PublicManagedZone)
+	if err != nil {
+		return fmt.Errorf("error looking up managed zone '%s': %s", g.conf.GCP.PublicManagedZone, err)
+	}
+
+	if expected := fmt.Sprintf("%s.", g.conf.GCP.PublicZone); dnsName != expected {
+		return fmt.Errorf("managed zone '%s' serves '%s', expected '%s'", g.conf.GCP.PublicManagedZone, dnsName, expected)
+	}
+
+	return nil
+}
+
+func (g *Google) verifyInstanceTypes() error {
+	var result error
+
+	svc, err := g.Compute()
+	if err != nil {
+		return err
+	}
+
+	verified := make(map[string]bool)
+	for _, instancePool := range g.tarmak.Cluster().InstancePools() {
+		instanceTypes := []string{instancePool.InstanceType()}
+		if spot := instancePool.Config().Spot; spot != nil {
+			for _, size := range spot.Sizes {
+				instanceType, err := g.InstanceType(size)
+				if err != nil {
+					return err
+				}
+				instanceTypes = append(instanceTypes, instanceType)
+			}
+		}
+
+		for _, instanceType := range instanceTypes {
+			if verified[instanceType] {
+				continue
+			}
+			verified[instanceType] = true
+
+			for _, zone := range g.AvailabilityZones() {
+				available, err := svc.MachineTypeAvailable(g.Project(), zone, instanceType)
+				if err != nil {
+					return fmt.Errorf("error reaching google to verify machine type %s: %v", instanceType, err)
+				}
+				if !available {
+					result = multierror.Append(result, fmt.Errorf("type %s is not available in zone %s", instanceType, zone))
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// This methods converts and possibly validates a generic instance type to a
+// provider specifc
+func (g *Google) InstanceType(typeIn string) (typeOut string, err error) {
+	if typeIn == clusterv1alpha1.InstancePoolSizeTiny {
+		return "g1-small", nil
+	}
+	if typeIn == clusterv1alpha1.InstancePoolSizeSmall {
+		return "n1-standard-1", nil
+	}
+	if typeIn == clusterv1alpha1.InstancePoolSizeMedium {
+		return "n1-standard-2", nil
+	}
+	if typeIn == clusterv1alpha1.InstancePoolSizeLarge {
+		return "n1-standard-4", nil
+	}
+
+	// custom instance types are verified against the zones by
+	// verifyInstanceTypes
+	return typeIn, nil
+}
+
+// This methods converts and possibly validates a generic volume type to a
+// provider specifc
+func (g *Google) VolumeType(typeIn string) (typeOut string, err error) {
+	if typeIn == clusterv1alpha1.VolumeTypeHDD {
+		return "pd-standard", nil
+	}
+	if typeIn == clusterv1alpha1.VolumeTypeSSD {
+		return "pd-ssd", nil
+	}
+	return typeIn, nil
+}
+
+func (g *Google) PublicZone() string {
+	return g.conf.GCP.PublicZone
+}
@@ -0,0 +1,392 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/google/compute"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/mocks"
+)
+
+type fakeGoogle struct {
+	*Google
+	ctrl *gomock.Controller
+
+	fakeGCS         *mocks.MockGCS
+	fakeCompute     *mocks.MockCompute
+	fakeEnvironment *mocks.MockEnvironment
+	fakeCluster     *mocks.MockCluster
+	fakeTarmak      *mocks.MockTarmak
+}
+
+func newFakeGoogle(t *testing.T) *fakeGoogle {
+
+	f := &fakeGoogle{
+		ctrl: gomock.NewController(t),
+		Google: &Google{
+			conf: &tarmakv1alpha1.Provider{
+				GCP: &tarmakv1alpha1.ProviderGCP{
+					Project:      "my-project",
+					BucketPrefix: "prefix-",
+				},
+			},
+			log: logrus.WithField("test", true),
+		},
+	}
+	f.fakeGCS = mocks.NewMockGCS(f.ctrl)
+	f.fakeCompute = mocks.NewMockCompute(f.ctrl)
+	f.fakeEnvironment = mocks.NewMockEnvironment(f.ctrl)
+	f.fakeCluster = mocks.NewMockCluster(f.ctrl)
+	f.fakeTarmak = mocks.NewMockTarmak(f.ctrl)
+	f.Google.gcs = f.fakeGCS
+	f.Google.compute = f.fakeCompute
+	f.Google.tarmak = f.fakeTarmak
+	f.fakeTarmak.EXPECT().Cluster().AnyTimes().Return(f.fakeCluster)
+	f.fakeTarmak.EXPECT().Environment().AnyTimes().Return(f.fakeEnvironment)
+	f.fakeCluster.EXPECT().Environment().AnyTimes().Return(f.fakeEnvironment)
+	f.fakeEnvironment.EXPECT().Location().AnyTimes().Return("europe-west1")
+	f.fakeEnvironment.EXPECT().Name().AnyTimes().Return("env")
+
+	return f
+}
+
+func TestGoogle_verifyZonesNoneGiven(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	g.fakeCluster.EXPECT().Subnets().Return([]clusterv1alpha1.Subnet{}).MinTimes(1)
+	g.fakeCompute.EXPECT().Zones("my-project", "europe-west1").Return([]string{
+		"europe-west1-d",
+		"europe-west1-b",
+		"europe-west1-c",
+	}, nil)
+
+	if err := g.verifyZones(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if act, exp := g.AvailabilityZones(), []string{"europe-west1-b"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected zones: act=%+v exp=%+v", act, exp)
+	}
+}
+
+func TestGoogle_verifyZonesInvalid(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	g.fakeCluster.EXPECT().Subnets().Return([]clusterv1alpha1.Subnet{
+		clusterv1alpha1.Subnet{Zone: "europe-west1-b"},
+		clusterv1alpha1.Subnet{Zone: "europe-west1-a"},
+	}).MinTimes(1)
+	g.fakeCompute.EXPECT().Zones("my-project", "europe-west1").Return([]string{
+		"europe-west1-b",
+		"europe-west1-c",
+	}, nil)
+
+	err := g.verifyZones()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "specified invalid zone 'europe-west1-a' for region 'europe-west1'") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestGoogle_verifyInstanceTypes(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	g.availabilityZones = &[]string{"europe-west1-b", "europe-west1-c"}
+
+	instancePool := mocks.NewMockInstancePool(g.ctrl)
+	instancePool.EXPECT().InstanceType().Return("n1-highmem-8")
+	instancePool.EXPECT().Config().Return(&clusterv1alpha1.InstancePool{
+		Size: clusterv1alpha1.InstancePoolSizeSmall,
+		Spot: &clusterv1alpha1.InstancePoolSpot{
+			Sizes: []string{clusterv1alpha1.InstancePoolSizeMedium},
+		},
+	})
+	g.fakeCluster.EXPECT().InstancePools().Return([]interfaces.InstancePool{instancePool})
+
+	g.fakeCompute.EXPECT().MachineTypeAvailable("my-project", "europe-west1-b", "n1-highmem-8").Return(true, nil)
+	g.fakeCompute.EXPECT().MachineTypeAvailable("my-project", "europe-west1-c", "n1-highmem-8").Return(false, nil)
+	g.fakeCompute.EXPECT().MachineTypeAvailable("my-project", "europe-west1-b", "n1-standard-2").Return(true, nil)
+	g.fakeCompute.EXPECT().MachineTypeAvailable("my-project", "europe-west1-c", "n1-standard-2").Return(true, nil)
+
+	err := g.verifyInstanceTypes()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "type n1-highmem-8 is not available in zone europe-west1-c") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestGoogle_InstanceType(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	for _, c := range []struct {
+		in, out string
+	}{
+		{clusterv1alpha1.InstancePoolSizeTiny, "g1-small"},
+		{clusterv1alpha1.InstancePoolSizeSmall, "n1-standard-1"},
+		{clusterv1alpha1.InstancePoolSizeMedium, "n1-standard-2"},
+		{clusterv1alpha1.InstancePoolSizeLarge, "n1-standard-4"},
+		{"n1-highmem-8", "n1-highmem-8"},
+	} {
+		out, err := g.InstanceType(c.in)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if out != c.out {
+			t.Errorf("unexpected instance type for %s: act=%s exp=%s", c.in, out, c.out)
+		}
+	}
+
+	for _, c := range []struct {
+		in, out string
+	}{
+		{clusterv1alpha1.VolumeTypeHDD, "pd-standard"},
+		{clusterv1alpha1.VolumeTypeSSD, "pd-ssd"},
+	} {
+		out, err := g.VolumeType(c.in)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if out != c.out {
+			t.Errorf("unexpected volume type for %s: act=%s exp=%s", c.in, out, c.out)
+		}
+	}
+}
+
+func TestGoogle_ListHosts(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	g.fakeCluster.EXPECT().ClusterName().AnyTimes().Return("env-cluster")
+	g.fakeEnvironment.EXPECT().HubName().AnyTimes().Return("env-hub")
+
+	g.fakeCompute.EXPECT().Instances("my-project", map[string]string{
+		labelEnvironment: "env",
+	}).Return([]*compute.Instance{
+		&compute.Instance{
+			Name:      "bastion-abcd",
+			Status:    "RUNNING",
+			PrivateIP: "10.0.0.2",
+			PublicIP:  "1.2.3.4",
+			Labels:    map[string]string{labelCluster: "env-hub", labelRole: "bastion"},
+		},
+		&compute.Instance{
+			Name:      "worker-b",
+			Status:    "RUNNING",
+			PrivateIP: "10.0.1.3",
+			Labels:    map[string]string{labelCluster: "env-cluster"},
+			Metadata:  map[string]string{metadataRoles: "worker"},
+		},
+		&compute.Instance{
+			Name:      "worker-a",
+			Status:    "RUNNING",
+			PrivateIP: "10.0.1.2",
+			Labels:    map[string]string{labelCluster: "env-cluster", labelRole: "worker"},
+		},
+		&compute.Instance{
+			Name:      "other-cluster",
+			Status:    "RUNNING",
+			PrivateIP: "10.0.1.4",
+			Labels:    map[string]string{labelCluster: "env-other", labelRole: "worker"},
+		},
+		&compute.Instance{
+			Name:      "stopped",
+			Status:    "TERMINATED",
+			PrivateIP: "10.0.1.5",
+			Labels:    map[string]string{labelCluster: "env-cluster", labelRole: "worker"},
+		},
+		&compute.Instance{
+			Name:      "no-role",
+			Status:    "RUNNING",
+			PrivateIP: "10.0.1.6",
+			Labels:    map[string]string{labelCluster: "env-cluster"},
+		},
+	}, nil)
+
+	hosts, err := g.ListHosts(g.fakeCluster)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	act := map[string][]string{}
+	for _, h := range hosts {
+		act[h.ID()] = append(h.Aliases(), h.Hostname())
+	}
+
+	exp := map[string][]string{
+		"bastion-abcd": []string{"bastion", "1.2.3.4"},
+		"worker-a":     []string{"worker-1", "10.0.1.2"},
+		"worker-b":     []string{"worker-2", "10.0.1.3"},
+	}
+
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected hosts: act=%+v exp=%+v", act, exp)
+	}
+}
+
+func TestGoogle_QueryImages(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	g.fakeCompute.EXPECT().Images("my-project", map[string]string{
+		tarmakv1alpha1.ImageTagEnvironment:       "env",
+		tarmakv1alpha1.ImageTagKubernetesVersion: "1-10-6",
+	}).Return([]*compute.Image{
+		&compute.Image{
+			Name:              "tarmak-centos-123",
+			CreationTimestamp: "2018-06-01T10:11:12.123-07:00",
+			Labels: map[string]string{
+				tarmakv1alpha1.ImageTagEnvironment:   "env",
+				tarmakv1alpha1.ImageTagBaseImageName: "centos-puppet-agent",
+			},
+		},
+	}, nil)
+
+	images, err := g.QueryImages(map[string]string{
+		tarmakv1alpha1.ImageTagEnvironment:       "env",
+		tarmakv1alpha1.ImageTagKubernetesVersion: "1.10.6",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(images) != 1 {
+		t.Fatalf("unexpected number of images: %d", len(images))
+	}
+
+	if act, exp := images[0].BaseImage, "centos-puppet-agent"; act != exp {
+		t.Errorf("unexpected base image: act=%s exp=%s", act, exp)
+	}
+	if act, exp := images[0].Location, "europe-west1"; act != exp {
+		t.Errorf("unexpected location: act=%s exp=%s", act, exp)
+	}
+	if images[0].CreationTimestamp.IsZero() {
+		t.Error("expected creation timestamp to be set")
+	}
+}
+
+func TestGoogle_UploadConfiguration(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	g.fakeCluster.EXPECT().ClusterName().AnyTimes().Return("env-cluster")
+
+	content := []byte("puppet-tar-gz")
+	bucket := "prefix-env-europe-west1-secrets"
+
+	expectContent := func(exp []byte) func(string, string, io.Reader) error {
+		return func(_, _ string, body io.Reader) error {
+			act, err := ioutil.ReadAll(body)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(act, exp) {
+				t.Errorf("unexpected content: act=%s exp=%s", act, exp)
+			}
+			return nil
+		}
+	}
+
+	gomock.InOrder(
+		g.fakeGCS.EXPECT().PutObject(bucket, "env-cluster/puppet.tar.gz", gomock.Any()).DoAndReturn(expectContent(content)),
+		g.fakeGCS.EXPECT().PutObject(bucket, "env-cluster/puppet-manifests/abcd-puppet.tar.gz", gomock.Any()).DoAndReturn(expectContent(content)),
+		g.fakeGCS.EXPECT().PutObject(bucket, "env-cluster/puppet-manifests/latest-puppet-hash", gomock.Any()).DoAndReturn(expectContent([]byte("abcd"))),
+	)
+
+	if err := g.UploadConfiguration(g.fakeCluster, bytes.NewReader(content), "abcd"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestGoogle_RemoteState(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	state := g.RemoteState("env", "cluster", "kubernetes")
+	for _, exp := range []string{
+		`backend "gcs"`,
+		`bucket = "prefix-europe-west1-terraform-state"`,
+		`prefix = "env/cluster"`,
+		`project = "my-project"`,
+	} {
+		if !strings.Contains(state, exp) {
+			t.Errorf("expected remote state to contain '%s': %s", exp, state)
+		}
+	}
+}
+
+func TestGoogle_ensureRemoteStateBucket(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	gomock.InOrder(
+		g.fakeGCS.EXPECT().BucketExists("prefix-europe-west1-terraform-state").Return(false, nil),
+		g.fakeGCS.EXPECT().CreateBucket("my-project", "prefix-europe-west1-terraform-state", "europe-west1").Return(nil),
+	)
+
+	if err := g.ensureRemoteStateBucket(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestGoogle_Remove(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	bucket := "prefix-europe-west1-terraform-state"
+	g.fakeCluster.EXPECT().Name().AnyTimes().Return("cluster")
+
+	gomock.InOrder(
+		g.fakeGCS.EXPECT().ListObjectGenerations(bucket, "env/cluster/").Return(map[string][]int64{
+			"env/cluster/kubernetes.tfstate": {1, 2},
+			"env/cluster/kubernetes.tflock":  {3},
+			"env/cluster/puppet.tar.gz":      {4},
+		}, nil),
+		g.fakeGCS.EXPECT().ListObjectGenerations(bucket, "").Return(map[string][]int64{}, nil),
+		g.fakeGCS.EXPECT().DeleteBucket(bucket).Return(nil),
+	)
+	g.fakeGCS.EXPECT().DeleteObjectGeneration(bucket, "env/cluster/kubernetes.tfstate", int64(1)).Return(nil)
+	g.fakeGCS.EXPECT().DeleteObjectGeneration(bucket, "env/cluster/kubernetes.tfstate", int64(2)).Return(nil)
+	g.fakeGCS.EXPECT().DeleteObjectGeneration(bucket, "env/cluster/kubernetes.tflock", int64(3)).Return(nil)
+
+	if err := g.Remove(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestGoogle_bucketEmpty_noncurrent(t *testing.T) {
+	g := newFakeGoogle(t)
+	defer g.ctrl.Finish()
+
+	g.fakeGCS.EXPECT().ListObjectGenerations("prefix-europe-west1-terraform-state", "").Return(map[string][]int64{
+		"env/cluster/kubernetes.tfstate": {1},
+	}, nil)
+
+	empty, err := g.bucketEmpty()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if empty {
+		t.Error("expected bucket with noncurrent generations not to be empty")
+	}
+}
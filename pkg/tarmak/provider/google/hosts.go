@@ -0,0 +1,220 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+const (
+	labelEnvironment = "tarmak_environment"
+	labelCluster     = "tarmak_cluster"
+	labelRole        = "tarmak_role"
+	labelPool        = "tarmak_instance_pool"
+
+	// label values are limited to [a-z0-9_-], so lists of roles and ssh host
+	// keys are stored in the instance metadata
+	metadataRoles       = "tarmak-roles"
+	metadataSSHHostKeys = "tarmak-ssh-host-keys"
+)
+
+type host struct {
+	id             string
+	zone           string
+	host           string
+	hostnamePublic bool
+	hostname       string
+	aliases        []string
+	roles          []string
+	instancePool   string
+	user           string
+	sshHostKeys    string
+
+	cluster interfaces.Cluster
+}
+
+var _ interfaces.Host = &host{}
+
+func (h *host) ID() string {
+	return h.id
+}
+
+func (h *host) Roles() []string {
+	return h.roles
+}
+
+func (h *host) InstancePool() string {
+	return h.instancePool
+}
+
+func (h *host) Aliases() []string {
+	return h.aliases
+}
+
+func (h *host) Hostname() string {
+	return h.hostname
+}
+
+func (h *host) HostnamePublic() bool {
+	return h.hostnamePublic
+}
+
+func (h *host) User() string {
+	return h.user
+}
+
+func (h *host) Parameters() map[string]string {
+	return map[string]string{
+		"id":       h.ID(),
+		"hostname": h.Hostname(),
+		"roles":    strings.Join(h.Roles(), ", "),
+	}
+}
+
+func (h *host) SSHHostPublicKeys() ([]ssh.PublicKey, error) {
+	var hostKeys []ssh.PublicKey
+
+	for _, line := range strings.Split(h.sshHostKeys, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			h.cluster.Log().Warnf(
+				"failed to parse public keys from metadata '%s' of host '%s': %v",
+				metadataSSHHostKeys,
+				h.Aliases(),
+				err,
+			)
+			continue
+		}
+		hostKeys = append(hostKeys, hostKey)
+	}
+
+	return hostKeys, nil
+}
+
+func (h *host) SSHConfig(strictChecking string) string {
+	return utils.HostSSHConfig(h, h.HostnamePublic(), h.cluster, strictChecking)
+}
+
+func (g *Google) ListHosts(c interfaces.Cluster) ([]interfaces.Host, error) {
+	svc, err := g.Compute()
+	if err != nil {
+		return []interfaces.Host{}, err
+	}
+
+	instances, err := svc.Instances(g.Project(), map[string]string{
+		labelEnvironment: c.Environment().Name(),
+	})
+	if err != nil {
+		return []interfaces.Host{}, err
+	}
+
+	hosts := []*host{}
+
+	for _, instance := range instances {
+		if instance.PrivateIP == "" || instance.Name == "" {
+			continue
+		}
+
+		if instance.Status != "RUNNING" && instance.Status != "PROVISIONING" && instance.Status != "STAGING" {
+			continue
+		}
+
+		// skip if instance is not from the hub or current cluster
+		if cluster := instance.Labels[labelCluster]; cluster != c.ClusterName() && cluster != c.Environment().HubName() {
+			continue
+		}
+
+		host := &host{
+			id:             instance.Name,
+			zone:           instance.Zone,
+			hostname:       instance.PrivateIP,
+			hostnamePublic: false,
+			user:           "centos",
+			cluster:        g.tarmak.Cluster(),
+			instancePool:   instance.Labels[labelPool],
+			sshHostKeys:    instance.Metadata[metadataSSHHostKeys],
+		}
+		if instance.PublicIP != "" {
+			host.hostname = instance.PublicIP
+			host.hostnamePublic = true
+		}
+
+		if roles, ok := instance.Metadata[metadataRoles]; ok && roles != "" {
+			host.roles = strings.Split(roles, ",")
+		} else if role, ok := instance.Labels[labelRole]; ok && role != "" {
+			host.roles = []string{role}
+		}
+
+		// skip non-tarmak instances
+		if len(host.roles) == 0 {
+			continue
+		}
+
+		hosts = append(hosts, host)
+	}
+
+	// make sure aliases are stable across calls
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].id < hosts[j].id })
+
+	hostsByRole := map[string][]*host{}
+	for _, h := range hosts {
+		for _, role := range h.roles {
+			hostsByRole[role] = append(hostsByRole[role], h)
+			h.aliases = append(h.aliases, fmt.Sprintf("%s-%d", role, len(hostsByRole[role])))
+		}
+	}
+
+	// remove role-1 for single instances
+	for role, hosts := range hostsByRole {
+		if len(hosts) != 1 {
+			continue
+		}
+		for pos, _ := range hosts[0].aliases {
+			if hosts[0].aliases[pos] == fmt.Sprintf("%s-1", role) {
+				hosts[0].aliases[pos] = role
+			}
+		}
+	}
+
+	hostsInterfaces := make([]interfaces.Host, len(hosts))
+
+	for pos, _ := range hosts {
+		hostsInterfaces[pos] = hosts[pos]
+	}
+
+	return hostsInterfaces, nil
+}
+
+// TerminateHosts deletes the instances of the given hosts, instances of
+// managed instance groups get recreated by their group, others by the next
+// terraform apply
+func (g *Google) TerminateHosts(hosts []interfaces.Host) error {
+	svc, err := g.Compute()
+	if err != nil {
+		return err
+	}
+
+	for _, h := range hosts {
+		gh, ok := h.(*host)
+		if !ok {
+			return fmt.Errorf("host '%s' is not a google host", h.ID())
+		}
+
+		if err := svc.DeleteInstance(g.Project(), gh.zone, gh.id); err != nil {
+			return fmt.Errorf("error deleting instance '%s': %s", gh.id, err)
+		}
+	}
+
+	return nil
+}
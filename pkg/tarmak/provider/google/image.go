@@ -0,0 +1,88 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/google/compute"
+)
+
+var invalidLabelChars = regexp.MustCompile("[^a-z0-9_-]")
+
+// labelValue converts a tag value into a valid Compute Engine label value
+func labelValue(value string) string {
+	return invalidLabelChars.ReplaceAllString(strings.ToLower(value), "-")
+}
+
+func (g *Google) DefaultImage(version string) (*tarmakv1alpha1.Image, error) {
+	return nil, fmt.Errorf(
+		"there are no pre-built images for %s %s, images need to be available in project '%s' with label %s",
+		g.Cloud(),
+		version,
+		g.Project(),
+		tarmakv1alpha1.ImageTagBaseImageName,
+	)
+}
+
+func (g *Google) QueryImages(tags map[string]string) (images []*tarmakv1alpha1.Image, err error) {
+	svc, err := g.Compute()
+	if err != nil {
+		return images, err
+	}
+
+	labels := make(map[string]string, len(tags))
+	for key, value := range tags {
+		labels[key] = labelValue(value)
+	}
+
+	computeImages, err := svc.Images(g.Project(), labels)
+	if err != nil {
+		return images, err
+	}
+
+	for _, computeImage := range computeImages {
+		image, err := g.imageFromComputeImage(computeImage)
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+func (g *Google) imageFromComputeImage(computeImage *compute.Image) (*tarmakv1alpha1.Image, error) {
+	image := &tarmakv1alpha1.Image{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: make(map[string]string),
+		},
+	}
+
+	// copy over labels from the image to image annotations
+	for key, value := range computeImage.Labels {
+		image.Annotations[key] = value
+		// copy over base image name from labels
+		if key == tarmakv1alpha1.ImageTagBaseImageName {
+			image.BaseImage = value
+		}
+	}
+
+	creationTimestamp, err := time.Parse(time.RFC3339, computeImage.CreationTimestamp)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing time stamp '%s'", err)
+	}
+
+	image.CreationTimestamp.Time = creationTimestamp
+	image.Name = computeImage.Name
+	image.Location = g.Region()
+	image.Encrypted = computeImage.Encrypted
+
+	return image, nil
+}
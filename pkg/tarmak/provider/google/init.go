@@ -0,0 +1,115 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"fmt"
+
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
+)
+
+func Init(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	if provider.GCP == nil {
+		provider.GCP = &tarmakv1alpha1.ProviderGCP{}
+	}
+
+	err := initProject(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initCredentials(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initBucketPrefix(in, provider)
+	if err != nil {
+		return err
+	}
+
+	err = initPublicZone(in, provider)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func initProject(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	project, err := in.AskOpen(&input.AskOpen{
+		Query: "Which Google Cloud project should be used?",
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.GCP.Project = project
+	return nil
+}
+
+func initCredentials(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	credentials, err := in.AskOpen(&input.AskOpen{
+		Query:      "Which service account key file should be used? (leave empty for application default credentials)",
+		AllowEmpty: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	provider.GCP.Credentials = credentials
+	return nil
+}
+
+func initBucketPrefix(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	for {
+		bucketPrefix, err := in.AskOpen(&input.AskOpen{
+			Query:   "Which prefix should be used for the state buckets? ([a-z0-9-]+, should be globally unique)",
+			Default: fmt.Sprintf("%s-tarmak-", provider.Name),
+		})
+		if err != nil {
+			return err
+		}
+
+		nameValid := input.RegexpProviderName.MatchString(bucketPrefix)
+
+		if !nameValid {
+			in.Warnf("bucket prefix '%s' is not valid", bucketPrefix)
+		} else {
+			provider.GCP.BucketPrefix = bucketPrefix
+			break
+		}
+	}
+
+	return nil
+}
+
+func initPublicZone(in *input.Input, provider *tarmakv1alpha1.Provider) error {
+	for {
+		publicZone, err := in.AskOpen(&input.AskOpen{
+			Query: "Which public DNS zone should be used? (the zone needs to be served by a Cloud DNS managed zone)",
+		})
+		if err != nil {
+			return err
+		}
+
+		zoneValid := input.RegexpDNS.MatchString(publicZone)
+
+		if !zoneValid {
+			in.Warnf("Public DNS zone '%s' is not valid", publicZone)
+		} else {
+			provider.GCP.PublicZone = publicZone
+			break
+		}
+	}
+
+	managedZone, err := in.AskOpen(&input.AskOpen{
+		Query: fmt.Sprintf("What is the name of the managed zone serving '%s'?", provider.GCP.PublicZone),
+	})
+	if err != nil {
+		return err
+	}
+	provider.GCP.PublicManagedZone = managedZone
+
+	return nil
+}
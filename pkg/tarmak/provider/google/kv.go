@@ -0,0 +1,101 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"bytes"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/jetstack/vault-unsealer/pkg/kv"
+)
+
+// gcsKV stores the vault unseal keys as objects in a GCS bucket, which is
+// encrypted using the default Cloud KMS key of the bucket
+type gcsKV struct {
+	gcs    GCS
+	bucket string
+	prefix string
+}
+
+var _ kv.Service = &gcsKV{}
+
+func (k *gcsKV) key(key string) string {
+	return fmt.Sprintf("%s%s", k.prefix, key)
+}
+
+func (k *gcsKV) Set(key string, value []byte) error {
+	if err := k.gcs.PutObject(k.bucket, k.key(key), bytes.NewReader(value)); err != nil {
+		return fmt.Errorf("error setting key '%s' in bucket '%s': %s", k.key(key), k.bucket, err)
+	}
+	return nil
+}
+
+func (k *gcsKV) Get(key string) ([]byte, error) {
+	value, err := k.gcs.GetObject(k.bucket, k.key(key))
+	if err == storage.ErrObjectNotExist {
+		return nil, kv.NewNotFoundError("key '%s' not found in bucket '%s'", k.key(key), k.bucket)
+	} else if err != nil {
+		return nil, fmt.Errorf("error getting key '%s' from bucket '%s': %s", k.key(key), k.bucket, err)
+	}
+	return value, nil
+}
+
+func (k *gcsKV) Test(key string) error {
+	keys, err := k.gcs.ListObjects(k.bucket, k.key(key))
+	if err != nil {
+		return fmt.Errorf("error testing bucket '%s': %s", k.bucket, err)
+	}
+	for _, existing := range keys {
+		if existing == k.key(key) {
+			return nil
+		}
+	}
+	return kv.NewNotFoundError("key '%s' not found in bucket '%s'", k.key(key), k.bucket)
+}
+
+func (g *Google) hubOutputString(key string) (string, error) {
+	output, err := g.tarmak.Cluster().Environment().Hub().TerraformOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting hub terraform output: %s", err)
+	}
+
+	valueIntf, ok := output[key]
+	if !ok {
+		return "", fmt.Errorf("error could not find '%s' in terraform state output", key)
+	}
+
+	value, ok := valueIntf.(string)
+	if !ok {
+		return "", fmt.Errorf("error unexpected type for '%s': %T", key, valueIntf)
+	}
+
+	return value, nil
+}
+
+func (g *Google) VaultKV() (kv.Service, error) {
+	bucket, err := g.hubOutputString("vault_kms_key_id")
+	if err != nil {
+		return nil, err
+	}
+
+	unsealKeyName, err := g.hubOutputString("vault_unseal_key_name")
+	if err != nil {
+		return nil, err
+	}
+
+	return g.VaultKVWithParams(bucket, unsealKeyName)
+}
+
+// On Google the key ID references the bucket the unseal keys are stored in
+func (g *Google) VaultKVWithParams(bucket, unsealKeyName string) (kv.Service, error) {
+	svc, err := g.GCS()
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcsKV{
+		gcs:    svc,
+		bucket: bucket,
+		prefix: unsealKeyName,
+	}, nil
+}
@@ -0,0 +1,129 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// gcsClient implements the GCS interface on top of the Google Cloud Storage
+// client
+type gcsClient struct {
+	client *storage.Client
+}
+
+var _ GCS = &gcsClient{}
+
+func newGCSClient(credentials string) (*gcsClient, error) {
+	var opts []option.ClientOption
+	if credentials != "" {
+		opts = append(opts, option.WithCredentialsFile(credentials))
+	}
+
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcsClient{client: client}, nil
+}
+
+func (g *gcsClient) BucketExists(bucket string) (bool, error) {
+	_, err := g.client.Bucket(bucket).Attrs(context.Background())
+	if err == storage.ErrBucketNotExist {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (g *gcsClient) CreateBucket(project, bucket, location string) error {
+	return g.client.Bucket(bucket).Create(context.Background(), project, &storage.BucketAttrs{
+		Location:          location,
+		VersioningEnabled: true,
+	})
+}
+
+func (g *gcsClient) DeleteBucket(bucket string) error {
+	return g.client.Bucket(bucket).Delete(context.Background())
+}
+
+func (g *gcsClient) PutObject(bucket, key string, body io.Reader) error {
+	w := g.client.Bucket(bucket).Object(key).NewWriter(context.Background())
+	if _, err := io.Copy(w, body); err != nil {
+		w.Close()
+		return fmt.Errorf("error writing object gs://%s/%s: %s", bucket, key, err)
+	}
+	return w.Close()
+}
+
+func (g *gcsClient) GetObject(bucket, key string) ([]byte, error) {
+	r, err := g.client.Bucket(bucket).Object(key).NewReader(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
+
+func (g *gcsClient) DeleteObject(bucket, key string) error {
+	err := g.client.Bucket(bucket).Object(key).Delete(context.Background())
+	if err == storage.ErrObjectNotExist {
+		return nil
+	}
+	return err
+}
+
+func (g *gcsClient) ListObjects(bucket, prefix string) ([]string, error) {
+	var keys []string
+
+	it := g.client.Bucket(bucket).Objects(context.Background(), &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, attrs.Name)
+	}
+
+	return keys, nil
+}
+
+func (g *gcsClient) DeleteObjectGeneration(bucket, key string, generation int64) error {
+	err := g.client.Bucket(bucket).Object(key).Generation(generation).Delete(context.Background())
+	if err == storage.ErrObjectNotExist {
+		return nil
+	}
+	return err
+}
+
+// ListObjectGenerations returns the generations of the objects by key,
+// including noncurrent ones
+func (g *gcsClient) ListObjectGenerations(bucket, prefix string) (map[string][]int64, error) {
+	generations := map[string][]int64{}
+
+	it := g.client.Bucket(bucket).Objects(context.Background(), &storage.Query{Prefix: prefix, Versions: true})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		generations[attrs.Name] = append(generations[attrs.Name], attrs.Generation)
+	}
+
+	return generations, nil
+}
@@ -0,0 +1,129 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"fmt"
+	"path"
+)
+
+func (g *Google) RemoteStateName() string {
+	return fmt.Sprintf(
+		"%s%s-terraform-state",
+		g.conf.GCP.BucketPrefix,
+		g.Region(),
+	)
+}
+
+func (g *Google) RemoteStateBucketName() string {
+	return g.RemoteStateName()
+}
+
+// The gcs backend stores the state in <prefix>/<workspace>.tfstate
+func (g *Google) RemoteStatePrefix(namespace string, clusterName string) string {
+	return fmt.Sprintf("%s/%s", namespace, clusterName)
+}
+
+func (g *Google) LegacyPuppetTFName() string {
+	return "google_storage_bucket_object.legacy-puppet-tar-gz"
+}
+
+func (g *Google) RemoteState(namespace string, clusterName string, stackName string) string {
+	return fmt.Sprintf(`terraform {
+  backend "gcs" {
+    bucket = "%s"
+    prefix = "%s"
+    project = "%s"
+  }
+}`,
+		g.RemoteStateName(),
+		g.RemoteStatePrefix(namespace, clusterName),
+		g.Project(),
+	)
+}
+
+func (g *Google) RemoteStateBucketAvailable() (bool, error) {
+	svc, err := g.GCS()
+	if err != nil {
+		return false, err
+	}
+
+	exists, err := svc.BucketExists(g.RemoteStateName())
+	if err != nil {
+		return false, fmt.Errorf("error while checking if remote state is available: %s", err)
+	}
+
+	return exists, nil
+}
+
+func (g *Google) ensureRemoteStateBucket() error {
+	svc, err := g.GCS()
+	if err != nil {
+		return err
+	}
+
+	exists, err := svc.BucketExists(g.RemoteStateName())
+	if err != nil {
+		return fmt.Errorf("error looking for terraform state bucket: %s", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if err := svc.CreateBucket(g.Project(), g.RemoteStateName(), g.Region()); err != nil {
+		return fmt.Errorf("error creating terraform state bucket: %s", err)
+	}
+
+	return nil
+}
+
+func (g *Google) deleteRemoteStateObjects() error {
+	svc, err := g.GCS()
+	if err != nil {
+		return err
+	}
+
+	prefix := g.RemoteStatePrefix(g.tarmak.Environment().Name(), g.tarmak.Cluster().Name())
+	// the bucket is versioned, so every generation needs to be deleted
+	generations, err := svc.ListObjectGenerations(g.RemoteStateName(), fmt.Sprintf("%s/", prefix))
+	if err != nil {
+		return err
+	}
+
+	for key, keyGenerations := range generations {
+		if path.Ext(key) != ".tfstate" && path.Ext(key) != ".tflock" {
+			continue
+		}
+		for _, generation := range keyGenerations {
+			if err := svc.DeleteObjectGeneration(g.RemoteStateName(), key, generation); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *Google) bucketEmpty() (bool, error) {
+	svc, err := g.GCS()
+	if err != nil {
+		return false, err
+	}
+
+	// noncurrent generations prevent deleting the bucket as well
+	generations, err := svc.ListObjectGenerations(g.RemoteStateName(), "")
+	if err != nil {
+		return false, err
+	}
+
+	return len(generations) == 0, nil
+}
+
+func (g *Google) deleteRemoteStateBucket() error {
+	svc, err := g.GCS()
+	if err != nil {
+		return err
+	}
+
+	return svc.DeleteBucket(g.RemoteStateName())
+}
@@ -0,0 +1,71 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+// This uploads the main configuration to the GCS bucket
+func (g *Google) UploadConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) error {
+	bucketName := g.secretsBucketName(cluster)
+
+	svc, err := g.GCS()
+	if err != nil {
+		return err
+	}
+
+	manifestKey := filepath.Join(cluster.ClusterName(), "puppet.tar.gz")
+	if err := svc.PutObject(bucketName, manifestKey, stateFile); err != nil {
+		return err
+	}
+
+	if _, err := stateFile.Seek(0, 0); err != nil {
+		return fmt.Errorf("failed to rewind puppet state file: %s", err)
+	}
+
+	dirPath := filepath.Join(cluster.ClusterName(), "puppet-manifests")
+	hashPointerKey := filepath.Join(dirPath, "latest-puppet-hash")
+	manifestKey = filepath.Join(dirPath, fmt.Sprintf("%s-puppet.tar.gz", md5Hash))
+	if err := svc.PutObject(bucketName, manifestKey, stateFile); err != nil {
+		return err
+	}
+
+	if err := svc.PutObject(bucketName, hashPointerKey, bytes.NewReader([]byte(md5Hash))); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// This uploads a configuration to the GCS bucket without making it the latest
+// one, so instances can run it in dry run mode. It returns the manifest URL
+// wing is able to download it from.
+func (g *Google) UploadDryRunConfiguration(cluster interfaces.Cluster, stateFile io.ReadSeeker, md5Hash string) (string, error) {
+	bucketName := g.secretsBucketName(cluster)
+
+	svc, err := g.GCS()
+	if err != nil {
+		return "", err
+	}
+
+	manifestKey := filepath.Join(cluster.ClusterName(), "puppet-manifests", fmt.Sprintf("%s-puppet.tar.gz", md5Hash))
+	if err := svc.PutObject(bucketName, manifestKey, stateFile); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("gs://%s/%s", bucketName, manifestKey), nil
+}
+
+func (g *Google) secretsBucketName(cluster interfaces.Cluster) string {
+	return fmt.Sprintf(
+		"%s%s-%s-secrets",
+		g.conf.GCP.BucketPrefix,
+		cluster.Environment().Name(),
+		g.Region(),
+	)
+}
@@ -6,6 +6,9 @@ import (
 	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
 	"github.com/jetstack/tarmak/pkg/tarmak/provider/amazon"
+	"github.com/jetstack/tarmak/pkg/tarmak/provider/azure"
+	"github.com/jetstack/tarmak/pkg/tarmak/provider/baremetal"
+	"github.com/jetstack/tarmak/pkg/tarmak/provider/google"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils/input"
 )
 
@@ -39,7 +42,7 @@ func Init(init interfaces.Initialize) (provider *tarmakv1alpha1.Provider, err er
 
 providerloop:
 	for {
-		clouds := []string{clusterv1alpha1.CloudAmazon, clusterv1alpha1.CloudAzure}
+		clouds := []string{clusterv1alpha1.CloudAmazon, clusterv1alpha1.CloudGoogle, clusterv1alpha1.CloudAzure, clusterv1alpha1.CloudBaremetal}
 		cloud, err := init.Input().AskSelection(&input.AskSelection{
 			Query:   "Select a cloud",
 			Choices: clouds,
@@ -56,6 +59,24 @@ providerloop:
 				return nil, err
 			}
 			break providerloop
+		case clusterv1alpha1.CloudGoogle:
+			err := google.Init(init.Input(), provider)
+			if err != nil {
+				return nil, err
+			}
+			break providerloop
+		case clusterv1alpha1.CloudAzure:
+			err := azure.Init(init.Input(), provider)
+			if err != nil {
+				return nil, err
+			}
+			break providerloop
+		case clusterv1alpha1.CloudBaremetal:
+			err := baremetal.Init(init.Input(), provider)
+			if err != nil {
+				return nil, err
+			}
+			break providerloop
 		default:
 			init.Input().Warn("unsupported cloud provider: ", clouds[cloud])
 		}
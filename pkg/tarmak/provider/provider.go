@@ -7,6 +7,9 @@ import (
 	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
 	"github.com/jetstack/tarmak/pkg/tarmak/provider/amazon"
+	"github.com/jetstack/tarmak/pkg/tarmak/provider/azure"
+	"github.com/jetstack/tarmak/pkg/tarmak/provider/baremetal"
+	"github.com/jetstack/tarmak/pkg/tarmak/provider/google"
 )
 
 func NewFromConfig(tarmak interfaces.Tarmak, conf *tarmakv1alpha1.Provider) (interfaces.Provider, error) {
@@ -20,6 +23,27 @@ func NewFromConfig(tarmak interfaces.Tarmak, conf *tarmakv1alpha1.Provider) (int
 		provider, err = amazon.NewFromConfig(tarmak, conf)
 	}
 
+	if conf.GCP != nil {
+		if provider != nil {
+			return nil, fmt.Errorf("provider '%s' has configuration options for to different clouds", conf.Name)
+		}
+		provider, err = google.NewFromConfig(tarmak, conf)
+	}
+
+	if conf.Azure != nil {
+		if provider != nil {
+			return nil, fmt.Errorf("provider '%s' has configuration options for to different clouds", conf.Name)
+		}
+		provider, err = azure.NewFromConfig(tarmak, conf)
+	}
+
+	if conf.Baremetal != nil {
+		if provider != nil {
+			return nil, fmt.Errorf("provider '%s' has configuration options for to different clouds", conf.Name)
+		}
+		provider, err = baremetal.NewFromConfig(tarmak, conf)
+	}
+
 	if provider == nil {
 		return nil, fmt.Errorf("Unknown provider '%s'", conf.Name)
 	}
@@ -0,0 +1,84 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+var manifestHashRegexp = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// Rollback pins instances to an earlier manifest, which wing applies from its
+// cache. The next apply makes them converge the latest manifest again.
+func (c *CmdTarmak) Rollback() error {
+	flags := c.flags.Cluster.Instances.Rollback
+	if flags.Hash == "" {
+		return fmt.Errorf("--hash of the manifest to roll back to is required")
+	}
+
+	for _, f := range []func() error{
+		c.Validate,
+		c.writeSSHConfigForClusterHosts,
+	} {
+		if err := f(); err != nil {
+			return err
+		}
+	}
+
+	instances, err := c.Cluster().Instances()
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %s", err)
+	}
+
+	hash, err := resolveManifestHash(flags.Hash, instances)
+	if err != nil {
+		return err
+	}
+
+	if err := c.Cluster().RollbackConfiguration(hash, flags.Instances); err != nil {
+		return err
+	}
+
+	return c.Cluster().WaitForConvergance()
+}
+
+// resolveManifestHash expands a hash prefix to one of the manifest hashes the
+// instances have converged, manifests of dry runs are not cached by wing
+func resolveManifestHash(prefix string, instances []*wingv1alpha1.Instance) (string, error) {
+	hash := prefix
+	if !strings.HasPrefix(hash, "sha256:") {
+		hash = "sha256:" + hash
+	}
+	if manifestHashRegexp.MatchString(hash) {
+		return hash, nil
+	}
+
+	known := map[string]bool{}
+	for _, instance := range instances {
+		if instance.Status == nil || instance.Status.Converge == nil {
+			continue
+		}
+		if strings.HasPrefix(instance.Status.Converge.Hash, hash) {
+			known[instance.Status.Converge.Hash] = true
+		}
+	}
+
+	var matches []string
+	for h := range known {
+		matches = append(matches, h)
+	}
+	sort.Strings(matches)
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no manifest with hash '%s' reported by instances, the full hash is required", prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("hash '%s' is ambiguous, it matches %s", prefix, strings.Join(matches, ", "))
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"strings"
+	"testing"
+
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+func TestResolveManifestHash(t *testing.T) {
+	hashA := "sha256:" + strings.Repeat("a", 64)
+	hashAB := "sha256:" + "ab" + strings.Repeat("0", 62)
+	hashC := "sha256:" + strings.Repeat("c", 64)
+	hashD := "sha256:" + strings.Repeat("d", 64)
+
+	instances := []*wingv1alpha1.Instance{
+		{Status: &wingv1alpha1.InstanceStatus{
+			Converge: &wingv1alpha1.InstanceStatusManifest{Hash: hashA},
+		}},
+		{Status: &wingv1alpha1.InstanceStatus{
+			Converge: &wingv1alpha1.InstanceStatusManifest{Hash: hashAB},
+			DryRun:   &wingv1alpha1.InstanceStatusManifest{Hash: hashD},
+		}},
+		{},
+	}
+
+	for _, tc := range []struct {
+		prefix string
+		exp    string
+		err    string
+	}{
+		{prefix: "aaaa", exp: hashA},
+		{prefix: "sha256:ab", exp: hashAB},
+		{prefix: hashC, exp: hashC},
+		{prefix: strings.Repeat("c", 64), exp: hashC},
+		{prefix: "a", err: "ambiguous"},
+		{prefix: "dd", err: "no manifest"},
+	} {
+		t.Run(tc.prefix, func(t *testing.T) {
+			hash, err := resolveManifestHash(tc.prefix, instances)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing '%s', got: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if hash != tc.exp {
+				t.Errorf("unexpected hash, exp=%s act=%s", tc.exp, hash)
+			}
+		})
+	}
+}
@@ -0,0 +1,707 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+const (
+	rollingUpdateInterval     = 10 * time.Second
+	rollingUpdateDrainTimeout = 5 * time.Minute
+	rollingUpdateTimeout      = 30 * time.Minute
+
+	annotationMirrorPod = "kubernetes.io/config.mirror"
+)
+
+type rollingUpdatePool struct {
+	name string
+	role string
+
+	// stateful pools are replaced one instance at a time, after their quorum
+	// has been verified
+	oneAtATime bool
+	stateful   bool
+
+	hosts []interfaces.Host
+}
+
+type rollingUpdate struct {
+	*CmdTarmak
+
+	clientset   kubernetes.Interface
+	currentHash string
+}
+
+// RollingUpdate replaces the instances of the cluster pool by pool, so they
+// pick up the latest image and launch configuration
+func (c *CmdTarmak) RollingUpdate() error {
+	flags := c.flags.Cluster.RollingUpdate
+	if flags.MaxUnavailable < 1 {
+		return fmt.Errorf("--max-unavailable needs to be at least 1, got %d", flags.MaxUnavailable)
+	}
+
+	if err := c.setupTerraform(); err != nil {
+		return err
+	}
+
+	if !c.managesInfrastructure() {
+		return fmt.Errorf("provider %s does not manage any infrastructure, instances can't be replaced", c.Cluster().Environment().Provider())
+	}
+
+	// stateful instances get recreated by terraform, make sure this is the
+	// only change applied
+	changesNeeded, _, err := c.terraform.Plan(c.Cluster(), false)
+	if err != nil {
+		return err
+	}
+	if changesNeeded {
+		return fmt.Errorf("infrastructure of cluster %s has pending changes, run 'tarmak cluster apply' first", c.Cluster().Name())
+	}
+
+	currentHash, err := c.Cluster().ConfigurationHash()
+	if err != nil {
+		return fmt.Errorf("failed to build puppet manifest: %s", err)
+	}
+
+	hosts, err := c.Cluster().ListHosts()
+	if err != nil {
+		return fmt.Errorf("failed to list hosts: %s", err)
+	}
+
+	pools, err := rollingUpdatePools(c.Cluster().InstancePools(), hosts, flags.Pool)
+	if err != nil {
+		return err
+	}
+
+	u := &rollingUpdate{
+		CmdTarmak:   c,
+		currentHash: currentHash,
+	}
+
+	if c.Cluster().Type() != clusterv1alpha1.ClusterTypeHub {
+		u.clientset, err = c.kubectl.Clientset(false)
+		if err != nil {
+			return fmt.Errorf("failed to connect to Kubernetes API: %s", err)
+		}
+	}
+
+	for _, pool := range pools {
+		if err := u.updatePool(pool, flags.MaxUnavailable); err != nil {
+			return fmt.Errorf("rolling update of pool %s failed: %s", pool.name, err)
+		}
+	}
+
+	c.log.Info("rolling update finished")
+	return nil
+}
+
+// rollingUpdatePools groups the hosts by their instance pool, the bastion is
+// skipped as it is running the wing API
+func rollingUpdatePools(instancePools []interfaces.InstancePool, hosts []interfaces.Host, poolName string) ([]*rollingUpdatePool, error) {
+	var pools []*rollingUpdatePool
+
+	for _, instancePool := range instancePools {
+		if poolName != "" && instancePool.Name() != poolName {
+			continue
+		}
+
+		roleName := instancePool.Role().Name()
+		if roleName == clusterv1alpha1.InstancePoolTypeBastion {
+			if poolName != "" {
+				return nil, fmt.Errorf("pool %s can't be replaced by a rolling update, as it is running the wing API", poolName)
+			}
+			continue
+		}
+
+		pool := &rollingUpdatePool{
+			name:     instancePool.Name(),
+			role:     roleName,
+			stateful: instancePool.Role().Stateful,
+		}
+		pool.oneAtATime = pool.stateful || pool.etcd() || pool.vault()
+
+		poolHosts, err := instancePoolHosts(instancePools, instancePool, hosts)
+		if err != nil {
+			return nil, err
+		}
+		pool.hosts = poolHosts
+
+		pools = append(pools, pool)
+	}
+
+	if poolName != "" && len(pools) == 0 {
+		return nil, fmt.Errorf("cluster has no instance pool named %s", poolName)
+	}
+
+	return pools, nil
+}
+
+// instancePoolHosts returns the hosts of an instance pool. Hosts are matched by
+// their instance pool tag and role. Hosts launched without the tag are matched
+// by their role only, which is ambiguous if other pools share the role.
+func instancePoolHosts(instancePools []interfaces.InstancePool, instancePool interfaces.InstancePool, hosts []interfaces.Host) ([]interfaces.Host, error) {
+	roleName := instancePool.Role().Name()
+
+	sharedRole := false
+	for _, other := range instancePools {
+		if other.Name() != instancePool.Name() && other.Role().Name() == roleName {
+			sharedRole = true
+		}
+	}
+
+	var result []interfaces.Host
+	for _, host := range hosts {
+		if !hasRole(host.Roles(), roleName) {
+			continue
+		}
+
+		if host.InstancePool() == "" && sharedRole {
+			return nil, fmt.Errorf("host %s has been launched without an instance pool tag, it can't be assigned to one of the instance pools sharing role %s", host.ID(), roleName)
+		}
+		if host.InstancePool() != "" && host.InstancePool() != instancePool.Name() {
+			continue
+		}
+
+		result = append(result, host)
+	}
+
+	return result, nil
+}
+
+// hasRole matches the plain role name as well as the numbered roles of
+// stateful instances, e.g. etcd-1
+func hasRole(roles []string, role string) bool {
+	for _, hostRole := range roles {
+		if hostRole == role {
+			return true
+		}
+		if !strings.HasPrefix(hostRole, role+"-") {
+			continue
+		}
+		if _, err := strconv.Atoi(strings.TrimPrefix(hostRole, role+"-")); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *rollingUpdatePool) etcd() bool {
+	return p.role == clusterv1alpha1.InstancePoolTypeEtcd || p.role == "etcd-master"
+}
+
+func (p *rollingUpdatePool) vault() bool {
+	return p.role == clusterv1alpha1.InstancePoolTypeVault
+}
+
+// rollingUpdateBatch returns the next hosts to replace
+func rollingUpdateBatch(hosts []interfaces.Host, replaced map[string]bool, size int) []interfaces.Host {
+	var batch []interfaces.Host
+	for _, host := range hosts {
+		if len(batch) == size {
+			break
+		}
+		if !replaced[host.ID()] {
+			batch = append(batch, host)
+		}
+	}
+	return batch
+}
+
+func (u *rollingUpdate) updatePool(pool *rollingUpdatePool, maxUnavailable int) error {
+	if len(pool.hosts) == 0 {
+		u.log.Infof("pool %s has no instances, skipping", pool.name)
+		return nil
+	}
+
+	batchSize := maxUnavailable
+	if pool.oneAtATime {
+		batchSize = 1
+	}
+
+	u.log.Infof("replacing %d instances of pool %s, %d at a time", len(pool.hosts), pool.name, batchSize)
+
+	replaced := make(map[string]bool)
+	for {
+		batch := rollingUpdateBatch(pool.hosts, replaced, batchSize)
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if pool.oneAtATime {
+			if err := u.waitFor(fmt.Sprintf("quorum of pool %s", pool.name), func() error {
+				return u.checkQuorum(pool)
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := u.replaceBatch(pool, batch); err != nil {
+			return err
+		}
+
+		for _, host := range batch {
+			replaced[host.ID()] = true
+		}
+	}
+}
+
+func (u *rollingUpdate) replaceBatch(pool *rollingUpdatePool, batch []interfaces.Host) error {
+	var ids []string
+	batchIDs := make(map[string]bool)
+	for _, host := range batch {
+		ids = append(ids, host.ID())
+		batchIDs[host.ID()] = true
+	}
+
+	// hosts of the pool which are not part of this batch
+	hosts, err := u.Cluster().ListHosts()
+	if err != nil {
+		return fmt.Errorf("failed to list hosts: %s", err)
+	}
+	untouched := make(map[string]bool)
+	poolSize := 0
+	for _, host := range hosts {
+		if !hasRole(host.Roles(), pool.role) {
+			continue
+		}
+		poolSize++
+		if !batchIDs[host.ID()] {
+			untouched[host.ID()] = true
+		}
+	}
+
+	// hosts replaced in place are only done, once they converged again
+	lastUpdates, err := u.lastUpdates()
+	if err != nil {
+		return err
+	}
+
+	expectedNodes := 0
+	if u.clientset != nil {
+		u.log.Infof("draining instances %s", strings.Join(ids, ", "))
+		expectedNodes, err = u.drainHosts(batch)
+		if err != nil {
+			return err
+		}
+	}
+
+	u.log.Infof("terminating instances %s", strings.Join(ids, ", "))
+	if err := u.Cluster().Environment().Provider().TerminateHosts(batch); err != nil {
+		return err
+	}
+
+	// instances outside of scaling groups are recreated by terraform
+	if pool.stateful {
+		if _, err := u.terraform.Apply(u.Cluster()); err != nil {
+			return fmt.Errorf("failed to recreate instances: %s", err)
+		}
+	}
+
+	if err := u.waitFor(fmt.Sprintf("replacements of %s", strings.Join(ids, ", ")), func() error {
+		return u.checkReplacements(pool, untouched, poolSize, lastUpdates, expectedNodes)
+	}); err != nil {
+		return err
+	}
+
+	if u.clientset != nil {
+		if err := u.uncordonInPlace(batchIDs); err != nil {
+			return err
+		}
+	}
+
+	if pool.oneAtATime {
+		if err := u.waitFor(fmt.Sprintf("quorum of pool %s", pool.name), func() error {
+			return u.checkQuorum(pool)
+		}); err != nil {
+			return err
+		}
+	}
+
+	u.log.Infof("replaced instances %s", strings.Join(ids, ", "))
+	return nil
+}
+
+// waitFor retries the check until it succeeds or times out
+func (u *rollingUpdate) waitFor(description string, check func() error) error {
+	timeout := time.After(rollingUpdateTimeout)
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+		u.log.Infof("waiting for %s: %s", description, err)
+
+		select {
+		case <-u.ctx.Done():
+			return u.ctx.Err()
+		case <-timeout:
+			return fmt.Errorf("timed out waiting for %s: %s", description, err)
+		case <-time.After(rollingUpdateInterval):
+		}
+	}
+}
+
+func (u *rollingUpdate) lastUpdates() (map[string]time.Time, error) {
+	instances, err := u.Cluster().Instances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %s", err)
+	}
+
+	lastUpdates := make(map[string]time.Time)
+	for _, instance := range instances {
+		if instance.Status != nil && instance.Status.Converge != nil {
+			lastUpdates[instance.Name] = instance.Status.Converge.LastUpdateTimestamp.Time
+		}
+	}
+	return lastUpdates, nil
+}
+
+// checkReplacements verifies that the pool is back to its size and all
+// replacements have converged and, if they are Kubernetes nodes, are ready
+func (u *rollingUpdate) checkReplacements(pool *rollingUpdatePool, untouched map[string]bool, poolSize int, lastUpdates map[string]time.Time, expectedNodes int) error {
+	hosts, err := u.Cluster().ListHosts()
+	if err != nil {
+		return err
+	}
+
+	var replacements []interfaces.Host
+	count := 0
+	for _, host := range hosts {
+		if !hasRole(host.Roles(), pool.role) {
+			continue
+		}
+		count++
+		if !untouched[host.ID()] {
+			replacements = append(replacements, host)
+		}
+	}
+	if count < poolSize {
+		return fmt.Errorf("%d of %d instances running", count, poolSize)
+	}
+
+	instances, err := u.Cluster().Instances()
+	if err != nil {
+		return err
+	}
+	instancesByName := make(map[string]*wingv1alpha1.Instance)
+	for _, instance := range instances {
+		instancesByName[instance.Name] = instance
+	}
+
+	for _, host := range replacements {
+		if err := replacementConverged(instancesByName[host.ID()], lastUpdates[host.ID()]); err != nil {
+			return fmt.Errorf("instance %s %s", host.ID(), err)
+		}
+	}
+
+	if expectedNodes > 0 {
+		if err := u.checkNodesReady(replacements, expectedNodes); err != nil {
+			return err
+		}
+	}
+
+	for _, host := range replacements {
+		if hash := instancesByName[host.ID()].Status.Converge.Hash; hash != u.currentHash {
+			u.log.Warnf("instance %s converged with puppet manifest %s, which is not the current one", host.ID(), shortHash(hash))
+		}
+	}
+
+	return nil
+}
+
+func (u *rollingUpdate) checkNodesReady(replacements []interfaces.Host, expectedNodes int) error {
+	nodes, err := u.clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	ready := 0
+	for _, host := range replacements {
+		if node := nodeForHost(nodes.Items, host); node != nil && nodeReady(node) {
+			ready++
+		}
+	}
+	if ready < expectedNodes {
+		return fmt.Errorf("%d of %d nodes ready", ready, expectedNodes)
+	}
+
+	return nil
+}
+
+// replacementConverged checks that the instance has converged after it has
+// been replaced, instances replaced in place need to converge again
+func replacementConverged(instance *wingv1alpha1.Instance, lastUpdate time.Time) error {
+	if instance == nil || instance.Status == nil || instance.Status.Converge == nil {
+		return fmt.Errorf("has not reported to wing yet")
+	}
+
+	status := instance.Status.Converge
+	if status.State != wingv1alpha1.InstanceManifestStateConverged {
+		return fmt.Errorf("is in state %s", status.State)
+	}
+	if !status.LastUpdateTimestamp.Time.After(lastUpdate) {
+		return fmt.Errorf("has not converged again since it got replaced")
+	}
+
+	return nil
+}
+
+// checkQuorum makes sure that all members of a stateful pool are healthy, so
+// the quorum is kept while one of them is replaced
+func (u *rollingUpdate) checkQuorum(pool *rollingUpdatePool) error {
+	instances, err := u.Cluster().Instances()
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if !hasRole(strings.Split(instance.InstancePool, ","), pool.role) {
+			continue
+		}
+		if instance.Status == nil || instance.Status.Converge == nil || instance.Status.Converge.State != wingv1alpha1.InstanceManifestStateConverged {
+			return fmt.Errorf("instance %s has not converged", instance.Name)
+		}
+	}
+
+	if pool.etcd() && u.clientset != nil {
+		statuses, err := u.clientset.CoreV1().ComponentStatuses().List(metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		if unhealthy := unhealthyEtcdMembers(statuses.Items); len(unhealthy) > 0 {
+			return fmt.Errorf("etcd members %s are not healthy", strings.Join(unhealthy, ", "))
+		}
+	}
+
+	if pool.vault() {
+		fqdns, vaultCA, err := u.vaultInstances()
+		if err != nil {
+			return err
+		}
+
+		unsealed, err := u.Environment().Vault().UnsealedFromFQDNs(fqdns, vaultCA)
+		if err != nil {
+			return err
+		}
+		if len(unsealed) < len(fqdns) {
+			return fmt.Errorf("%d of %d vault instances unsealed", len(unsealed), len(fqdns))
+		}
+	}
+
+	return nil
+}
+
+func unhealthyEtcdMembers(statuses []corev1.ComponentStatus) []string {
+	var unhealthy []string
+	for _, status := range statuses {
+		if !strings.HasPrefix(status.Name, "etcd-") {
+			continue
+		}
+		healthy := false
+		for _, condition := range status.Conditions {
+			if condition.Type == corev1.ComponentHealthy && condition.Status == corev1.ConditionTrue {
+				healthy = true
+			}
+		}
+		if !healthy {
+			unhealthy = append(unhealthy, status.Name)
+		}
+	}
+	return unhealthy
+}
+
+// drainHosts cordons the nodes of the hosts and evicts their pods, it
+// returns the number of hosts running a node
+func (u *rollingUpdate) drainHosts(hosts []interfaces.Host) (int, error) {
+	nodes, err := u.clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list nodes: %s", err)
+	}
+
+	count := 0
+	for _, host := range hosts {
+		node := nodeForHost(nodes.Items, host)
+		if node == nil {
+			u.log.Debugf("host %s is not a Kubernetes node", host.ID())
+			continue
+		}
+		count++
+
+		if err := u.cordonNode(node.Name); err != nil {
+			return count, fmt.Errorf("failed to cordon node %s: %s", node.Name, err)
+		}
+
+		if err := u.evictPods(node.Name); err != nil {
+			return count, fmt.Errorf("failed to drain node %s: %s", node.Name, err)
+		}
+	}
+
+	return count, nil
+}
+
+func (u *rollingUpdate) cordonNode(name string) error {
+	node, err := u.clientset.CoreV1().Nodes().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	if node.Spec.Unschedulable {
+		return nil
+	}
+
+	u.log.Infof("cordoning node %s", name)
+	node.Spec.Unschedulable = true
+	_, err = u.clientset.CoreV1().Nodes().Update(node)
+	return err
+}
+
+// uncordonInPlace makes nodes schedulable again, whose host has been replaced
+// in place and kept its node
+func (u *rollingUpdate) uncordonInPlace(batchIDs map[string]bool) error {
+	hosts, err := u.Cluster().ListHosts()
+	if err != nil {
+		return fmt.Errorf("failed to list hosts: %s", err)
+	}
+
+	nodes, err := u.clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %s", err)
+	}
+
+	for _, host := range hosts {
+		if !batchIDs[host.ID()] {
+			continue
+		}
+
+		node := nodeForHost(nodes.Items, host)
+		if node == nil || !node.Spec.Unschedulable {
+			continue
+		}
+
+		u.log.Infof("uncordoning node %s", node.Name)
+		node.Spec.Unschedulable = false
+		if _, err := u.clientset.CoreV1().Nodes().Update(node); err != nil {
+			return fmt.Errorf("failed to uncordon node %s: %s", node.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (u *rollingUpdate) evictPods(nodeName string) error {
+	listPods := func() ([]corev1.Pod, error) {
+		pods, err := u.clientset.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return podsToEvict(pods.Items), nil
+	}
+
+	timeout := time.After(rollingUpdateDrainTimeout)
+	for {
+		pods, err := listPods()
+		if err != nil {
+			return err
+		}
+		if len(pods) == 0 {
+			return nil
+		}
+
+		u.log.Infof("evicting %d pods from node %s", len(pods), nodeName)
+		for _, pod := range pods {
+			err := u.clientset.PolicyV1beta1().Evictions(pod.Namespace).Evict(&policyv1beta1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+			})
+			// disruption budgets are retried until the timeout
+			if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsTooManyRequests(err) {
+				return fmt.Errorf("failed to evict pod %s/%s: %s", pod.Namespace, pod.Name, err)
+			}
+		}
+
+		select {
+		case <-u.ctx.Done():
+			return u.ctx.Err()
+		case <-timeout:
+			return fmt.Errorf("timed out evicting pods")
+		case <-time.After(rollingUpdateInterval):
+		}
+	}
+}
+
+// podsToEvict skips pods, which would be recreated on the same node or are
+// already finished
+func podsToEvict(pods []corev1.Pod) []corev1.Pod {
+	var result []corev1.Pod
+	for _, pod := range pods {
+		if _, ok := pod.Annotations[annotationMirrorPod]; ok {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+
+		daemonSet := false
+		for _, ref := range pod.OwnerReferences {
+			if ref.Kind == "DaemonSet" {
+				daemonSet = true
+			}
+		}
+		if daemonSet {
+			continue
+		}
+
+		result = append(result, pod)
+	}
+	return result
+}
+
+// nodeForHost matches nodes by the instance ID in their provider ID, their
+// name or their addresses
+func nodeForHost(nodes []corev1.Node, host interfaces.Host) *corev1.Node {
+	for pos := range nodes {
+		node := &nodes[pos]
+
+		providerID := node.Spec.ProviderID
+		if providerID != "" && providerID[strings.LastIndex(providerID, "/")+1:] == host.ID() {
+			return node
+		}
+
+		if node.Name == host.ID() {
+			return node
+		}
+
+		for _, address := range node.Status.Addresses {
+			if address.Address == host.Hostname() {
+				return node
+			}
+		}
+	}
+	return nil
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
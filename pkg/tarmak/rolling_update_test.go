@@ -0,0 +1,337 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/tarmak/mocks"
+	"github.com/jetstack/tarmak/pkg/tarmak/role"
+)
+
+func fakeHost(ctrl *gomock.Controller, id, hostname string, roles ...string) interfaces.Host {
+	return fakePoolHost(ctrl, id, hostname, "", roles...)
+}
+
+func fakePoolHost(ctrl *gomock.Controller, id, hostname, instancePool string, roles ...string) interfaces.Host {
+	h := mocks.NewMockHost(ctrl)
+	h.EXPECT().ID().AnyTimes().Return(id)
+	h.EXPECT().Hostname().AnyTimes().Return(hostname)
+	h.EXPECT().Roles().AnyTimes().Return(roles)
+	h.EXPECT().InstancePool().AnyTimes().Return(instancePool)
+	return h
+}
+
+func fakeInstancePool(ctrl *gomock.Controller, name, roleName string, stateful bool) interfaces.InstancePool {
+	r := &role.Role{Stateful: stateful}
+	r.WithName(roleName)
+
+	p := mocks.NewMockInstancePool(ctrl)
+	p.EXPECT().Name().AnyTimes().Return(name)
+	p.EXPECT().Role().AnyTimes().Return(r)
+	return p
+}
+
+func hostIDs(hosts []interfaces.Host) []string {
+	var ids []string
+	for _, h := range hosts {
+		ids = append(ids, h.ID())
+	}
+	return ids
+}
+
+func TestRollingUpdate_hasRole(t *testing.T) {
+	for _, c := range []struct {
+		roles []string
+		role  string
+		exp   bool
+	}{
+		{[]string{"worker"}, "worker", true},
+		{[]string{"etcd-1"}, "etcd", true},
+		{[]string{"etcd-12"}, "etcd", true},
+		{[]string{"etcd-master"}, "etcd", false},
+		{[]string{"etcd-master"}, "etcd-master", true},
+		{[]string{"master", "etcd"}, "etcd", true},
+		{[]string{"vault-1"}, "etcd", false},
+		{[]string{}, "worker", false},
+	} {
+		if act := hasRole(c.roles, c.role); act != c.exp {
+			t.Errorf("hasRole(%v, %s): act=%v exp=%v", c.roles, c.role, act, c.exp)
+		}
+	}
+}
+
+func TestRollingUpdate_rollingUpdatePools(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	instancePools := []interfaces.InstancePool{
+		fakeInstancePool(ctrl, "bastion", "bastion", true),
+		fakeInstancePool(ctrl, "etcd", "etcd", true),
+		fakeInstancePool(ctrl, "master", "master", false),
+		fakeInstancePool(ctrl, "worker", "worker", false),
+	}
+	hosts := []interfaces.Host{
+		fakeHost(ctrl, "i-bastion", "192.0.2.1", "bastion"),
+		fakeHost(ctrl, "i-etcd-1", "10.0.0.1", "etcd-1"),
+		fakeHost(ctrl, "i-etcd-2", "10.0.0.2", "etcd-2"),
+		fakeHost(ctrl, "i-master", "10.0.0.3", "master"),
+		fakeHost(ctrl, "i-worker-1", "10.0.0.4", "worker"),
+		fakeHost(ctrl, "i-worker-2", "10.0.0.5", "worker"),
+	}
+
+	pools, err := rollingUpdatePools(instancePools, hosts, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	type result struct {
+		name       string
+		oneAtATime bool
+		hosts      []string
+	}
+	var act []result
+	for _, p := range pools {
+		act = append(act, result{p.name, p.oneAtATime, hostIDs(p.hosts)})
+	}
+	exp := []result{
+		{"etcd", true, []string{"i-etcd-1", "i-etcd-2"}},
+		{"master", false, []string{"i-master"}},
+		{"worker", false, []string{"i-worker-1", "i-worker-2"}},
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected pools:\nact=%+v\nexp=%+v", act, exp)
+	}
+
+	pools, err = rollingUpdatePools(instancePools, hosts, "worker")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pools) != 1 || pools[0].name != "worker" {
+		t.Errorf("expected only the worker pool, got: %+v", pools)
+	}
+
+	for pool, expErr := range map[string]string{
+		"bastion": "running the wing API",
+		"jenkins": "no instance pool named jenkins",
+	} {
+		_, err := rollingUpdatePools(instancePools, hosts, pool)
+		if err == nil || !strings.Contains(err.Error(), expErr) {
+			t.Errorf("pool %s: unexpected error: %v", pool, err)
+		}
+	}
+}
+
+func TestRollingUpdate_rollingUpdatePools_sharedRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	instancePools := []interfaces.InstancePool{
+		fakeInstancePool(ctrl, "master", "master", false),
+		fakeInstancePool(ctrl, "worker", "worker", false),
+		fakeInstancePool(ctrl, "worker-gpu", "worker", false),
+	}
+	hosts := []interfaces.Host{
+		fakeHost(ctrl, "i-master", "10.0.0.1", "master"),
+		fakePoolHost(ctrl, "i-worker-1", "10.0.0.2", "worker", "worker"),
+		fakePoolHost(ctrl, "i-worker-gpu-1", "10.0.0.3", "worker-gpu", "worker"),
+		fakePoolHost(ctrl, "i-worker-2", "10.0.0.4", "worker", "worker"),
+	}
+
+	pools, err := rollingUpdatePools(instancePools, hosts, "worker-gpu")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(pools) != 1 {
+		t.Fatalf("expected only the worker-gpu pool, got: %+v", pools)
+	}
+	if act, exp := hostIDs(pools[0].hosts), []string{"i-worker-gpu-1"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected hosts: act=%v exp=%v", act, exp)
+	}
+
+	pools, err = rollingUpdatePools(instancePools, hosts, "worker")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if act, exp := hostIDs(pools[0].hosts), []string{"i-worker-1", "i-worker-2"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected hosts: act=%v exp=%v", act, exp)
+	}
+
+	// hosts without the instance pool tag can only be assigned by their role,
+	// which is ambiguous for the worker pools
+	hosts = append(hosts, fakeHost(ctrl, "i-worker-3", "10.0.0.5", "worker"))
+	pools, err = rollingUpdatePools(instancePools, hosts, "master")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if act, exp := hostIDs(pools[0].hosts), []string{"i-master"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected hosts: act=%v exp=%v", act, exp)
+	}
+
+	_, err = rollingUpdatePools(instancePools, hosts, "worker")
+	if exp := "host i-worker-3 has been launched without an instance pool tag, it can't be assigned to one of the instance pools sharing role worker"; err == nil || err.Error() != exp {
+		t.Errorf("unexpected error: act=%v exp=%s", err, exp)
+	}
+}
+
+func TestRollingUpdate_rollingUpdateBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hosts := []interfaces.Host{
+		fakeHost(ctrl, "i-1", "10.0.0.1"),
+		fakeHost(ctrl, "i-2", "10.0.0.2"),
+		fakeHost(ctrl, "i-3", "10.0.0.3"),
+	}
+
+	replaced := map[string]bool{}
+	var act [][]string
+	for {
+		batch := rollingUpdateBatch(hosts, replaced, 2)
+		if len(batch) == 0 {
+			break
+		}
+		act = append(act, hostIDs(batch))
+		for _, h := range batch {
+			replaced[h.ID()] = true
+		}
+	}
+
+	exp := [][]string{{"i-1", "i-2"}, {"i-3"}}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected batches: act=%v exp=%v", act, exp)
+	}
+}
+
+func TestRollingUpdate_podsToEvict(t *testing.T) {
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "app"}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:        "kube-apiserver",
+			Annotations: map[string]string{annotationMirrorPod: "abc"},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "fluentd",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "fluentd"}},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "web",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web"}},
+		}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "job"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	}
+
+	var act []string
+	for _, pod := range podsToEvict(pods) {
+		act = append(act, pod.Name)
+	}
+
+	if exp := []string{"app", "web"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected pods: act=%v exp=%v", act, exp)
+	}
+}
+
+func TestRollingUpdate_nodeForHost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	nodes := []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-1.eu-west-1.compute.internal"},
+			Spec:       corev1.NodeSpec{ProviderID: "aws:///eu-west-1a/i-0123"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-worker-abcd"},
+			Spec:       corev1.NodeSpec{ProviderID: "gce://project/europe-west1-b/cluster-worker-abcd"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-3"},
+			Status: corev1.NodeStatus{Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.3"},
+			}},
+		},
+	}
+
+	for _, c := range []struct {
+		host interfaces.Host
+		exp  string
+	}{
+		{fakeHost(ctrl, "i-0123", "10.0.0.1"), "ip-10-0-0-1.eu-west-1.compute.internal"},
+		{fakeHost(ctrl, "cluster-worker-abcd", "10.0.0.2"), "cluster-worker-abcd"},
+		{fakeHost(ctrl, "cluster-worker_3", "10.0.0.3"), "worker-3"},
+		{fakeHost(ctrl, "i-4567", "10.0.0.4"), ""},
+	} {
+		act := ""
+		if node := nodeForHost(nodes, c.host); node != nil {
+			act = node.Name
+		}
+		if act != c.exp {
+			t.Errorf("host %s: act=%s exp=%s", c.host.ID(), act, c.exp)
+		}
+	}
+}
+
+func TestRollingUpdate_unhealthyEtcdMembers(t *testing.T) {
+	status := func(name string, healthy corev1.ConditionStatus) corev1.ComponentStatus {
+		return corev1.ComponentStatus{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Conditions: []corev1.ComponentCondition{{Type: corev1.ComponentHealthy, Status: healthy}},
+		}
+	}
+
+	act := unhealthyEtcdMembers([]corev1.ComponentStatus{
+		status("scheduler", corev1.ConditionFalse),
+		status("etcd-0", corev1.ConditionTrue),
+		status("etcd-1", corev1.ConditionFalse),
+		{ObjectMeta: metav1.ObjectMeta{Name: "etcd-2"}},
+	})
+
+	if exp := []string{"etcd-1", "etcd-2"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected unhealthy members: act=%v exp=%v", act, exp)
+	}
+}
+
+func TestRollingUpdate_replacementConverged(t *testing.T) {
+	replaced := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	instance := func(state wingv1alpha1.InstanceManifestState, updated time.Time) *wingv1alpha1.Instance {
+		return &wingv1alpha1.Instance{
+			Status: &wingv1alpha1.InstanceStatus{
+				Converge: &wingv1alpha1.InstanceStatusManifest{
+					State:               state,
+					LastUpdateTimestamp: metav1.NewTime(updated),
+				},
+			},
+		}
+	}
+
+	for _, c := range []struct {
+		name       string
+		instance   *wingv1alpha1.Instance
+		lastUpdate time.Time
+		err        string
+	}{
+		{"not reported", nil, time.Time{}, "has not reported to wing yet"},
+		{"new instance", instance(wingv1alpha1.InstanceManifestStateConverged, replaced), time.Time{}, ""},
+		{"converging", instance(wingv1alpha1.InstanceManifestStateConverging, replaced), time.Time{}, "is in state converging"},
+		{"in place stale", instance(wingv1alpha1.InstanceManifestStateConverged, replaced), replaced, "has not converged again"},
+		{"in place converged", instance(wingv1alpha1.InstanceManifestStateConverged, replaced.Add(time.Minute)), replaced, ""},
+	} {
+		err := replacementConverged(c.instance, c.lastUpdate)
+		if c.err == "" && err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		} else if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s: expected error '%s', got: %v", c.name, c.err, err)
+		}
+	}
+}
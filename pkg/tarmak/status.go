@@ -0,0 +1,202 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+const (
+	statusWatchInterval = 5 * time.Second
+	statusMessageLength = 80
+)
+
+var statusKeys = []string{"name", "pool", "state", "hash", "current", "message"}
+
+var statusWideKeys = []string{"updated", "exit code", "failed"}
+
+// Status prints the converge state of all instances in the cluster, with
+// --watch it keeps printing state changes until cancelled
+func (c *CmdTarmak) Status() error {
+	for _, f := range []func() error{
+		c.Validate,
+		c.writeSSHConfigForClusterHosts,
+	} {
+		if err := f(); err != nil {
+			return err
+		}
+	}
+
+	currentHash, err := c.Cluster().ConfigurationHash()
+	if err != nil {
+		return fmt.Errorf("failed to build puppet manifest: %s", err)
+	}
+
+	instances, err := c.Cluster().Instances()
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %s", err)
+	}
+
+	if err := printInstances(os.Stdout, c.flags.Output, instances, currentHash); err != nil {
+		return err
+	}
+
+	if !c.flags.Cluster.Status.Watch {
+		return nil
+	}
+
+	lastStates := instanceStates(instances)
+	for {
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-time.After(statusWatchInterval):
+		}
+
+		instances, err := c.Cluster().Instances()
+		if err != nil {
+			c.log.Warnf("failed to list instances: %s", err)
+			continue
+		}
+
+		states := instanceStates(instances)
+
+		var changed []*wingv1alpha1.Instance
+		for _, instance := range instances {
+			if lastStates[instance.Name] != states[instance.Name] {
+				changed = append(changed, instance)
+			}
+		}
+
+		for name := range lastStates {
+			if _, ok := states[name]; !ok {
+				c.log.Infof("instance %s has been removed", name)
+			}
+		}
+
+		lastStates = states
+
+		if len(changed) == 0 {
+			continue
+		}
+
+		if err := printInstanceChanges(os.Stdout, c.flags.Output, changed, currentHash); err != nil {
+			return err
+		}
+	}
+}
+
+func printInstances(out io.Writer, format string, instances []*wingv1alpha1.Instance, currentHash string) error {
+	list := &wingv1alpha1.InstanceList{}
+	list.APIVersion = wingv1alpha1.SchemeGroupVersion.String()
+	list.Kind = "InstanceList"
+
+	varMaps := make([]map[string]string, 0)
+	for _, instance := range instances {
+		list.Items = append(list.Items, *instance)
+		varMaps = append(varMaps, instanceParameters(instance, currentHash))
+	}
+
+	return utils.List(out, format, list, statusKeys, statusWideKeys, varMaps)
+}
+
+// In table mode every change is printed as a single line, so the output can
+// be followed like a log
+func printInstanceChanges(out io.Writer, format string, instances []*wingv1alpha1.Instance, currentHash string) error {
+	if format != utils.OutputTable && format != utils.OutputWide && format != "" {
+		return printInstances(out, format, instances, currentHash)
+	}
+
+	for _, instance := range instances {
+		p := instanceParameters(instance, currentHash)
+		fmt.Fprintf(
+			out,
+			"%s %s (%s): %s, hash %s, current %s, exit code %s: %s\n",
+			time.Now().Format(time.RFC3339),
+			p["name"],
+			p["pool"],
+			p["state"],
+			p["hash"],
+			p["current"],
+			p["exit code"],
+			p["message"],
+		)
+		if p["failed"] != "" {
+			fmt.Fprintf(out, "  failed resources: %s\n", p["failed"])
+		}
+	}
+
+	return nil
+}
+
+func instanceParameters(instance *wingv1alpha1.Instance, currentHash string) map[string]string {
+	p := map[string]string{
+		"name": instance.Name,
+		"pool": instance.InstancePool,
+	}
+
+	if instance.Status == nil || instance.Status.Converge == nil {
+		p["state"] = "unknown"
+		return p
+	}
+	status := instance.Status.Converge
+
+	p["state"] = string(status.State)
+	if status.Hash != "" {
+		p["hash"] = shortHash(status.Hash)
+		p["current"] = strconv.FormatBool(status.Hash == currentHash)
+	}
+	if !status.LastUpdateTimestamp.IsZero() {
+		p["updated"] = status.LastUpdateTimestamp.Format(time.RFC3339)
+	}
+	if len(status.ExitCodes) > 0 {
+		p["exit code"] = strconv.Itoa(status.ExitCodes[len(status.ExitCodes)-1])
+	}
+	if len(status.Messages) > 0 {
+		p["message"] = lastLine(status.Messages[len(status.Messages)-1], statusMessageLength)
+	}
+	if status.Report != nil && len(status.Report.FailedResources) > 0 {
+		p["failed"] = truncate(strings.Join(status.Report.FailedResources, ", "), statusMessageLength)
+	}
+
+	return p
+}
+
+// instanceStates returns a comparable representation of the converge state
+// per instance
+func instanceStates(instances []*wingv1alpha1.Instance) map[string]string {
+	states := make(map[string]string, len(instances))
+	for _, instance := range instances {
+		p := instanceParameters(instance, "")
+		states[instance.Name] = strings.Join([]string{p["state"], p["hash"], p["updated"], p["exit code"], p["failed"], p["message"]}, "|")
+	}
+	return states
+}
+
+func shortHash(hash string) string {
+	parts := strings.SplitN(hash, ":", 2)
+	value := parts[len(parts)-1]
+	if len(value) > 12 {
+		value = value[:12]
+	}
+	return value
+}
+
+func lastLine(message string, length int) string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	return truncate(strings.TrimSpace(lines[len(lines)-1]), length)
+}
+
+func truncate(line string, length int) string {
+	if len(line) > length {
+		line = line[:length-3] + "..."
+	}
+	return line
+}
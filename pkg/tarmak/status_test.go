@@ -0,0 +1,114 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+func TestStatus_instanceParameters(t *testing.T) {
+	updated := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		name     string
+		instance *wingv1alpha1.Instance
+		exp      map[string]string
+	}{
+		{
+			name: "no status",
+			instance: &wingv1alpha1.Instance{
+				ObjectMeta:   metav1.ObjectMeta{Name: "i-1"},
+				InstancePool: "worker",
+			},
+			exp: map[string]string{"name": "i-1", "pool": "worker", "state": "unknown"},
+		},
+		{
+			name: "converged current",
+			instance: &wingv1alpha1.Instance{
+				ObjectMeta:   metav1.ObjectMeta{Name: "i-2"},
+				InstancePool: "master",
+				Status: &wingv1alpha1.InstanceStatus{
+					Converge: &wingv1alpha1.InstanceStatusManifest{
+						State:               wingv1alpha1.InstanceManifestStateConverged,
+						Hash:                "sha256:0123456789abcdef",
+						LastUpdateTimestamp: metav1.NewTime(updated),
+						Messages:            []string{"first\n", "Notice: Applied catalog\n\n"},
+						ExitCodes:           []int{6, 0},
+					},
+				},
+			},
+			exp: map[string]string{
+				"name":      "i-2",
+				"pool":      "master",
+				"state":     "converged",
+				"hash":      "0123456789ab",
+				"current":   "true",
+				"updated":   "2018-07-01T12:00:00Z",
+				"exit code": "0",
+				"message":   "Notice: Applied catalog",
+			},
+		},
+		{
+			name: "outdated error",
+			instance: &wingv1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Name: "i-3"},
+				Status: &wingv1alpha1.InstanceStatus{
+					Converge: &wingv1alpha1.InstanceStatusManifest{
+						State:     wingv1alpha1.InstanceManifestStateError,
+						Hash:      "sha256:ffff",
+						Messages:  []string{strings.Repeat("x", 100)},
+						ExitCodes: []int{4},
+					},
+				},
+			},
+			exp: map[string]string{
+				"name":      "i-3",
+				"pool":      "",
+				"state":     "error",
+				"hash":      "ffff",
+				"current":   "false",
+				"exit code": "4",
+				"message":   strings.Repeat("x", 77) + "...",
+			},
+		},
+		{
+			name: "failed resources",
+			instance: &wingv1alpha1.Instance{
+				ObjectMeta: metav1.ObjectMeta{Name: "i-4"},
+				Status: &wingv1alpha1.InstanceStatus{
+					Converge: &wingv1alpha1.InstanceStatusManifest{
+						State:     wingv1alpha1.InstanceManifestStateConverging,
+						Hash:      "sha256:0123456789abcdef",
+						Messages:  []string{"Error: Could not start Service[kubelet]\n"},
+						ExitCodes: []int{6},
+						Report: &wingv1alpha1.InstanceManifestReport{
+							ResourcesTotal:  100,
+							ResourcesFailed: 2,
+							FailedResources: []string{"File[/etc/motd]", "Service[kubelet]"},
+						},
+					},
+				},
+			},
+			exp: map[string]string{
+				"name":      "i-4",
+				"pool":      "",
+				"state":     "converging",
+				"hash":      "0123456789ab",
+				"current":   "true",
+				"exit code": "6",
+				"failed":    "File[/etc/motd], Service[kubelet]",
+				"message":   "Error: Could not start Service[kubelet]",
+			},
+		},
+	} {
+		if act := instanceParameters(c.instance, "sha256:0123456789abcdef"); !reflect.DeepEqual(act, c.exp) {
+			t.Errorf("%s: unexpected parameters:\nact=%+v\nexp=%+v", c.name, act, c.exp)
+		}
+	}
+}
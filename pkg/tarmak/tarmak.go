@@ -119,7 +119,9 @@ func New(flags *tarmakv1alpha1.Flags) *Tarmak {
 
 	}
 
-	if flags.Initialize {
+	// the validation reports errors of the config rather than failing to
+	// initialize the current cluster
+	if flags.Initialize || flags.Validate {
 		return t
 	}
 
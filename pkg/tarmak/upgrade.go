@@ -0,0 +1,439 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/cluster"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+	"github.com/jetstack/tarmak/pkg/utils/file"
+)
+
+const upgradeProgressFile = "kubernetes-upgrade.json"
+
+const (
+	upgradeStageEtcd   = "etcd"
+	upgradeStageMaster = "master"
+	upgradeStageWorker = "worker"
+)
+
+// upgradeProgress is persisted in the cluster's config folder, so an
+// interrupted upgrade can be resumed
+type upgradeProgress struct {
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Hash      string   `json:"hash,omitempty"`
+	Completed []string `json:"completed,omitempty"`
+}
+
+// upgradeStep is a set of hosts converging the upgraded manifest together
+type upgradeStep struct {
+	name  string
+	stage string
+	pool  *rollingUpdatePool
+	hosts []interfaces.Host
+}
+
+type kubernetesUpgrade struct {
+	*rollingUpdate
+
+	version string
+}
+
+// Upgrade upgrades the Kubernetes version of the cluster. Etcd is upgraded
+// first, followed by the masters one at a time and the workers pool by pool.
+func (c *CmdTarmak) Upgrade() error {
+	to := strings.TrimPrefix(c.flags.Cluster.Upgrade.To, "v")
+	if to == "" {
+		return fmt.Errorf("--to version is required")
+	}
+
+	if c.Cluster().Type() == clusterv1alpha1.ClusterTypeHub {
+		return fmt.Errorf("cluster %s is a hub, which does not run Kubernetes", c.Cluster().Name())
+	}
+
+	for _, f := range []func() error{
+		c.Validate,
+		c.writeSSHConfigForClusterHosts,
+	} {
+		if err := f(); err != nil {
+			return err
+		}
+	}
+
+	progressPath := filepath.Join(c.Cluster().ConfigPath(), upgradeProgressFile)
+	progress, err := readUpgradeProgress(progressPath)
+	if err != nil {
+		return err
+	}
+
+	if progress == nil {
+		from := c.Cluster().Config().Kubernetes.Version
+		if err := cluster.CheckKubernetesUpgrade(from, to); err != nil {
+			return err
+		}
+
+		// instances keep their manifest until it is their turn
+		if err := c.pinConvergedManifests(); err != nil {
+			return err
+		}
+
+		progress = &upgradeProgress{From: from, To: to}
+		if err := writeUpgradeProgress(progressPath, progress); err != nil {
+			return err
+		}
+		c.log.Infof("upgrading Kubernetes from %s to %s", from, to)
+	} else if progress.To != to {
+		return fmt.Errorf("an upgrade from %s to %s is in progress, resume it with --to %s", progress.From, progress.To, progress.To)
+	} else {
+		c.log.Infof("resuming upgrade of Kubernetes from %s to %s, %d steps completed", progress.From, progress.To, len(progress.Completed))
+	}
+
+	if err := c.Cluster().SetKubernetesVersion(to); err != nil {
+		return err
+	}
+
+	if err := c.Cluster().UploadConfiguration(); err != nil {
+		return err
+	}
+
+	hash, err := c.Cluster().ConfigurationHash()
+	if err != nil {
+		return fmt.Errorf("failed to build puppet manifest: %s", err)
+	}
+	if progress.Hash != "" && progress.Hash != hash {
+		return fmt.Errorf("puppet manifest changed since the upgrade started, expected %s got %s", shortHash(progress.Hash), shortHash(hash))
+	}
+	progress.Hash = hash
+	if err := writeUpgradeProgress(progressPath, progress); err != nil {
+		return err
+	}
+
+	hosts, err := c.Cluster().ListHosts()
+	if err != nil {
+		return fmt.Errorf("failed to list hosts: %s", err)
+	}
+
+	clientset, err := c.kubectl.Clientset(false)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Kubernetes API: %s", err)
+	}
+
+	u := &kubernetesUpgrade{
+		rollingUpdate: &rollingUpdate{
+			CmdTarmak:   c,
+			clientset:   clientset,
+			currentHash: hash,
+		},
+		version: to,
+	}
+
+	completed := make(map[string]bool)
+	for _, name := range progress.Completed {
+		completed[name] = true
+	}
+
+	steps, err := kubernetesUpgradeSteps(c.Cluster().InstancePools(), hosts)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if completed[step.name] {
+			c.log.Infof("%s has already been upgraded, skipping", step.name)
+			continue
+		}
+
+		if err := u.upgradeStep(step); err != nil {
+			return fmt.Errorf("upgrade of %s failed: %s", step.name, err)
+		}
+
+		progress.Completed = append(progress.Completed, step.name)
+		if err := writeUpgradeProgress(progressPath, progress); err != nil {
+			return err
+		}
+	}
+
+	// remove the pins, so all remaining instances converge the latest manifest
+	if err := c.Cluster().ReapplyConfiguration(); err != nil {
+		return err
+	}
+	if err := c.Cluster().WaitForConvergance(); err != nil {
+		return err
+	}
+
+	if err := u.waitFor("Kubernetes API", u.checkAPIServer); err != nil {
+		return err
+	}
+
+	if err := os.Remove(progressPath); err != nil {
+		return fmt.Errorf("failed to remove upgrade progress: %s", err)
+	}
+
+	c.log.Infof("upgrade of Kubernetes to %s finished", to)
+	return nil
+}
+
+// pinConvergedManifests pins every instance to the manifest it has converged,
+// it fails if an instance has not converged
+func (c *CmdTarmak) pinConvergedManifests() error {
+	instances, err := c.Cluster().Instances()
+	if err != nil {
+		return fmt.Errorf("failed to list instances: %s", err)
+	}
+
+	byHash, err := convergedManifests(instances)
+	if err != nil {
+		return err
+	}
+
+	var hashes []string
+	for hash := range byHash {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+
+	for _, hash := range hashes {
+		if err := c.Cluster().RollbackConfiguration(hash, byHash[hash]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// convergedManifests returns the names of the instances by the hash of the
+// manifest they have converged
+func convergedManifests(instances []*wingv1alpha1.Instance) (map[string][]string, error) {
+	byHash := make(map[string][]string)
+	var notConverged []string
+
+	for _, instance := range instances {
+		if instance.Status == nil || instance.Status.Converge == nil ||
+			instance.Status.Converge.State != wingv1alpha1.InstanceManifestStateConverged ||
+			instance.Status.Converge.Hash == "" {
+			notConverged = append(notConverged, instance.Name)
+			continue
+		}
+		byHash[instance.Status.Converge.Hash] = append(byHash[instance.Status.Converge.Hash], instance.Name)
+	}
+
+	if len(notConverged) > 0 {
+		sort.Strings(notConverged)
+		return nil, fmt.Errorf("instances %s have not converged, run 'tarmak cluster apply' first", strings.Join(notConverged, ", "))
+	}
+
+	return byHash, nil
+}
+
+// kubernetesUpgradeSteps orders the hosts of the Kubernetes instance pools:
+// etcd and master hosts are upgraded one at a time, workers pool by pool
+func kubernetesUpgradeSteps(instancePools []interfaces.InstancePool, hosts []interfaces.Host) ([]*upgradeStep, error) {
+	stages := map[string]string{
+		clusterv1alpha1.KubernetesEtcdRoleName:   upgradeStageEtcd,
+		clusterv1alpha1.KubernetesMasterRoleName: upgradeStageMaster,
+		"etcd-master":                            upgradeStageMaster,
+		clusterv1alpha1.KubernetesWorkerRoleName: upgradeStageWorker,
+	}
+
+	var steps []*upgradeStep
+	for _, stage := range []string{upgradeStageEtcd, upgradeStageMaster, upgradeStageWorker} {
+		for _, instancePool := range instancePools {
+			roleName := instancePool.Role().Name()
+			if stages[roleName] != stage {
+				continue
+			}
+
+			pool := &rollingUpdatePool{
+				name: instancePool.Name(),
+				role: roleName,
+			}
+
+			poolHosts, err := instancePoolHosts(instancePools, instancePool, hosts)
+			if err != nil {
+				return nil, err
+			}
+			if len(poolHosts) == 0 {
+				continue
+			}
+			sort.Slice(poolHosts, func(i, j int) bool {
+				return poolHosts[i].ID() < poolHosts[j].ID()
+			})
+
+			if stage == upgradeStageWorker {
+				steps = append(steps, &upgradeStep{
+					name:  pool.name,
+					stage: stage,
+					pool:  pool,
+					hosts: poolHosts,
+				})
+				continue
+			}
+
+			for _, host := range poolHosts {
+				steps = append(steps, &upgradeStep{
+					name:  fmt.Sprintf("%s/%s", pool.name, host.ID()),
+					stage: stage,
+					pool:  pool,
+					hosts: []interfaces.Host{host},
+				})
+			}
+		}
+	}
+
+	return steps, nil
+}
+
+func (u *kubernetesUpgrade) upgradeStep(step *upgradeStep) error {
+	var ids []string
+	for _, host := range step.hosts {
+		ids = append(ids, host.ID())
+	}
+
+	if step.stage != upgradeStageWorker {
+		if err := u.waitFor(fmt.Sprintf("quorum of pool %s", step.pool.name), func() error {
+			return u.checkQuorum(step.pool)
+		}); err != nil {
+			return err
+		}
+	}
+
+	u.log.Infof("upgrading %s", step.name)
+	if err := u.Cluster().RollbackConfiguration(u.currentHash, ids); err != nil {
+		return err
+	}
+
+	if err := u.waitFor(fmt.Sprintf("convergence of %s", strings.Join(ids, ", ")), func() error {
+		return u.checkUpgraded(step.hosts)
+	}); err != nil {
+		return err
+	}
+
+	if err := u.waitFor("Kubernetes API", u.checkAPIServer); err != nil {
+		return err
+	}
+
+	switch step.stage {
+	case upgradeStageWorker:
+		if err := u.waitFor(fmt.Sprintf("nodes of pool %s", step.pool.name), func() error {
+			return u.checkKubelets(step.hosts)
+		}); err != nil {
+			return err
+		}
+	default:
+		if err := u.waitFor(fmt.Sprintf("quorum of pool %s", step.pool.name), func() error {
+			return u.checkQuorum(step.pool)
+		}); err != nil {
+			return err
+		}
+	}
+
+	u.log.Infof("upgraded %s", step.name)
+	return nil
+}
+
+// checkUpgraded verifies that the hosts have converged the upgraded manifest
+func (u *kubernetesUpgrade) checkUpgraded(hosts []interfaces.Host) error {
+	instances, err := u.Cluster().Instances()
+	if err != nil {
+		return err
+	}
+	instancesByName := make(map[string]*wingv1alpha1.Instance)
+	for _, instance := range instances {
+		instancesByName[instance.Name] = instance
+	}
+
+	for _, host := range hosts {
+		if err := upgradeConverged(instancesByName[host.ID()], u.currentHash); err != nil {
+			return fmt.Errorf("instance %s %s", host.ID(), err)
+		}
+	}
+
+	return nil
+}
+
+func upgradeConverged(instance *wingv1alpha1.Instance, hash string) error {
+	if instance == nil || instance.Status == nil || instance.Status.Converge == nil {
+		return fmt.Errorf("has not reported to wing yet")
+	}
+
+	status := instance.Status.Converge
+	if status.Hash != hash {
+		return fmt.Errorf("has not converged manifest %s yet", shortHash(hash))
+	}
+	if status.State != wingv1alpha1.InstanceManifestStateConverged {
+		return fmt.Errorf("is in state %s", status.State)
+	}
+
+	return nil
+}
+
+// checkAPIServer verifies that the API server is healthy
+func (u *kubernetesUpgrade) checkAPIServer() error {
+	if _, err := u.clientset.Discovery().RESTClient().Get().AbsPath("/healthz").Do().Raw(); err != nil {
+		return fmt.Errorf("API server is not healthy: %s", err)
+	}
+	return nil
+}
+
+// checkKubelets verifies that the nodes of the hosts are ready and run the
+// upgraded kubelet
+func (u *kubernetesUpgrade) checkKubelets(hosts []interfaces.Host) error {
+	nodes, err := u.clientset.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	for _, host := range hosts {
+		node := nodeForHost(nodes.Items, host)
+		if node == nil {
+			return fmt.Errorf("host %s has not registered a node", host.ID())
+		}
+		if !nodeReady(node) {
+			return fmt.Errorf("node %s is not ready", node.Name)
+		}
+		if kubelet := strings.TrimPrefix(node.Status.NodeInfo.KubeletVersion, "v"); kubelet != u.version {
+			return fmt.Errorf("node %s runs kubelet %s", node.Name, kubelet)
+		}
+	}
+
+	return nil
+}
+
+func readUpgradeProgress(path string) (*upgradeProgress, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read upgrade progress: %s", err)
+	}
+
+	progress := &upgradeProgress{}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, fmt.Errorf("failed to parse upgrade progress %s: %s", path, err)
+	}
+	return progress, nil
+}
+
+func writeUpgradeProgress(path string, progress *upgradeProgress) error {
+	data, err := json.MarshalIndent(progress, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := file.WriteAtomic(path, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write upgrade progress: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	wingv1alpha1 "github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+func TestUpgrade_kubernetesUpgradeSteps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	instancePools := []interfaces.InstancePool{
+		fakeInstancePool(ctrl, "bastion", "bastion", true),
+		fakeInstancePool(ctrl, "worker", "worker", false),
+		fakeInstancePool(ctrl, "master", "master", false),
+		fakeInstancePool(ctrl, "etcd", "etcd", true),
+		fakeInstancePool(ctrl, "vault", "vault", true),
+		fakeInstancePool(ctrl, "empty", "worker-empty", false),
+	}
+	hosts := []interfaces.Host{
+		fakeHost(ctrl, "i-bastion", "192.0.2.1", "bastion"),
+		fakeHost(ctrl, "i-etcd-2", "10.0.0.2", "etcd-2"),
+		fakeHost(ctrl, "i-etcd-1", "10.0.0.1", "etcd-1"),
+		fakeHost(ctrl, "i-master-1", "10.0.1.1", "master"),
+		fakeHost(ctrl, "i-master-2", "10.0.1.2", "master"),
+		fakeHost(ctrl, "i-worker-1", "10.0.2.1", "worker"),
+		fakeHost(ctrl, "i-worker-2", "10.0.2.2", "worker"),
+		fakeHost(ctrl, "i-vault-1", "10.0.3.1", "vault-1"),
+	}
+
+	steps, err := kubernetesUpgradeSteps(instancePools, hosts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var act [][]string
+	for _, step := range steps {
+		act = append(act, append([]string{step.name, step.stage}, hostIDs(step.hosts)...))
+	}
+
+	exp := [][]string{
+		{"etcd/i-etcd-1", "etcd", "i-etcd-1"},
+		{"etcd/i-etcd-2", "etcd", "i-etcd-2"},
+		{"master/i-master-1", "master", "i-master-1"},
+		{"master/i-master-2", "master", "i-master-2"},
+		{"worker", "worker", "i-worker-1", "i-worker-2"},
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected steps:\nact=%v\nexp=%v", act, exp)
+	}
+}
+
+func TestUpgrade_kubernetesUpgradeSteps_sharedRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	instancePools := []interfaces.InstancePool{
+		fakeInstancePool(ctrl, "worker", "worker", false),
+		fakeInstancePool(ctrl, "worker-gpu", "worker", false),
+	}
+	hosts := []interfaces.Host{
+		fakePoolHost(ctrl, "i-worker-1", "10.0.2.1", "worker", "worker"),
+		fakePoolHost(ctrl, "i-worker-gpu-1", "10.0.2.2", "worker-gpu", "worker"),
+		fakePoolHost(ctrl, "i-worker-2", "10.0.2.3", "worker", "worker"),
+	}
+
+	steps, err := kubernetesUpgradeSteps(instancePools, hosts)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var act [][]string
+	for _, step := range steps {
+		act = append(act, append([]string{step.name, step.stage}, hostIDs(step.hosts)...))
+	}
+
+	exp := [][]string{
+		{"worker", "worker", "i-worker-1", "i-worker-2"},
+		{"worker-gpu", "worker", "i-worker-gpu-1"},
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected steps:\nact=%v\nexp=%v", act, exp)
+	}
+
+	hosts = append(hosts, fakeHost(ctrl, "i-worker-3", "10.0.2.4", "worker"))
+	if _, err := kubernetesUpgradeSteps(instancePools, hosts); err == nil {
+		t.Error("expected an error for a host without an instance pool tag")
+	}
+}
+
+func TestUpgrade_convergedManifests(t *testing.T) {
+	instance := func(name string, state wingv1alpha1.InstanceManifestState, hash string) *wingv1alpha1.Instance {
+		i := &wingv1alpha1.Instance{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if state != "" {
+			i.Status = &wingv1alpha1.InstanceStatus{
+				Converge: &wingv1alpha1.InstanceStatusManifest{State: state, Hash: hash},
+			}
+		}
+		return i
+	}
+
+	byHash, err := convergedManifests([]*wingv1alpha1.Instance{
+		instance("i-1", wingv1alpha1.InstanceManifestStateConverged, "sha256:aaa"),
+		instance("i-2", wingv1alpha1.InstanceManifestStateConverged, "sha256:bbb"),
+		instance("i-3", wingv1alpha1.InstanceManifestStateConverged, "sha256:aaa"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if exp := map[string][]string{"sha256:aaa": {"i-1", "i-3"}, "sha256:bbb": {"i-2"}}; !reflect.DeepEqual(byHash, exp) {
+		t.Errorf("unexpected manifests: act=%v exp=%v", byHash, exp)
+	}
+
+	_, err = convergedManifests([]*wingv1alpha1.Instance{
+		instance("i-1", wingv1alpha1.InstanceManifestStateConverged, "sha256:aaa"),
+		instance("i-2", wingv1alpha1.InstanceManifestStateError, "sha256:aaa"),
+		instance("i-3", "", ""),
+	})
+	if err == nil {
+		t.Error("expected an error for instances that have not converged")
+	}
+}
+
+func TestUpgrade_upgradeConverged(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		instance *wingv1alpha1.Instance
+		valid    bool
+	}{
+		{
+			name:     "not reported",
+			instance: &wingv1alpha1.Instance{},
+		},
+		{
+			name: "previous manifest",
+			instance: &wingv1alpha1.Instance{Status: &wingv1alpha1.InstanceStatus{
+				Converge: &wingv1alpha1.InstanceStatusManifest{State: wingv1alpha1.InstanceManifestStateConverged, Hash: "sha256:old"},
+			}},
+		},
+		{
+			name: "converging",
+			instance: &wingv1alpha1.Instance{Status: &wingv1alpha1.InstanceStatus{
+				Converge: &wingv1alpha1.InstanceStatusManifest{State: wingv1alpha1.InstanceManifestStateConverging, Hash: "sha256:new"},
+			}},
+		},
+		{
+			name: "converged",
+			instance: &wingv1alpha1.Instance{Status: &wingv1alpha1.InstanceStatus{
+				Converge: &wingv1alpha1.InstanceStatusManifest{State: wingv1alpha1.InstanceManifestStateConverged, Hash: "sha256:new"},
+			}},
+			valid: true,
+		},
+	} {
+		err := upgradeConverged(c.instance, "sha256:new")
+		if c.valid && err != nil {
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
+
+func TestUpgrade_progress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tarmak-upgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, upgradeProgressFile)
+
+	progress, err := readUpgradeProgress(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if progress != nil {
+		t.Errorf("expected no progress, got %+v", progress)
+	}
+
+	exp := &upgradeProgress{
+		From:      "1.12.9",
+		To:        "1.13.5",
+		Hash:      "sha256:new",
+		Completed: []string{"etcd/i-etcd-1", "master/i-master-1"},
+	}
+	if err := writeUpgradeProgress(path, exp); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	progress, err = readUpgradeProgress(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(progress, exp) {
+		t.Errorf("unexpected progress: act=%+v exp=%+v", progress, exp)
+	}
+}
@@ -2,12 +2,89 @@
 package utils
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
 	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputTable = "table"
+	OutputWide  = "wide"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
 )
 
+var OutputFormats = []string{OutputTable, OutputWide, OutputJSON, OutputYAML}
+
+// List prints a list either as a table of parameters or serialises the
+// versioned list object. The table starts with the columns given by keys,
+// followed by all other parameters except wideKeys, wide adds the wideKeys
+// set in any of the parameters after keys.
+func List(out io.Writer, format string, list interface{}, keys []string, wideKeys []string, varMaps []map[string]string) error {
+	switch format {
+	case OutputTable, "":
+		ListParameters(out, keys, withoutParameters(wideKeys, varMaps))
+
+	case OutputWide:
+		ListParameters(out, append(keys[:len(keys):len(keys)], presentParameters(wideKeys, varMaps)...), varMaps)
+
+	case OutputJSON:
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error serialising list: %s", err)
+		}
+		fmt.Fprintf(out, "%s\n", data)
+
+	case OutputYAML:
+		data, err := yaml.Marshal(list)
+		if err != nil {
+			return fmt.Errorf("error serialising list: %s", err)
+		}
+		fmt.Fprintf(out, "%s", data)
+
+	default:
+		return fmt.Errorf("unknown output format '%s', valid formats are: %s", format, strings.Join(OutputFormats, ", "))
+	}
+
+	return nil
+}
+
+func withoutParameters(keys []string, varMaps []map[string]string) []map[string]string {
+	if len(keys) == 0 {
+		return varMaps
+	}
+
+	filtered := make([]map[string]string, len(varMaps))
+	for pos, varMap := range varMaps {
+		filtered[pos] = map[string]string{}
+		for key, val := range varMap {
+			filtered[pos][key] = val
+		}
+		for _, key := range keys {
+			delete(filtered[pos], key)
+		}
+	}
+	return filtered
+}
+
+// presentParameters returns the keys which are set in any of the parameters
+func presentParameters(keys []string, varMaps []map[string]string) []string {
+	var present []string
+	for _, key := range keys {
+		for _, varMap := range varMaps {
+			if _, ok := varMap[key]; ok {
+				present = append(present, key)
+				break
+			}
+		}
+	}
+	return present
+}
+
 func ListParameters(out io.Writer, keys []string, varMaps []map[string]string) {
 
 	inlistMap := map[string]bool{}
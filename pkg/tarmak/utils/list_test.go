@@ -0,0 +1,81 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type testList struct {
+	Kind  string   `json:"kind"`
+	Items []string `json:"items"`
+}
+
+func TestList(t *testing.T) {
+	list := &testList{Kind: "TestList", Items: []string{"a", "b"}}
+	varMaps := []map[string]string{
+		{"name": "a", "extra": "x"},
+		{"name": "b"},
+	}
+
+	for _, c := range []struct {
+		format string
+		exp    string
+	}{
+		{OutputTable, "NAME\t\na \t\nb \t\n"},
+		{OutputWide, "NAME\tEXTRA\t\na \tx \t\nb \t\t\n"},
+		{OutputJSON, "{\n  \"kind\": \"TestList\",\n  \"items\": [\n    \"a\",\n    \"b\"\n  ]\n}\n"},
+		{OutputYAML, "items:\n- a\n- b\nkind: TestList\n"},
+	} {
+		out := new(bytes.Buffer)
+		if err := List(out, c.format, list, []string{"name"}, []string{"extra"}, varMaps); err != nil {
+			t.Errorf("%s: unexpected error: %s", c.format, err)
+			continue
+		}
+		if act := out.String(); act != c.exp {
+			t.Errorf("%s: unexpected output:\nact=%q\nexp=%q", c.format, act, c.exp)
+		}
+	}
+
+	// without wide keys the table contains all parameters
+	out := new(bytes.Buffer)
+	if err := List(out, OutputTable, list, []string{"name"}, nil, varMaps); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if act, exp := out.String(), "NAME\tEXTRA\t\na \tx \t\nb \t\t\n"; act != exp {
+		t.Errorf("unexpected output:\nact=%q\nexp=%q", act, exp)
+	}
+
+	if err := List(new(bytes.Buffer), "xml", list, []string{"name"}, nil, varMaps); err == nil || !strings.Contains(err.Error(), "unknown output format 'xml'") {
+		t.Errorf("expected unknown format error, got: %v", err)
+	}
+}
+
+func TestList_WideAddsWideKeys(t *testing.T) {
+	varMaps := []map[string]string{
+		{"name": "a", "zone": "z", "tags": "t", "other": "o"},
+		{"name": "b", "zone": "y"},
+	}
+
+	table := new(bytes.Buffer)
+	if err := List(table, OutputTable, nil, []string{"name"}, []string{"zone", "unset", "tags"}, varMaps); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if act, exp := table.String(), "NAME\tOTHER\t\na \to \t\nb \t\t\n"; act != exp {
+		t.Errorf("unexpected table output:\nact=%q\nexp=%q", act, exp)
+	}
+
+	// wide keys follow keys in their order, unset wide keys are left out
+	wide := new(bytes.Buffer)
+	if err := List(wide, OutputWide, nil, []string{"name"}, []string{"zone", "unset", "tags"}, varMaps); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if act, exp := wide.String(), "NAME\tZONE\tTAGS\tOTHER\t\na \tz \tt \to \t\nb \ty \t\t\t\n"; act != exp {
+		t.Errorf("unexpected wide output:\nact=%q\nexp=%q", act, exp)
+	}
+
+	if table.String() == wide.String() {
+		t.Error("expected wide output to differ from table output")
+	}
+}
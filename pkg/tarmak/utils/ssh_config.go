@@ -0,0 +1,50 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
+)
+
+// HostSSHConfig generates the ssh_config entry for a host of a cluster. Hosts
+// without a public hostname are reached through the bastion.
+func HostSSHConfig(h interfaces.Host, hostnamePublic bool, cluster interfaces.Cluster, strictChecking string) string {
+	config := fmt.Sprintf(`host %s
+    User %s
+    Hostname %s
+
+    # use custom host key file per cluster
+    UserKnownHostsFile %s
+    StrictHostKeyChecking %s
+
+    # enable connection multiplexing
+    ControlPath %s/ssh-control-%%r@%%h:%%p
+    ControlMaster auto
+    ControlPersist 10m
+
+    # keep connections alive
+    ServerAliveInterval 60
+    IdentitiesOnly yes
+    IdentityFile %s
+`,
+		strings.Join(append(h.Aliases(), h.ID()), " "),
+		h.User(),
+		h.Hostname(),
+		cluster.SSHHostKeysPath(),
+		strictChecking,
+		os.TempDir(),
+		cluster.Environment().SSHPrivateKeyPath(),
+	)
+
+	if !hostnamePublic {
+		config += fmt.Sprintf(
+			"    ProxyCommand ssh -F %s -W %%h:%%p bastion\n",
+			cluster.SSHConfigPath(),
+		)
+	}
+	config += "\n"
+	return config
+}
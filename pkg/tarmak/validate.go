@@ -0,0 +1,126 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
+	tarmakv1alpha1 "github.com/jetstack/tarmak/pkg/apis/tarmak/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/tarmak/cluster"
+	"github.com/jetstack/tarmak/pkg/tarmak/environment"
+	"github.com/jetstack/tarmak/pkg/tarmak/instance_pool"
+	"github.com/jetstack/tarmak/pkg/tarmak/lint"
+	"github.com/jetstack/tarmak/pkg/tarmak/utils"
+)
+
+// ValidateConfig validates the configuration of the current or all
+// environments without contacting any cloud provider and reports every error
+// and warning found
+func (c *CmdTarmak) ValidateConfig() error {
+	var environments []*tarmakv1alpha1.Environment
+	if c.flags.Cluster.Validate.AllEnvironments {
+		environments = c.Config().Environments()
+	} else {
+		name, err := c.Config().CurrentEnvironmentName()
+		if err != nil {
+			return fmt.Errorf("failed to retrieve current environment: %s", err)
+		}
+		conf, err := c.Config().Environment(name)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve environment %s: %s", name, err)
+		}
+		environments = append(environments, conf)
+	}
+
+	findings := &lint.Findings{}
+	for _, conf := range environments {
+		c.validateEnvironment(findings, conf)
+	}
+
+	if err := listValidationFindings(os.Stdout, c.flags.Output, findings.Items()); err != nil {
+		return err
+	}
+
+	if findings.Errors() > 0 {
+		return fmt.Errorf("configuration is invalid, found %d error(s) and %d warning(s)", findings.Errors(), findings.Warnings())
+	}
+
+	c.log.Infof("configuration is valid, found %d warning(s)", findings.Warnings())
+	return nil
+}
+
+// validateEnvironment runs the validations of the fields first, so their
+// errors are reported at the most precise location, followed by the
+// validations of the environment, its clusters and instance pools
+func (c *CmdTarmak) validateEnvironment(findings *lint.Findings, conf *tarmakv1alpha1.Environment) {
+	envPath := lint.EnvironmentPath(conf.Name)
+
+	// order the hub first, as the clusters are validated against it
+	var clusterConfs []*clusterv1alpha1.Cluster
+	for _, clusterConf := range c.Config().Clusters(conf.Name) {
+		if clusterConf.Name == clusterv1alpha1.ClusterTypeHub {
+			clusterConfs = append([]*clusterv1alpha1.Cluster{clusterConf}, clusterConfs...)
+		} else {
+			clusterConfs = append(clusterConfs, clusterConf)
+		}
+	}
+
+	lint.Environment(findings, conf)
+	lint.NetworkOverlap(findings, clusterConfs)
+	for _, clusterConf := range clusterConfs {
+		lint.Cluster(findings, clusterConf)
+	}
+
+	env, err := environment.NewFromConfig(c.Tarmak, conf, nil)
+	if err != nil {
+		findings.Error(envPath.Child("provider"), err)
+		return
+	}
+	findings.Error(envPath, env.Validate())
+
+	for _, clusterConf := range clusterConfs {
+		clusterPath := lint.ClusterPath(clusterConf)
+
+		cl, err := cluster.NewFromConfig(env, clusterConf)
+		if cl == nil {
+			findings.Error(clusterPath, err)
+			continue
+		}
+		if len(clusterConfs) == 1 || clusterConf.Name == clusterv1alpha1.ClusterTypeHub {
+			env.HubCluster = cl
+		}
+
+		for pos := range clusterConf.InstancePools {
+			poolConf := clusterConf.InstancePools[pos]
+			poolPath := clusterPath.Child("instancePools").Key(poolConf.Name)
+
+			pool, err := instance_pool.NewFromConfig(cl, &poolConf)
+			findings.Error(poolPath, err)
+			if pool != nil {
+				findings.Error(poolPath, pool.Validate())
+			}
+		}
+		findings.Error(clusterPath, err)
+		findings.Error(clusterPath, cl.Validate())
+	}
+}
+
+func listValidationFindings(out io.Writer, format string, items []tarmakv1alpha1.ValidationFinding) error {
+	list := &tarmakv1alpha1.ValidationFindingList{}
+	list.APIVersion = tarmakv1alpha1.SchemeGroupVersion.String()
+	list.Kind = "ValidationFindingList"
+
+	varMaps := make([]map[string]string, 0)
+	for _, item := range items {
+		varMaps = append(varMaps, map[string]string{
+			"severity": item.Severity,
+			"field":    item.Field,
+			"message":  item.Message,
+		})
+		list.Items = append(list.Items, item)
+	}
+
+	return utils.List(out, format, list, []string{"severity", "field", "message"}, nil, varMaps)
+}
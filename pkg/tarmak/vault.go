@@ -0,0 +1,71 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package tarmak
+
+import (
+	"fmt"
+)
+
+// VaultRotateRootToken replaces the root token of the environment's vault
+// cluster and revokes the old one
+func (c *CmdTarmak) VaultRotateRootToken() error {
+	fqdns, vaultCA, err := c.setupVault()
+	if err != nil {
+		return err
+	}
+
+	if err := c.Environment().Vault().RotateRootToken(fqdns, vaultCA); err != nil {
+		return fmt.Errorf("failed to rotate vault root token: %s", err)
+	}
+
+	c.log.Info("vault root token rotated")
+	return nil
+}
+
+// VaultRekey replaces the unseal key of the environment's vault cluster
+func (c *CmdTarmak) VaultRekey() error {
+	fqdns, vaultCA, err := c.setupVault()
+	if err != nil {
+		return err
+	}
+
+	if err := c.Environment().Vault().Rekey(fqdns, vaultCA); err != nil {
+		return fmt.Errorf("failed to rekey vault: %s", err)
+	}
+
+	c.log.Info("vault rekeyed")
+	return nil
+}
+
+func (c *CmdTarmak) setupVault() ([]string, string, error) {
+	for _, f := range []func() error{
+		c.Validate,
+		c.writeSSHConfigForClusterHosts,
+	} {
+		if err := f(); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return c.vaultInstances()
+}
+
+// vaultInstances returns the FQDNs of the vault instances and their CA from
+// the hub's outputs
+func (c *CmdTarmak) vaultInstances() ([]string, string, error) {
+	outputs, err := c.Environment().Hub().TerraformOutput()
+	if err != nil {
+		return nil, "", err
+	}
+
+	fqdnsIntf, ok := outputs["instance_fqdns"].([]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("hub has no vault instance FQDNs")
+	}
+	var fqdns []string
+	for _, fqdn := range fqdnsIntf {
+		fqdns = append(fqdns, fmt.Sprintf("%v", fqdn))
+	}
+	vaultCA, _ := outputs["vault_ca"].(string)
+
+	return fqdns, vaultCA, nil
+}
@@ -0,0 +1,185 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package vault
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cenkalti/backoff"
+	"github.com/google/uuid"
+	vault "github.com/hashicorp/vault/api"
+	"github.com/jetstack/vault-unsealer/pkg/kv"
+
+	"github.com/jetstack/tarmak/pkg/utils/file"
+)
+
+// RotateRootToken replaces the root token with a newly generated one and
+// revokes the old one. Tokens created by the old root token stay valid.
+func (v *Vault) RotateRootToken(instances []string, vaultCA string) error {
+	path := v.rootTokenPath()
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("unable to read vault root token %s: %s", path, err)
+	}
+
+	oldToken, err := v.RootToken()
+	if err != nil {
+		return err
+	}
+
+	tunnel, err := v.TunnelFromFQDNs(instances, vaultCA)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Stop()
+
+	cl := tunnel.VaultClient()
+	cl.SetToken(oldToken)
+
+	// keep the new token on disk before vault knows it, so it can't get lost
+	newToken := uuid.New().String()
+	pendingPath := path + ".new"
+	if err := file.WriteAtomic(pendingPath, bytes.NewReader([]byte(fmt.Sprintf("%s\n", newToken)))); err != nil {
+		return err
+	}
+
+	_, err = cl.Auth().Token().CreateOrphan(&vault.TokenCreateRequest{
+		ID:          newToken,
+		Policies:    []string{"root"},
+		DisplayName: "root-token",
+		NoParent:    true,
+	})
+	if err != nil {
+		os.Remove(pendingPath)
+		return fmt.Errorf("error creating new root token: %s", err)
+	}
+
+	cl.SetToken(newToken)
+	if _, err := cl.Auth().Token().LookupSelf(); err != nil {
+		return fmt.Errorf("error verifying new root token, it has been kept in %s: %s", pendingPath, err)
+	}
+
+	if err := os.Rename(pendingPath, path); err != nil {
+		return fmt.Errorf("error replacing vault root token %s, the new one has been kept in %s: %s", path, pendingPath, err)
+	}
+	v.log.Infof("new root token written to %s", path)
+
+	// children of the old token are used by instances, so only revoke the
+	// token itself
+	if err := cl.Auth().Token().RevokeOrphan(oldToken); err != nil {
+		return fmt.Errorf("error revoking old root token: %s", err)
+	}
+	v.log.Info("old root token revoked")
+
+	return nil
+}
+
+// Rekey replaces the unseal key of the vault cluster and stores the new one
+// in the provider's KV, where the unsealers of the instances read it from
+func (v *Vault) Rekey(instances []string, vaultCA string) error {
+	rootToken, err := v.RootToken()
+	if err != nil {
+		return err
+	}
+
+	kvService, err := v.cluster.Environment().Provider().VaultKV()
+	if err != nil {
+		return err
+	}
+
+	keyID := unsealKeyForID(0)
+	oldKey, err := kvService.Get(keyID)
+	if err != nil {
+		return fmt.Errorf("error reading unseal key '%s': %s", keyID, err)
+	}
+
+	tunnel, err := v.TunnelFromFQDNs(instances, vaultCA)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Stop()
+
+	cl := tunnel.VaultClient()
+	cl.SetToken(rootToken)
+
+	status, err := cl.Sys().RekeyStatus()
+	if err != nil {
+		return fmt.Errorf("error getting rekey status: %s", err)
+	}
+	if status.Started {
+		v.log.Warnf("cancelling rekey in progress with nonce %s", status.Nonce)
+		if err := cl.Sys().RekeyCancel(); err != nil {
+			return fmt.Errorf("error cancelling rekey in progress: %s", err)
+		}
+	}
+
+	// vault-unsealer initialised vault with a single share
+	status, err = cl.Sys().RekeyInit(&vault.RekeyInitRequest{
+		SecretShares:    1,
+		SecretThreshold: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("error starting rekey: %s", err)
+	}
+
+	resp, err := cl.Sys().RekeyUpdate(string(oldKey), status.Nonce)
+	if err != nil {
+		cl.Sys().RekeyCancel()
+		return fmt.Errorf("error rekeying vault: %s", err)
+	}
+	if !resp.Complete || len(resp.Keys) != 1 {
+		cl.Sys().RekeyCancel()
+		return fmt.Errorf("rekey did not complete, got %d keys", len(resp.Keys))
+	}
+
+	// the old key is invalid from now on, keep the new one on disk until the
+	// KV has it
+	newKey := resp.Keys[0]
+	pendingPath := filepath.Join(v.cluster.Environment().ConfigPath(), "vault_unseal_key.new")
+	if err := file.WriteAtomic(pendingPath, bytes.NewReader([]byte(fmt.Sprintf("%s\n", newKey)))); err != nil {
+		return fmt.Errorf("error keeping new unseal key, it needs to be stored as '%s' manually: %s", keyID, err)
+	}
+
+	if err := v.storeKey(kvService, keyID, []byte(newKey)); err != nil {
+		return fmt.Errorf("error storing new unseal key '%s', it has been kept in %s: %s", keyID, pendingPath, err)
+	}
+
+	if err := os.Remove(pendingPath); err != nil {
+		return fmt.Errorf("error removing %s: %s", pendingPath, err)
+	}
+
+	v.log.Infof("new unseal key stored as '%s'", keyID)
+	return nil
+}
+
+// storeKey writes a key to the KV and reads it back
+func (v *Vault) storeKey(kvService kv.Service, key string, value []byte) error {
+	storeFunc := func() error {
+		if err := kvService.Set(key, value); err != nil {
+			v.log.Warnf("error storing '%s': %s", key, err)
+			return err
+		}
+
+		stored, err := kvService.Get(key)
+		if err != nil {
+			v.log.Warnf("error reading back '%s': %s", key, err)
+			return err
+		}
+		if !bytes.Equal(stored, value) {
+			return fmt.Errorf("stored value of '%s' differs", key)
+		}
+
+		return nil
+	}
+
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.MaxElapsedTime = time.Minute
+
+	return backoff.Retry(storeFunc, expBackoff)
+}
+
+func unsealKeyForID(i int) string {
+	return fmt.Sprintf("%s-unseal-%d", keyPrefix, i)
+}
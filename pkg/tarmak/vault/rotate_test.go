@@ -0,0 +1,51 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package vault
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jetstack/vault-unsealer/pkg/kv"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeKV struct {
+	values   map[string][]byte
+	failSets int
+}
+
+var _ kv.Service = &fakeKV{}
+
+func (f *fakeKV) Set(key string, value []byte) error {
+	if f.failSets > 0 {
+		f.failSets--
+		return errors.New("throttled")
+	}
+	f.values[key] = value
+	return nil
+}
+
+func (f *fakeKV) Get(key string) ([]byte, error) {
+	value, ok := f.values[key]
+	if !ok {
+		return nil, kv.NewNotFoundError("key '%s' not found", key)
+	}
+	return value, nil
+}
+
+func (f *fakeKV) Test(key string) error {
+	return nil
+}
+
+func TestVault_storeKey(t *testing.T) {
+	v := &Vault{log: logrus.WithField("test", true)}
+	store := &fakeKV{values: map[string][]byte{"vault-unseal-0": []byte("old")}, failSets: 1}
+
+	if err := v.storeKey(store, unsealKeyForID(0), []byte("new")); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if act := string(store.values["vault-unseal-0"]); act != "new" {
+		t.Errorf("unexpected unseal key: %s", act)
+	}
+}
@@ -33,6 +33,9 @@ const (
 
 const (
 	Retries = 60
+
+	// prefix of the keys vault-unsealer stores in the provider's KV
+	keyPrefix = "vault"
 )
 
 var _ interfaces.Vault = &Vault{}
@@ -239,7 +242,7 @@ func (v *Vault) VerifyInitFromFQDNs(instances []string, vaultCA, vaultKMSKeyID,
 
 		} else if !health.Initialized {
 			unsealer, err := vaultUnsealer.New(kv, cl, vaultUnsealer.Config{
-				KeyPrefix: "vault",
+				KeyPrefix: keyPrefix,
 
 				SecretShares:    1,
 				SecretThreshold: 1,
@@ -287,3 +290,33 @@ func (v *Vault) VerifyInitFromFQDNs(instances []string, vaultCA, vaultKMSKeyID,
 
 	return nil
 }
+
+// UnsealedFromFQDNs returns the FQDNs of all vault instances that are
+// initialised and unsealed
+func (v *Vault) UnsealedFromFQDNs(instances []string, vaultCA string) ([]string, error) {
+	tunnels, err := v.createTunnelsWithCA(instances, vaultCA)
+	if err != nil {
+		return nil, err
+	}
+
+	var unsealed []string
+	for _, t := range tunnels {
+		if err := t.Start(); err != nil {
+			v.log.Debugf("failed to connect to vault instance %s: %s", t.FQDN(), err)
+			continue
+		}
+
+		health, err := t.VaultClient().Sys().Health()
+		t.Stop()
+		if err != nil {
+			v.log.Debugf("failed to get status of vault instance %s: %s", t.FQDN(), err)
+			continue
+		}
+
+		if health.Initialized && !health.Sealed {
+			unsealed = append(unsealed, t.FQDN())
+		}
+	}
+
+	return unsealed, nil
+}
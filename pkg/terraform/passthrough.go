@@ -12,11 +12,11 @@ import (
 	"github.com/hashicorp/terraform/command"
 	"github.com/hashicorp/terraform/plugin"
 	"github.com/mitchellh/cli"
-	provideraws "github.com/terraform-providers/terraform-provider-aws/aws"
 	providerrandom "github.com/terraform-providers/terraform-provider-random/random"
 	providertemplate "github.com/terraform-providers/terraform-provider-template/template"
 	providertls "github.com/terraform-providers/terraform-provider-tls/tls"
 
+	provideraws "github.com/jetstack/tarmak/pkg/terraform/providers/aws"
 	providerawstag "github.com/jetstack/tarmak/pkg/terraform/providers/awstag"
 	providertarmak "github.com/jetstack/tarmak/pkg/terraform/providers/tarmak"
 )
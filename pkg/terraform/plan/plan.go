@@ -55,7 +55,7 @@ func (p *Plan) UpdatingPuppet() bool {
 	for _, module := range p.Diff.Modules {
 		for key, resource := range module.Resources {
 			s := strings.Split(key, ".")
-			if len(s) > 1 && s[0] == "aws_s3_bucket_object" {
+			if len(s) > 1 && (s[0] == "aws_s3_bucket_object" || s[0] == "google_storage_bucket_object" || s[0] == "azurerm_storage_blob") {
 				if s[1] == "puppet-tar-gz" || s[1] == "latest-puppet-hash" {
 					if t := resource.ChangeType(); t != terraform.DiffNone && t != terraform.DiffDestroy {
 						return true
@@ -0,0 +1,249 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package plan
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+type Action string
+
+const (
+	ActionCreate  Action = "create"
+	ActionUpdate  Action = "update"
+	ActionReplace Action = "replace"
+	ActionDestroy Action = "destroy"
+)
+
+const rootModule = "root"
+
+// resource types of the supported providers, grouped by what makes changing
+// them risky
+var (
+	loadBalancerTypes = []string{
+		"aws_elb",
+		"google_compute_forwarding_rule",
+		"google_compute_target_pool",
+		"azurerm_lb",
+	}
+
+	instanceTypes = []string{
+		"aws_instance",
+		"google_compute_instance",
+		"azurerm_virtual_machine",
+	}
+
+	volumeTypes = []string{
+		"aws_ebs_volume",
+		"google_compute_disk",
+		"azurerm_managed_disk",
+	}
+
+	securityGroupTypes = []string{
+		"aws_security_group",
+		"aws_security_group_rule",
+		"google_compute_firewall",
+		"azurerm_network_security_group",
+		"azurerm_network_security_rule",
+	}
+
+	dnsTypePrefixes = []string{
+		"aws_route53_",
+		"google_dns_",
+		"azurerm_dns_",
+	}
+)
+
+// Summary lists every resource change of a plan, grouped by module and
+// instance pool
+type Summary struct {
+	Create  int `json:"create"`
+	Update  int `json:"update"`
+	Replace int `json:"replace"`
+	Destroy int `json:"destroy"`
+	Risky   int `json:"risky"`
+
+	Groups []*Group `json:"groups"`
+}
+
+type Group struct {
+	Module       string `json:"module"`
+	InstancePool string `json:"instancePool,omitempty"`
+
+	Changes []*Change `json:"changes"`
+}
+
+type Change struct {
+	Address string   `json:"address"`
+	Type    string   `json:"type"`
+	Action  Action   `json:"action"`
+	Risks   []string `json:"risks,omitempty"`
+}
+
+// Summary classifies all changes of the plan. Resources are attributed to
+// the instance pool whose terraform name prefixes the resource name.
+func (p *Plan) Summary(instancePools []string) *Summary {
+	s := &Summary{Groups: []*Group{}}
+	groups := map[string]*Group{}
+
+	if p.Diff == nil {
+		return s
+	}
+
+	for _, module := range p.Diff.Modules {
+		moduleName := rootModule
+		if len(module.Path) > 1 {
+			moduleName = strings.Join(module.Path[1:], ".")
+		}
+
+		for key, resource := range module.Resources {
+			var action Action
+			switch resource.ChangeType() {
+			case terraform.DiffCreate:
+				action = ActionCreate
+				s.Create++
+			case terraform.DiffUpdate:
+				action = ActionUpdate
+				s.Update++
+			case terraform.DiffDestroyCreate:
+				action = ActionReplace
+				s.Replace++
+			case terraform.DiffDestroy:
+				action = ActionDestroy
+				s.Destroy++
+			default:
+				continue
+			}
+
+			address := key
+			if moduleName != rootModule {
+				address = fmt.Sprintf("module.%s.%s", moduleName, key)
+			}
+
+			resourceType, resourceName := splitResourceKey(key)
+			pool := instancePoolForResource(instancePools, resourceName)
+
+			change := &Change{
+				Address: address,
+				Type:    resourceType,
+				Action:  action,
+				Risks:   risks(resourceType, resourceName, pool, action),
+			}
+			if len(change.Risks) > 0 {
+				s.Risky++
+			}
+
+			groupKey := moduleName + "/" + pool
+			group, ok := groups[groupKey]
+			if !ok {
+				group = &Group{
+					Module:       moduleName,
+					InstancePool: pool,
+				}
+				groups[groupKey] = group
+				s.Groups = append(s.Groups, group)
+			}
+			group.Changes = append(group.Changes, change)
+		}
+	}
+
+	sort.Slice(s.Groups, func(i, j int) bool {
+		if s.Groups[i].Module != s.Groups[j].Module {
+			return s.Groups[i].Module < s.Groups[j].Module
+		}
+		return s.Groups[i].InstancePool < s.Groups[j].InstancePool
+	})
+	for _, group := range s.Groups {
+		sort.Slice(group.Changes, func(i, j int) bool {
+			return group.Changes[i].Address < group.Changes[j].Address
+		})
+	}
+
+	return s
+}
+
+func (s *Summary) HasChanges() bool {
+	return s.Create+s.Update+s.Replace+s.Destroy > 0
+}
+
+func (s *Summary) String() string {
+	return fmt.Sprintf(
+		"%d to create, %d to update in-place, %d to replace, %d to destroy, %d risky",
+		s.Create, s.Update, s.Replace, s.Destroy, s.Risky,
+	)
+}
+
+// splitResourceKey returns type and name of a resource key like
+// aws_instance.etcd.0 or data.aws_ami.centos
+func splitResourceKey(key string) (resourceType, resourceName string) {
+	parts := strings.Split(key, ".")
+	if parts[0] == "data" {
+		parts = parts[1:]
+	}
+	if len(parts) < 2 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func instancePoolForResource(instancePools []string, resourceName string) string {
+	var match string
+	for _, pool := range instancePools {
+		if len(pool) <= len(match) {
+			continue
+		}
+		if resourceName == pool || strings.HasPrefix(resourceName, pool+"_") || strings.HasPrefix(resourceName, pool+"-") {
+			match = pool
+		}
+	}
+	return match
+}
+
+func risks(resourceType, resourceName, pool string, action Action) (risks []string) {
+	replacedOrDestroyed := action == ActionReplace || action == ActionDestroy
+
+	if replacedOrDestroyed && contains(loadBalancerTypes, resourceType) {
+		risks = append(risks, fmt.Sprintf("load balancer will be %s, its address changes", pastTense(action)))
+	}
+
+	if replacedOrDestroyed && contains(instanceTypes, resourceType) &&
+		(strings.Contains(pool, "etcd") || strings.Contains(resourceName, "etcd")) {
+		risks = append(risks, fmt.Sprintf("etcd instance will be %s, quorum can be lost", pastTense(action)))
+	}
+
+	if replacedOrDestroyed && contains(volumeTypes, resourceType) {
+		risks = append(risks, fmt.Sprintf("volume will be %s, its data is lost", pastTense(action)))
+	}
+
+	if contains(securityGroupTypes, resourceType) {
+		risks = append(risks, "security group changes, traffic can be blocked or exposed")
+	}
+
+	for _, prefix := range dnsTypePrefixes {
+		if strings.HasPrefix(resourceType, prefix) {
+			risks = append(risks, "DNS record changes, clients can resolve stale or missing addresses")
+			break
+		}
+	}
+
+	return risks
+}
+
+func pastTense(action Action) string {
+	if action == ActionReplace {
+		return "replaced"
+	}
+	return "destroyed"
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package plan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	for _, tc := range []struct {
+		plan                             string
+		create, update, replace, destroy int
+		risky                            int
+		groups                           []string
+	}{
+		{plan: "nochanges", groups: []string{}},
+		{plan: "create", create: 3, groups: []string{"etcd/"}},
+		{plan: "modify", update: 3, groups: []string{"etcd/"}},
+		{plan: "tainted", replace: 1, risky: 1, groups: []string{"etcd/"}},
+		{plan: "recreate", replace: 3, risky: 3, groups: []string{"etcd/"}},
+		{plan: "destroy_non_ebs", destroy: 1, groups: []string{"etcd/"}},
+		{plan: "destroy_non_module_ebs", destroy: 1, risky: 1, groups: []string{"root/"}},
+	} {
+		s := NewTest(t, "test_data/"+tc.plan+".plan").Summary([]string{"kubernetes_etcd"})
+
+		if exp, act := []int{tc.create, tc.update, tc.replace, tc.destroy, tc.risky}, []int{s.Create, s.Update, s.Replace, s.Destroy, s.Risky}; !reflect.DeepEqual(exp, act) {
+			t.Errorf("%s: unexpected counts exp=%+v act=%+v", tc.plan, exp, act)
+		}
+
+		groups := []string{}
+		for _, group := range s.Groups {
+			groups = append(groups, group.Module+"/"+group.InstancePool)
+		}
+		if exp, act := tc.groups, groups; !reflect.DeepEqual(exp, act) {
+			t.Errorf("%s: unexpected groups exp=%+v act=%+v", tc.plan, exp, act)
+		}
+	}
+}
+
+func TestSummary_DestroyNonModuleEbs(t *testing.T) {
+	s := NewTest(t, "test_data/destroy_non_module_ebs.plan").Summary(nil)
+
+	exp := []*Change{{
+		Address: "aws_ebs_volume.extra",
+		Type:    "aws_ebs_volume",
+		Action:  ActionDestroy,
+		Risks:   []string{"volume will be destroyed, its data is lost"},
+	}}
+	if act := s.Groups[0].Changes; !reflect.DeepEqual(exp, act) {
+		t.Errorf("unexpected changes exp=%+v act=%+v", exp, act)
+	}
+}
+
+func TestInstancePoolForResource(t *testing.T) {
+	pools := []string{"etcd", "worker", "worker_spot", "jenkins"}
+
+	for _, tc := range []struct {
+		resourceName string
+		exp          string
+	}{
+		{"etcd", "etcd"},
+		{"etcd_data", "etcd"},
+		{"worker", "worker"},
+		{"worker_spot", "worker_spot"},
+		{"worker_spot_Name", "worker_spot"},
+		{"etcd-exporter-srv", "etcd"},
+		{"etcdx", ""},
+		{"vault", ""},
+	} {
+		if act := instancePoolForResource(pools, tc.resourceName); act != tc.exp {
+			t.Errorf("%s: unexpected pool exp=%s act=%s", tc.resourceName, tc.exp, act)
+		}
+	}
+}
+
+func TestRisks(t *testing.T) {
+	for _, tc := range []struct {
+		resourceType string
+		resourceName string
+		pool         string
+		action       Action
+		risky        bool
+	}{
+		{"aws_elb", "master", "", ActionReplace, true},
+		{"aws_elb", "master", "", ActionUpdate, false},
+		{"azurerm_lb", "master", "", ActionDestroy, true},
+		{"aws_instance", "etcd", "etcd", ActionReplace, true},
+		{"google_compute_instance", "kubernetes_etcd", "kubernetes_etcd", ActionDestroy, true},
+		{"aws_instance", "etcd", "etcd", ActionUpdate, false},
+		{"aws_instance", "worker", "worker", ActionReplace, false},
+		{"aws_security_group_rule", "master_elb_public_ingress_allow_all_api", "", ActionCreate, true},
+		{"google_compute_firewall", "master", "", ActionUpdate, true},
+		{"aws_route53_record", "master_api", "", ActionUpdate, true},
+		{"azurerm_dns_a_record", "api", "", ActionCreate, true},
+		{"aws_s3_bucket_object", "puppet-tar-gz", "", ActionUpdate, false},
+	} {
+		if act := len(risks(tc.resourceType, tc.resourceName, tc.pool, tc.action)) > 0; act != tc.risky {
+			t.Errorf("%s.%s %s: unexpected risky exp=%t act=%t", tc.resourceType, tc.resourceName, tc.action, tc.risky, act)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package aws
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/helper/validation"
+	"github.com/hashicorp/terraform/terraform"
+	provideraws "github.com/terraform-providers/terraform-provider-aws/aws"
+)
+
+// SpotAllocationStrategies lists the spot allocation strategies accepted in
+// the mixed instances policy of autoscaling groups
+var SpotAllocationStrategies = []string{
+	"lowest-price",
+	"capacity-optimized",
+}
+
+// Provider returns the aws provider, its autoscaling groups accept all
+// SpotAllocationStrategies. The provider passes the strategy on to the API
+// as is, only its validation is limited to lowest-price.
+func Provider() terraform.ResourceProvider {
+	provider := provideraws.Provider().(*schema.Provider)
+
+	if s := spotAllocationStrategySchema(provider); s != nil {
+		s.ValidateFunc = validation.StringInSlice(SpotAllocationStrategies, false)
+	}
+
+	return provider
+}
+
+func spotAllocationStrategySchema(provider *schema.Provider) *schema.Schema {
+	path := []string{"mixed_instances_policy", "instances_distribution", "spot_allocation_strategy"}
+
+	resource, ok := provider.ResourcesMap["aws_autoscaling_group"]
+	if !ok {
+		return nil
+	}
+
+	for pos, key := range path {
+		s, ok := resource.Schema[key]
+		if !ok {
+			return nil
+		}
+		if pos == len(path)-1 {
+			return s
+		}
+		if resource, ok = s.Elem.(*schema.Resource); !ok {
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package aws
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestProvider_spotAllocationStrategy(t *testing.T) {
+	s := spotAllocationStrategySchema(Provider().(*schema.Provider))
+	if s == nil {
+		t.Fatal("expected the spot allocation strategy of autoscaling groups")
+	}
+
+	for _, strategy := range SpotAllocationStrategies {
+		if _, errs := s.ValidateFunc(strategy, "spot_allocation_strategy"); len(errs) > 0 {
+			t.Errorf("unexpected errors for %s: %v", strategy, errs)
+		}
+	}
+
+	if _, errs := s.ValidateFunc("random", "spot_allocation_strategy"); len(errs) == 0 {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
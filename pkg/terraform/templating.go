@@ -16,6 +16,7 @@ import (
 	clusterv1alpha1 "github.com/jetstack/tarmak/pkg/apis/cluster/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/tarmak/binaries"
 	"github.com/jetstack/tarmak/pkg/tarmak/cluster"
+	"github.com/jetstack/tarmak/pkg/tarmak/cluster/firewall"
 	"github.com/jetstack/tarmak/pkg/tarmak/interfaces"
 	"github.com/jetstack/tarmak/pkg/tarmak/provider/amazon"
 	"github.com/jetstack/tarmak/pkg/tarmak/utils"
@@ -160,6 +161,12 @@ type terraformTemplate struct {
 }
 
 func (t *terraformTemplate) Generate() error {
+	switch t.cluster.Environment().Provider().Cloud() {
+	case clusterv1alpha1.CloudGoogle:
+		return t.generateObjectStorage("gcs")
+	case clusterv1alpha1.CloudAzure:
+		return t.generateObjectStorage("blob")
+	}
 
 	var result error
 	if err := t.generateRemoteStateConfig(); err != nil {
@@ -237,6 +244,70 @@ func (t *terraformTemplate) Generate() error {
 	return result
 }
 
+// generateObjectStorage renders the templates of providers, which store the
+// wing binary and puppet manifests in the object storage named by storage
+func (t *terraformTemplate) generateObjectStorage(storage string) error {
+
+	var result error
+	if err := t.generateRemoteStateConfig(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if err := t.generateModuleInstanceTemplates("kubernetes"); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	for _, module := range []string{"bastion", "vault", "kubernetes"} {
+		if err := t.generateTemplate("wing_"+storage, fmt.Sprintf("modules/%s/wing_%s", module, storage), "tf", module); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	for _, module := range []string{"vault", "kubernetes"} {
+		for _, tmpl := range []struct {
+			name, target, fType string
+		}{
+			{"puppet_" + storage, "modules/%s/puppet_" + storage, "tf"},
+			{"puppet_agent_user_data", "modules/%s/templates/puppet_agent_user_data", "yaml"},
+		} {
+
+			if err := t.generateTemplate(tmpl.name, fmt.Sprintf(tmpl.target, module), tmpl.fType, module); err != nil {
+				result = multierror.Append(result, err)
+			}
+		}
+	}
+
+	for _, tmpl := range []struct {
+		name, target string
+	}{
+		{"modules", "modules"},
+		{"inputs", "inputs"},
+		{"outputs", "outputs"},
+		{"providers", "providers"},
+	} {
+		if err := t.generateTemplate(tmpl.name, tmpl.target, "tf", "kubernetes"); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if err := t.generateTemplate("bastion_user_data",
+		"modules/bastion/templates/bastion_user_data", "yaml", "bastion"); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if t.cluster.Type() != clusterv1alpha1.ClusterTypeClusterMulti {
+		if err := t.generateTemplate("vault_instances", "modules/vault/vault_instances", "tf", "vault"); err != nil {
+			result = multierror.Append(result, err)
+		}
+	}
+
+	if err := t.generateTerraformVariables(); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	return result
+}
+
 func (t *terraformTemplate) data(module string) map[string]interface{} {
 
 	_, existingVPC := t.cluster.Config().Network.ObjectMeta.Annotations[clusterv1alpha1.ExistingVPCAnnotationKey]
@@ -269,6 +340,7 @@ func (t *terraformTemplate) data(module string) map[string]interface{} {
 		"VaultInstancePool":     t.cluster.InstancePool("vault"),
 		"BastionInstancePool":   t.cluster.InstancePool("bastion"),
 		"AmazonEBSEncrypted":    t.cluster.AmazonEBSEncrypted(),
+		"WingEnvironment":       t.cluster.WingEnvironment(),
 	}
 }
 
@@ -311,11 +383,18 @@ func (t *terraformTemplate) generateTemplate(name, target, fileType, module stri
 
 	mainTemplate := templatesParsed.Lookup(fmt.Sprintf("%s.%s.template", name, fileType))
 
+	targetPath := filepath.Join(
+		t.destDir,
+		fmt.Sprintf("%s.%s", target, fileType),
+	)
+
+	// modules without static templates do not ship a templates directory
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0700); err != nil {
+		return err
+	}
+
 	file, err := os.OpenFile(
-		filepath.Join(
-			t.destDir,
-			fmt.Sprintf("%s.%s", target, fileType),
-		),
+		targetPath,
 		os.O_RDWR|os.O_CREATE|os.O_TRUNC,
 		0644,
 	)
@@ -337,7 +416,7 @@ func (t *terraformTemplate) generateTemplate(name, target, fileType, module stri
 func (t *terraformTemplate) generateModuleInstanceTemplates(module string) error {
 	data := t.data(module)
 	// generate instance pools security group rules
-	if len(t.cluster.InstancePools()) > 0 {
+	if len(t.cluster.InstancePools()) > 0 && t.cluster.Environment().Provider().Cloud() == clusterv1alpha1.CloudAmazon {
 		awsSGRules, err := t.generateAWSSecurityGroup()
 		if err != nil {
 			return err
@@ -389,13 +468,20 @@ func (t *terraformTemplate) generateModuleInstanceTemplates(module string) error
 // TODO: move this to the cloud provider
 func (t *terraformTemplate) generateAWSSecurityGroup() (rules map[string][]*amazon.AWSSGRule, err error) {
 	rules = make(map[string][]*amazon.AWSSGRule)
+
+	// merge the rules specified for instance pools with the built-in rules
+	firewallRules, err := firewall.ClusterRules(t.cluster.Config().InstancePools)
+	if err != nil {
+		return nil, fmt.Errorf("invalid firewall configuration: %s", err)
+	}
+
 	for _, role := range t.cluster.Roles() {
 
 		if role.Name() == "bastion" || role.Name() == "vault" {
 			continue
 		}
 
-		roleRules, err := amazon.GenerateAWSRules(role)
+		roleRules, err := amazon.GenerateAWSRules(role, firewallRules)
 		if err != nil {
 			return nil, err
 		}
@@ -385,8 +385,9 @@ func errIsTerraformPlanChangesNeeded(err error) bool {
 	return true
 }
 
-func (t *Terraform) Plan(cluster interfaces.Cluster, preApply bool) (changesNeeded bool, err error) {
-	var tfPlan *plan.Plan
+// Plan runs terraform plan and returns the parsed plan. The plan is also
+// returned together with an error about destroyed EBS volumes.
+func (t *Terraform) Plan(cluster interfaces.Cluster, preApply bool) (changesNeeded bool, tfPlan *plan.Plan, err error) {
 	planPath := t.tarmak.ClusterFlags().Apply.PlanFileLocation
 	changesNeeded = true
 
@@ -396,19 +397,19 @@ func (t *Terraform) Plan(cluster interfaces.Cluster, preApply bool) (changesNeed
 	if !preApply || !customPlanFile {
 		planPath, err = t.planFileStore(cluster)
 		if err != nil {
-			return changesNeeded, err
+			return changesNeeded, nil, err
 		}
 
 		changesNeeded, tfPlan, err = t.planWrapper(cluster, planPath)
 		if err != nil {
-			return changesNeeded, err
+			return changesNeeded, nil, err
 		}
 	} else {
 		t.log.Infof("using custom plan file %s", planPath)
 
 		tfPlan, err = plan.New(planPath)
 		if err != nil {
-			return changesNeeded, fmt.Errorf("error while trying to read plan file: %s", err)
+			return changesNeeded, nil, fmt.Errorf("error while trying to read plan file: %s", err)
 		}
 	}
 
@@ -419,20 +420,20 @@ func (t *Terraform) Plan(cluster interfaces.Cluster, preApply bool) (changesNeed
 			"-allow-missing", "-module=kubernetes",
 			cluster.Environment().Provider().LegacyPuppetTFName(),
 		}); err != nil {
-			return changesNeeded, err
+			return changesNeeded, nil, err
 		}
 
 		t.log.Info("running plan again to update plan file against new state")
 
 		changesNeeded, tfPlan, err = t.planWrapper(cluster, planPath)
 		if err != nil {
-			return changesNeeded, err
+			return changesNeeded, nil, err
 		}
 	}
 
 	destroyingEBSVolume, ebsVolumesToDestroy := tfPlan.IsDestroyingEBSVolume()
 	if !destroyingEBSVolume {
-		return changesNeeded, nil
+		return changesNeeded, tfPlan, nil
 	}
 
 	destroyStr := fmt.Sprintf(
@@ -441,12 +442,12 @@ func (t *Terraform) Plan(cluster interfaces.Cluster, preApply bool) (changesNeed
 
 	// We exit early here since we are only doing a plan. Bubble the ebs error up.
 	if !preApply {
-		return changesNeeded, errors.New(destroyStr)
+		return changesNeeded, tfPlan, errors.New(destroyStr)
 	}
 
 	if t.tarmak.ClusterFlags().Apply.AutoApproveDeletingData && t.tarmak.ClusterFlags().Apply.AutoApprove {
 		t.log.Warnf("auto approved deleting, %s", destroyStr)
-		return changesNeeded, nil
+		return changesNeeded, tfPlan, nil
 	}
 
 	query := fmt.Sprintf("%s\nThis cannot be undone. Are you sure you want to continue?", destroyStr)
@@ -455,20 +456,20 @@ func (t *Terraform) Plan(cluster interfaces.Cluster, preApply bool) (changesNeed
 		Query:   query,
 	})
 	if err != nil {
-		return changesNeeded, err
+		return changesNeeded, nil, err
 	}
 
 	if !d {
-		return changesNeeded, fmt.Errorf("error: %s", destroyStr)
+		return changesNeeded, nil, fmt.Errorf("error: %s", destroyStr)
 	}
 
 	t.log.Warn(destroyStr)
-	return changesNeeded, nil
+	return changesNeeded, tfPlan, nil
 }
 
 func (t *Terraform) Apply(cluster interfaces.Cluster) (hasChanged bool, err error) {
 	// generate a plan
-	changesNeeded, err := t.Plan(cluster, true)
+	changesNeeded, _, err := t.Plan(cluster, true)
 	if err != nil || !changesNeeded {
 		return false, err
 	}
@@ -0,0 +1,39 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package file
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteAtomic writes to a temporary file next to path and renames it, so
+// readers never see a partially written file. Missing directories are
+// created, the file is only readable by its owner.
+func WriteAtomic(path string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("error creating directory '%s': %s", filepath.Dir(path), err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return fmt.Errorf("error writing '%s': %s", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing '%s': %s", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing '%s': %s", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing '%s': %s", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package file
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tarmak-file")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "env", "vault_root_token")
+	for _, content := range []string{"old\n", "new\n"} {
+		if err := WriteAtomic(path, bytes.NewReader([]byte(content))); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	act, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if string(act) != "new\n" {
+		t.Errorf("unexpected content: %q", act)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("unexpected file mode: %o", mode)
+	}
+
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("expected no temporary files to be left, got %d files", len(files))
+	}
+}
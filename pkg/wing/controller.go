@@ -55,10 +55,6 @@ func (c *Controller) processNextItem() bool {
 // information about the instance to stdout. In case an error happened, it has to simply return the error.
 // The retry logic should not be part of the business logic.
 func (c *Controller) syncToStdout(key string) error {
-
-	// ensure only one converge at a time
-	c.wing.convergeWG.Wait()
-
 	obj, exists, err := c.indexer.GetByKey(key)
 	if err != nil {
 		c.log.Errorf("Fetching object with key %s from store failed with %v", key, err)
@@ -88,28 +84,56 @@ func (c *Controller) syncToStdout(key string) error {
 		// is dependent on the actual instance, to detect that a Instance was recreated with the same name
 		instance := obj.(*v1alpha1.Instance)
 
-		// trigger converge if status time is older or not existing
-		if instance.Spec != nil && instance.Spec.Converge != nil && !instance.Spec.Converge.RequestTimestamp.Time.IsZero() {
-			if instance.Status != nil && instance.Status.Converge != nil && !instance.Status.Converge.LastUpdateTimestamp.Time.IsZero() {
-				if instance.Status.Converge.LastUpdateTimestamp.Time.After(instance.Spec.Converge.RequestTimestamp.Time) {
-					c.log.Debug("no converge neccessary, last update was after request")
-					return nil
-				}
-			} else {
-				c.log.Debug("no converge neccessary, no status section found or update timestamp zero")
-				return nil
-			}
-		} else {
-			c.log.Debug("no converge neccessary, no spec section found or request timestamp zero")
-			return nil
+		if c.convergeRequested(instance) {
+			c.log.Infof("running converge")
+			c.wing.converge()
 		}
 
-		c.log.Infof("running converge")
-		c.wing.converge()
+		if c.dryRunRequested(instance) {
+			c.log.Infof("running dry run")
+			c.wing.dryRun(instance.Spec.DryRun)
+		}
 	}
 	return nil
 }
 
+// trigger converge if status time is older or not existing
+func (c *Controller) convergeRequested(instance *v1alpha1.Instance) bool {
+	if instance.Spec == nil || instance.Spec.Converge == nil || instance.Spec.Converge.RequestTimestamp.Time.IsZero() {
+		c.log.Debug("no converge neccessary, no spec section found or request timestamp zero")
+		return false
+	}
+
+	if instance.Status == nil || instance.Status.Converge == nil || instance.Status.Converge.LastUpdateTimestamp.Time.IsZero() {
+		c.log.Debug("no converge neccessary, no status section found or update timestamp zero")
+		return false
+	}
+
+	if instance.Status.Converge.LastUpdateTimestamp.Time.After(instance.Spec.Converge.RequestTimestamp.Time) {
+		c.log.Debug("no converge neccessary, last update was after request")
+		return false
+	}
+
+	return true
+}
+
+// trigger dry run if it has been requested after the last dry run status
+// update or if there is no dry run status yet
+func (c *Controller) dryRunRequested(instance *v1alpha1.Instance) bool {
+	if instance.Spec == nil || instance.Spec.DryRun == nil || instance.Spec.DryRun.RequestTimestamp.Time.IsZero() {
+		c.log.Debug("no dry run neccessary, no spec section found or request timestamp zero")
+		return false
+	}
+
+	if instance.Status != nil && instance.Status.DryRun != nil &&
+		instance.Status.DryRun.LastUpdateTimestamp.Time.After(instance.Spec.DryRun.RequestTimestamp.Time) {
+		c.log.Debug("no dry run neccessary, last update was after request")
+		return false
+	}
+
+	return true
+}
+
 // handleErr checks if an error happened and makes sure we will retry later.
 func (c *Controller) handleErr(err error, key interface{}) {
 	if err == nil {
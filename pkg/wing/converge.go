@@ -0,0 +1,74 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/cenkalti/backoff"
+)
+
+const (
+	DefaultConvergeRetryInitialInterval = 30 * time.Second
+	DefaultConvergeRetryMaxInterval     = backoff.DefaultMaxInterval
+	DefaultConvergeRetryMaxElapsedTime  = 30 * time.Minute
+)
+
+// seeded per process, so instances started together spread their converge runs
+var reconvergeRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// convergeBackOff returns the retry policy of puppet apply, unset values use
+// the defaults
+func (w *Wing) convergeBackOff() *backoff.ExponentialBackOff {
+	b := backoff.NewExponentialBackOff()
+
+	b.InitialInterval = DefaultConvergeRetryInitialInterval
+	if w.flags.ConvergeRetryInitialInterval > 0 {
+		b.InitialInterval = w.flags.ConvergeRetryInitialInterval
+	}
+
+	b.MaxInterval = DefaultConvergeRetryMaxInterval
+	if w.flags.ConvergeRetryMaxInterval > 0 {
+		b.MaxInterval = w.flags.ConvergeRetryMaxInterval
+	}
+
+	b.MaxElapsedTime = DefaultConvergeRetryMaxElapsedTime
+	if w.flags.ConvergeRetryMaxElapsedTime > 0 {
+		b.MaxElapsedTime = w.flags.ConvergeRetryMaxElapsedTime
+	}
+
+	b.Reset()
+	return b
+}
+
+// reconvergeDelay returns the time until the next scheduled converge, a
+// random jitter spreads the converge runs of a cluster's instances
+func (w *Wing) reconvergeDelay() time.Duration {
+	delay := w.flags.ReconvergeInterval
+	if w.flags.ReconvergeJitter > 0 {
+		delay += time.Duration(reconvergeRand.Int63n(int64(w.flags.ReconvergeJitter)))
+	}
+	return delay
+}
+
+// reconverge runs converge periodically, so configuration drift is repaired
+// and failed converge runs are retried. It is disabled without an interval.
+func (w *Wing) reconverge() {
+	if w.flags.ReconvergeInterval <= 0 {
+		return
+	}
+
+	for {
+		delay := w.reconvergeDelay()
+		w.log.Infof("next scheduled converge in %s", delay)
+
+		select {
+		case <-w.stopCh:
+			return
+		case <-time.After(delay):
+		}
+
+		w.log.Infof("running scheduled converge")
+		w.converge()
+	}
+}
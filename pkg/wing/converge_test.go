@@ -0,0 +1,54 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWing_convergeBackOff(t *testing.T) {
+	w := &Wing{flags: &Flags{}}
+
+	b := w.convergeBackOff()
+	if b.InitialInterval != DefaultConvergeRetryInitialInterval {
+		t.Errorf("unexpected default initial interval: %s", b.InitialInterval)
+	}
+	if b.MaxInterval != DefaultConvergeRetryMaxInterval {
+		t.Errorf("unexpected default max interval: %s", b.MaxInterval)
+	}
+	if b.MaxElapsedTime != DefaultConvergeRetryMaxElapsedTime {
+		t.Errorf("unexpected default max elapsed time: %s", b.MaxElapsedTime)
+	}
+
+	w.flags = &Flags{
+		ConvergeRetryInitialInterval: time.Minute,
+		ConvergeRetryMaxInterval:     10 * time.Minute,
+		ConvergeRetryMaxElapsedTime:  2 * time.Hour,
+	}
+
+	b = w.convergeBackOff()
+	if b.InitialInterval != time.Minute {
+		t.Errorf("unexpected initial interval: %s", b.InitialInterval)
+	}
+	if b.MaxInterval != 10*time.Minute {
+		t.Errorf("unexpected max interval: %s", b.MaxInterval)
+	}
+	if b.MaxElapsedTime != 2*time.Hour {
+		t.Errorf("unexpected max elapsed time: %s", b.MaxElapsedTime)
+	}
+}
+
+func TestWing_reconvergeDelay(t *testing.T) {
+	w := &Wing{flags: &Flags{ReconvergeInterval: 6 * time.Hour}}
+	if delay := w.reconvergeDelay(); delay != 6*time.Hour {
+		t.Errorf("unexpected delay without jitter: %s", delay)
+	}
+
+	w.flags.ReconvergeJitter = 30 * time.Minute
+	for i := 0; i < 100; i++ {
+		delay := w.reconvergeDelay()
+		if delay < 6*time.Hour || delay >= 6*time.Hour+30*time.Minute {
+			t.Fatalf("delay %s outside of interval and jitter", delay)
+		}
+	}
+}
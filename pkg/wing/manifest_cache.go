@@ -0,0 +1,229 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+	"github.com/jetstack/tarmak/pkg/utils/file"
+)
+
+const (
+	DefaultManifestCacheDir  = "/var/lib/wing/manifests"
+	DefaultManifestCacheSize = 5
+
+	manifestCacheSuffix   = ".tar.gz"
+	manifestCacheLastGood = "last-good"
+	manifestCachePinned   = "pinned"
+)
+
+var manifestHashRegexp = regexp.MustCompile(`^sha256:([0-9a-f]{64})$`)
+
+// manifestCache keeps the last verified manifests, named by their hash. A nil
+// cache never holds anything.
+type manifestCache struct {
+	log  *logrus.Entry
+	dir  string
+	size int
+}
+
+func newManifestCache(log *logrus.Entry, dir string, size int) *manifestCache {
+	if dir == "" || size < 1 {
+		return nil
+	}
+
+	return &manifestCache{
+		log:  log.WithField("dir", dir),
+		dir:  dir,
+		size: size,
+	}
+}
+
+func (c *manifestCache) path(hash string) (string, error) {
+	match := manifestHashRegexp.FindStringSubmatch(hash)
+	if match == nil {
+		return "", fmt.Errorf("invalid manifest hash '%s'", hash)
+	}
+	return filepath.Join(c.dir, match[1]+manifestCacheSuffix), nil
+}
+
+// Get returns the cached manifest, or nil if it is not cached
+func (c *manifestCache) Get(hash string) ([]byte, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	path, err := c.path(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading cached manifest: %s", err)
+	}
+
+	// keep recently used manifests longer
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		c.log.Warnf("error updating cached manifest %s: %s", path, err)
+	}
+
+	return data, nil
+}
+
+// Put stores a manifest and removes the least recently used ones exceeding
+// the cache size, the last good manifest is always kept
+func (c *manifestCache) Put(hash string, data []byte) error {
+	if c == nil {
+		return nil
+	}
+
+	path, err := c.path(hash)
+	if err != nil {
+		return err
+	}
+
+	if err := file.WriteAtomic(path, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("error caching manifest: %s", err)
+	}
+
+	return c.prune()
+}
+
+// MarkGood remembers the manifest as the last one that converged
+func (c *manifestCache) MarkGood(hash string) error {
+	if c == nil {
+		return nil
+	}
+
+	if _, err := c.path(hash); err != nil {
+		return err
+	}
+
+	return file.WriteAtomic(filepath.Join(c.dir, manifestCacheLastGood), bytes.NewReader([]byte(hash)))
+}
+
+// LastGood returns the last manifest that converged, if it is still cached
+func (c *manifestCache) LastGood() (hash string, data []byte, err error) {
+	if c == nil {
+		return "", nil, nil
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(c.dir, manifestCacheLastGood))
+	if os.IsNotExist(err) {
+		return "", nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("error reading last good manifest: %s", err)
+	}
+
+	hash = strings.TrimSpace(string(content))
+	data, err = c.Get(hash)
+	if err != nil || data == nil {
+		return "", nil, err
+	}
+
+	return hash, data, nil
+}
+
+// Pin remembers the converge spec of the instance, so the pin is kept while
+// the wing API is unreachable. A spec without hash removes the pin.
+func (c *manifestCache) Pin(spec *v1alpha1.InstanceSpecManifest) error {
+	if c == nil {
+		return nil
+	}
+
+	path := filepath.Join(c.dir, manifestCachePinned)
+	if spec == nil || spec.Hash == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing pinned manifest: %s", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("error serialising pinned manifest: %s", err)
+	}
+
+	return file.WriteAtomic(path, bytes.NewReader(data))
+}
+
+// Pinned returns the last converge spec remembered by Pin, or nil if the
+// instance is not pinned
+func (c *manifestCache) Pinned() (*v1alpha1.InstanceSpecManifest, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(c.dir, manifestCachePinned))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading pinned manifest: %s", err)
+	}
+
+	spec := &v1alpha1.InstanceSpecManifest{}
+	if err := json.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("error parsing pinned manifest: %s", err)
+	}
+
+	return spec, nil
+}
+
+func (c *manifestCache) prune() error {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("error listing cached manifests: %s", err)
+	}
+
+	lastGood, _ := ioutil.ReadFile(filepath.Join(c.dir, manifestCacheLastGood))
+	lastGoodPath, _ := c.path(strings.TrimSpace(string(lastGood)))
+
+	var manifests []os.FileInfo
+	for _, f := range files {
+		if !f.Mode().IsRegular() || !strings.HasSuffix(f.Name(), manifestCacheSuffix) {
+			continue
+		}
+		if filepath.Join(c.dir, f.Name()) == lastGoodPath {
+			continue
+		}
+		manifests = append(manifests, f)
+	}
+
+	// the last good manifest takes up one place
+	keep := c.size
+	if lastGoodPath != "" {
+		keep--
+	}
+	if len(manifests) <= keep {
+		return nil
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		return manifests[i].ModTime().After(manifests[j].ModTime())
+	})
+
+	for _, f := range manifests[keep:] {
+		path := filepath.Join(c.dir, f.Name())
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("error removing cached manifest: %s", err)
+		}
+		c.log.Debugf("removed cached manifest %s", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+func testManifestHash(content string) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content)))
+}
+
+func TestManifestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wing-manifest-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newManifestCache(logrus.NewEntry(logrus.New()), dir, 2)
+
+	hashes := []string{testManifestHash("a"), testManifestHash("b"), testManifestHash("c")}
+
+	if err := c.Put(hashes[0], []byte("a")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := c.MarkGood(hashes[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, content := range []string{"b", "c"} {
+		// modification times decide which manifests are removed
+		if i > 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err := c.Put(hashes[i+1], []byte(content)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the last good manifest is kept, the oldest other one is removed
+	for hash, exp := range map[string]string{hashes[0]: "a", hashes[1]: "", hashes[2]: "c"} {
+		data, err := c.Get(hash)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != exp {
+			t.Errorf("unexpected content of %s, exp=%q act=%q", hash, exp, data)
+		}
+	}
+
+	hash, data, err := c.LastGood()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash != hashes[0] || string(data) != "a" {
+		t.Errorf("unexpected last good manifest %s: %q", hash, data)
+	}
+
+	if _, err := c.Get("sha256:../../etc/passwd"); err == nil {
+		t.Error("expected error for invalid hash")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+manifestCacheSuffix))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(files) != 2 {
+		t.Errorf("expected 2 cached manifests, got %s", files)
+	}
+}
+
+func TestManifestCacheDisabled(t *testing.T) {
+	c := newManifestCache(logrus.NewEntry(logrus.New()), "", DefaultManifestCacheSize)
+	if c != nil {
+		t.Fatal("expected cache to be disabled")
+	}
+
+	if err := c.Put(testManifestHash("a"), []byte("a")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if data, err := c.Get(testManifestHash("a")); err != nil || data != nil {
+		t.Errorf("expected nothing to be cached, got %q, %v", data, err)
+	}
+}
+
+func TestManifestCachePin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wing-manifest-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := newManifestCache(logrus.NewEntry(logrus.New()), dir, DefaultManifestCacheSize)
+
+	spec := &v1alpha1.InstanceSpecManifest{Hash: testManifestHash("a"), Path: "s3://bucket/a.tar.gz"}
+	if err := c.Pin(spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pinned, err := c.Pinned()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(pinned, spec) {
+		t.Errorf("unexpected pin, exp=%+v act=%+v", spec, pinned)
+	}
+
+	// a spec without hash removes the pin
+	if err := c.Pin(&v1alpha1.InstanceSpecManifest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pinned, err := c.Pinned(); err != nil || pinned != nil {
+		t.Errorf("expected no pin, got %+v, %v", pinned, err)
+	}
+}
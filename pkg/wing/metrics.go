@@ -0,0 +1,97 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+const (
+	DefaultMetricsListenAddress = ":9451"
+
+	metricsNamespace = "wing"
+)
+
+var (
+	convergeDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "converge_duration_seconds",
+		Help:      "Duration of converge runs, including all retries of puppet apply.",
+		Buckets:   []float64{30, 60, 120, 300, 600, 900, 1200, 1800, 2700, 3600},
+	})
+
+	convergeRetries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "converge_retries",
+		Help:      "Number of puppet apply retries of the last converge run.",
+	})
+
+	puppetLastExitCode = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "puppet_last_exit_code",
+		Help:      "Exit code of the last puppet apply.",
+	})
+
+	manifestDownloadDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "manifest_download_duration_seconds",
+		Help:      "Duration of manifest downloads.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	})
+
+	convergeState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "converge_state",
+		Help:      "Current state of converging the manifest, the gauge of the current state is 1.",
+	}, []string{"state"})
+
+	registerMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(
+			convergeDuration,
+			convergeRetries,
+			puppetLastExitCode,
+			manifestDownloadDuration,
+			convergeState,
+		)
+	})
+}
+
+// serveMetrics exposes the agent's metrics on /metrics, it is disabled
+// without a listen address
+func (w *Wing) serveMetrics() {
+	if w.flags.MetricsListenAddress == "" {
+		return
+	}
+
+	registerMetrics()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler())
+
+	go func() {
+		w.log.Infof("serving metrics on %s", w.flags.MetricsListenAddress)
+		if err := http.ListenAndServe(w.flags.MetricsListenAddress, mux); err != nil {
+			w.log.Errorf("error serving metrics: %s", err)
+		}
+	}()
+}
+
+// setConvergeState exports a gauge for every state, so that alerts can rely
+// on all of them being present
+func setConvergeState(state v1alpha1.InstanceManifestState) {
+	for _, s := range v1alpha1.InstanceManifestStates {
+		value := 0.0
+		if s == state {
+			value = 1.0
+		}
+		convergeState.WithLabelValues(string(s)).Set(value)
+	}
+}
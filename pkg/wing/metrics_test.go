@@ -0,0 +1,34 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+func TestSetConvergeState(t *testing.T) {
+	for _, state := range []v1alpha1.InstanceManifestState{
+		v1alpha1.InstanceManifestStateConverging,
+		v1alpha1.InstanceManifestStateVerificationFailed,
+	} {
+		setConvergeState(state)
+
+		for _, s := range v1alpha1.InstanceManifestStates {
+			metric := &dto.Metric{}
+			if err := convergeState.WithLabelValues(string(s)).Write(metric); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			exp := 0.0
+			if s == state {
+				exp = 1.0
+			}
+			if act := metric.GetGauge().GetValue(); act != exp {
+				t.Errorf("state %s: expected gauge %s to be %v, got %v", state, s, exp, act)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/jetstack/tarmak/pkg/azure/arm"
+	"github.com/jetstack/tarmak/pkg/wing/provider/hash"
+)
+
+const blobHostSuffix = ".blob.core.windows.net"
+
+type Azure struct{}
+
+// GetManifest downloads the manifest from an Azure blob storage URL using
+// the managed identity of the instance. Besides the https URL of the blob,
+// azblob://<account>/<container>/<path> URLs are supported. If the URL points
+// to the manifest directory, the latest hash blob is used to find the
+// manifest
+func (a *Azure) GetManifest(manifestString string) (io.ReadCloser, error) {
+	manifestURL, err := url.Parse(manifestString)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case manifestURL.Scheme == "azblob":
+		manifestURL.Scheme = "https"
+		if !IsBlobHost(manifestURL.Host) {
+			manifestURL.Host = manifestURL.Host + blobHostSuffix
+		}
+	case manifestURL.Scheme != "https" || !IsBlobHost(manifestURL.Host):
+		return nil, fmt.Errorf("manifest URL '%s' is not an azure blob storage URL", manifestString)
+	}
+
+	authorizer, err := arm.NewMSIAuthorizer()
+	if err != nil {
+		return nil, err
+	}
+	client := arm.New("", authorizer)
+
+	if !strings.HasSuffix(manifestURL.Path, ".tar.gz") {
+		hashURL := *manifestURL
+		hashURL.Path = path.Join(manifestURL.Path, hash.S3HashObject)
+
+		reader, err := client.GetBlob(hashURL.String())
+		if err != nil {
+			return nil, fmt.Errorf("error getting blob '%s': %s", hashURL.String(), err)
+		}
+		defer reader.Close()
+
+		hashValue, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading blob '%s': %s", hashURL.String(), err)
+		}
+
+		manifestURL.Path = path.Join(manifestURL.Path, fmt.Sprintf("%s-puppet.tar.gz", strings.TrimSpace(string(hashValue))))
+	}
+
+	reader, err := client.GetBlob(manifestURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("error getting blob '%s': %s", manifestURL.String(), err)
+	}
+
+	return reader, nil
+}
+
+// IsBlobHost returns whether the host is an azure blob storage endpoint
+func IsBlobHost(host string) bool {
+	return strings.HasSuffix(host, blobHostSuffix)
+}
+
+func (a *Azure) Name() string {
+	return "azure"
+}
@@ -4,14 +4,19 @@ package file
 import (
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 )
 
 type File struct{}
 
+// GetManifest opens the manifest at a local path or file:// URL
 func (f *File) GetManifest(manifestURL string) (io.ReadCloser, error) {
 	path := filepath.Join(manifestURL)
+	if u, err := url.Parse(manifestURL); err == nil && u.Scheme == "file" {
+		path = filepath.Join(u.Path)
+	}
 	filestream, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("error opening file %s: %s", path, err)
@@ -0,0 +1,68 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package gcs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/storage"
+
+	"github.com/jetstack/tarmak/pkg/wing/provider/hash"
+)
+
+type GCS struct{}
+
+// GetManifest downloads the manifest from a gs:// URL. If the URL points to
+// the manifest directory, the latest hash object is used to find the
+// manifest
+func (g *GCS) GetManifest(manifestString string) (io.ReadCloser, error) {
+	manifestURL, err := url.Parse(manifestString)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifestURL.Scheme != "gs" {
+		return nil, fmt.Errorf("manifest URL '%s' is not a gs:// URL", manifestString)
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcs client: %s", err)
+	}
+
+	bucket := client.Bucket(manifestURL.Host)
+	key := strings.TrimPrefix(manifestURL.Path, "/")
+
+	if !strings.HasSuffix(key, ".tar.gz") {
+		hashKey := path.Join(key, hash.S3HashObject)
+		reader, err := bucket.Object(hashKey).NewReader(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error getting gcs object '%s' in bucket '%s': %s", hashKey, manifestURL.Host, err)
+		}
+		defer reader.Close()
+
+		hashValue, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error reading gcs object '%s' in bucket '%s': %s", hashKey, manifestURL.Host, err)
+		}
+
+		key = path.Join(key, fmt.Sprintf("%s-puppet.tar.gz", strings.TrimSpace(string(hashValue))))
+	}
+
+	reader, err := bucket.Object(key).NewReader(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting gcs object '%s' in bucket '%s': %s", key, manifestURL.Host, err)
+	}
+
+	return reader, nil
+}
+
+func (g *GCS) Name() string {
+	return "gcs"
+}
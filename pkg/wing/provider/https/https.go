@@ -0,0 +1,124 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package https
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jetstack/tarmak/pkg/wing/provider/hash"
+)
+
+const (
+	// the sha256 sum of a manifest is read from this side-car object
+	checksumSuffix = ".sha256"
+
+	requestTimeout = 5 * time.Minute
+)
+
+type HTTPS struct {
+	client *http.Client
+}
+
+// GetManifest downloads the manifest from a https:// URL and verifies it
+// against the sha256 sum in the side-car object <URL>.sha256. Manifests
+// without or with a mismatching sum are rejected. If the URL points to the
+// manifest directory, the latest hash object is used to find the manifest
+func (h *HTTPS) GetManifest(manifestString string) (io.ReadCloser, error) {
+	manifestURL, err := url.Parse(manifestString)
+	if err != nil {
+		return nil, err
+	}
+
+	if manifestURL.Scheme != "https" {
+		return nil, fmt.Errorf("manifest URL '%s' is not a https:// URL", manifestString)
+	}
+
+	if !strings.HasSuffix(manifestURL.Path, ".tar.gz") {
+		hashURL := *manifestURL
+		hashURL.Path = path.Join(manifestURL.Path, hash.S3HashObject)
+
+		hashValue, err := h.get(hashURL.String())
+		if err != nil {
+			return nil, err
+		}
+
+		manifestURL.Path = path.Join(manifestURL.Path, fmt.Sprintf("%s-puppet.tar.gz", strings.TrimSpace(string(hashValue))))
+	}
+
+	checksumURL := *manifestURL
+	checksumURL.Path = manifestURL.Path + checksumSuffix
+
+	checksum, err := h.get(checksumURL.String())
+	if err != nil {
+		return nil, fmt.Errorf("error getting checksum of manifest, it is mandatory for https: %s", err)
+	}
+
+	expected, err := parseChecksum(checksum)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing checksum '%s': %s", checksumURL.String(), err)
+	}
+
+	manifest, err := h.get(manifestURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(manifest)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return nil, fmt.Errorf("sha256 sum of manifest '%s' is %s, expected %s", manifestURL.String(), actual, expected)
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(manifest)), nil
+}
+
+func (h *HTTPS) Name() string {
+	return "https"
+}
+
+func (h *HTTPS) get(u string) ([]byte, error) {
+	client := h.client
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+
+	resp, err := client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("error getting '%s': %s", u, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error getting '%s': unexpected status %s", u, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading '%s': %s", u, err)
+	}
+
+	return body, nil
+}
+
+// parseChecksum accepts a plain hex sum as well as the output of sha256sum
+func parseChecksum(data []byte) (string, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("checksum is empty")
+	}
+
+	sum := strings.ToLower(fields[0])
+	if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("'%s' is not a sha256 sum", fields[0])
+	}
+
+	return sum, nil
+}
@@ -0,0 +1,90 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package https
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPSGetManifest(t *testing.T) {
+	manifest := []byte("manifest content")
+	sum := sha256.Sum256(manifest)
+	checksum := hex.EncodeToString(sum[:])
+
+	objects := map[string]string{
+		"/good/puppet.tar.gz":            string(manifest),
+		"/good/puppet.tar.gz.sha256":     fmt.Sprintf("%s  puppet.tar.gz\n", checksum),
+		"/plain/puppet.tar.gz":           string(manifest),
+		"/plain/puppet.tar.gz.sha256":    strings.ToUpper(checksum),
+		"/missing/puppet.tar.gz":         string(manifest),
+		"/mismatch/puppet.tar.gz":        "tampered",
+		"/mismatch/puppet.tar.gz.sha256": checksum,
+		"/invalid/puppet.tar.gz":         string(manifest),
+		"/invalid/puppet.tar.gz.sha256":  "abc",
+		"/dir/latest-puppet-hash":        "1234\n",
+		"/dir/1234-puppet.tar.gz":        string(manifest),
+		"/dir/1234-puppet.tar.gz.sha256": checksum,
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := objects[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+
+	h := &HTTPS{client: server.Client()}
+
+	for _, tc := range []struct {
+		path string
+		err  string
+	}{
+		{path: "/good/puppet.tar.gz"},
+		{path: "/plain/puppet.tar.gz"},
+		{path: "/dir"},
+		{path: "/missing/puppet.tar.gz", err: "checksum of manifest, it is mandatory"},
+		{path: "/mismatch/puppet.tar.gz", err: "expected " + checksum},
+		{path: "/invalid/puppet.tar.gz", err: "is not a sha256 sum"},
+		{path: "/notfound/puppet.tar.gz", err: "404"},
+	} {
+		t.Run(tc.path, func(t *testing.T) {
+			rc, err := h.GetManifest(server.URL + tc.path)
+			if tc.err != "" {
+				if err == nil {
+					t.Fatalf("expected error containing '%s'", tc.err)
+				}
+				if !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing '%s', got: %s", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer rc.Close()
+
+			content, err := ioutil.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != string(manifest) {
+				t.Errorf("unexpected manifest content: %s", content)
+			}
+		})
+	}
+}
+
+func TestHTTPSGetManifestRejectsHTTP(t *testing.T) {
+	if _, err := new(HTTPS).GetManifest("http://example.com/puppet.tar.gz"); err == nil {
+		t.Error("expected error for http:// URL")
+	}
+}
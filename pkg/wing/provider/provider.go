@@ -2,13 +2,18 @@
 package provider
 
 import (
+	"fmt"
 	"io"
+	"net/url"
+	"sort"
+	"strings"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/sirupsen/logrus"
 
+	"github.com/jetstack/tarmak/pkg/wing/provider/azure"
 	"github.com/jetstack/tarmak/pkg/wing/provider/file"
-	"github.com/jetstack/tarmak/pkg/wing/provider/hash"
+	"github.com/jetstack/tarmak/pkg/wing/provider/gcs"
+	"github.com/jetstack/tarmak/pkg/wing/provider/https"
 	"github.com/jetstack/tarmak/pkg/wing/provider/s3"
 )
 
@@ -17,25 +22,64 @@ type Provider interface {
 	Name() string
 }
 
+// providers maps manifest URL schemes to the provider downloading them
+var providers = map[string]Provider{}
+
+func init() {
+	Register("s3", new(s3.S3))
+	Register("gs", new(gcs.GCS))
+	Register("azblob", new(azure.Azure))
+	Register("https", new(https.HTTPS))
+	Register("file", new(file.File))
+}
+
+// Register makes a provider responsible for manifest URLs with the scheme
+func Register(scheme string, p Provider) {
+	providers[scheme] = p
+}
+
+// GetManifest downloads the manifest using the provider registered for the
+// scheme of the manifest URL. URLs without a scheme are local files.
 func GetManifest(log *logrus.Entry, manifestURL string) (io.ReadCloser, error) {
-	var result *multierror.Error
-
-	for _, p := range []Provider{
-		new(hash.Hash),
-		new(s3.S3),
-		new(file.File),
-	} {
-		rc, err := p.GetManifest(manifestURL)
-		if err != nil {
-			result = multierror.Append(result, err)
-			log.Warn(err.Error())
-			continue
-		}
+	p, err := providerForURL(manifestURL)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("using provider '%s'", p.Name())
 
-		log.Infof("using provider '%s'", p.Name())
+	rc, err := p.GetManifest(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("error getting manifest using provider '%s': %s", p.Name(), err)
+	}
+
+	return rc, nil
+}
+
+func providerForURL(manifestURL string) (Provider, error) {
+	u, err := url.Parse(manifestURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing manifest URL '%s': %s", manifestURL, err)
+	}
+
+	scheme := u.Scheme
+	switch {
+	case scheme == "":
+		scheme = "file"
+	// instances on azure are given the https URL of the blob
+	case scheme == "https" && azure.IsBlobHost(u.Host):
+		scheme = "azblob"
+	}
 
-		return rc, nil
+	p, ok := providers[scheme]
+	if !ok {
+		var schemes []string
+		for s := range providers {
+			schemes = append(schemes, s+"://")
+		}
+		sort.Strings(schemes)
+		return nil, fmt.Errorf("unsupported scheme '%s' of manifest URL '%s', supported are %s", u.Scheme, manifestURL, strings.Join(schemes, ", "))
 	}
 
-	return nil, result.ErrorOrNil()
+	return p, nil
 }
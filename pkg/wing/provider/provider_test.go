@@ -0,0 +1,42 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProviderForURL(t *testing.T) {
+	for _, tc := range []struct {
+		url      string
+		provider string
+		err      string
+	}{
+		{url: "s3://bucket/cluster/puppet-manifests", provider: "s3"},
+		{url: "s3://bucket/cluster/puppet-manifests/abc-puppet.tar.gz", provider: "s3"},
+		{url: "gs://bucket/cluster/puppet-manifests", provider: "gcs"},
+		{url: "azblob://account/container/cluster/puppet-manifests", provider: "azure"},
+		{url: "https://account.blob.core.windows.net/container/cluster/puppet-manifests", provider: "azure"},
+		{url: "https://manifests.example.com/cluster/puppet.tar.gz", provider: "https"},
+		{url: "file:///var/lib/tarmak/puppet.tar.gz", provider: "file"},
+		{url: "/var/lib/tarmak/puppet.tar.gz", provider: "file"},
+		{url: "http://manifests.example.com/puppet.tar.gz", err: "unsupported scheme 'http'"},
+		{url: "ftp://manifests.example.com/puppet.tar.gz", err: "supported are azblob://, file://, gs://, https://, s3://"},
+	} {
+		t.Run(tc.url, func(t *testing.T) {
+			p, err := providerForURL(tc.url)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing '%s', got: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if p.Name() != tc.provider {
+				t.Errorf("expected provider '%s', got '%s'", tc.provider, p.Name())
+			}
+		})
+	}
+}
@@ -5,20 +5,35 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"path"
+	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/jetstack/tarmak/pkg/wing/provider/hash"
 )
 
 type S3 struct{}
 
+// GetManifest downloads the manifest from a s3:// URL. If the URL points to
+// the manifest directory or the legacy manifest object, the latest hash
+// object is used to find the manifest
 func (s *S3) GetManifest(manifestString string) (io.ReadCloser, error) {
 	manifestURL, err := url.Parse(manifestString)
 	if err != nil {
 		return nil, err
 	}
 
+	if manifestURL.Scheme != "s3" {
+		return nil, fmt.Errorf("manifest URL '%s' is not a s3:// URL", manifestString)
+	}
+
+	if !strings.HasSuffix(manifestURL.Path, ".tar.gz") || path.Base(manifestURL.Path) == hash.S3LegacyObject {
+		return new(hash.Hash).GetManifest(manifestString)
+	}
+
 	bucket := manifestURL.Host
 	key := manifestURL.Path
 
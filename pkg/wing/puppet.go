@@ -24,6 +24,7 @@ import (
 
 	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
 	"github.com/jetstack/tarmak/pkg/wing/provider"
+	"github.com/jetstack/tarmak/pkg/wing/signature"
 )
 
 // This make sure puppet is converged when neccessary
@@ -40,56 +41,42 @@ func (w *Wing) runPuppet() (*v1alpha1.InstanceStatus, error) {
 		w.log.Warn("reporting status failed: ", err)
 	}
 
-	originalReader, err := w.getManifests(w.flags.ManifestURL)
+	// a hash in the converge spec pins the instance to that manifest, without
+	// a path it is only read from the cache, as the latest manifest differs
+	spec, err := w.convergeSpec()
 	if err != nil {
 		return status, err
 	}
 
-	// buffer file locally
-	buf, err := ioutil.ReadAll(originalReader)
-	if err != nil {
-		return status, err
-	}
-	err = originalReader.Close()
-	if err != nil {
-		return status, err
-	}
-	// create reader from buffer
-	reader := bytes.NewReader(buf)
-
-	// build hash over puppet.tar.gz
-	hash := sha256.New()
-	if _, err := io.Copy(hash, reader); err != nil {
-		return status, err
+	manifestURL := w.flags.ManifestURL
+	var expectedHash string
+	if spec != nil {
+		expectedHash = spec.Hash
+		if spec.Path != "" {
+			manifestURL = spec.Path
+		} else if expectedHash != "" {
+			manifestURL = ""
+		}
 	}
-	hashString := fmt.Sprintf("sha256:%x", hash.Sum(nil))
-
-	// roll back reader
-	reader.Seek(0, 0)
-
-	// read tar in
-	tarReader, err := gzip.NewReader(reader)
-	if err != nil {
-		return status, err
+	if expectedHash != "" {
+		w.log.Infof("converging pinned manifest %s", expectedHash)
 	}
 
-	dir, err := ioutil.TempDir("", "wing-puppet-tar-gz")
+	dir, hashString, err := w.downloadManifests(manifestURL, expectedHash, true)
+	status.Converge.Hash = hashString
 	if err != nil {
 		return status, err
 	}
 	defer os.RemoveAll(dir) // clean up
 
-	err = archive.Unpack(tarReader, dir, &archive.TarOptions{})
-	if err != nil {
-		return status, err
-	}
-	tarReader.Close()
-
 	var puppetMessages []string
 	var puppetRetCodes []int
+	var attempts int
 
 	puppetApplyCmd := func() error {
-		output, retCode, err := w.puppetApply(dir)
+		removePuppetReport(dir)
+		output, retCode, err := w.puppetApply(dir, false)
+		attempts++
 
 		if err == nil && retCode != 0 {
 			err = fmt.Errorf("puppet apply has not converged yet (return code %d)", retCode)
@@ -99,8 +86,14 @@ func (w *Wing) runPuppet() (*v1alpha1.InstanceStatus, error) {
 			output = fmt.Sprintf("puppet apply error: %s\n%s", err, output)
 		}
 
-		puppetMessages = append(puppetMessages, output)
+		// keep only the last retries, matching messages and exit codes
+		puppetMessages = appendMessage(puppetMessages, output)
 		puppetRetCodes = append(puppetRetCodes, retCode)
+		if len(puppetRetCodes) > len(puppetMessages) {
+			puppetRetCodes = puppetRetCodes[len(puppetRetCodes)-len(puppetMessages):]
+		}
+		convergeRetries.Set(float64(attempts - 1))
+		puppetLastExitCode.Set(float64(retCode))
 
 		// start converging mainfest
 		status = &v1alpha1.InstanceStatus{
@@ -109,6 +102,7 @@ func (w *Wing) runPuppet() (*v1alpha1.InstanceStatus, error) {
 				Messages:  puppetMessages,
 				ExitCodes: puppetRetCodes,
 				Hash:      hashString,
+				Report:    w.puppetReport(dir),
 			},
 		}
 		statusErr := w.reportStatus(status)
@@ -119,23 +113,20 @@ func (w *Wing) runPuppet() (*v1alpha1.InstanceStatus, error) {
 		return err
 	}
 
-	expBackoff := backoff.NewExponentialBackOff()
-	expBackoff.InitialInterval = time.Second * 30
-	expBackoff.MaxElapsedTime = time.Minute * 30
-
 	// add context to backoff
 	ctx, cancelRetries := context.WithCancel(context.Background())
-	b := backoff.WithContext(expBackoff, ctx)
+	b := backoff.WithContext(w.convergeBackOff(), ctx)
 
 	quitCh := make(chan struct{})
 	defer close(quitCh)
 
-	// cancel retries when supposed to stop
-
+	// cancel retries when supposed to stop, the stop channel is only replaced
+	// between puppet runs
+	stopCh := w.convergeStopCh
 	go func() {
 		for {
 			select {
-			case <-w.convergeStopCh:
+			case <-stopCh:
 				cancelRetries()
 				return
 			case <-quitCh:
@@ -144,60 +135,310 @@ func (w *Wing) runPuppet() (*v1alpha1.InstanceStatus, error) {
 		}
 	}()
 
+	// once retries are exhausted the converge has failed
 	err = backoff.Retry(puppetApplyCmd, b)
 	if err != nil {
-		w.log.Error("error applying puppet:", err)
+		return status, fmt.Errorf("error applying puppet: %s", err)
+	}
+
+	if err := w.manifestCache.MarkGood(hashString); err != nil {
+		w.log.Warnf("error marking manifest %s as good: %s", hashString, err)
 	}
 
 	return status, nil
 }
 
+// convergeSpec returns the converge spec of the instance. While the wing API
+// is unreachable the locally remembered pin is used, without a manifest
+// cache the converge fails rather than dropping the pin.
+func (w *Wing) convergeSpec() (*v1alpha1.InstanceSpecManifest, error) {
+	instance, err := w.clientset.WingV1alpha1().Instances(w.flags.ClusterName).Get(
+		w.flags.InstanceName,
+		metav1.GetOptions{},
+	)
+	if kerr, ok := err.(*apierrors.StatusError); ok && kerr.ErrStatus.Reason == metav1.StatusReasonNotFound {
+		instance, err = &v1alpha1.Instance{}, nil
+	}
+	if err != nil {
+		if w.manifestCache == nil {
+			return nil, fmt.Errorf("error getting converge spec: %s", err)
+		}
+		w.log.Warnf("error getting converge spec, using the local pin: %s", err)
+		return w.manifestCache.Pinned()
+	}
+
+	var spec *v1alpha1.InstanceSpecManifest
+	if instance.Spec != nil {
+		spec = instance.Spec.Converge
+	}
+
+	if err := w.manifestCache.Pin(spec); err != nil {
+		w.log.Warnf("error remembering pinned manifest: %s", err)
+	}
+
+	return spec, nil
+}
+
 func (w *Wing) converge() {
 	w.convergeWG.Add(1)
 	defer w.convergeWG.Done()
 
+	// ensure only one puppet run at a time
+	w.puppetMu.Lock()
+	defer w.puppetMu.Unlock()
+
+	start := time.Now()
+	setConvergeState(v1alpha1.InstanceManifestStateConverging)
+	convergeRetries.Set(0)
+
 	// run puppet
 	status, err := w.runPuppet()
 	if err != nil {
-		status.Converge.State = v1alpha1.InstanceManifestStateError
-		status.Converge.Messages = append(status.Converge.Messages, err.Error())
+		status.Converge.State = manifestErrorState(err)
+		status.Converge.Messages = appendMessage(status.Converge.Messages, err.Error())
 		w.log.Error(err)
 	} else {
 		status.Converge.State = v1alpha1.InstanceManifestStateConverged
 	}
 
+	convergeDuration.Observe(time.Since(start).Seconds())
+	setConvergeState(status.Converge.State)
+
 	// feedback puppet status to apiserver
 	if err := w.reportStatus(status); err != nil {
 		w.log.Warn("reporting status failed: ", err)
 	}
 }
 
-func (w *Wing) puppetCommand(dir string) Command {
+// This runs puppet in noop mode against the requested manifest and reports
+// the changes that would be applied
+func (w *Wing) runPuppetDryRun(spec *v1alpha1.InstanceSpecManifest) (*v1alpha1.InstanceStatus, error) {
+	status := &v1alpha1.InstanceStatus{
+		DryRun: &v1alpha1.InstanceStatusManifest{
+			State: v1alpha1.InstanceManifestStateConverging,
+		},
+	}
+
+	err := w.reportStatus(status)
+	if err != nil {
+		w.log.Warn("reporting status failed: ", err)
+	}
+
+	manifestURL := w.flags.ManifestURL
+	if spec.Path != "" {
+		manifestURL = spec.Path
+	}
+
+	dir, hashString, err := w.downloadManifests(manifestURL, spec.Hash, false)
+	status.DryRun.Hash = hashString
+	if err != nil {
+		return status, err
+	}
+	defer os.RemoveAll(dir) // clean up
+
+	output, retCode, err := w.puppetApply(dir, true)
+	status.DryRun.Messages = []string{truncateMessage(output)}
+	status.DryRun.ExitCodes = []int{retCode}
+	status.DryRun.Report = w.puppetReport(dir)
+	if err != nil {
+		return status, err
+	}
+
+	// with --detailed-exitcodes, 2 signals pending changes and anything with
+	// bit 4 set signals failures
+	if retCode&4 != 0 {
+		return status, fmt.Errorf("puppet dry run failed (return code %d)", retCode)
+	}
+
+	return status, nil
+}
+
+func (w *Wing) dryRun(spec *v1alpha1.InstanceSpecManifest) {
+	w.convergeWG.Add(1)
+	defer w.convergeWG.Done()
+
+	// a dry run shares the vardir and lock of puppet with converge runs
+	w.puppetMu.Lock()
+	defer w.puppetMu.Unlock()
+
+	// run puppet in noop mode
+	status, err := w.runPuppetDryRun(spec)
+	if err != nil {
+		status.DryRun.State = manifestErrorState(err)
+		status.DryRun.Messages = appendMessage(status.DryRun.Messages, err.Error())
+		w.log.Error(err)
+	} else {
+		status.DryRun.State = v1alpha1.InstanceManifestStateConverged
+	}
+
+	// feedback dry run status to apiserver
+	if err := w.reportStatus(status); err != nil {
+		w.log.Warn("reporting status failed: ", err)
+	}
+}
+
+// manifests failing verification are reported separately from puppet errors
+func manifestErrorState(err error) v1alpha1.InstanceManifestState {
+	if signature.IsVerificationError(err) {
+		return v1alpha1.InstanceManifestStateVerificationFailed
+	}
+	return v1alpha1.InstanceManifestStateError
+}
+
+// download the manifests, verify them against expectedHash if not empty and
+// unpack them into a temporary directory. Only manifests to converge are
+// cached, as dry runs may test manifests which are never applied.
+func (w *Wing) downloadManifests(manifestURL, expectedHash string, cache bool) (dir string, hashString string, err error) {
+	buf, err := w.fetchManifests(manifestURL, expectedHash)
+	if err != nil {
+		return "", "", err
+	}
+
+	// create reader from buffer
+	reader := bytes.NewReader(buf)
+
+	// build hash over puppet.tar.gz
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", "", err
+	}
+	hashString = fmt.Sprintf("sha256:%x", hash.Sum(nil))
+
+	if expectedHash != "" && expectedHash != hashString {
+		return "", hashString, fmt.Errorf("manifest hash mismatch, expected '%s' got '%s'", expectedHash, hashString)
+	}
+
+	// roll back reader
+	reader.Seek(0, 0)
+
+	// read tar in
+	var tarReader io.ReadCloser
+	if w.manifestPublicKey != nil {
+		tarData, err := signature.VerifyTarGz(w.manifestPublicKey, reader)
+		if err != nil {
+			return "", hashString, err
+		}
+		tarReader = ioutil.NopCloser(bytes.NewReader(tarData))
+	} else {
+		tarReader, err = gzip.NewReader(reader)
+		if err != nil {
+			return "", hashString, err
+		}
+	}
+
+	dir, err = ioutil.TempDir("", "wing-puppet-tar-gz")
+	if err != nil {
+		return "", hashString, err
+	}
+
+	err = archive.Unpack(tarReader, dir, &archive.TarOptions{})
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", hashString, err
+	}
+	tarReader.Close()
+
+	if !cache {
+		return dir, hashString, nil
+	}
+	if err := w.manifestCache.Put(hashString, buf); err != nil {
+		w.log.Warnf("error caching manifest %s: %s", hashString, err)
+	}
+
+	return dir, hashString, nil
+}
+
+// fetchManifests reads the manifest from the cache if it is pinned by hash,
+// otherwise it is downloaded. A pinned manifest without an URL is only read
+// from the cache. If the download fails the last manifest that converged is
+// used.
+func (w *Wing) fetchManifests(manifestURL, expectedHash string) ([]byte, error) {
+	if expectedHash != "" {
+		buf, err := w.manifestCache.Get(expectedHash)
+		if err != nil {
+			w.log.Warnf("error reading manifest %s from cache: %s", expectedHash, err)
+		} else if buf != nil {
+			w.log.Infof("using cached manifest %s", expectedHash)
+			return buf, nil
+		}
+		if manifestURL == "" {
+			return nil, fmt.Errorf("pinned manifest %s is not cached", expectedHash)
+		}
+	}
+
+	buf, err := w.readManifests(manifestURL)
+	if err == nil {
+		return buf, nil
+	}
+
+	// a pinned manifest must not be replaced by another one
+	if expectedHash != "" {
+		return nil, err
+	}
+
+	hash, cached, cacheErr := w.manifestCache.LastGood()
+	if cacheErr != nil {
+		w.log.Warnf("error reading last good manifest from cache: %s", cacheErr)
+	}
+	if cached == nil {
+		return nil, err
+	}
+
+	w.log.Warnf("error downloading manifest, using last good manifest %s: %s", hash, err)
+	return cached, nil
+}
+
+func (w *Wing) readManifests(manifestURL string) ([]byte, error) {
+	start := time.Now()
+
+	reader, err := w.getManifests(manifestURL)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// buffer file locally
+	buf, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	manifestDownloadDuration.Observe(time.Since(start).Seconds())
+	return buf, nil
+}
+
+func (w *Wing) puppetCommand(dir string, noop bool) Command {
 	if w.puppetCommandOverride != nil {
 		return w.puppetCommandOverride
 	}
 
+	args := []string{
+		"apply",
+		"--detailed-exitcodes",
+		"--color",
+		"no",
+		"--environment",
+		"production",
+		"--hiera_config",
+		filepath.Join(dir, "hiera.yaml"),
+		"--modulepath",
+		filepath.Join(dir, "modules"),
+	}
+	if noop {
+		args = append(args, "--noop")
+	}
+	args = append(args, puppetReportArgs(dir)...)
+	args = append(args, filepath.Join(dir, "manifests/site.pp"))
+
 	return &execCommand{
-		Cmd: exec.Command(
-			"puppet",
-			"apply",
-			"--detailed-exitcodes",
-			"--color",
-			"no",
-			"--environment",
-			"production",
-			"--hiera_config",
-			filepath.Join(dir, "hiera.yaml"),
-			"--modulepath",
-			filepath.Join(dir, "modules"),
-			filepath.Join(dir, "manifests/site.pp"),
-		),
+		Cmd: exec.Command("puppet", args...),
 	}
 }
 
-// apply puppet code in a specific directory
-func (w *Wing) puppetApply(dir string) (output string, retCode int, err error) {
-	puppetCmd := w.puppetCommand(dir)
+// apply puppet code in a specific directory, noop only reports the changes
+// that would be made
+func (w *Wing) puppetApply(dir string, noop bool) (output string, retCode int, err error) {
+	puppetCmd := w.puppetCommand(dir, noop)
 
 	var mu sync.Mutex
 	var wg sync.WaitGroup
@@ -242,10 +483,11 @@ func (w *Wing) puppetApply(dir string) (output string, retCode int, err error) {
 	// handle exit signal
 	wg.Add(1)
 	quitCh := make(chan struct{})
+	stopCh := w.convergeStopCh
 	go func() {
 		for {
 			select {
-			case <-w.convergeStopCh:
+			case <-stopCh:
 				if puppetCmd != nil && puppetCmd.Process() != nil {
 					w.log.Debugf("terminating puppet pid=%d process early", puppetCmd.Process().Pid)
 					err := puppetCmd.Process().Signal(syscall.SIGTERM)
@@ -314,7 +556,17 @@ func (w *Wing) reportStatus(status *v1alpha1.InstanceStatus) error {
 		return fmt.Errorf("error get existing instance: %s", err)
 	}
 
-	instance.Status = status.DeepCopy()
+	// only replace the parts of the status we are reporting on, so converge
+	// and dry run reports do not overwrite each other
+	if instance.Status == nil {
+		instance.Status = &v1alpha1.InstanceStatus{}
+	}
+	if status.Converge != nil {
+		instance.Status.Converge = status.Converge.DeepCopy()
+	}
+	if status.DryRun != nil {
+		instance.Status.DryRun = status.DryRun.DeepCopy()
+	}
 	_, err = instanceAPI.Update(instance)
 	if err != nil {
 		return fmt.Errorf("error updating existing instance: %s", err)
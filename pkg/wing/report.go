@@ -0,0 +1,153 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+const (
+	puppetSummaryFile = "last_run_summary.yaml"
+	puppetReportFile  = "last_run_report.yaml"
+
+	// only the tail of puppet's output of the last retries is kept in the
+	// instance status, the report has the details
+	maxMessageLength = 4096
+	maxMessages      = 5
+)
+
+// puppetSummary is the part of puppet's last run summary wing reports
+type puppetSummary struct {
+	Resources map[string]int     `yaml:"resources"`
+	Time      map[string]float64 `yaml:"time"`
+}
+
+// puppetRunReport is the part of puppet's last run report wing reports, the
+// ruby object tags of the report are ignored
+type puppetRunReport struct {
+	ResourceStatuses map[string]struct {
+		Failed bool `yaml:"failed"`
+	} `yaml:"resource_statuses"`
+}
+
+// puppetReportArgs makes puppet write its summary and report into dir,
+// report processors are disabled so reports do not pile up on the instance
+func puppetReportArgs(dir string) []string {
+	return []string{
+		"--report",
+		"--reports",
+		"none",
+		"--lastrunfile",
+		filepath.Join(dir, puppetSummaryFile),
+		"--lastrunreport",
+		filepath.Join(dir, puppetReportFile),
+	}
+}
+
+// removePuppetReport makes sure a report is not read twice, if puppet fails
+// before writing a new one
+func removePuppetReport(dir string) {
+	for _, file := range []string{puppetSummaryFile, puppetReportFile} {
+		os.Remove(filepath.Join(dir, file))
+	}
+}
+
+// puppetReport returns the report of the last puppet run in dir, it is nil if
+// puppet has not written one
+func (w *Wing) puppetReport(dir string) *v1alpha1.InstanceManifestReport {
+	report, err := readPuppetReport(dir)
+	if err != nil {
+		w.log.Warnf("error reading puppet report: %s", err)
+	}
+	return report
+}
+
+func readPuppetReport(dir string) (*v1alpha1.InstanceManifestReport, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, puppetSummaryFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var summary puppetSummary
+	if err := yaml.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", puppetSummaryFile, err)
+	}
+
+	report := &v1alpha1.InstanceManifestReport{
+		ResourcesTotal:     summary.Resources["total"],
+		ResourcesChanged:   summary.Resources["changed"],
+		ResourcesFailed:    summary.Resources["failed"],
+		ResourcesOutOfSync: summary.Resources["out_of_sync"],
+	}
+
+	for key, seconds := range summary.Time {
+		// last_run is a timestamp
+		if key == "last_run" {
+			continue
+		}
+		if report.Timings == nil {
+			report.Timings = make(map[string]metav1.Duration)
+		}
+		report.Timings[key] = metav1.Duration{
+			Duration: time.Duration(seconds * float64(time.Second)).Round(time.Millisecond),
+		}
+	}
+
+	// the resources that failed are only part of the full report
+	data, err = ioutil.ReadFile(filepath.Join(dir, puppetReportFile))
+	if os.IsNotExist(err) {
+		return report, nil
+	} else if err != nil {
+		return report, err
+	}
+
+	var runReport puppetRunReport
+	if err := yaml.Unmarshal(data, &runReport); err != nil {
+		return report, fmt.Errorf("error parsing %s: %s", puppetReportFile, err)
+	}
+
+	for title, status := range runReport.ResourceStatuses {
+		if status.Failed {
+			report.FailedResources = append(report.FailedResources, title)
+		}
+	}
+	sort.Strings(report.FailedResources)
+
+	return report, nil
+}
+
+// truncateMessage keeps the tail of puppet's output, starting at a full line
+func truncateMessage(message string) string {
+	if len(message) <= maxMessageLength {
+		return message
+	}
+
+	tail := message[len(message)-maxMessageLength:]
+	if pos := strings.Index(tail, "\n"); pos >= 0 && pos < len(tail)-1 {
+		tail = tail[pos+1:]
+	}
+
+	return fmt.Sprintf("[%d bytes truncated]\n%s", len(message)-len(tail), tail)
+}
+
+// appendMessage adds the truncated message and drops the oldest messages
+// exceeding maxMessages
+func appendMessage(messages []string, message string) []string {
+	messages = append(messages, truncateMessage(message))
+	if len(messages) > maxMessages {
+		messages = messages[len(messages)-maxMessages:]
+	}
+	return messages
+}
@@ -0,0 +1,144 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package wing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+const testPuppetSummary = `---
+version:
+  config: 1541500000
+  puppet: 5.5.6
+resources:
+  changed: 2
+  corrective_change: 0
+  failed: 1
+  failed_to_restart: 0
+  out_of_sync: 3
+  restarted: 0
+  scheduled: 0
+  skipped: 0
+  total: 120
+time:
+  file: 0.25
+  service: 1.5
+  config_retrieval: 2.0004
+  total: 3.75
+  last_run: 1541500123
+changes:
+  total: 2
+events:
+  failure: 1
+  success: 2
+  total: 3
+`
+
+const testPuppetReport = `--- !ruby/object:Puppet::Transaction::Report
+host: worker-1
+time: 2018-11-06 10:22:03.123456789 +00:00
+status: failed
+resource_statuses:
+  Service[kubelet]: !ruby/object:Puppet::Resource::Status
+    title: kubelet
+    resource_type: Service
+    failed: true
+    events:
+    - !ruby/object:Puppet::Transaction::Event
+      status: failure
+  File[/etc/motd]: !ruby/object:Puppet::Resource::Status
+    title: "/etc/motd"
+    resource_type: File
+    failed: false
+    changed: true
+`
+
+func TestReadPuppetReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wing-puppet-report")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// no report written
+	report, err := readPuppetReport(dir)
+	if err != nil || report != nil {
+		t.Fatalf("expected no report and no error, got %+v, %v", report, err)
+	}
+
+	for file, content := range map[string]string{
+		puppetSummaryFile: testPuppetSummary,
+		puppetReportFile:  testPuppetReport,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0600); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	report, err = readPuppetReport(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := &v1alpha1.InstanceManifestReport{
+		ResourcesTotal:     120,
+		ResourcesChanged:   2,
+		ResourcesFailed:    1,
+		ResourcesOutOfSync: 3,
+		FailedResources:    []string{"Service[kubelet]"},
+		Timings: map[string]metav1.Duration{
+			"file":             {Duration: 250 * time.Millisecond},
+			"service":          {Duration: 1500 * time.Millisecond},
+			"config_retrieval": {Duration: 2 * time.Second},
+			"total":            {Duration: 3750 * time.Millisecond},
+		},
+	}
+	if !reflect.DeepEqual(report, exp) {
+		t.Errorf("unexpected report\nexp: %+v\nact: %+v", exp, report)
+	}
+
+	// a stale report is removed before puppet runs
+	removePuppetReport(dir)
+	if report, _ := readPuppetReport(dir); report != nil {
+		t.Errorf("expected report to be removed, got %+v", report)
+	}
+}
+
+func TestAppendMessage(t *testing.T) {
+	short := "Notice: Applied catalog\n"
+	if act := truncateMessage(short); act != short {
+		t.Errorf("short message should not be truncated: %q", act)
+	}
+
+	long := strings.Repeat("Notice: line of output\n", 1000) + "Error: last line\n"
+	truncated := truncateMessage(long)
+	if len(truncated) > maxMessageLength+50 {
+		t.Errorf("message not truncated, length %d", len(truncated))
+	}
+	if !strings.HasPrefix(truncated, "[") || !strings.Contains(truncated, "bytes truncated]\nNotice: line of output\n") {
+		t.Errorf("truncated message should start at a full line: %q", truncated[:80])
+	}
+	if !strings.HasSuffix(truncated, "Error: last line\n") {
+		t.Error("truncated message should keep the tail")
+	}
+
+	var messages []string
+	for i := 0; i < maxMessages+3; i++ {
+		messages = appendMessage(messages, strings.Repeat("x", i))
+	}
+	if len(messages) != maxMessages {
+		t.Fatalf("expected %d messages, got %d", maxMessages, len(messages))
+	}
+	if messages[0] != strings.Repeat("x", 3) {
+		t.Errorf("expected oldest messages to be dropped, first is %q", messages[0])
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package server
+
+import (
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+	listers "github.com/jetstack/tarmak/pkg/wing/client/listers/wing/v1alpha1"
+)
+
+// instances without a reported converge state
+const instanceStateUnknown = "unknown"
+
+var instancesDesc = prometheus.NewDesc(
+	"wing_instances",
+	"Number of instances per cluster in each converge state.",
+	[]string{"cluster", "state"},
+	nil,
+)
+
+// instanceCollector counts the instances known to the wing server by their
+// converge state
+type instanceCollector struct {
+	lister listers.InstanceLister
+}
+
+var _ prometheus.Collector = &instanceCollector{}
+
+func newInstanceCollector(lister listers.InstanceLister) *instanceCollector {
+	return &instanceCollector{lister: lister}
+}
+
+func (c *instanceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- instancesDesc
+}
+
+func (c *instanceCollector) Collect(ch chan<- prometheus.Metric) {
+	instances, err := c.lister.List(labels.Everything())
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(instancesDesc, fmt.Errorf("error listing instances: %s", err))
+		return
+	}
+
+	for cluster, states := range countInstances(instances) {
+		for state, count := range states {
+			ch <- prometheus.MustNewConstMetric(instancesDesc, prometheus.GaugeValue, float64(count), cluster, state)
+		}
+	}
+}
+
+// countInstances returns the number of instances per cluster and state, all
+// known states are present for every cluster
+func countInstances(instances []*v1alpha1.Instance) map[string]map[string]int {
+	counts := map[string]map[string]int{}
+
+	for _, instance := range instances {
+		cluster := instance.Namespace
+		if _, ok := counts[cluster]; !ok {
+			counts[cluster] = map[string]int{instanceStateUnknown: 0}
+			for _, state := range v1alpha1.InstanceManifestStates {
+				counts[cluster][string(state)] = 0
+			}
+		}
+
+		state := instanceStateUnknown
+		if instance.Status != nil && instance.Status.Converge != nil && instance.Status.Converge.State != "" {
+			state = string(instance.Status.Converge.State)
+		}
+		counts[cluster][state]++
+	}
+
+	return counts
+}
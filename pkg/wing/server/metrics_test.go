@@ -0,0 +1,41 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
+)
+
+func newInstance(cluster string, state v1alpha1.InstanceManifestState) *v1alpha1.Instance {
+	instance := &v1alpha1.Instance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: cluster},
+	}
+	if state != "" {
+		instance.Status = &v1alpha1.InstanceStatus{
+			Converge: &v1alpha1.InstanceStatusManifest{State: state},
+		}
+	}
+	return instance
+}
+
+func TestCountInstances(t *testing.T) {
+	counts := countInstances([]*v1alpha1.Instance{
+		newInstance("env-a", v1alpha1.InstanceManifestStateConverged),
+		newInstance("env-a", v1alpha1.InstanceManifestStateConverged),
+		newInstance("env-a", v1alpha1.InstanceManifestStateError),
+		newInstance("env-b", v1alpha1.InstanceManifestStateVerificationFailed),
+		newInstance("env-b", ""),
+	})
+
+	exp := map[string]map[string]int{
+		"env-a": {"unknown": 0, "converging": 0, "converged": 2, "error": 1, "verificationFailed": 0},
+		"env-b": {"unknown": 1, "converging": 0, "converged": 0, "error": 0, "verificationFailed": 1},
+	}
+	if !reflect.DeepEqual(counts, exp) {
+		t.Errorf("unexpected counts\nexp: %+v\nact: %+v", exp, counts)
+	}
+}
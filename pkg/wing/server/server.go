@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -183,5 +184,12 @@ func (o WingServerOptions) RunWingServer(stopCh <-chan struct{}) error {
 		return err
 	}
 
+	// export instance states next to the apiserver's metrics
+	prometheus.MustRegister(newInstanceCollector(o.SharedInformerFactory.Wing().V1alpha1().Instances().Lister()))
+	server.GenericAPIServer.AddPostStartHookOrDie("start-wing-informers", func(context genericapiserver.PostStartHookContext) error {
+		o.SharedInformerFactory.Start(context.StopCh)
+		return nil
+	})
+
 	return server.GenericAPIServer.PrepareRun().Run(stopCh)
 }
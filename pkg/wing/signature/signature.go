@@ -0,0 +1,111 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+
+// Package signature signs puppet manifests and verifies them. The ed25519
+// signature of the uncompressed tar stream is stored in the comment of the
+// gzip header, so a signed puppet.tar.gz can be uploaded and downloaded like
+// an unsigned one.
+package signature
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	commentPrefix = "tarmak-signature:ed25519:"
+
+	// DefaultPublicKeyPath is where instances find the public key of their
+	// environment
+	DefaultPublicKeyPath = "/etc/wing/manifest-signing-key.pub"
+)
+
+// VerificationError signals a manifest that is unsigned or not signed by the
+// expected key
+type VerificationError struct {
+	reason string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("manifest signature verification failed: %s", e.reason)
+}
+
+// IsVerificationError returns whether err is a VerificationError
+func IsVerificationError(err error) bool {
+	_, ok := err.(*VerificationError)
+	return ok
+}
+
+// SignTarGz gzips the tar stream read from reader and signs it with key
+func SignTarGz(key ed25519.PrivateKey, reader io.Reader, writer io.Writer) error {
+	tarData, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("error reading tar: %s", err)
+	}
+
+	gzipWriter := gzip.NewWriter(writer)
+	gzipWriter.Comment = commentPrefix + base64.StdEncoding.EncodeToString(ed25519.Sign(key, tarData))
+
+	if _, err := gzipWriter.Write(tarData); err != nil {
+		return fmt.Errorf("error compressing tar: %s", err)
+	}
+
+	return gzipWriter.Close()
+}
+
+// VerifyTarGz decompresses the puppet.tar.gz and returns the tar stream if it
+// is signed by key
+func VerifyTarGz(key ed25519.PublicKey, reader io.Reader) ([]byte, error) {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	tarData, err := ioutil.ReadAll(gzipReader)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := gzipReader.Header.Comment
+	if !strings.HasPrefix(comment, commentPrefix) {
+		return nil, &VerificationError{reason: "manifest is not signed"}
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(comment, commentPrefix))
+	if err != nil {
+		return nil, &VerificationError{reason: fmt.Sprintf("error decoding signature: %s", err)}
+	}
+
+	if !ed25519.Verify(key, tarData, sig) {
+		return nil, &VerificationError{reason: "manifest is not signed by the environment's key"}
+	}
+
+	return tarData, nil
+}
+
+// EncodePublicKey returns the base64 encoded public key as read by
+// ParsePublicKey
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey reads a base64 encoded public key
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding public key: %s", err)
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key has %d bytes, expected %d", len(key), ed25519.PublicKeySize)
+	}
+
+	return ed25519.PublicKey(key), nil
+}
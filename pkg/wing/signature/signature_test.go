@@ -0,0 +1,91 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package signature
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestSignVerifyTarGz(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tarData := []byte("tar content")
+
+	signed := new(bytes.Buffer)
+	if err := SignTarGz(priv, bytes.NewReader(tarData), signed); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	unsigned := new(bytes.Buffer)
+	gzipWriter := gzip.NewWriter(unsigned)
+	gzipWriter.Write(tarData)
+	gzipWriter.Close()
+
+	tampered := new(bytes.Buffer)
+	gzipReader, err := gzip.NewReader(bytes.NewReader(signed.Bytes()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	gzipWriter = gzip.NewWriter(tampered)
+	gzipWriter.Comment = gzipReader.Header.Comment
+	gzipWriter.Write([]byte("other tar content"))
+	gzipWriter.Close()
+
+	for _, tc := range []struct {
+		name  string
+		key   ed25519.PublicKey
+		data  []byte
+		valid bool
+	}{
+		{name: "signed", key: pub, data: signed.Bytes(), valid: true},
+		{name: "other key", key: otherPub, data: signed.Bytes()},
+		{name: "unsigned", key: pub, data: unsigned.Bytes()},
+		{name: "tampered", key: pub, data: tampered.Bytes()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := VerifyTarGz(tc.key, bytes.NewReader(tc.data))
+			if !tc.valid {
+				if !IsVerificationError(err) {
+					t.Fatalf("expected verification error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !bytes.Equal(data, tarData) {
+				t.Errorf("unexpected tar content: %s", data)
+			}
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	parsed, err := ParsePublicKey([]byte(EncodePublicKey(pub) + "\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !bytes.Equal(parsed, pub) {
+		t.Error("parsed public key differs")
+	}
+
+	if _, err := ParsePublicKey([]byte("dG9vIHNob3J0")); err == nil {
+		t.Error("expected error for short key")
+	}
+}
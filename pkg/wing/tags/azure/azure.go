@@ -0,0 +1,139 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/jetstack/tarmak/pkg/azure/arm"
+)
+
+const (
+	metadataEndpoint = "http://169.254.169.254/metadata/instance/compute?api-version=2017-08-01"
+	keyDir           = "/etc/ssh"
+)
+
+type AzureTags struct {
+	log         *logrus.Entry
+	environment string
+}
+
+func New(log *logrus.Entry, e string) *AzureTags {
+	return &AzureTags{
+		log:         log,
+		environment: e,
+	}
+}
+
+type instanceMetadata struct {
+	SubscriptionID    string `json:"subscriptionId"`
+	ResourceGroupName string `json:"resourceGroupName"`
+	Name              string `json:"name"`
+	VMScaleSetName    string `json:"vmScaleSetName"`
+}
+
+// resourceID returns the ID of the virtual machine or of the scale set
+// instance, which is named <scale set>_<instance id>
+func (m *instanceMetadata) resourceID() string {
+	prefix := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute", m.SubscriptionID, m.ResourceGroupName)
+
+	if m.VMScaleSetName == "" {
+		return fmt.Sprintf("%s/virtualMachines/%s", prefix, m.Name)
+	}
+
+	instanceID := strings.TrimPrefix(m.Name, fmt.Sprintf("%s_", m.VMScaleSetName))
+	return fmt.Sprintf("%s/virtualMachineScaleSets/%s/virtualMachines/%s", prefix, m.VMScaleSetName, instanceID)
+}
+
+// EnsureMachineTags publishes the SSH host public keys in the tags of the
+// virtual machine
+func (a *AzureTags) EnsureMachineTags() error {
+	metadata, err := a.requestMetadata()
+	if err != nil {
+		return err
+	}
+
+	keys, err := a.fetchLocalPublicKeys()
+	if err != nil {
+		return err
+	}
+
+	authorizer, err := arm.NewMSIAuthorizer()
+	if err != nil {
+		return err
+	}
+
+	if err := arm.New(metadata.SubscriptionID, authorizer).UpdateTags(
+		metadata.resourceID(),
+		arm.SSHHostKeyTags(keys),
+	); err != nil {
+		return fmt.Errorf("failed to update tags: %s", err)
+	}
+
+	a.log.Infof("successfully ensured instance tags")
+
+	return nil
+}
+
+func (a *AzureTags) requestMetadata() (*instanceMetadata, error) {
+	req, err := http.NewRequest(http.MethodGet, metadataEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query instance metadata: %s", err)
+	}
+	defer resp.Body.Close()
+
+	metadata := new(instanceMetadata)
+	if err := json.NewDecoder(resp.Body).Decode(metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode instance metadata: %s", err)
+	}
+
+	return metadata, nil
+}
+
+func (a *AzureTags) fetchLocalPublicKeys() ([]string, error) {
+	fs, err := ioutil.ReadDir(keyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []string
+	for _, f := range fs {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), "ssh_host") || !strings.HasSuffix(f.Name(), ".pub") {
+			continue
+		}
+
+		path := filepath.Join(keyDir, f.Name())
+
+		fileData, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// ensure we do have a public key
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey(fileData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse local public key %s: %s", path, err)
+		}
+
+		a.log.Debugf("using public key %s", path)
+		publicKeys = append(publicKeys, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))))
+	}
+
+	sort.Strings(publicKeys)
+
+	return publicKeys, nil
+}
@@ -0,0 +1,25 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package baremetal
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// BaremetalTags does nothing, as the SSH host keys of existing machines are
+// part of the static inventory
+type BaremetalTags struct {
+	log         *logrus.Entry
+	environment string
+}
+
+func New(log *logrus.Entry, e string) *BaremetalTags {
+	return &BaremetalTags{
+		log:         log,
+		environment: e,
+	}
+}
+
+func (b *BaremetalTags) EnsureMachineTags() error {
+	b.log.Debug("no machine tags to ensure on baremetal")
+	return nil
+}
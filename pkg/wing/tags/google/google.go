@@ -0,0 +1,114 @@
+// Copyright Jetstack Ltd. See LICENSE for details.
+package google
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/compute/metadata"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/oauth2/google"
+
+	"github.com/jetstack/tarmak/pkg/google/compute"
+)
+
+const (
+	keyDir = "/etc/ssh"
+
+	// needs to match the metadata key tarmak reads the host keys from
+	metadataSSHHostKeys = "tarmak-ssh-host-keys"
+)
+
+type GoogleTags struct {
+	log         *logrus.Entry
+	environment string
+}
+
+func New(log *logrus.Entry, e string) *GoogleTags {
+	return &GoogleTags{
+		log:         log,
+		environment: e,
+	}
+}
+
+// EnsureMachineTags publishes the SSH host public keys in the metadata of
+// the instance
+func (g *GoogleTags) EnsureMachineTags() error {
+	project, err := metadata.ProjectID()
+	if err != nil {
+		return fmt.Errorf("failed to get project from metadata server: %s", err)
+	}
+
+	zone, err := metadata.Zone()
+	if err != nil {
+		return fmt.Errorf("failed to get zone from metadata server: %s", err)
+	}
+
+	instance, err := metadata.InstanceName()
+	if err != nil {
+		return fmt.Errorf("failed to get instance name from metadata server: %s", err)
+	}
+
+	keys, err := g.fetchLocalPublicKeys()
+	if err != nil {
+		return err
+	}
+
+	client, err := google.DefaultClient(context.Background(), compute.ScopeCompute)
+	if err != nil {
+		return fmt.Errorf("failed to create google client: %s", err)
+	}
+
+	if err := compute.NewWithClient(client).SetInstanceMetadata(
+		project,
+		zone,
+		instance,
+		metadataSSHHostKeys,
+		strings.Join(keys, "\n"),
+	); err != nil {
+		return fmt.Errorf("failed to set instance metadata: %s", err)
+	}
+
+	g.log.Infof("successfully ensured instance metadata")
+
+	return nil
+}
+
+func (g *GoogleTags) fetchLocalPublicKeys() ([]string, error) {
+	fs, err := ioutil.ReadDir(keyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []string
+	for _, f := range fs {
+		if f.IsDir() || !strings.HasPrefix(f.Name(), "ssh_host") || !strings.HasSuffix(f.Name(), ".pub") {
+			continue
+		}
+
+		path := filepath.Join(keyDir, f.Name())
+
+		fileData, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// ensure we do have a public key
+		publicKey, _, _, _, err := ssh.ParseAuthorizedKey(fileData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse local public key %s: %s", path, err)
+		}
+
+		g.log.Debugf("using public key %s", path)
+		publicKeys = append(publicKeys, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))))
+	}
+
+	sort.Strings(publicKeys)
+
+	return publicKeys, nil
+}
@@ -6,6 +6,9 @@ import (
 	"os"
 
 	"github.com/jetstack/tarmak/pkg/wing/tags/aws"
+	"github.com/jetstack/tarmak/pkg/wing/tags/azure"
+	"github.com/jetstack/tarmak/pkg/wing/tags/baremetal"
+	"github.com/jetstack/tarmak/pkg/wing/tags/google"
 	"github.com/sirupsen/logrus"
 )
 
@@ -26,6 +29,15 @@ func New(log *logrus.Entry, environment string) (Tags, error) {
 	case "amazon", "aws", "":
 		return aws.New(log, environment), nil
 
+	case "google", "gce":
+		return google.New(log, environment), nil
+
+	case "azure":
+		return azure.New(log, environment), nil
+
+	case "baremetal":
+		return baremetal.New(log, environment), nil
+
 	default:
 		return nil, fmt.Errorf("target provider for tags not supported %s", provider)
 	}
@@ -2,7 +2,9 @@
 package wing
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"strings"
@@ -11,6 +13,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ed25519"
 	"k8s.io/apimachinery/pkg/fields"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
@@ -19,6 +22,7 @@ import (
 
 	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
 	client "github.com/jetstack/tarmak/pkg/wing/client/clientset/versioned"
+	"github.com/jetstack/tarmak/pkg/wing/signature"
 	"github.com/jetstack/tarmak/pkg/wing/tags"
 )
 
@@ -36,19 +40,36 @@ type Wing struct {
 
 	convergeStopCh chan struct{}  // stop channel, signals to cancel current puppet run
 	convergeWG     sync.WaitGroup // wait group for converge runs
+	puppetMu       sync.Mutex     // ensures only one puppet run at a time
 
 	// controller loop
 	controller *Controller
 
 	// allows overriding puppet command for testing
 	puppetCommandOverride Command
+
+	// manifests need to be signed by this key, if set
+	manifestPublicKey ed25519.PublicKey
+
+	// the last verified manifests
+	manifestCache *manifestCache
 }
 
 type Flags struct {
-	ManifestURL  string
-	ServerURL    string
-	ClusterName  string
-	InstanceName string
+	ManifestURL           string
+	ManifestPublicKeyPath string
+	ManifestCacheDir      string
+	ManifestCacheSize     int
+	MetricsListenAddress  string
+	ServerURL             string
+	ClusterName           string
+	InstanceName          string
+
+	ConvergeRetryInitialInterval time.Duration
+	ConvergeRetryMaxInterval     time.Duration
+	ConvergeRetryMaxElapsedTime  time.Duration
+	ReconvergeInterval           time.Duration
+	ReconvergeJitter             time.Duration
 }
 
 func New(flags *Flags) *Wing {
@@ -61,6 +82,7 @@ func New(flags *Flags) *Wing {
 		stopCh:         make(chan struct{}),
 		convergeStopCh: make(chan struct{}),
 	}
+	t.manifestCache = newManifestCache(t.log, flags.ManifestCacheDir, flags.ManifestCacheSize)
 	return t
 }
 
@@ -101,6 +123,10 @@ func (w *Wing) Run(args []string) error {
 		return err
 	}
 
+	if err := w.loadManifestPublicKey(); err != nil {
+		return err
+	}
+
 	// create connection to wing server
 	restConfig := &rest.Config{
 		Host: w.flags.ServerURL,
@@ -119,9 +145,14 @@ func (w *Wing) Run(args []string) error {
 	signal.Notify(signalCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
 	w.signalHandler(signalCh)
 
+	w.serveMetrics()
+
 	// run converge loop after first start
 	go w.converge()
 
+	// re-converge periodically, if enabled
+	go w.reconverge()
+
 	// start watching for API server events that trigger applies
 	w.watchForNotifications()
 
@@ -132,6 +163,36 @@ func (w *Wing) Run(args []string) error {
 	return err
 }
 
+// instances created before manifests got signed have no public key, their
+// manifests are applied without verification
+func (w *Wing) loadManifestPublicKey() error {
+	if w.flags.ManifestPublicKeyPath == "" {
+		w.log.Warn("no manifest public key given, manifest signatures are not verified")
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(w.flags.ManifestPublicKeyPath)
+	if os.IsNotExist(err) {
+		w.log.Warnf("manifest public key %s not found, manifest signatures are not verified", w.flags.ManifestPublicKeyPath)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading manifest public key: %s", err)
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		w.log.Warnf("manifest public key %s is empty, manifest signatures are not verified", w.flags.ManifestPublicKeyPath)
+		return nil
+	}
+
+	key, err := signature.ParsePublicKey(data)
+	if err != nil {
+		return fmt.Errorf("error parsing manifest public key %s: %s", w.flags.ManifestPublicKeyPath, err)
+	}
+	w.manifestPublicKey = key
+
+	return nil
+}
+
 func (w *Wing) Must(err error) *Wing {
 	if err != nil {
 		w.log.Fatal(err)
@@ -194,10 +255,11 @@ func (w *Wing) signalHandler(ch chan os.Signal) {
 				// if the puppet process is still running, kill and wait before re-converging
 				w.log.Infof("terminating puppet if existing")
 				close(w.convergeStopCh)
-				w.convergeWG.Wait()
 
 				// create new converge stop channel and run converge
+				w.puppetMu.Lock()
 				w.convergeStopCh = make(chan struct{})
+				w.puppetMu.Unlock()
 				w.converge()
 
 			case syscall.SIGINT:
@@ -3,12 +3,15 @@ package wing
 
 import (
 	"bytes"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
@@ -16,15 +19,20 @@ import (
 	"github.com/docker/docker/pkg/archive"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/hashicorp/go-multierror"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ed25519"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/rest"
 
+	"github.com/jetstack/tarmak/pkg/apis/wing/v1alpha1"
 	client "github.com/jetstack/tarmak/pkg/wing/client/clientset/versioned"
 	"github.com/jetstack/tarmak/pkg/wing/mocks"
+	"github.com/jetstack/tarmak/pkg/wing/signature"
 )
 
-var manifestURL, manifestURLgz string
+var manifestURL, manifestURLgz, manifestCacheDir string
 
 type fakeWing struct {
 	*Wing
@@ -73,6 +81,8 @@ func newFakeWing(t *testing.T) *fakeWing {
 		},
 	}
 
+	w.manifestCache = newManifestCache(w.log, manifestCacheDir, DefaultManifestCacheSize)
+
 	w.signalCh = make(chan os.Signal, 1)
 
 	w.fakeCommand = mocks.NewMockCommand(w.ctrl)
@@ -266,6 +276,9 @@ func TestWing_SIGHUP_handler_first_excute(t *testing.T) {
 	if _, ok := (<-w.stopCh); ok {
 		t.Error("expected stopCh to be closed")
 	}
+
+	// wait for the converge started by SIGHUP to finish
+	w.convergeWG.Wait()
 }
 
 // this tests when the SIGHUP hits wing when it's currently waiting in the exp backoff
@@ -336,6 +349,58 @@ func TestWing_SIGHUP_handler_backoff(t *testing.T) {
 	}
 }
 
+// this tests that puppet failing until the retries are exhausted fails the
+// converge
+func TestWing_puppet_failed(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	w.flags.ConvergeRetryInitialInterval = time.Millisecond
+	w.flags.ConvergeRetryMaxInterval = time.Millisecond
+	w.flags.ConvergeRetryMaxElapsedTime = 10 * time.Millisecond
+
+	process := exec.Command(
+		"false",
+	)
+	if err := process.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	processErr := process.Wait()
+
+	w.fakeCommand.EXPECT().Start().MinTimes(2)
+	w.fakeCommand.EXPECT().Wait().Return(processErr).MinTimes(2)
+
+	status, err := w.runPuppet()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "error applying puppet: puppet apply has not converged yet (return code 1)") {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if act, exp := manifestErrorState(err), v1alpha1.InstanceManifestStateError; act != exp {
+		t.Errorf("unexpected state: act=%s exp=%s", act, exp)
+	}
+	if codes := status.Converge.ExitCodes; len(codes) == 0 || codes[len(codes)-1] != 1 {
+		t.Errorf("unexpected exit codes: %v", codes)
+	}
+
+	w.converge()
+
+	for state, exp := range map[v1alpha1.InstanceManifestState]float64{
+		v1alpha1.InstanceManifestStateError:     1,
+		v1alpha1.InstanceManifestStateConverged: 0,
+	} {
+		metric := &dto.Metric{}
+		if err := convergeState.WithLabelValues(string(state)).Write(metric); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if act := metric.GetGauge().GetValue(); act != exp {
+			t.Errorf("expected gauge %s to be %v, got %v", state, exp, act)
+		}
+	}
+}
+
 //Test nothing happens when sending a SIGTERM after puppet has converged
 func TestWing_SIGTERM_puppet_converged(t *testing.T) {
 	w := newFakeWing(t)
@@ -450,6 +515,329 @@ func TestWing_SIGHUP_puppet_converged(t *testing.T) {
 	}
 }
 
+// this tests a dry run reporting pending changes
+func TestWing_dryRun_changes(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	// puppet apply --noop --detailed-exitcodes returns 2 for pending changes
+	process := exec.Command(
+		"sh", "-c", "exit 2",
+	)
+	if err := process.Start(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	processErr := process.Wait()
+
+	w.fakeCommand.EXPECT().Start()
+	w.fakeCommand.EXPECT().Wait().Return(processErr)
+
+	status, err := w.runPuppetDryRun(&v1alpha1.InstanceSpecManifest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if exp, act := []int{2}, status.DryRun.ExitCodes; len(act) != 1 || act[0] != exp[0] {
+		t.Errorf("unexpected exit codes, exp=%v act=%v", exp, act)
+	}
+	if !strings.HasPrefix(status.DryRun.Hash, "sha256:") {
+		t.Errorf("unexpected hash: %s", status.DryRun.Hash)
+	}
+	if status.Converge != nil {
+		t.Error("expected converge status to be untouched")
+	}
+}
+
+// this tests a dry run requested for a different manifest hash
+func TestWing_dryRun_hash_mismatch(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	status, err := w.runPuppetDryRun(&v1alpha1.InstanceSpecManifest{
+		Path: manifestURLgz,
+		Hash: "sha256:0000",
+	})
+	if err == nil {
+		t.Fatal("expected hash mismatch error")
+	}
+	if !strings.Contains(err.Error(), "hash mismatch") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if status.DryRun.Hash == "" {
+		t.Error("expected actual manifest hash to be reported")
+	}
+}
+
+// this tests a dry run of an unsigned manifest on an instance with a public
+// key
+func TestWing_dryRun_unsigned(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.manifestPublicKey = pub
+
+	status, err := w.runPuppetDryRun(&v1alpha1.InstanceSpecManifest{})
+	if !signature.IsVerificationError(err) {
+		t.Fatalf("expected verification error, got: %v", err)
+	}
+	if exp, act := v1alpha1.InstanceManifestStateVerificationFailed, manifestErrorState(err); exp != act {
+		t.Errorf("unexpected state, exp=%s act=%s", exp, act)
+	}
+	if status.DryRun.Hash == "" {
+		t.Error("expected manifest hash to be reported")
+	}
+}
+
+// this tests a dry run of a signed manifest
+func TestWing_dryRun_signed(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.manifestPublicKey = pub
+
+	reader, err := archive.TarWithOptions(manifestURL, &archive.TarOptions{NoLchown: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	signed := new(bytes.Buffer)
+	if err := signature.SignTarGz(priv, reader, signed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(manifestURLgz, signed.Bytes(), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w.fakeCommand.EXPECT().Start()
+	w.fakeCommand.EXPECT().Wait()
+
+	if _, err := w.runPuppetDryRun(&v1alpha1.InstanceSpecManifest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// this tests a dry run requested while a converge is running
+func TestWing_dryRun_during_converge(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	var running, overlaps int32
+	startedCh := make(chan struct{}, 2)
+
+	w.fakeCommand.EXPECT().Start().Do(func() {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		startedCh <- struct{}{}
+	}).Times(2)
+	w.fakeCommand.EXPECT().Wait().Do(func() {
+		// keep puppet running long enough for the dry run to be requested
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}).Times(2)
+
+	convergeDoneCh := make(chan struct{})
+	go func() {
+		w.converge()
+		close(convergeDoneCh)
+	}()
+
+	// request the dry run once the converge has started puppet
+	<-startedCh
+	w.dryRun(&v1alpha1.InstanceSpecManifest{})
+	<-convergeDoneCh
+
+	if act := atomic.LoadInt32(&overlaps); act != 0 {
+		t.Errorf("expected dry run not to overlap with converge, got %d overlapping runs", act)
+	}
+}
+
+// this tests falling back to the last good manifest if the download fails
+// and pinning to a cached manifest
+func TestWing_fetchManifests_cache(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	dir, err := ioutil.TempDir("", "wing-manifest-cache")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	w.manifestCache = newManifestCache(w.log, dir, DefaultManifestCacheSize)
+
+	unreachable := manifestURLgz + ".missing"
+
+	if _, err := w.fetchManifests(unreachable, ""); err == nil {
+		t.Fatal("expected error without cached manifest")
+	}
+
+	// manifests of dry runs are not cached
+	manifestDir, hash, err := w.downloadManifests(manifestURLgz, "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	os.RemoveAll(manifestDir)
+	if buf, err := w.manifestCache.Get(hash); err != nil || buf != nil {
+		t.Fatalf("expected dry run manifest not to be cached, err=%v", err)
+	}
+
+	manifestDir, hash, err = w.downloadManifests(manifestURLgz, "", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	os.RemoveAll(manifestDir)
+
+	if _, err := w.fetchManifests(unreachable, ""); err == nil {
+		t.Fatal("expected error without last good manifest")
+	}
+
+	if err := w.manifestCache.MarkGood(hash); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected, err := ioutil.ReadFile(manifestURLgz)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name         string
+		manifestURL  string
+		expectedHash string
+		err          bool
+	}{
+		{name: "last good", manifestURL: unreachable},
+		{name: "pinned", manifestURL: unreachable, expectedHash: hash},
+		{name: "pinned without url", expectedHash: hash},
+		{name: "pinned not cached", manifestURL: unreachable, expectedHash: testManifestHash("other"), err: true},
+		{name: "pinned not cached without url", expectedHash: testManifestHash("other"), err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf, err := w.fetchManifests(tc.manifestURL, tc.expectedHash)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(buf, expected) {
+				t.Error("unexpected manifest content")
+			}
+		})
+	}
+}
+
+// this tests that the pin is kept while the wing API is unreachable
+func TestWing_convergeSpec_unreachable(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	spec, err := w.convergeSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec != nil {
+		t.Errorf("expected no pin, got: %+v", spec)
+	}
+
+	pinned := &v1alpha1.InstanceSpecManifest{Hash: testManifestHash("a")}
+	if err := w.manifestCache.Pin(pinned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec, err = w.convergeSpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec == nil || spec.Hash != pinned.Hash {
+		t.Errorf("expected pin %s, got: %+v", pinned.Hash, spec)
+	}
+
+	// without local pin the converge fails rather than unpinning
+	w.manifestCache = nil
+	if _, err := w.convergeSpec(); err == nil || !strings.Contains(err.Error(), "error getting converge spec") {
+		t.Errorf("expected error getting converge spec, got: %v", err)
+	}
+}
+
+func TestController_dryRunRequested(t *testing.T) {
+	w := newFakeWing(t)
+	defer w.ctrl.Finish()
+	defer deleteTmpFiles(t)
+
+	c := &Controller{log: w.log, wing: w.Wing}
+
+	requested := metav1.NewTime(time.Now())
+	before := metav1.NewTime(requested.Add(-time.Minute))
+	after := metav1.NewTime(requested.Add(time.Minute))
+
+	for _, tc := range []struct {
+		name     string
+		instance *v1alpha1.Instance
+		exp      bool
+	}{
+		{
+			name:     "no spec",
+			instance: &v1alpha1.Instance{},
+			exp:      false,
+		},
+		{
+			name: "no status",
+			instance: &v1alpha1.Instance{
+				Spec: &v1alpha1.InstanceSpec{
+					DryRun: &v1alpha1.InstanceSpecManifest{RequestTimestamp: requested},
+				},
+			},
+			exp: true,
+		},
+		{
+			name: "outdated status",
+			instance: &v1alpha1.Instance{
+				Spec: &v1alpha1.InstanceSpec{
+					DryRun: &v1alpha1.InstanceSpecManifest{RequestTimestamp: requested},
+				},
+				Status: &v1alpha1.InstanceStatus{
+					DryRun: &v1alpha1.InstanceStatusManifest{LastUpdateTimestamp: before},
+				},
+			},
+			exp: true,
+		},
+		{
+			name: "up to date status",
+			instance: &v1alpha1.Instance{
+				Spec: &v1alpha1.InstanceSpec{
+					DryRun: &v1alpha1.InstanceSpecManifest{RequestTimestamp: requested},
+				},
+				Status: &v1alpha1.InstanceStatus{
+					DryRun: &v1alpha1.InstanceStatusManifest{LastUpdateTimestamp: after},
+				},
+			},
+			exp: false,
+		},
+	} {
+		if act := c.dryRunRequested(tc.instance); act != tc.exp {
+			t.Errorf("%s: unexpected result, exp=%t act=%t", tc.name, tc.exp, act)
+		}
+	}
+}
+
 func createTmpFiles() error {
 	file, err := ioutil.TempFile(os.TempDir(), "manifestURL")
 	if err != nil {
@@ -462,6 +850,11 @@ func createTmpFiles() error {
 	manifestURL = file.Name()
 	manifestURLgz = filegz.Name()
 
+	manifestCacheDir, err = ioutil.TempDir(os.TempDir(), "manifestCache")
+	if err != nil {
+		return err
+	}
+
 	if err := ioutil.WriteFile(manifestURL, []byte("0000"), 0644); err != nil {
 		return err
 	}
@@ -494,6 +887,10 @@ func deleteTmpFiles(t *testing.T) {
 		result = multierror.Append(result, err)
 	}
 
+	if err := os.RemoveAll(manifestCacheDir); err != nil {
+		result = multierror.Append(result, err)
+	}
+
 	if result != nil {
 		t.Errorf("failed to delete temp files: %v", result)
 	}
@@ -5,8 +5,8 @@ class fluent_bit::daemonset(
   include ::fluent_bit::params
   require ::kubernetes
 
-  $fluent_bit_version = $::fluent_bit::params::version
-  $fluent_bit_image_tag = $::fluent_bit::params::version
+  $fluent_bit_version = $::fluent_bit::version
+  $fluent_bit_image_tag = $::fluent_bit::version
   $busybox_image_tag = '1.30.0'
 
   $namespace = 'kube-system'
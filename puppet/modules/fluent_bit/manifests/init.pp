@@ -1,4 +1,5 @@
 class fluent_bit (
+  String $version = $::fluent_bit::params::version,
   $package_name = $::fluent_bit::params::package_name,
   $service_name = $::fluent_bit::params::service_name,
   Enum['present', 'absent'] $ensure = 'present',
@@ -2,7 +2,7 @@ class fluent_bit::install {
   include ::fluent_bit::params
 
   include stdlib
-  $fluent_bit_version = $::fluent_bit::params::version
+  $fluent_bit_version = $::fluent_bit::version
 
   ensure_resource('package', [$::fluent_bit::package_name, 'curl'],{
     ensure => present
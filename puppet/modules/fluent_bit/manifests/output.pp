@@ -6,14 +6,41 @@ define fluent_bit::output (
   $path = $::fluent_bit::path
   $types = $config['types']
   $elasticsearch = $config['elasticsearch']
+  $loki = $config['loki']
+  $splunk_hec = $config['splunkHEC']
+  $http = $config['http']
+  $kafka = $config['kafka']
+  $s3 = $config['s3']
 
-  if $elasticsearch and $elasticsearch['tlsCA'] and $elasticsearch['tlsCA'] != '' {
+  # all sinks apart from s3 share the same tls options
+  $_tls_sinks = [$elasticsearch, $loki, $splunk_hec, $http, $kafka].filter |$sink| { $sink != undef }
+  $tls_config = $_tls_sinks[0]
+
+  if $tls_config and $tls_config['tlsCA'] and $tls_config['tlsCA'] != '' {
     file { "/etc/td-agent-bit/ssl/${name}-ca.pem":
       ensure  => file,
       mode    => '0640',
       owner   => 'root',
       group   => 'root',
-      content => $elasticsearch['tlsCA'],
+      content => $tls_config['tlsCA'],
+    }
+  }
+
+  if $tls_config and $tls_config['tlsCert'] and $tls_config['tlsCert'] != '' {
+    file { "/etc/td-agent-bit/ssl/${name}-cert.pem":
+      ensure  => file,
+      mode    => '0640',
+      owner   => 'root',
+      group   => 'root',
+      content => $tls_config['tlsCert'],
+    }
+
+    file { "/etc/td-agent-bit/ssl/${name}-key.pem":
+      ensure  => file,
+      mode    => '0600',
+      owner   => 'root',
+      group   => 'root',
+      content => $tls_config['tlsKey'],
     }
   }
 
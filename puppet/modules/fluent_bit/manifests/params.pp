@@ -1,8 +1,10 @@
 class fluent_bit::params(
   String $version = '1.0.4',
+  # the loki and s3 outputs are not available in earlier versions
+  String $sinks_version = '1.6.10',
 ){
   $package_name = 'td-agent-bit'
   $service_name = 'td-agent-bit'
-  # After updating this version you need to make sure you run the scripts in
+  # After updating these versions you need to make sure you run the scripts in
   # /hack/fluentbit-repo/ to clone their repo and lock the version
 }
@@ -156,4 +156,152 @@ describe 'fluent_bit::output', :type => :define do
 
   end
 
+  context 'loki with tenant and labels' do
+    let(:title) { 'test' }
+    let(:params) {
+      {
+        :config => {"loki" => {
+            "host" => "loki.example.com",
+            "port" => 443,
+            "tls" => true,
+            "tlsVerify" => true,
+            "tenantID" => "team-a",
+            "labels" => {"job" => "fluent-bit", "cluster" => "prod"},
+            "httpBasicAuth" => {
+              "username" => "user",
+              "password" => "secret",
+            },
+          },
+          "types" => ["all"],
+        },
+      }
+    }
+
+    it 'should configure output right' do
+      should output.with_content(/#{Regexp.escape('Name loki')}/)
+      should output.with_content(/#{Regexp.escape('Host loki.example.com')}/)
+      should output.with_content(/#{Regexp.escape('Tenant_ID team-a')}/)
+      should output.with_content(/#{Regexp.escape('Labels cluster=prod, job=fluent-bit')}/)
+      should output.with_content(/#{Regexp.escape('HTTP_User user')}/)
+      should output.with_content(/#{Regexp.escape('tls.verify On')}/)
+    end
+
+  end
+
+  context 'splunk hec with client certificate' do
+    let(:title) { 'test' }
+    let(:params) {
+      {
+        :config => {"splunkHEC" => {
+            "host" => "splunk.example.com",
+            "port" => 8088,
+            "token" => "hec-token",
+            "tls" => true,
+            "tlsCA" => "ca",
+            "tlsCert" => "cert",
+            "tlsKey" => "key",
+          },
+          "types" => ["audit"],
+        },
+      }
+    }
+
+    it 'should configure output right' do
+      should output.with_content(/#{Regexp.escape('Name splunk')}/)
+      should output.with_content(/#{Regexp.escape('Splunk_Token hec-token')}/)
+      should output.with_content(/#{Regexp.escape('tls.ca_file /etc/td-agent-bit/ssl/test-ca.pem')}/)
+      should output.with_content(/#{Regexp.escape('tls.crt_file /etc/td-agent-bit/ssl/test-cert.pem')}/)
+      should output.with_content(/#{Regexp.escape('tls.key_file /etc/td-agent-bit/ssl/test-key.pem')}/)
+      should output.with_content(/#{Regexp.escape('Match audit*')}/)
+    end
+
+    it 'should write certificates' do
+      should contain_file('/etc/td-agent-bit/ssl/test-ca.pem').with_content('ca')
+      should contain_file('/etc/td-agent-bit/ssl/test-cert.pem').with_content('cert')
+      should contain_file('/etc/td-agent-bit/ssl/test-key.pem').with_content('key').with_mode('0600')
+    end
+
+  end
+
+  context 'http without tls' do
+    let(:title) { 'test' }
+    let(:params) {
+      {
+        :config => {"http" => {
+            "host" => "logs.example.com",
+            "port" => 80,
+            "uri" => "/ingest",
+            "format" => "json_lines",
+            "tls" => false,
+            "headers" => {"X-Team" => "platform"},
+          },
+          "types" => ["all"],
+        },
+      }
+    }
+
+    it 'should configure output right' do
+      should output.with_content(/#{Regexp.escape('Name http')}/)
+      should output.with_content(/#{Regexp.escape('URI /ingest')}/)
+      should output.with_content(/#{Regexp.escape('Format json_lines')}/)
+      should output.with_content(/#{Regexp.escape('Header X-Team platform')}/)
+      should output.without_content(/#{Regexp.escape('tls On')}/)
+    end
+
+  end
+
+  context 'kafka with sasl' do
+    let(:title) { 'test' }
+    let(:params) {
+      {
+        :config => {"kafka" => {
+            "brokers" => ["kafka-0.example.com:9093", "kafka-1.example.com:9093"],
+            "topic" => "logs",
+            "tls" => true,
+            "tlsVerify" => true,
+            "sasl" => {
+              "mechanism" => "SCRAM-SHA-512",
+              "username" => "user",
+              "password" => "secret",
+            },
+          },
+          "types" => ["all"],
+        },
+      }
+    }
+
+    it 'should configure output right' do
+      should output.with_content(/#{Regexp.escape('Name kafka')}/)
+      should output.with_content(/#{Regexp.escape('Brokers kafka-0.example.com:9093,kafka-1.example.com:9093')}/)
+      should output.with_content(/#{Regexp.escape('Topics logs')}/)
+      should output.with_content(/#{Regexp.escape('rdkafka.security.protocol SASL_SSL')}/)
+      should output.with_content(/#{Regexp.escape('rdkafka.sasl.mechanism SCRAM-SHA-512')}/)
+      should output.without_content(/#{Regexp.escape('tls On')}/)
+    end
+
+  end
+
+  context 's3 archive' do
+    let(:title) { 'test' }
+    let(:params) {
+      {
+        :config => {"s3" => {
+            "bucket" => "logs-archive",
+            "region" => "eu-west-1",
+            "prefix" => "fluent-bit-logs",
+          },
+          "types" => ["all"],
+        },
+      }
+    }
+
+    it 'should configure output right' do
+      should output.with_content(/#{Regexp.escape('Name s3')}/)
+      should output.with_content(/#{Regexp.escape('Bucket logs-archive')}/)
+      should output.with_content(/#{Regexp.escape('S3_Key_Format /fluent-bit-logs/$TAG/%Y/%m/%d/%H/%M/%S')}/)
+      should output.without_content(/#{Regexp.escape('tls On')}/)
+    end
+
+  end
+
 end
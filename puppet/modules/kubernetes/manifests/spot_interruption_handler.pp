@@ -0,0 +1,71 @@
+# class kubernetes::spot_interruption_handler
+#
+# Cordons and drains the node when EC2 announces the termination of its spot
+# instance, the notice is given two minutes before the instance is terminated
+class kubernetes::spot_interruption_handler(
+  Enum['present', 'absent'] $ensure = 'present',
+  Integer $poll_interval = 5,
+  Integer $grace_period = 90,
+  Optional[String] $node_name = undef,
+  Array[String] $systemd_wants = [],
+  Array[String] $systemd_requires = [],
+  Array[String] $systemd_after = [],
+  Array[String] $systemd_before = [],
+) {
+  require ::kubernetes
+  include ::kubernetes::install
+
+  $service_name = 'spot-interruption-handler'
+
+  # the node's credentials allow to cordon it and delete its pods
+  $kubeconfig_path = "${::kubernetes::config_dir}/kubeconfig-kubelet"
+  $hyperkube_path = "${::kubernetes::_dest_dir}/hyperkube"
+  $handler_path = "${::kubernetes::_dest_dir}/${service_name}"
+
+  $_systemd_wants = $systemd_wants
+  $_systemd_requires = $systemd_requires
+  $_systemd_after = ['network.target', 'kubelet.service'] + $systemd_after
+  $_systemd_before = $systemd_before
+
+  if $ensure == 'present' {
+    file {$handler_path:
+      ensure  => file,
+      mode    => '0755',
+      owner   => 'root',
+      group   => 'root',
+      content => template("kubernetes/${service_name}.sh.erb"),
+      notify  => Service["${service_name}.service"],
+    }
+
+    file{"${::kubernetes::systemd_dir}/${service_name}.service":
+      ensure  => file,
+      mode    => '0644',
+      owner   => 'root',
+      group   => 'root',
+      content => template("kubernetes/${service_name}.service.erb"),
+      notify  => Service["${service_name}.service"],
+    }
+    ~> exec { "${service_name}-daemon-reload":
+      command     => 'systemctl daemon-reload',
+      path        => $::kubernetes::path,
+      refreshonly => true,
+    }
+    -> service{ "${service_name}.service":
+      ensure => running,
+      enable => true,
+    }
+  } else {
+    service{ "${service_name}.service":
+      ensure => stopped,
+      enable => false,
+    }
+    -> file{[$handler_path, "${::kubernetes::systemd_dir}/${service_name}.service"]:
+      ensure => absent,
+    }
+    ~> exec { "${service_name}-daemon-reload":
+      command     => 'systemctl daemon-reload',
+      path        => $::kubernetes::path,
+      refreshonly => true,
+    }
+  }
+}
@@ -0,0 +1,49 @@
+require 'spec_helper'
+
+describe 'kubernetes::spot_interruption_handler' do
+  let(:pre_condition) do
+    "class{'kubernetes': version => '1.11.0'}"
+  end
+
+  let :service_file do
+    '/etc/systemd/system/spot-interruption-handler.service'
+  end
+
+  let :handler_file do
+    '/opt/kubernetes-1.11.0/spot-interruption-handler'
+  end
+
+  context 'with defaults' do
+    it 'installs the handler' do
+      should contain_file(handler_file).with_content(%r{spot/instance-action})
+      should contain_file(handler_file).with_content(%r{GRACE_PERIOD=90})
+      should contain_file(handler_file).with_content(%r{meta-data/local-hostname})
+      should contain_file(service_file).with_content(%r{KUBECONFIG=/etc/kubernetes/kubeconfig-kubelet})
+      should contain_service('spot-interruption-handler.service').with_ensure('running')
+    end
+  end
+
+  context 'with node name and grace period' do
+    let(:params) { {
+      'node_name'    => 'worker-1.example.com',
+      'grace_period' => 30,
+    } }
+
+    it 'configures the handler' do
+      should contain_file(handler_file).with_content(%r{NODE_NAME=worker-1\.example\.com})
+      should contain_file(handler_file).with_content(%r{GRACE_PERIOD=30})
+    end
+  end
+
+  context 'with ensure absent' do
+    let(:params) { {
+      'ensure' => 'absent',
+    } }
+
+    it 'removes the handler' do
+      should contain_service('spot-interruption-handler.service').with_ensure('stopped')
+      should contain_file(handler_file).with_ensure('absent')
+      should contain_file(service_file).with_ensure('absent')
+    end
+  end
+end
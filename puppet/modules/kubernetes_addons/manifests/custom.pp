@@ -0,0 +1,17 @@
+# Applies the manifests of custom addons configured in the cluster, disabled
+# addons are removed by the addon manager
+class kubernetes_addons::custom(
+  Hash[String, Struct[{
+    ensure    => Enum['present', 'absent'],
+    manifests => Array[String],
+  }]] $addons = {},
+) {
+  require ::kubernetes
+
+  $addons.each |$name, $addon| {
+    kubernetes::apply{"custom-${name}":
+      ensure    => $addon['ensure'],
+      manifests => $addon['manifests'],
+    }
+  }
+}
@@ -0,0 +1,45 @@
+require 'spec_helper'
+describe 'kubernetes_addons::custom' do
+  let(:pre_condition) do
+    "
+      class kubernetes{}
+      define kubernetes::apply(
+        Enum['present', 'absent'] $ensure = 'present',
+        $manifests,
+      ){}
+    "
+  end
+
+  context 'with defaults' do
+    it { should compile }
+    it { should have_kubernetes__apply_resource_count(0) }
+  end
+
+  context 'with custom addons' do
+    let(:params) do
+      {
+        :addons => {
+          'echo' => {
+            'ensure'    => 'present',
+            'manifests' => ["apiVersion: v1\nkind: Namespace\nmetadata:\n  name: echo\n"],
+          },
+          'legacy' => {
+            'ensure'    => 'absent',
+            'manifests' => ["apiVersion: v1\nkind: Namespace\nmetadata:\n  name: legacy\n"],
+          },
+        }
+      }
+    end
+
+    it do
+      should contain_kubernetes__apply('custom-echo').with(
+        'ensure'    => 'present',
+        'manifests' => ["apiVersion: v1\nkind: Namespace\nmetadata:\n  name: echo\n"],
+      )
+    end
+
+    it do
+      should contain_kubernetes__apply('custom-legacy').with_ensure('absent')
+    end
+  end
+end
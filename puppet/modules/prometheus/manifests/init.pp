@@ -23,6 +23,7 @@ class prometheus(
       include ::prometheus::server
       include ::prometheus::blackbox_exporter_etcd
       include ::prometheus::node_exporter
+      include ::prometheus::wing
 
       include ::prometheus::kube_state_metrics
       include ::prometheus::blackbox_exporter
@@ -32,6 +33,7 @@ class prometheus(
       include ::prometheus::server
       include ::prometheus::blackbox_exporter_etcd
       include ::prometheus::node_exporter
+      include ::prometheus::wing
     }
   }
 
@@ -0,0 +1,111 @@
+class prometheus::wing (
+  $port = 9451,
+  $server_port = 9443,
+)
+{
+  include ::prometheus
+
+  # Setup scrapes and rules for the wing agents converging the instances
+  if $::prometheus::role == 'master' {
+    include ::prometheus::server
+    $kubernetes_token_file = $::prometheus::server::kubernetes_token_file
+    $kubernetes_ca_file = $::prometheus::server::kubernetes_ca_file
+
+    prometheus::rule { 'WingConvergeFailed':
+      expr        => 'wing_converge_state{state=~"error|verificationFailed"} == 1',
+      for         => '5m',
+      summary     => '{{$labels.instance}}: Puppet manifest failed to converge',
+      description => '{{$labels.instance}}: wing reports converge state {{$labels.state}}',
+    }
+
+    prometheus::rule { 'WingNotConverged':
+      expr        => 'wing_puppet_last_exit_code != 0',
+      for         => '1h',
+      summary     => '{{$labels.instance}}: Puppet manifest has not converged',
+      description => '{{$labels.instance}}: last puppet apply exited with {{ $value }} for more than an hour',
+    }
+
+    prometheus::rule { 'WingConvergeStuck':
+      expr        => 'wing_converge_state{state="converging"} == 1',
+      for         => '1h',
+      summary     => '{{$labels.instance}}: Puppet manifest is converging for too long',
+      description => '{{$labels.instance}}: wing is converging for more than an hour',
+    }
+
+    # the wing server reports the instances of all clusters of the
+    # environment, only the ones of this cluster are alerted on
+    $cluster_name = $::tarmak::cluster_name
+
+    prometheus::rule { 'WingInstancesFailed':
+      expr        => "sum(wing_instances{cluster=\"${cluster_name}\",state=~\"error|verificationFailed\"}) > 0",
+      for         => '5m',
+      summary     => "${cluster_name}: Instances failed to converge",
+      description => "${cluster_name}: the wing server reports {{ \$value }} instances which failed to converge",
+    }
+
+    prometheus::rule { 'WingInstancesUnknown':
+      expr        => "wing_instances{cluster=\"${cluster_name}\",state=\"unknown\"} > 0",
+      for         => '1h',
+      summary     => "${cluster_name}: Instances have not reported a converge state",
+      description => "${cluster_name}: {{ \$value }} instances have not reported a converge state to the wing server for more than an hour",
+    }
+
+    # scrape the wing server on the bastion, it serves a self-signed
+    # certificate
+    prometheus::scrape_config { 'wing-server':
+      order  =>  142,
+      config => {
+        'static_configs' => [{
+          'targets' => ["bastion.${::tarmak_environment}.${::tarmak::dns_root}:${server_port}"],
+        }],
+        'scheme'         => 'https',
+        'tls_config'     => {
+          'insecure_skip_verify' => true,
+        },
+      }
+    }
+
+    # scrape wing running on etcd nodes
+    prometheus::scrape_config { 'etcd-nodes-wing':
+      order  =>  140,
+      config => {
+        'dns_sd_configs'  => [{
+          'names' => $tarmak::etcd_cluster_exporters,
+        }],
+        'relabel_configs' => [{
+          'source_labels' => ['__address__'],
+          'regex'         => '(.+):(.+)',
+          'target_label'  => '__address__',
+          'replacement'   => "\${1}:${port}",
+        }],
+      }
+    }
+
+    # scrape wing running on every kubernetes node (through api proxy)
+    prometheus::scrape_config { 'kubernetes-nodes-wing':
+      order  =>  141,
+      config => {
+        'kubernetes_sd_configs' => [{
+          'role' => 'node',
+        }],
+        'tls_config'            => {
+          'ca_file' => $kubernetes_ca_file,
+        },
+        'bearer_token_file'     => $kubernetes_token_file,
+        'scheme'                => 'https',
+        'relabel_configs'       => [{
+          'action' => 'labelmap',
+          'regex'  => '__meta_kubernetes_node_label_(.+)',
+          },{
+            'target_label' => '__address__',
+            'replacement'  => 'kubernetes.default.svc:443',
+            }, {
+              'source_labels' => ['__meta_kubernetes_node_name'],
+              'regex'         => '(.+)',
+              'target_label'  => '__metrics_path__',
+              'replacement'   => "/api/v1/nodes/\${1}:${port}/proxy/metrics",
+          }],
+      }
+    }
+  }
+}
@@ -0,0 +1,63 @@
+require 'spec_helper'
+
+describe 'prometheus::wing' do
+  context 'on etcd node' do
+    let(:pre_condition) {[
+      'class tarmak {',
+      "  $role = 'etcd'",
+      '  $etcd_k8s_main_client_port = 1234',
+      '  $etcd_k8s_events_client_port = 1235',
+      '  $etcd_overlay_client_port = 1236',
+      "  $etcd_cluster_exporters = ['etcd-exporters.example.tarmak.local']",
+      '}',
+      'include tarmak',
+    ]}
+
+    it { should contain_class('prometheus') }
+    it { should_not contain_prometheus__scrape_config('etcd-nodes-wing') }
+  end
+
+  context 'on master node' do
+    let(:facts) do
+      { :tarmak_environment => 'example' }
+    end
+
+    let(:pre_condition) {[
+      'class tarmak {',
+      "  $role = 'master'",
+      "  $cluster_name = 'example-cluster'",
+      "  $dns_root = 'tarmak.local'",
+      '  $etcd_k8s_main_client_port = 1234',
+      '  $etcd_k8s_events_client_port = 1235',
+      '  $etcd_overlay_client_port = 1236',
+      "  $etcd_cluster_exporters = ['etcd-exporters.example.tarmak.local']",
+      '}',
+      'include tarmak',
+      'class kubernetes::apiserver{}',
+      'require kubernetes::apiserver',
+    ]}
+
+    it { should contain_class('prometheus::server') }
+    it { should contain_prometheus__rule('WingConvergeFailed') }
+
+    it 'should alert on the instances of the cluster' do
+      should contain_prometheus__rule('WingInstancesFailed').with_expr(/wing_instances\{cluster="example-cluster",/)
+      should contain_prometheus__rule('WingInstancesUnknown').with_expr(/wing_instances\{cluster="example-cluster",state="unknown"\}/)
+    end
+
+    it 'should scrape the wing server' do
+      should contain_prometheus__scrape_config('wing-server').with_config(/bastion\.example\.tarmak\.local:9443/)
+    end
+
+    context 'with custom port' do
+      let :params do
+        { :port => 1234 }
+      end
+
+      it 'should scrape the port' do
+        should contain_prometheus__scrape_config('etcd-nodes-wing').with_config(/:1234/)
+        should contain_prometheus__scrape_config('kubernetes-nodes-wing').with_config(%r{/api/v1/nodes/\$\{1\}:1234/proxy/metrics})
+      end
+    end
+  end
+end
@@ -1,6 +1,17 @@
 class tarmak::fluent_bit(
 ){
   include ::tarmak
+  include ::fluent_bit::params
+
+  # only clusters with loki or s3 sinks use the later version of fluent-bit
+  $_sinks_configs = $::tarmak::fluent_bit_configs.filter |Hash $fluent_bit_config| {
+    $fluent_bit_config['loki'] or $fluent_bit_config['s3']
+  }
+  if $_sinks_configs != [] {
+    class { '::fluent_bit':
+      version => $::fluent_bit::params::sinks_version,
+    }
+  }
 
   $::tarmak::fluent_bit_configs.each |Integer $index, Hash $fluent_bit_config| {
     ::fluent_bit::output{"fluent-bit-output-${index}":
@@ -0,0 +1,144 @@
+resource "azurerm_user_assigned_identity" "bastion" {
+  name                = "${var.environment}-bastion"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+}
+
+# allows to read the wing binary
+resource "azurerm_role_assignment" "bastion_secrets_read" {
+  scope                = "${var.secrets_storage_account_id}"
+  role_definition_name = "Storage Blob Data Reader"
+  principal_id         = "${azurerm_user_assigned_identity.bastion.principal_id}"
+}
+
+# allows wing to publish the SSH host keys of the instance
+resource "azurerm_role_assignment" "bastion_tags" {
+  scope                = "${data.azurerm_resource_group.main.id}"
+  role_definition_name = "Tag Contributor"
+  principal_id         = "${azurerm_user_assigned_identity.bastion.principal_id}"
+}
+
+data "template_file" "bastion_user_data" {
+  template = "${file("${path.module}/templates/bastion_user_data.yaml")}"
+
+  vars {
+    fqdn               = "bastion.${var.private_zone}"
+    tarmak_environment = "${var.environment}"
+
+    # These are only used in the template when running in Wing dev mode
+    wing_binary_path = "${var.secrets_storage_account}.blob.core.windows.net/tarmak/${var.wing_binary_path}"
+    wing_version     = "${var.wing_version}"
+  }
+}
+
+resource "azurerm_public_ip" "bastion" {
+  name                = "${var.environment}-bastion"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+  allocation_method   = "Static"
+  sku                 = "Standard"
+  zones               = ["${element(var.availability_zones, 0)}"]
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+resource "azurerm_network_security_group" "bastion" {
+  name                = "${var.environment}-bastion"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+
+  security_rule {
+    name                       = "ssh"
+    priority                   = 100
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_range     = "22"
+    source_address_prefixes    = ["${var.bastion_admin_cidrs}"]
+    destination_address_prefix = "*"
+  }
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+resource "azurerm_network_interface" "bastion" {
+  name                      = "${var.environment}-bastion"
+  resource_group_name       = "${var.resource_group}"
+  location                  = "${var.region}"
+  network_security_group_id = "${azurerm_network_security_group.bastion.id}"
+
+  ip_configuration {
+    name                          = "primary"
+    subnet_id                     = "${var.public_subnet_id}"
+    private_ip_address_allocation = "Dynamic"
+    public_ip_address_id          = "${azurerm_public_ip.bastion.id}"
+  }
+}
+
+resource "azurerm_virtual_machine" "bastion" {
+  name                          = "${var.environment}-bastion"
+  resource_group_name           = "${var.resource_group}"
+  location                      = "${var.region}"
+  vm_size                       = "${var.bastion_instance_type}"
+  zones                         = ["${element(var.availability_zones, 0)}"]
+  network_interface_ids         = ["${azurerm_network_interface.bastion.id}"]
+  delete_os_disk_on_termination = true
+
+  storage_image_reference {
+    id = "${var.bastion_ami}"
+  }
+
+  storage_os_disk {
+    name              = "${var.environment}-bastion-root"
+    caching           = "ReadWrite"
+    create_option     = "FromImage"
+    managed_disk_type = "Premium_LRS"
+    disk_size_gb      = "${var.bastion_root_size}"
+  }
+
+  os_profile {
+    computer_name  = "bastion"
+    admin_username = "centos"
+    custom_data    = "${data.template_file.bastion_user_data.rendered}"
+  }
+
+  os_profile_linux_config {
+    disable_password_authentication = true
+
+    ssh_keys {
+      path     = "/home/centos/.ssh/authorized_keys"
+      key_data = "${var.ssh_public_key}"
+    }
+  }
+
+  identity {
+    type         = "UserAssigned"
+    identity_ids = ["${azurerm_user_assigned_identity.bastion.id}"]
+  }
+
+  tags {
+    tarmak_environment = "${var.environment}"
+    tarmak_cluster     = "${var.environment}-hub"
+    tarmak_role        = "bastion"
+  }
+
+  depends_on = ["azurerm_role_assignment.bastion_secrets_read"]
+
+  lifecycle {
+    # wing publishes the SSH host keys of the instance as tags
+    ignore_changes = ["tags"]
+  }
+}
+
+resource "azurerm_dns_a_record" "bastion" {
+  name                = "bastion"
+  zone_name           = "${var.private_zone}"
+  resource_group_name = "${var.resource_group}"
+  ttl                 = 180
+  records             = ["${azurerm_network_interface.bastion.private_ip_address}"]
+}
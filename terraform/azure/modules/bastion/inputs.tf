@@ -0,0 +1,41 @@
+variable "name" {}
+
+variable "region" {}
+
+variable "resource_group" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "availability_zones" {
+  type = "list"
+}
+
+variable "public_subnet_id" {}
+
+variable "private_zone" {}
+
+variable "bastion_ami" {}
+
+variable "bastion_instance_type" {}
+
+variable "bastion_root_size" {}
+
+variable "bastion_admin_cidrs" {
+  type = "list"
+}
+
+variable "ssh_public_key" {}
+
+variable "secrets_storage_account" {}
+
+variable "secrets_storage_account_id" {}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
+
+data "azurerm_resource_group" "main" {
+  name = "${var.resource_group}"
+}
@@ -0,0 +1,7 @@
+output "bastion_instance_id" {
+  value = "${azurerm_virtual_machine.bastion.id}"
+}
+
+output "bastion_principal_id" {
+  value = "${azurerm_user_assigned_identity.bastion.principal_id}"
+}
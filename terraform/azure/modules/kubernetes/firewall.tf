@@ -0,0 +1,25 @@
+# security group attached to the network interfaces of the masters, the
+# default rules allow traffic within the virtual network and from Azure's load
+# balancer health probes
+resource "azurerm_network_security_group" "master" {
+  name                = "${data.template_file.stack_name.rendered}-master"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+
+  security_rule {
+    name                       = "api-admin"
+    priority                   = 100
+    direction                  = "Inbound"
+    access                     = "Allow"
+    protocol                   = "Tcp"
+    source_port_range          = "*"
+    destination_port_range     = "6443"
+    source_address_prefixes    = ["${var.api_admin_cidrs}"]
+    destination_address_prefix = "*"
+  }
+
+  tags {
+    tarmak_environment = "${var.environment}"
+    tarmak_cluster     = "${data.template_file.stack_name.rendered}"
+  }
+}
@@ -0,0 +1,58 @@
+variable "name" {}
+
+variable "region" {}
+
+variable "resource_group" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "vault_cluster_name" {}
+
+variable "ssh_public_key" {}
+
+# data.terraform_remote_state.hub_state.secrets_storage_account
+variable "secrets_storage_account" {}
+
+# data.terraform_remote_state.hub_state.backups_storage_account
+variable "backups_storage_account" {}
+
+variable "secrets_storage_account_id" {}
+
+variable "backups_storage_account_id" {}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
+
+data "azurerm_resource_group" "main" {
+  name = "${var.resource_group}"
+}
+
+variable "internal_fqdns" {
+  type = "list"
+}
+
+variable "vault_kms_key_id" {}
+
+variable "vault_unseal_key_name" {}
+
+# template variables
+variable "availability_zones" {
+  type = "list"
+}
+
+variable "api_admin_cidrs" {
+  type = "list"
+}
+
+variable "private_subnet_id" {}
+
+variable "private_zone" {}
+
+variable "public_zone" {}
+
+variable "vault_ca" {}
+
+variable "vault_url" {}
@@ -0,0 +1,6 @@
+resource "azurerm_dns_zone" "private" {
+  name                           = "${var.private_zone}"
+  resource_group_name            = "${var.resource_group}"
+  zone_type                      = "Private"
+  resolution_virtual_network_ids = ["${azurerm_virtual_network.main.id}"]
+}
@@ -0,0 +1,62 @@
+resource "azurerm_virtual_network" "main" {
+  name                = "${data.template_file.stack_name.rendered}"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+  address_space       = ["${var.network}"]
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+# subnets in Azure span all zones of a region, so a single private and public
+# subnet is sufficient. Instances without a public IP address reach the
+# internet through Azure's default outbound access.
+resource "azurerm_subnet" "private" {
+  name                      = "${data.template_file.stack_name.rendered}-private"
+  resource_group_name       = "${var.resource_group}"
+  virtual_network_name      = "${azurerm_virtual_network.main.name}"
+  address_prefix            = "${cidrsubnet(var.network, 1, 0)}"
+  network_security_group_id = "${azurerm_network_security_group.private.id}"
+  service_endpoints         = ["Microsoft.Storage", "Microsoft.KeyVault"]
+}
+
+resource "azurerm_subnet" "public" {
+  name                      = "${data.template_file.stack_name.rendered}-public"
+  resource_group_name       = "${var.resource_group}"
+  virtual_network_name      = "${azurerm_virtual_network.main.name}"
+  address_prefix            = "${cidrsubnet(var.network, 1, 1)}"
+  network_security_group_id = "${azurerm_network_security_group.public.id}"
+}
+
+# the default security rules allow all traffic within the virtual network and
+# from Azure's load balancers, everything else from outside is denied
+resource "azurerm_network_security_group" "private" {
+  name                = "${data.template_file.stack_name.rendered}-private"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+resource "azurerm_network_security_group" "public" {
+  name                = "${data.template_file.stack_name.rendered}-public"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+resource "azurerm_subnet_network_security_group_association" "private" {
+  subnet_id                 = "${azurerm_subnet.private.id}"
+  network_security_group_id = "${azurerm_network_security_group.private.id}"
+}
+
+resource "azurerm_subnet_network_security_group_association" "public" {
+  subnet_id                 = "${azurerm_subnet.public.id}"
+  network_security_group_id = "${azurerm_network_security_group.public.id}"
+}
@@ -0,0 +1,27 @@
+output "availability_zones" {
+  value = ["${var.availability_zones}"]
+}
+
+output "vnet_id" {
+  value = "${azurerm_virtual_network.main.id}"
+}
+
+output "private_subnet_id" {
+  value = "${azurerm_subnet.private.id}"
+}
+
+output "private_subnet_cidr" {
+  value = "${azurerm_subnet.private.address_prefix}"
+}
+
+output "public_subnet_id" {
+  value = "${azurerm_subnet.public.id}"
+}
+
+output "public_security_group_name" {
+  value = "${azurerm_network_security_group.public.name}"
+}
+
+output "private_zone" {
+  value = "${azurerm_dns_zone.private.name}"
+}
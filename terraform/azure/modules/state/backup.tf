@@ -0,0 +1,40 @@
+resource "azurerm_storage_account" "backups" {
+  name                      = "${var.backups_storage_account}"
+  resource_group_name       = "${var.resource_group}"
+  location                  = "${var.region}"
+  account_kind              = "StorageV2"
+  account_tier              = "Standard"
+  account_replication_type  = "LRS"
+  enable_https_traffic_only = true
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+resource "azurerm_storage_container" "backups" {
+  name                  = "backups"
+  resource_group_name   = "${var.resource_group}"
+  storage_account_name  = "${azurerm_storage_account.backups.name}"
+  container_access_type = "private"
+}
+
+resource "azurerm_storage_management_policy" "backups" {
+  storage_account_id = "${azurerm_storage_account.backups.id}"
+
+  rule {
+    name    = "backups"
+    enabled = true
+
+    filters {
+      blob_types = ["blockBlob"]
+    }
+
+    actions {
+      base_blob {
+        tier_to_cool_after_days_since_modification_greater_than = "${var.backup_transition_cool_days}"
+        delete_after_days_since_modification_greater_than       = "${var.backup_expiration_days}"
+      }
+    }
+  }
+}
@@ -0,0 +1,10 @@
+resource "azurerm_dns_txt_record" "star-txt" {
+  name                = "*._tarmak.${var.environment}"
+  zone_name           = "${var.public_zone}"
+  resource_group_name = "${var.public_zone_resource_group}"
+  ttl                 = 300
+
+  record {
+    value = "tarmak delegation works"
+  }
+}
@@ -0,0 +1,29 @@
+variable "name" {}
+
+variable "region" {}
+
+variable "resource_group" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "public_zone" {}
+
+variable "public_zone_resource_group" {}
+
+variable "secrets_storage_account" {}
+
+variable "backups_storage_account" {}
+
+variable "backup_expiration_days" {
+  default = 365
+}
+
+variable "backup_transition_cool_days" {
+  default = 90
+}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
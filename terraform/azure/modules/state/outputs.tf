@@ -0,0 +1,31 @@
+output "stack_name" {
+  value = "${data.template_file.stack_name.rendered}"
+}
+
+output "environment" {
+  value = "${var.environment}"
+}
+
+output "public_zone" {
+  value = "${var.public_zone}"
+}
+
+output "public_zone_resource_group" {
+  value = "${var.public_zone_resource_group}"
+}
+
+output "secrets_storage_account" {
+  value = "${azurerm_storage_account.secrets.name}"
+}
+
+output "backups_storage_account" {
+  value = "${azurerm_storage_account.backups.name}"
+}
+
+output "secrets_storage_account_id" {
+  value = "${azurerm_storage_account.secrets.id}"
+}
+
+output "backups_storage_account_id" {
+  value = "${azurerm_storage_account.backups.id}"
+}
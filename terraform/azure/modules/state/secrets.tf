@@ -0,0 +1,20 @@
+resource "azurerm_storage_account" "secrets" {
+  name                      = "${var.secrets_storage_account}"
+  resource_group_name       = "${var.resource_group}"
+  location                  = "${var.region}"
+  account_kind              = "StorageV2"
+  account_tier              = "Standard"
+  account_replication_type  = "LRS"
+  enable_https_traffic_only = true
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+resource "azurerm_storage_container" "secrets" {
+  name                  = "tarmak"
+  resource_group_name   = "${var.resource_group}"
+  storage_account_name  = "${azurerm_storage_account.secrets.name}"
+  container_access_type = "private"
+}
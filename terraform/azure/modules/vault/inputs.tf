@@ -0,0 +1,65 @@
+variable "name" {}
+
+variable "region" {}
+
+variable "resource_group" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "availability_zones" {
+  type = "list"
+}
+
+variable "private_subnet_id" {}
+
+variable "private_subnet_cidr" {}
+
+variable "private_zone" {}
+
+variable "ssh_public_key" {}
+
+# data.terraform_remote_state.state.secrets_storage_account
+variable "secrets_storage_account" {}
+
+# data.terraform_remote_state.state.backups_storage_account
+variable "backups_storage_account" {}
+
+variable "secrets_storage_account_id" {}
+
+variable "backups_storage_account_id" {}
+
+variable "bastion_instance_id" {}
+
+variable "vault_cluster_name" {}
+
+variable "vault_key_vault_name" {}
+
+variable "consul_version" {}
+
+variable "vault_version" {}
+
+variable "vault_root_size" {}
+
+variable "vault_data_size" {}
+
+variable "vault_min_instance_count" {}
+
+variable "vault_instance_type" {}
+
+variable "vault_ami" {}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
+
+data "azurerm_resource_group" "main" {
+  name = "${var.resource_group}"
+}
+
+data "azurerm_client_config" "current" {}
+
+locals {
+  vault_unseal_key_name = "vault-${var.environment}-"
+}
@@ -0,0 +1,20 @@
+output "vault_ca" {
+  value = "${element(concat(tls_self_signed_cert.ca.*.cert_pem, list("")), 0)}"
+}
+
+output "vault_url" {
+  value = "https://vault.${var.private_zone}:8200"
+}
+
+# on Azure the unseal keys are stored as secrets of the key vault
+output "vault_kms_key_id" {
+  value = "${azurerm_key_vault.vault.name}"
+}
+
+output "vault_unseal_key_name" {
+  value = "${local.vault_unseal_key_name}"
+}
+
+output "instance_fqdns" {
+  value = ["${local.instance_fqdns}"]
+}
@@ -0,0 +1,28 @@
+resource "azurerm_storage_blob" "node-keys" {
+  count                  = "${var.vault_min_instance_count}"
+  name                   = "vault-${var.environment}/cert-${count.index+1}-key.pem"
+  resource_group_name    = "${var.resource_group}"
+  storage_account_name   = "${var.secrets_storage_account}"
+  storage_container_name = "tarmak"
+  type                   = "block"
+  source_content         = "${element(tls_private_key.vault.*.private_key_pem, count.index)}"
+}
+
+resource "azurerm_storage_blob" "node-certs" {
+  count                  = "${var.vault_min_instance_count}"
+  name                   = "vault-${var.environment}/cert-${count.index+1}.pem"
+  resource_group_name    = "${var.resource_group}"
+  storage_account_name   = "${var.secrets_storage_account}"
+  storage_container_name = "tarmak"
+  type                   = "block"
+  source_content         = "${element(tls_locally_signed_cert.vault.*.cert_pem, count.index)}"
+}
+
+resource "azurerm_storage_blob" "ca-cert" {
+  name                   = "vault-${var.environment}/ca.pem"
+  resource_group_name    = "${var.resource_group}"
+  storage_account_name   = "${var.secrets_storage_account}"
+  storage_container_name = "tarmak"
+  type                   = "block"
+  source_content         = "${tls_self_signed_cert.ca.cert_pem}"
+}
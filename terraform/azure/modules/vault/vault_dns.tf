@@ -0,0 +1,22 @@
+resource "azurerm_dns_a_record" "per-instance" {
+  count               = "${var.vault_min_instance_count}"
+  name                = "vault-${count.index + 1}"
+  zone_name           = "${var.private_zone}"
+  resource_group_name = "${var.resource_group}"
+  ttl                 = 180
+  records             = ["${element(azurerm_network_interface.vault.*.private_ip_address, count.index)}"]
+}
+
+resource "azurerm_dns_a_record" "endpoint" {
+  count               = 1
+  name                = "vault"
+  zone_name           = "${var.private_zone}"
+  resource_group_name = "${var.resource_group}"
+  ttl                 = 180
+  records             = ["${azurerm_network_interface.vault.*.private_ip_address}"]
+}
+
+locals {
+  # Azure DNS record names are relative to the zone
+  instance_fqdns = ["${formatlist("%s.%s", azurerm_dns_a_record.per-instance.*.name, var.private_zone)}"]
+}
@@ -0,0 +1,25 @@
+resource "azurerm_user_assigned_identity" "vault" {
+  name                = "${var.environment}-vault"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+}
+
+# vault reads its TLS material and manifests from the secrets storage account
+resource "azurerm_role_assignment" "vault_secrets" {
+  scope                = "${var.secrets_storage_account_id}"
+  role_definition_name = "Storage Blob Data Reader"
+  principal_id         = "${azurerm_user_assigned_identity.vault.principal_id}"
+}
+
+resource "azurerm_role_assignment" "vault_backups" {
+  scope                = "${var.backups_storage_account_id}"
+  role_definition_name = "Storage Blob Data Contributor"
+  principal_id         = "${azurerm_user_assigned_identity.vault.principal_id}"
+}
+
+# allows wing to publish the SSH host keys of the instances
+resource "azurerm_role_assignment" "vault_tags" {
+  scope                = "${data.azurerm_resource_group.main.id}"
+  role_definition_name = "Tag Contributor"
+  principal_id         = "${azurerm_user_assigned_identity.vault.principal_id}"
+}
@@ -0,0 +1,32 @@
+# the key vault stores the unseal keys and the root token of vault
+resource "azurerm_key_vault" "vault" {
+  name                = "${var.vault_key_vault_name}"
+  resource_group_name = "${var.resource_group}"
+  location            = "${var.region}"
+  tenant_id           = "${data.azurerm_client_config.current.tenant_id}"
+
+  sku {
+    name = "standard"
+  }
+
+  tags {
+    tarmak_environment = "${var.environment}"
+  }
+}
+
+# tarmak reads the root token when setting up vault
+resource "azurerm_key_vault_access_policy" "tarmak" {
+  key_vault_id = "${azurerm_key_vault.vault.id}"
+  tenant_id    = "${data.azurerm_client_config.current.tenant_id}"
+  object_id    = "${data.azurerm_client_config.current.object_id}"
+
+  secret_permissions = ["get", "list", "set", "delete"]
+}
+
+resource "azurerm_key_vault_access_policy" "vault" {
+  key_vault_id = "${azurerm_key_vault.vault.id}"
+  tenant_id    = "${azurerm_user_assigned_identity.vault.tenant_id}"
+  object_id    = "${azurerm_user_assigned_identity.vault.principal_id}"
+
+  secret_permissions = ["get", "list", "set"]
+}
@@ -0,0 +1,87 @@
+resource "google_service_account" "bastion" {
+  account_id   = "${substr(format("%s-bastion", var.environment), 0, min(30, length(format("%s-bastion", var.environment))))}"
+  display_name = "Bastion of environment ${var.environment}"
+}
+
+# allows to read the wing binary and to publish its host keys
+resource "google_storage_bucket_iam_member" "bastion_secrets_read" {
+  bucket = "${var.secrets_bucket}"
+  role   = "roles/storage.objectViewer"
+  member = "serviceAccount:${google_service_account.bastion.email}"
+}
+
+data "template_file" "bastion_user_data" {
+  template = "${file("${path.module}/templates/bastion_user_data.yaml")}"
+
+  vars {
+    fqdn               = "bastion.${var.private_zone}"
+    tarmak_environment = "${var.environment}"
+
+    # These are only used in the template when running in Wing dev mode
+    wing_binary_path = "${var.secrets_bucket}/${var.wing_binary_path}"
+    wing_version     = "${var.wing_version}"
+  }
+}
+
+resource "google_compute_instance" "bastion" {
+  name         = "${var.environment}-bastion"
+  machine_type = "${var.bastion_instance_type}"
+  zone         = "${element(var.availability_zones, 0)}"
+
+  boot_disk {
+    initialize_params {
+      image = "${var.bastion_ami}"
+      size  = "${var.bastion_root_size}"
+      type  = "pd-ssd"
+    }
+  }
+
+  network_interface {
+    subnetwork = "${var.public_subnet}"
+
+    access_config {}
+  }
+
+  metadata {
+    user-data    = "${data.template_file.bastion_user_data.rendered}"
+    tarmak-roles = "bastion"
+  }
+
+  labels {
+    tarmak_environment = "${var.environment}"
+    tarmak_cluster     = "${var.environment}-hub"
+    tarmak_role        = "bastion"
+  }
+
+  tags = ["${var.environment}-bastion"]
+
+  service_account {
+    email  = "${google_service_account.bastion.email}"
+    scopes = ["cloud-platform"]
+  }
+
+  lifecycle {
+    ignore_changes = ["metadata.tarmak-ssh-host-keys"]
+  }
+}
+
+resource "google_compute_firewall" "bastion_ssh" {
+  name    = "${var.environment}-bastion-ssh"
+  network = "${var.network_self_link}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+
+  source_ranges = ["${var.bastion_admin_cidrs}"]
+  target_tags   = ["${var.environment}-bastion"]
+}
+
+resource "google_dns_record_set" "bastion" {
+  managed_zone = "${var.private_managed_zone}"
+  name         = "bastion.${var.private_zone}."
+  type         = "A"
+  ttl          = "180"
+  rrdatas      = ["${google_compute_instance.bastion.network_interface.0.network_ip}"]
+}
@@ -0,0 +1,33 @@
+variable "name" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "availability_zones" {
+  type = "list"
+}
+
+variable "network_self_link" {}
+
+variable "public_subnet" {}
+
+variable "private_zone" {}
+
+variable "private_managed_zone" {}
+
+variable "bastion_ami" {}
+
+variable "bastion_instance_type" {}
+
+variable "bastion_root_size" {}
+
+variable "bastion_admin_cidrs" {
+  type = "list"
+}
+
+variable "secrets_bucket" {}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
@@ -0,0 +1,7 @@
+output "bastion_instance_id" {
+  value = "${google_compute_instance.bastion.name}"
+}
+
+output "bastion_service_account" {
+  value = "${google_service_account.bastion.email}"
+}
@@ -0,0 +1,26 @@
+resource "google_compute_firewall" "api_admin" {
+  name    = "${data.template_file.stack_name.rendered}-api-admin"
+  network = "${var.network_self_link}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["6443"]
+  }
+
+  source_ranges = ["${var.api_admin_cidrs}"]
+  target_tags   = ["${data.template_file.stack_name.rendered}-master"]
+}
+
+# allow Google's load balancer health checks to reach the instances
+resource "google_compute_firewall" "health_checks" {
+  name    = "${data.template_file.stack_name.rendered}-health-checks"
+  network = "${var.network_self_link}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["6443"]
+  }
+
+  source_ranges = ["35.191.0.0/16", "130.211.0.0/22"]
+  target_tags   = ["${data.template_file.stack_name.rendered}-master"]
+}
@@ -0,0 +1,54 @@
+variable "name" {}
+
+variable "google_project" {}
+
+variable "region" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "vault_cluster_name" {}
+
+# data.terraform_remote_state.hub_state.secrets_bucket
+variable "secrets_bucket" {}
+
+# data.terraform_remote_state.hub_state.backups_bucket
+variable "backups_bucket" {}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
+
+variable "internal_fqdns" {
+  type = "list"
+}
+
+variable "vault_kms_key_id" {}
+
+variable "vault_unseal_key_name" {}
+
+# template variables
+variable "availability_zones" {
+  type = "list"
+}
+
+variable "api_admin_cidrs" {
+  type = "list"
+}
+
+variable "network_self_link" {}
+
+variable "private_subnet" {}
+
+variable "private_zone" {}
+
+variable "private_managed_zone" {}
+
+variable "public_zone" {}
+
+variable "public_managed_zone" {}
+
+variable "vault_ca" {}
+
+variable "vault_url" {}
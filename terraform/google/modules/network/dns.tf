@@ -0,0 +1,11 @@
+resource "google_dns_managed_zone" "private" {
+  name       = "${data.template_file.stack_name.rendered}-private"
+  dns_name   = "${var.private_zone}."
+  visibility = "private"
+
+  private_visibility_config {
+    networks {
+      network_url = "${google_compute_network.main.self_link}"
+    }
+  }
+}
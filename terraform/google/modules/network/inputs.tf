@@ -0,0 +1,19 @@
+variable "name" {}
+
+variable "network" {}
+
+variable "region" {}
+
+variable "availability_zones" {
+  type = "list"
+}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "private_zone" {}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
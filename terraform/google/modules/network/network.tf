@@ -0,0 +1,59 @@
+resource "google_compute_network" "main" {
+  name                    = "${data.template_file.stack_name.rendered}"
+  auto_create_subnetworks = false
+}
+
+# subnets in Compute Engine span all zones of a region, so a single private
+# and public subnet is sufficient
+resource "google_compute_subnetwork" "private" {
+  name                     = "${data.template_file.stack_name.rendered}-private"
+  ip_cidr_range            = "${cidrsubnet(var.network, 1, 0)}"
+  region                   = "${var.region}"
+  network                  = "${google_compute_network.main.self_link}"
+  private_ip_google_access = true
+}
+
+resource "google_compute_subnetwork" "public" {
+  name          = "${data.template_file.stack_name.rendered}-public"
+  ip_cidr_range = "${cidrsubnet(var.network, 1, 1)}"
+  region        = "${var.region}"
+  network       = "${google_compute_network.main.self_link}"
+}
+
+resource "google_compute_router" "main" {
+  name    = "${data.template_file.stack_name.rendered}"
+  region  = "${var.region}"
+  network = "${google_compute_network.main.self_link}"
+}
+
+resource "google_compute_router_nat" "private" {
+  name                               = "${data.template_file.stack_name.rendered}-private"
+  router                             = "${google_compute_router.main.name}"
+  region                             = "${var.region}"
+  nat_ip_allocate_option             = "AUTO_ONLY"
+  source_subnetwork_ip_ranges_to_nat = "LIST_OF_SUBNETWORKS"
+
+  subnetwork {
+    name                    = "${google_compute_subnetwork.private.self_link}"
+    source_ip_ranges_to_nat = ["ALL_IP_RANGES"]
+  }
+}
+
+resource "google_compute_firewall" "internal" {
+  name    = "${data.template_file.stack_name.rendered}-internal"
+  network = "${google_compute_network.main.self_link}"
+
+  allow {
+    protocol = "icmp"
+  }
+
+  allow {
+    protocol = "tcp"
+  }
+
+  allow {
+    protocol = "udp"
+  }
+
+  source_ranges = ["${var.network}"]
+}
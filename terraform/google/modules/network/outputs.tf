@@ -0,0 +1,27 @@
+output "availability_zones" {
+  value = ["${var.availability_zones}"]
+}
+
+output "network_self_link" {
+  value = "${google_compute_network.main.self_link}"
+}
+
+output "private_subnet_self_link" {
+  value = "${google_compute_subnetwork.private.self_link}"
+}
+
+output "private_subnet_cidr" {
+  value = "${google_compute_subnetwork.private.ip_cidr_range}"
+}
+
+output "public_subnet_self_link" {
+  value = "${google_compute_subnetwork.public.self_link}"
+}
+
+output "private_zone" {
+  value = "${var.private_zone}"
+}
+
+output "private_managed_zone" {
+  value = "${google_dns_managed_zone.private.name}"
+}
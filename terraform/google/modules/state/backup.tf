@@ -0,0 +1,31 @@
+resource "google_storage_bucket" "backups" {
+  name          = "${var.bucket_prefix}${var.environment}-${var.region}-backups"
+  location      = "${var.region}"
+  storage_class = "REGIONAL"
+  force_destroy = "true"
+
+  lifecycle_rule {
+    action {
+      type          = "SetStorageClass"
+      storage_class = "COLDLINE"
+    }
+
+    condition {
+      age = "${var.backup_transition_coldline_days}"
+    }
+  }
+
+  lifecycle_rule {
+    action {
+      type = "Delete"
+    }
+
+    condition {
+      age = "${var.backup_expiration_days}"
+    }
+  }
+
+  labels {
+    tarmak_environment = "${var.environment}"
+  }
+}
@@ -0,0 +1,11 @@
+data "google_dns_managed_zone" "public" {
+  name = "${var.public_managed_zone}"
+}
+
+resource "google_dns_record_set" "star-txt" {
+  managed_zone = "${data.google_dns_managed_zone.public.name}"
+  name         = "*._tarmak.${var.environment}.${data.google_dns_managed_zone.public.dns_name}"
+  type         = "TXT"
+  ttl          = "300"
+  rrdatas      = ["\"tarmak delegation works\""]
+}
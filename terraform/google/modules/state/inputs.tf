@@ -0,0 +1,27 @@
+variable "name" {}
+
+variable "google_project" {}
+
+variable "region" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "public_zone" {}
+
+variable "public_managed_zone" {}
+
+variable "bucket_prefix" {}
+
+variable "backup_expiration_days" {
+  default = 365
+}
+
+variable "backup_transition_coldline_days" {
+  default = 90
+}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
@@ -0,0 +1,27 @@
+output "stack_name" {
+  value = "${data.template_file.stack_name.rendered}"
+}
+
+output "environment" {
+  value = "${var.environment}"
+}
+
+output "public_zone" {
+  value = "${var.public_zone}"
+}
+
+output "public_managed_zone" {
+  value = "${var.public_managed_zone}"
+}
+
+output "bucket_prefix" {
+  value = "${var.bucket_prefix}"
+}
+
+output "secrets_bucket" {
+  value = "${google_storage_bucket.secrets.name}"
+}
+
+output "backups_bucket" {
+  value = "${google_storage_bucket.backups.name}"
+}
@@ -0,0 +1,14 @@
+resource "google_storage_bucket" "secrets" {
+  name          = "${var.bucket_prefix}${var.environment}-${var.region}-secrets"
+  location      = "${var.region}"
+  storage_class = "REGIONAL"
+  force_destroy = "true"
+
+  versioning {
+    enabled = true
+  }
+
+  labels {
+    tarmak_environment = "${var.environment}"
+  }
+}
@@ -0,0 +1,55 @@
+variable "name" {}
+
+variable "google_project" {}
+
+variable "region" {}
+
+variable "environment" {}
+
+variable "stack_name_prefix" {}
+
+variable "availability_zones" {
+  type = "list"
+}
+
+variable "network_self_link" {}
+
+variable "private_subnet" {}
+
+variable "private_subnet_cidr" {}
+
+variable "private_zone" {}
+
+variable "private_managed_zone" {}
+
+# data.terraform_remote_state.state.secrets_bucket
+variable "secrets_bucket" {}
+
+# data.terraform_remote_state.state.backups_bucket
+variable "backups_bucket" {}
+
+variable "bastion_instance_id" {}
+
+variable "vault_cluster_name" {}
+
+variable "consul_version" {}
+
+variable "vault_version" {}
+
+variable "vault_root_size" {}
+
+variable "vault_data_size" {}
+
+variable "vault_min_instance_count" {}
+
+variable "vault_instance_type" {}
+
+variable "vault_ami" {}
+
+data "template_file" "stack_name" {
+  template = "${var.stack_name_prefix}${var.environment}-${var.name}"
+}
+
+locals {
+  vault_unseal_key_name = "vault-${var.environment}-"
+}
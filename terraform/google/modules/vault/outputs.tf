@@ -0,0 +1,20 @@
+output "vault_ca" {
+  value = "${element(concat(tls_self_signed_cert.ca.*.cert_pem, list("")), 0)}"
+}
+
+output "vault_url" {
+  value = "https://vault.${var.private_zone}:8200"
+}
+
+# on Google the unseal keys are stored in the secrets bucket
+output "vault_kms_key_id" {
+  value = "${var.secrets_bucket}"
+}
+
+output "vault_unseal_key_name" {
+  value = "${local.vault_unseal_key_name}"
+}
+
+output "instance_fqdns" {
+  value = ["${local.instance_fqdns}"]
+}
@@ -0,0 +1,19 @@
+resource "google_storage_bucket_object" "node-keys" {
+  count   = "${var.vault_min_instance_count}"
+  name    = "vault-${var.environment}/cert-${count.index+1}-key.pem"
+  bucket  = "${var.secrets_bucket}"
+  content = "${element(tls_private_key.vault.*.private_key_pem, count.index)}"
+}
+
+resource "google_storage_bucket_object" "node-certs" {
+  count   = "${var.vault_min_instance_count}"
+  name    = "vault-${var.environment}/cert-${count.index+1}.pem"
+  bucket  = "${var.secrets_bucket}"
+  content = "${element(tls_locally_signed_cert.vault.*.cert_pem, count.index)}"
+}
+
+resource "google_storage_bucket_object" "ca-cert" {
+  name    = "vault-${var.environment}/ca.pem"
+  bucket  = "${var.secrets_bucket}"
+  content = "${tls_self_signed_cert.ca.cert_pem}"
+}
@@ -0,0 +1,22 @@
+resource "google_dns_record_set" "per-instance" {
+  count        = "${var.vault_min_instance_count}"
+  managed_zone = "${var.private_managed_zone}"
+  name         = "vault-${count.index + 1}.${var.private_zone}."
+  type         = "A"
+  ttl          = "180"
+  rrdatas      = ["${element(google_compute_instance.vault.*.network_interface.0.network_ip, count.index)}"]
+}
+
+resource "google_dns_record_set" "endpoint" {
+  count        = 1
+  managed_zone = "${var.private_managed_zone}"
+  name         = "vault.${var.private_zone}."
+  type         = "A"
+  ttl          = "180"
+  rrdatas      = ["${google_compute_instance.vault.*.network_interface.0.network_ip}"]
+}
+
+locals {
+  # Cloud DNS record names are absolute and end with a dot
+  instance_fqdns = ["${split(",", replace(join(",", google_dns_record_set.per-instance.*.name), "/\\.(,|$)/", "$1"))}"]
+}
@@ -0,0 +1,12 @@
+resource "google_compute_firewall" "vault" {
+  name    = "${var.environment}-vault"
+  network = "${var.network_self_link}"
+
+  allow {
+    protocol = "tcp"
+    ports    = ["8200"]
+  }
+
+  source_ranges = ["${var.private_subnet_cidr}"]
+  target_tags   = ["${var.environment}-vault"]
+}
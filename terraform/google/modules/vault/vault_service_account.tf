@@ -0,0 +1,18 @@
+resource "google_service_account" "vault" {
+  account_id   = "${substr(format("%s-vault", var.environment), 0, min(30, length(format("%s-vault", var.environment))))}"
+  display_name = "Vault of environment ${var.environment}"
+}
+
+# vault reads its TLS material and manifests from and stores the unseal keys
+# in the secrets bucket
+resource "google_storage_bucket_iam_member" "vault_secrets" {
+  bucket = "${var.secrets_bucket}"
+  role   = "roles/storage.objectAdmin"
+  member = "serviceAccount:${google_service_account.vault.email}"
+}
+
+resource "google_storage_bucket_iam_member" "vault_backups" {
+  bucket = "${var.backups_bucket}"
+  role   = "roles/storage.objectCreator"
+  member = "serviceAccount:${google_service_account.vault.email}"
+}